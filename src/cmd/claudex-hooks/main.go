@@ -1,20 +1,32 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"strconv"
+	"path/filepath"
+	"time"
 
 	"claudex/internal/doc"
 	"claudex/internal/hooks/notification"
 	"claudex/internal/hooks/posttooluse"
 	"claudex/internal/hooks/pretooluse"
 	"claudex/internal/hooks/sessionend"
+	"claudex/internal/hooks/sessionstart"
 	"claudex/internal/hooks/shared"
+	"claudex/internal/hooks/stop"
 	"claudex/internal/hooks/subagent"
+	"claudex/internal/hooks/trigger"
+	"claudex/internal/hooks/userprompt"
 	"claudex/internal/notify"
+	"claudex/internal/services/clock"
 	"claudex/internal/services/commander"
+	"claudex/internal/services/config"
 	"claudex/internal/services/env"
+	"claudex/internal/services/jobs"
+	"claudex/internal/services/llm"
+	"claudex/internal/services/lock"
+	"claudex/internal/services/paths"
 
 	"github.com/spf13/afero"
 )
@@ -22,7 +34,7 @@ import (
 func main() {
 	if len(os.Args) < 2 {
 		fmt.Fprintf(os.Stderr, "Usage: claudex-hooks <command>\n")
-		fmt.Fprintf(os.Stderr, "Commands: notification, pre-tool-use, post-tool-use, auto-doc, doc-update, session-end, subagent-stop\n")
+		fmt.Fprintf(os.Stderr, "Commands: notification, pre-tool-use, post-tool-use, auto-doc, doc-update, job-worker, session-start, user-prompt-submit, pre-compact, stop, session-end, subagent-stop\n")
 		os.Exit(1)
 	}
 
@@ -53,12 +65,22 @@ func main() {
 		err = handlePostToolUse(logger, parser, builder)
 	case "auto-doc":
 		err = handleAutoDoc(fs, cmdr, environ, logger, parser, builder)
+	case "session-start":
+		err = handleSessionStart(fs, environ, logger, parser, builder)
+	case "user-prompt-submit":
+		err = handleUserPromptSubmit(environ, logger, parser)
+	case "pre-compact":
+		err = handlePreCompact(fs, cmdr, environ, logger, parser)
+	case "stop":
+		err = handleStop(fs, cmdr, environ, logger, parser)
 	case "session-end":
 		err = handleSessionEnd(fs, cmdr, environ, logger, parser, builder)
 	case "subagent-stop":
 		err = handleSubagentStop(fs, cmdr, environ, logger, parser, builder)
 	case "doc-update":
 		err = handleDocUpdate(fs, cmdr, environ, logger, parser)
+	case "job-worker":
+		err = handleJobWorker(fs, environ, logger, os.Args[2:])
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", cmd)
 		os.Exit(1)
@@ -67,6 +89,10 @@ func main() {
 	if err != nil {
 		_ = logger.LogError(fmt.Errorf("%s handler error: %w", cmd, err))
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		// The job worker retries a failed doc update unless told the input cannot succeed
+		if errors.Is(err, doc.ErrValidationFailed) {
+			os.Exit(jobs.ExitNoRetry)
+		}
 		os.Exit(1)
 	}
 }
@@ -98,12 +124,7 @@ func handlePreToolUse(fs afero.Fs, environ env.Environment, logger *shared.Logge
 	}
 
 	handler := pretooluse.NewHandler(fs, environ, logger)
-	output, err := handler.Handle(input)
-	if err != nil {
-		return err
-	}
-
-	return builder.BuildCustom(*output)
+	return handler.HandleFromBuilder(input, builder)
 }
 
 // handlePostToolUse processes post-tool-use hook events
@@ -129,26 +150,73 @@ func handleAutoDoc(fs afero.Fs, cmdr commander.Commander, environ env.Environmen
 		return err
 	}
 
-	// Create documentation updater
-	updater := doc.NewUpdater(fs, cmdr, environ)
+	// Create documentation updater (RunBackground only - the detached doc-update process calls the model)
+	updater := doc.NewUpdater(fs, cmdr, environ, nil)
 
-	// Read frequency from environment (default 5)
-	frequency := 5
-	if freqStr := environ.Get("CLAUDEX_AUTODOC_FREQUENCY"); freqStr != "" {
-		if freq, err := strconv.Atoi(freqStr); err == nil && freq > 0 {
-			frequency = freq
-		}
+	handler := posttooluse.NewAutoDocHandler(fs, environ, updater, logger)
+	output, err := handler.Handle(input)
+	if err != nil {
+		return err
 	}
 
-	handler := posttooluse.NewAutoDocHandler(fs, environ, updater, logger, frequency)
-	output, err := handler.Handle(input)
+	return builder.BuildCustom(*output)
+}
+
+// handleSessionStart processes session-start hook events
+func handleSessionStart(fs afero.Fs, environ env.Environment, logger *shared.Logger, parser *shared.Parser, builder *shared.Builder) error {
+	input, err := parser.ParseSessionStart()
 	if err != nil {
 		return err
 	}
 
+	handler := sessionstart.NewHandler(fs, environ, logger)
+	output, err := handler.Handle(input)
+	if err != nil || output == nil {
+		return err
+	}
+
 	return builder.BuildCustom(*output)
 }
 
+// handleUserPromptSubmit processes user-prompt-submit hook events
+func handleUserPromptSubmit(environ env.Environment, logger *shared.Logger, parser *shared.Parser) error {
+	input, err := parser.ParseUserPromptSubmit()
+	if err != nil {
+		return err
+	}
+
+	handler := userprompt.NewHandler(environ, logger)
+	return handler.Handle(input)
+}
+
+// handlePreCompact processes pre-compact hook events
+func handlePreCompact(fs afero.Fs, cmdr commander.Commander, environ env.Environment, logger *shared.Logger, parser *shared.Parser) error {
+	input, err := parser.ParsePreCompact()
+	if err != nil {
+		return err
+	}
+
+	// Create documentation updater (RunBackground only - the detached doc-update process calls the model)
+	updater := doc.NewUpdater(fs, cmdr, environ, nil)
+
+	handler := sessionend.NewHandler(fs, environ, updater, logger)
+	return handler.HandlePreCompact(input)
+}
+
+// handleStop processes stop hook events
+func handleStop(fs afero.Fs, cmdr commander.Commander, environ env.Environment, logger *shared.Logger, parser *shared.Parser) error {
+	input, err := parser.ParseStop()
+	if err != nil {
+		return err
+	}
+
+	// Create documentation updater (RunBackground only - the detached doc-update process calls the model)
+	updater := doc.NewUpdater(fs, cmdr, environ, nil)
+
+	handler := stop.NewHandler(fs, environ, updater, logger)
+	return handler.Handle(input)
+}
+
 // handleSessionEnd processes session-end hook events
 func handleSessionEnd(fs afero.Fs, cmdr commander.Commander, environ env.Environment, logger *shared.Logger, parser *shared.Parser, builder *shared.Builder) error {
 	input, err := parser.ParseSessionEnd()
@@ -156,8 +224,8 @@ func handleSessionEnd(fs afero.Fs, cmdr commander.Commander, environ env.Environ
 		return err
 	}
 
-	// Create documentation updater
-	updater := doc.NewUpdater(fs, cmdr, environ)
+	// Create documentation updater (RunBackground only - the detached doc-update process calls the model)
+	updater := doc.NewUpdater(fs, cmdr, environ, nil)
 
 	handler := sessionend.NewHandler(fs, environ, updater, logger)
 	return handler.Handle(input)
@@ -177,8 +245,8 @@ func handleSubagentStop(fs afero.Fs, cmdr commander.Commander, environ env.Envir
 	deps := &commanderAdapter{cmdr: cmdr}
 	notifier := notify.New(notifCfg, deps)
 
-	// Create documentation updater
-	updater := doc.NewUpdater(fs, cmdr, environ)
+	// Create documentation updater (RunBackground only - the detached doc-update process calls the model)
+	updater := doc.NewUpdater(fs, cmdr, environ, nil)
 
 	handler := subagent.NewHandler(fs, environ, updater, notifier, logger)
 	output, err := handler.Handle(input)
@@ -189,7 +257,7 @@ func handleSubagentStop(fs afero.Fs, cmdr commander.Commander, environ env.Envir
 	return builder.BuildCustom(*output)
 }
 
-// handleDocUpdate processes doc-update commands (detached subprocess for background updates)
+// handleDocUpdate processes doc-update commands (one attempt of a queued job, run by the job worker)
 func handleDocUpdate(fs afero.Fs, cmdr commander.Commander, environ env.Environment, logger *shared.Logger, parser *shared.Parser) error {
 	input, err := parser.ParseDocUpdate()
 	if err != nil {
@@ -198,8 +266,8 @@ func handleDocUpdate(fs afero.Fs, cmdr commander.Commander, environ env.Environm
 
 	_ = logger.LogInfo(fmt.Sprintf("Starting doc update for session: %s", input.SessionPath))
 
-	// Create documentation updater
-	updater := doc.NewUpdater(fs, cmdr, environ)
+	// Create documentation updater backed by the project's [llm] backend
+	updater := doc.NewUpdater(fs, cmdr, environ, newLLMClient(fs, cmdr, environ, logger, input.SessionPath))
 
 	// Convert input to UpdaterConfig
 	config := doc.UpdaterConfig{
@@ -210,9 +278,26 @@ func handleDocUpdate(fs afero.Fs, cmdr commander.Commander, environ env.Environm
 		SessionContext: input.SessionContext,
 		Model:          input.Model,
 		StartLine:      input.StartLine,
+		Trigger:        input.Trigger,
+		HistoryLimit:   input.HistoryLimit,
+	}
+	if input.Validation != nil {
+		config.Validation = &doc.ValidationRules{
+			RequiredSections: input.Validation.RequiredSections,
+			CheckLinks:       input.Validation.CheckLinks,
+		}
+	}
+	if input.Redaction != nil {
+		config.Redaction = &doc.RedactionRules{
+			Emails:           input.Redaction.Emails,
+			EntropyThreshold: input.Redaction.EntropyThreshold,
+		}
+		for _, p := range input.Redaction.Patterns {
+			config.Redaction.Patterns = append(config.Redaction.Patterns, doc.RedactionPattern{Name: p.Name, Regex: p.Regex})
+		}
 	}
 
-	// Run synchronously - this process is detached and can take its time
+	// Run synchronously - the job worker waits for us and records the exit code
 	if err := updater.Run(config); err != nil {
 		_ = logger.LogError(fmt.Errorf("doc update failed: %w", err))
 		return err
@@ -222,6 +307,61 @@ func handleDocUpdate(fs afero.Fs, cmdr commander.Commander, environ env.Environm
 	return nil
 }
 
+// handleJobWorker drains a session's background job queue (detached subprocess started by doc.Updater.RunBackground)
+func handleJobWorker(fs afero.Fs, environ env.Environment, logger *shared.Logger, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: claudex-hooks job-worker <session-path>")
+	}
+	sessionPath := args[0]
+
+	// Jobs run through this same binary unless overridden
+	hooksBin := environ.Get("CLAUDEX_HOOKS_BIN")
+	if hooksBin == "" {
+		if self, err := os.Executable(); err == nil {
+			hooksBin = self
+		} else {
+			hooksBin = "claudex-hooks"
+		}
+	}
+
+	clk := clock.New()
+	queue := jobs.NewQueue(fs, clk, sessionPath)
+	worker := jobs.NewWorker(queue, lock.New(fs), jobs.NewHooksProcess(hooksBin), clk)
+
+	attempts, err := worker.Drain()
+	if errors.Is(err, jobs.ErrLocked) {
+		_ = logger.LogInfo(fmt.Sprintf("Job queue busy, leaving new jobs to the running worker: %s", sessionPath))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("job worker failed: %w", err)
+	}
+
+	_ = logger.LogInfo(fmt.Sprintf("Job queue drained for %s (%d attempts)", sessionPath, attempts))
+	return nil
+}
+
+// newLLMClient builds the model backend from the project's [llm] config,
+// falling back to the Claude CLI defaults when the config cannot be used
+func newLLMClient(fs afero.Fs, cmdr commander.Commander, environ env.Environment, logger *shared.Logger, sessionPath string) llm.Client {
+	llmCfg := config.DefaultLLM()
+	if projectRoot, err := trigger.FindProjectRoot(fs, sessionPath); err == nil {
+		cfg, err := config.Load(fs, filepath.Join(projectRoot, paths.ConfigFile))
+		if err != nil {
+			_ = logger.LogError(fmt.Errorf("failed to load config, using default llm settings: %w", err))
+		} else {
+			llmCfg = cfg.LLM
+		}
+	}
+
+	client, err := llm.New(llmCfg, cmdr, environ)
+	if err != nil {
+		_ = logger.LogError(fmt.Errorf("failed to create llm backend, falling back to claude CLI: %w", err))
+		return llm.NewCLI(cmdr, environ, llmCfg.Model, time.Duration(llmCfg.TimeoutSeconds)*time.Second)
+	}
+	return client
+}
+
 // commanderAdapter adapts commander.Commander to notify.Dependencies
 type commanderAdapter struct {
 	cmdr commander.Commander
@@ -21,8 +21,12 @@ func (s *stringSlice) Set(v string) error { *s = append(*s, v); return nil }
 var noOverwrite = flag.Bool("no-overwrite", false, "skip overwriting existing .claude files")
 var showVersion = flag.Bool("version", false, "print version and exit")
 var updateDocs = flag.Bool("update-docs", false, "update index.md files based on git changes")
+var staged = flag.Bool("staged", false, "with --update-docs, update index.md files from the staged changes and stage them (pre-commit)")
+var worktree = flag.Bool("worktree", false, "with --update-docs, update index.md files from all uncommitted changes")
 var setupMCP = flag.Bool("setup-mcp", false, "configure recommended MCP servers (sequential-thinking, context7)")
 var createIndex = flag.String("create-index", "", "create index.md file at specified directory path")
+var offline = flag.Bool("offline", false, "with --create-index, write a deterministic index.md skeleton without calling the model")
+var force = flag.Bool("force", false, "with --create-index --offline, replace an existing index.md")
 var docPaths stringSlice
 
 func init() {
@@ -30,7 +34,18 @@ func init() {
 }
 
 func main() {
-	application := app.New(Version, showVersion, noOverwrite, updateDocs, setupMCP, createIndex, docPaths)
+	application := app.New(Version, showVersion, noOverwrite, updateDocs, staged, worktree, setupMCP, createIndex, offline, force, docPaths)
+
+	// Scriptable subcommands (e.g. `claudex session list`) bypass the interactive flow
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		err := application.RunCommand(os.Args[1], os.Args[2:])
+		application.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
 	if err := application.Init(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -37,3 +37,32 @@ func BuildDocumentationPrompt(template string, transcriptContent string, session
 
 	return prompt
 }
+
+// BuildOutputInstructions appends the response contract shared by all LLM backends:
+// the model replies with the complete document and claudex writes it to outputPath.
+func BuildOutputInstructions(outputPath string, existingContent string) string {
+	var b strings.Builder
+	b.WriteString("\n\n---\n\n")
+	if strings.TrimSpace(existingContent) != "" {
+		fmt.Fprintf(&b, "CURRENT CONTENT OF %s:\n%s\n\n", outputPath, existingContent)
+	}
+	fmt.Fprintf(&b, "Reply with ONLY the complete updated markdown content of %s. ", outputPath)
+	b.WriteString("Do not use tools or write files yourself, and do not add commentary before or after the document.")
+	return b.String()
+}
+
+// BuildCorrectionInstructions asks the model to fix a reply that failed validation.
+// It is appended to the original prompt, so the model still has the transcript and
+// the current document, and lists each issue found in the rejected reply.
+func BuildCorrectionInstructions(outputPath string, rejected string, issues []ValidationIssue) string {
+	var b strings.Builder
+	b.WriteString("\n\n---\n\n")
+	fmt.Fprintf(&b, "YOUR PREVIOUS REPLY FOR %s FAILED VALIDATION:\n", outputPath)
+	for _, issue := range issues {
+		fmt.Fprintf(&b, "- %s\n", issue)
+	}
+	fmt.Fprintf(&b, "\nREJECTED REPLY:\n%s\n\n", rejected)
+	fmt.Fprintf(&b, "Reply with ONLY the complete corrected markdown content of %s, fixing every issue above. ", outputPath)
+	b.WriteString("Keep the required sections and link only to files that exist in the session folder.")
+	return b.String()
+}
@@ -0,0 +1,208 @@
+package rangeupdater
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"claudex/internal/services/git"
+)
+
+// Bounds that keep the change summary small enough for the index prompt
+const (
+	maxSummaryCommits   = 20
+	maxDiffLinesPerFile = 40
+	maxDiffLinesTotal   = 200
+)
+
+// ChangeSet describes what a commit range changed: its commits, per-file line counts
+// and the files it created
+type ChangeSet struct {
+	Base    string
+	Head    string
+	Commits []git.Commit
+	Stats   map[string]git.FileStat
+	// Added holds the absolute paths of the files created in the range
+	Added map[string]bool
+	// Scope is set when the changes are uncommitted; Base, Head and Commits are then empty
+	Scope git.Scope
+}
+
+// loadChangeSet reads the commit log and diff stat of base..head.
+// The commit log is required to honor [skip-docs]; the diff stat and added files only
+// enrich the prompt and propagation, so failures there are logged and skipped.
+func loadChangeSet(gitSvc git.GitService, base, head string) (*ChangeSet, error) {
+	commits, err := gitSvc.GetCommits(base, head)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit log: %w", err)
+	}
+
+	changes := &ChangeSet{
+		Base:    base,
+		Head:    head,
+		Commits: commits,
+		Stats:   map[string]git.FileStat{},
+		Added:   map[string]bool{},
+	}
+
+	stats, err := gitSvc.GetDiffStat(base, head)
+	if err != nil {
+		log.Printf("Warning: failed to get diff stat for %s..%s: %v", shortSHA(base), shortSHA(head), err)
+	}
+	for _, stat := range stats {
+		changes.Stats[stat.Path] = stat
+	}
+
+	added, err := gitSvc.GetAddedFiles(base, head)
+	if err != nil {
+		log.Printf("Warning: failed to get added files for %s..%s: %v", shortSHA(base), shortSHA(head), err)
+	}
+	for _, file := range added {
+		if absPath, err := filepath.Abs(file); err == nil {
+			changes.Added[absPath] = true
+		}
+	}
+	return changes, nil
+}
+
+// loadPendingChangeSet reads the diff stat and created files of the uncommitted changes of a scope.
+// Both only enrich the prompt and propagation, so failures are logged and skipped.
+func loadPendingChangeSet(gitSvc git.GitService, scope git.Scope) *ChangeSet {
+	changes := &ChangeSet{
+		Stats: map[string]git.FileStat{},
+		Added: map[string]bool{},
+		Scope: scope,
+	}
+
+	stats, err := gitSvc.GetPendingDiffStat(scope)
+	if err != nil {
+		log.Printf("Warning: failed to get diff stat for %s changes: %v", scope, err)
+	}
+	for _, stat := range stats {
+		changes.Stats[stat.Path] = stat
+	}
+
+	added, err := gitSvc.GetPendingAddedFiles(scope)
+	if err != nil {
+		log.Printf("Warning: failed to get added files for %s changes: %v", scope, err)
+	}
+	for _, file := range added {
+		if absPath, err := filepath.Abs(file); err == nil {
+			changes.Added[absPath] = true
+		}
+	}
+	return changes
+}
+
+// fileDiff returns the diff of one file over the range or the uncommitted changes
+func (c *ChangeSet) fileDiff(gitSvc git.GitService, file string) (string, error) {
+	if c.Scope != "" {
+		return gitSvc.GetPendingFileDiff(c.Scope, file)
+	}
+	return gitSvc.GetFileDiff(c.Base, c.Head, file)
+}
+
+// Messages returns every commit message of the range joined by blank lines
+func (c *ChangeSet) Messages() string {
+	messages := make([]string, 0, len(c.Commits))
+	for _, commit := range c.Commits {
+		messages = append(messages, commit.Message())
+	}
+	return strings.Join(messages, "\n\n")
+}
+
+// Summary formats the commit subjects and the diffs of the files under indexDir for
+// the index prompt. Diffs are truncated per file and in total; files past the total
+// budget are listed with their line counts only.
+func (c *ChangeSet) Summary(gitSvc git.GitService, indexDir string, changedFiles []string) string {
+	var b strings.Builder
+
+	if len(c.Commits) > 0 {
+		b.WriteString("COMMITS:\n")
+		for i, commit := range c.Commits {
+			if i == maxSummaryCommits {
+				fmt.Fprintf(&b, "- ... and %d more\n", len(c.Commits)-maxSummaryCommits)
+				break
+			}
+			fmt.Fprintf(&b, "- %s %s\n", shortSHA(commit.SHA), commit.Subject)
+		}
+	}
+
+	budget := maxDiffLinesTotal
+	var diffs strings.Builder
+	for _, file := range changedFiles {
+		if !isUnder(indexDir, file) {
+			continue
+		}
+		fmt.Fprintf(&diffs, "\n%s%s\n", file, c.statLabel(file))
+		if budget <= 0 || c.Stats[file].Binary {
+			continue
+		}
+
+		diff, err := c.fileDiff(gitSvc, file)
+		if err != nil {
+			log.Printf("Warning: failed to get diff for %s: %v", file, err)
+			continue
+		}
+		hunk, used := truncateDiff(diff, min(maxDiffLinesPerFile, budget))
+		if hunk == "" {
+			continue
+		}
+		budget -= used
+		fmt.Fprintf(&diffs, "```diff\n%s\n```\n", hunk)
+	}
+
+	if diffs.Len() > 0 {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("DIFFS:")
+		b.WriteString(diffs.String())
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// statLabel returns the " (+added -deleted)" suffix for a file, if known
+func (c *ChangeSet) statLabel(file string) string {
+	stat, ok := c.Stats[file]
+	switch {
+	case !ok:
+		return ""
+	case stat.Binary:
+		return " (binary)"
+	default:
+		return fmt.Sprintf(" (+%d -%d)", stat.Added, stat.Deleted)
+	}
+}
+
+// truncateDiff keeps the hunks of a diff (dropping the file header) up to limit lines
+// and reports how many lines it kept
+func truncateDiff(diff string, limit int) (string, int) {
+	lines := strings.Split(diff, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "@@") {
+			lines = lines[i:]
+			break
+		}
+	}
+	if len(lines) == 0 || !strings.HasPrefix(lines[0], "@@") {
+		return "", 0
+	}
+
+	if len(lines) <= limit {
+		return strings.Join(lines, "\n"), len(lines)
+	}
+	kept := lines[:limit]
+	return strings.Join(kept, "\n") + fmt.Sprintf("\n... (%d more lines)", len(lines)-limit), limit
+}
+
+// isUnder reports whether a repository-relative file lives in dir or below it
+func isUnder(dir, file string) bool {
+	absFile, err := filepath.Abs(file)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(dir, absFile)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -3,57 +3,74 @@ package rangeupdater
 import (
 	"fmt"
 	"log"
-	"os/exec"
+	"strings"
+	"time"
 
-	"claudex/internal/services/commander"
 	"claudex/internal/services/env"
+	"claudex/internal/services/llm"
 )
 
-// InvokeClaudeForIndex invokes Claude to regenerate an index.md file.
-// Claude uses its Edit tool to update the file directly.
-// The recursion guard (CLAUDE_HOOK_INTERNAL=1) prevents infinite loops.
-func InvokeClaudeForIndex(cmdr commander.Commander, env env.Environment, indexPath, listing, modifiedFiles string) error {
+// noChangesMarker is the reply that tells us the index is already up to date
+const noChangesMarker = "NO_CHANGES"
+
+// IndexUpdate describes one index.md regeneration
+type IndexUpdate struct {
+	IndexPath     string
+	Current       string // current index.md content; empty for a new index
+	Listing       string // files in the index directory
+	ModifiedFiles string // changed files of the range
+	ChangeSummary string // commit subjects and bounded diffs; may be empty
+	Timeout       time.Duration
+}
+
+// InvokeClaudeForIndex asks the configured LLM backend to regenerate an index.md file
+// and returns the new content. It returns an empty string when the model replies
+// NO_CHANGES or returns nothing; writing the result is up to the caller.
+func InvokeClaudeForIndex(client llm.Client, env env.Environment, update IndexUpdate) (string, error) {
+	indexPath := update.IndexPath
+
 	// Recursion guard: check if we're already inside a hook invocation
 	if env.Get("CLAUDE_HOOK_INTERNAL") == "1" {
 		log.Printf("Skipping index update for %s: recursion guard triggered", indexPath)
-		return nil
+		return "", nil
 	}
 
-	log.Printf("Spawning background process to regenerate %s", indexPath)
+	log.Printf("Requesting index update for %s", indexPath)
 
-	// Build Claude prompt with context
-	prompt := buildPrompt(indexPath, listing, modifiedFiles)
+	// Build prompt with context
+	prompt := buildPrompt(indexPath, update.Current, update.Listing, update.ModifiedFiles, update.ChangeSummary)
 
-	// Create a detached background process using bash
-	// This ensures the process survives even after the calling process exits
-	// Claude will use its Edit tool to update the file directly
-	// Using --model haiku for cost efficiency (index updates are simple tasks)
-	bashScript := fmt.Sprintf(`
-export CLAUDE_HOOK_INTERNAL=1
-claude -p %q --model haiku 2>/dev/null
-`, prompt)
-
-	cmd := exec.Command("bash", "-c", bashScript)
-
-	// Detach the process so it survives after we exit
-	if err := cmd.Start(); err != nil {
-		log.Printf("Failed to start background Claude process for %s: %v", indexPath, err)
-		return fmt.Errorf("failed to start background Claude process: %w", err)
+	response, err := client.Complete(llm.Request{Prompt: prompt, Timeout: update.Timeout})
+	if err != nil {
+		return "", fmt.Errorf("failed to generate %s: %w", indexPath, err)
 	}
 
-	log.Printf("Background process started (PID: %d) for %s", cmd.Process.Pid, indexPath)
-	return nil
+	content := llm.StripCodeFence(response)
+	if content == noChangesMarker {
+		return "", nil
+	}
+	return content, nil
 }
 
-// buildPrompt constructs the Claude prompt for index.md regeneration
-func buildPrompt(indexPath, listing, modifiedFiles string) string {
+// buildPrompt constructs the index update prompt
+func buildPrompt(indexPath, currentContent, listing, modifiedFiles, changeSummary string) string {
+	changes := ""
+	if changeSummary != "" {
+		changes = fmt.Sprintf("\nWHAT CHANGED:\n%s\n", changeSummary)
+	}
+
 	return fmt.Sprintf(`A code change was made. Update the index.md at %s if needed.
 
 MODIFIED FILES:
 %s
-
+%s
 FILES IN DIRECTORY:
 %s
 
-This is a lightweight documentation pointer that helps developers understand the codebase. Other index.md files may exist in parent or child directories - explore them to understand the documentation structure and determine the appropriate scope for this file. Make thoughtful updates that keep it relevant and useful.`, indexPath, modifiedFiles, listing)
+CURRENT INDEX.MD:
+%s
+
+This is a lightweight documentation pointer that helps developers understand the codebase. Base your update on what the changes actually do rather than on file names alone, make thoughtful updates that keep it relevant and useful, and preserve its existing structure and tone.
+
+Reply with ONLY the complete updated markdown content of the index.md file. If no update is needed, reply with exactly %s.`, indexPath, modifiedFiles, changes, listing, strings.TrimSpace(currentContent), noChangesMarker)
 }
@@ -0,0 +1,48 @@
+package rangeupdater
+
+import (
+	"fmt"
+
+	"claudex/internal/services/glob"
+)
+
+// FileFilter decides per changed file whether it can trigger doc updates
+type FileFilter struct {
+	include *glob.Matcher
+	exclude *glob.Matcher
+}
+
+// NewFileFilter compiles the include and exclude patterns
+func NewFileFilter(include, exclude []string) (*FileFilter, error) {
+	includeMatcher, err := glob.New(include)
+	if err != nil {
+		return nil, fmt.Errorf("invalid include pattern: %w", err)
+	}
+	excludeMatcher, err := glob.New(exclude)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exclude pattern: %w", err)
+	}
+	return &FileFilter{include: includeMatcher, exclude: excludeMatcher}, nil
+}
+
+// Allows reports whether a changed file is included and not excluded
+func (f *FileFilter) Allows(file string) bool {
+	if !f.include.Empty() && !f.include.Match(file) {
+		return false
+	}
+	return !f.exclude.Match(file)
+}
+
+// Apply splits changed files into those that can trigger doc updates and those filtered out
+func (f *FileFilter) Apply(files []string) (kept, dropped []string) {
+	kept = []string{}
+	dropped = []string{}
+	for _, file := range files {
+		if f.Allows(file) {
+			kept = append(kept, file)
+		} else {
+			dropped = append(dropped, file)
+		}
+	}
+	return kept, dropped
+}
@@ -14,6 +14,7 @@ import (
 	"claudex/internal/services/commander"
 	"claudex/internal/services/doctracking"
 	"claudex/internal/services/git"
+	"claudex/internal/services/llm"
 	"claudex/internal/services/lock"
 
 	"github.com/spf13/afero"
@@ -94,6 +95,19 @@ func makeCommit(t *testing.T, repoPath string, files map[string]string, message
 	return strings.TrimSpace(string(output))
 }
 
+// currentBranch returns the checked-out branch of the test repository
+func currentBranch(t *testing.T, repoPath string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("Failed to get current branch: %v", err)
+	}
+	return strings.TrimSpace(string(output))
+}
+
 // mockEnv is a simple mock implementation of Environment for testing
 type mockEnv struct {
 	vars map[string]string
@@ -136,7 +150,7 @@ func createUpdater(t *testing.T, repoPath string) (*RangeUpdater, string, *mockE
 	lockSvc := lock.New(fs)
 
 	// Create real tracking service
-	trackingSvc := doctracking.New(fs, sessionPath)
+	trackingSvc := doctracking.New(fs, sessionPath, "main")
 
 	// Create mock environment
 	mockEnv := newMockEnv()
@@ -145,13 +159,13 @@ func createUpdater(t *testing.T, repoPath string) (*RangeUpdater, string, *mockE
 
 	// Create config
 	config := RangeUpdaterConfig{
-		SessionPath:   sessionPath,
-		DefaultBranch: "main",
-		SkipPatterns:  []string{},
-		LockTimeout:   0,
+		SessionPath:     sessionPath,
+		DefaultBranch:   "main",
+		ExcludePatterns: []string{},
+		LockTimeout:     0,
 	}
 
-	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, mockEnv)
+	updater := New(config, gitSvc, lockSvc, trackingSvc, llm.NewFake(noChangesMarker), fs, mockEnv)
 
 	return updater, sessionPath, mockEnv
 }
@@ -179,7 +193,7 @@ func TestIntegration_HappyPath(t *testing.T) {
 		UpdatedAt:           time.Now().Format(time.RFC3339),
 		StrategyVersion:     "v1",
 	}
-	if err := updater.trackingSvc.Write(tracking); err != nil {
+	if err := updater.trackingSvc.Write(currentBranch(t, repoPath), tracking); err != nil {
 		t.Fatalf("Failed to initialize tracking: %v", err)
 	}
 
@@ -218,7 +232,7 @@ func TestIntegration_HappyPath(t *testing.T) {
 	}
 
 	// Verify tracking was updated to commit2
-	newTracking, err := updater.trackingSvc.Read()
+	newTracking, err := updater.trackingSvc.Read(currentBranch(t, repoPath))
 	if err != nil {
 		t.Fatalf("Failed to read tracking: %v", err)
 	}
@@ -249,7 +263,7 @@ func TestIntegration_NoChanges(t *testing.T) {
 		UpdatedAt:           time.Now().Format(time.RFC3339),
 		StrategyVersion:     "v1",
 	}
-	if err := updater.trackingSvc.Write(tracking); err != nil {
+	if err := updater.trackingSvc.Write(currentBranch(t, repoPath), tracking); err != nil {
 		t.Fatalf("Failed to initialize tracking: %v", err)
 	}
 
@@ -294,7 +308,7 @@ func TestIntegration_DocsOnlyChanges(t *testing.T) {
 		UpdatedAt:           time.Now().Format(time.RFC3339),
 		StrategyVersion:     "v1",
 	}
-	if err := updater.trackingSvc.Write(tracking); err != nil {
+	if err := updater.trackingSvc.Write(currentBranch(t, repoPath), tracking); err != nil {
 		t.Fatalf("Failed to initialize tracking: %v", err)
 	}
 
@@ -320,10 +334,9 @@ func TestIntegration_DocsOnlyChanges(t *testing.T) {
 	}
 }
 
-// TestIntegration_SkipDocsTag tests skipping when commit message has [skip-docs]
+// TestIntegration_SkipDocsTag tests skipping when every commit of the range has [skip-docs],
+// and documenting the untagged commits' files when only some do
 func TestIntegration_SkipDocsTag(t *testing.T) {
-	t.Skip("Skipping: commit message checking not yet implemented in current skiprules")
-
 	repoPath := setupTestRepo(t)
 	originalDir, _ := os.Getwd()
 	defer os.Chdir(originalDir)
@@ -342,23 +355,60 @@ func TestIntegration_SkipDocsTag(t *testing.T) {
 		UpdatedAt:           time.Now().Format(time.RFC3339),
 		StrategyVersion:     "v1",
 	}
-	if err := updater.trackingSvc.Write(tracking); err != nil {
+	if err := updater.trackingSvc.Write(currentBranch(t, repoPath), tracking); err != nil {
 		t.Fatalf("Failed to initialize tracking: %v", err)
 	}
 
-	// Commit with [skip-docs] tag
-	makeCommit(t, repoPath, map[string]string{
+	// A range made only of [skip-docs] commits is skipped
+	commit2 := makeCommit(t, repoPath, map[string]string{
 		"src/foo.go": "package main\n\nfunc main() {\n\tprintln(\"hello\")\n}\n",
 	}, "fix: typo [skip-docs]")
 
-	// Run updater
 	result, err := updater.Run()
 	if err != nil {
 		t.Fatalf("Run() failed: %v", err)
 	}
 
-	// TODO: Update this test once commit message checking is implemented in skiprules
-	t.Logf("Result: %s - %s", result.Status, result.Reason)
+	if result.Status != "skipped" {
+		t.Errorf("Expected status 'skipped', got %s: %s", result.Status, result.Reason)
+	}
+	if !strings.Contains(result.Reason, "[skip-docs]") {
+		t.Errorf("Expected reason to mention [skip-docs], got: '%s'", result.Reason)
+	}
+
+	// Tracking moves past the tagged range so it is not re-evaluated
+	finalTracking, err := updater.trackingSvc.Read(currentBranch(t, repoPath))
+	if err != nil {
+		t.Fatalf("Failed to read tracking: %v", err)
+	}
+	if finalTracking.LastProcessedCommit != commit2 {
+		t.Errorf("Expected tracking to be %s, got %s", commit2, finalTracking.LastProcessedCommit)
+	}
+
+	// A tagged commit followed by a regular one only drops the tagged commit's files
+	makeCommit(t, repoPath, map[string]string{
+		"src/foo.go": "package main\n\nfunc main() {}\n",
+	}, "revert: typo [skip-docs]")
+	commit4 := makeCommit(t, repoPath, map[string]string{
+		"src/bar.go": "package main\n",
+	}, "Add bar")
+
+	result, err = updater.Run()
+	if err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+
+	if result.Status == "skipped" {
+		t.Errorf("Expected the untagged commit to be processed, got skipped: %s", result.Reason)
+	}
+
+	finalTracking, err = updater.trackingSvc.Read(currentBranch(t, repoPath))
+	if err != nil {
+		t.Fatalf("Failed to read tracking: %v", err)
+	}
+	if finalTracking.LastProcessedCommit != commit4 {
+		t.Errorf("Expected tracking to be %s, got %s", commit4, finalTracking.LastProcessedCommit)
+	}
 }
 
 // TestIntegration_UnreachableBase tests fallback when base SHA is unreachable
@@ -393,7 +443,7 @@ func TestIntegration_UnreachableBase(t *testing.T) {
 		UpdatedAt:           time.Now().Format(time.RFC3339),
 		StrategyVersion:     "v1",
 	}
-	if err := updater.trackingSvc.Write(tracking); err != nil {
+	if err := updater.trackingSvc.Write(currentBranch(t, repoPath), tracking); err != nil {
 		t.Fatalf("Failed to write tracking: %v", err)
 	}
 
@@ -413,7 +463,7 @@ func TestIntegration_UnreachableBase(t *testing.T) {
 	}
 
 	// Verify tracking was updated to current HEAD (commit2)
-	newTracking, err := updater.trackingSvc.Read()
+	newTracking, err := updater.trackingSvc.Read(currentBranch(t, repoPath))
 	if err != nil {
 		t.Fatalf("Failed to read tracking: %v", err)
 	}
@@ -449,7 +499,7 @@ func TestIntegration_LockContention(t *testing.T) {
 		UpdatedAt:           time.Now().Format(time.RFC3339),
 		StrategyVersion:     "v1",
 	}
-	if err := updater.trackingSvc.Write(tracking); err != nil {
+	if err := updater.trackingSvc.Write(currentBranch(t, repoPath), tracking); err != nil {
 		t.Fatalf("Failed to initialize tracking: %v", err)
 	}
 
@@ -508,7 +558,7 @@ func TestIntegration_MultipleIndexes(t *testing.T) {
 		UpdatedAt:           time.Now().Format(time.RFC3339),
 		StrategyVersion:     "v1",
 	}
-	if err := updater.trackingSvc.Write(tracking); err != nil {
+	if err := updater.trackingSvc.Write(currentBranch(t, repoPath), tracking); err != nil {
 		t.Fatalf("Failed to initialize tracking: %v", err)
 	}
 
@@ -553,7 +603,7 @@ func TestIntegration_MultipleIndexes(t *testing.T) {
 	}
 
 	// Verify tracking was updated to commit2
-	newTracking, err := updater.trackingSvc.Read()
+	newTracking, err := updater.trackingSvc.Read(currentBranch(t, repoPath))
 	if err != nil {
 		t.Fatalf("Failed to read tracking: %v", err)
 	}
@@ -562,3 +612,57 @@ func TestIntegration_MultipleIndexes(t *testing.T) {
 		t.Errorf("Expected tracking to be %s, got %s", commit2, newTracking.LastProcessedCommit)
 	}
 }
+
+// TestIntegration_StagedChanges tests that a pre-commit run stages the indexes it rewrites
+func TestIntegration_StagedChanges(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(repoPath)
+
+	commit1 := makeCommit(t, repoPath, map[string]string{
+		"src/foo.go":   "package main\n\nfunc main() {}\n",
+		"src/index.md": "# Index\n\n- foo.go: main package\n",
+	}, "Initial commit")
+
+	updater, _, env := createUpdater(t, repoPath)
+	env.Set("CLAUDE_HOOK_INTERNAL", "")
+	updater.llm = llm.NewFake("# Index\n\n- foo.go: main package that prints hello\n")
+
+	// Stage a change without committing it
+	if err := os.WriteFile(filepath.Join(repoPath, "src/foo.go"), []byte("package main\n\nfunc main() {\n\tprintln(\"hello\")\n}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	cmd := exec.Command("git", "add", "src/foo.go")
+	cmd.Dir = repoPath
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to stage file: %v", err)
+	}
+
+	result, err := updater.RunPending(git.ScopeStaged)
+	if err != nil {
+		t.Fatalf("RunPending() failed: %v", err)
+	}
+	if result.Status != "success" || len(result.AffectedIndexes) != 1 {
+		t.Fatalf("Expected one updated index, got %+v", result)
+	}
+
+	cmd = exec.Command("git", "diff", "--cached", "--name-only")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("Failed to list staged files: %v", err)
+	}
+	if !strings.Contains(string(output), "src/index.md") {
+		t.Errorf("Expected src/index.md to be staged, got %q", output)
+	}
+
+	// Tracking is untouched until the commit exists
+	tracking, err := updater.trackingSvc.Read(currentBranch(t, repoPath))
+	if err != nil {
+		t.Fatalf("Failed to read tracking: %v", err)
+	}
+	if tracking.LastProcessedCommit != "" && tracking.LastProcessedCommit != commit1 {
+		t.Errorf("Expected tracking to stay put, got %s", tracking.LastProcessedCommit)
+	}
+}
@@ -0,0 +1,133 @@
+package rangeupdater
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// An index update counts as significant when it changes at least this many lines,
+// or more than one line making up at least significantChangePercent of the previous index
+const (
+	significantChangeLines   = 3
+	significantChangePercent = 25
+)
+
+// indexWork is an index.md queued for an update
+type indexWork struct {
+	path string
+	// level counts the parent hops from a directly affected index (0 for those)
+	level int
+	// children summarizes the child index updates that queued this index
+	children []string
+	// files and changes describe the commit range the index is updated for
+	files   []string
+	changes *ChangeSet
+}
+
+// indexChange is the content of an index.md before and after its update
+type indexChange struct {
+	path   string
+	before string
+	after  string
+}
+
+// changedLines counts the lines the update inserted, deleted or replaced
+func (c indexChange) changedLines() int {
+	matcher := difflib.NewMatcher(splitIndexLines(c.before), splitIndexLines(c.after))
+	changed := 0
+	for _, op := range matcher.GetOpCodes() {
+		if op.Tag != 'e' {
+			changed += max(op.I2-op.I1, op.J2-op.J1)
+		}
+	}
+	return changed
+}
+
+// significant reports whether the update changed enough of the index to matter to its parent
+func (c indexChange) significant() bool {
+	changed := c.changedLines()
+	if changed == 0 {
+		return false
+	}
+	previous := len(splitIndexLines(c.before))
+	return changed >= significantChangeLines || (changed > 1 && changed*100 >= previous*significantChangePercent)
+}
+
+// propagate queues the parent of an index that was created in the range or changed
+// significantly, with a summary of that change, up to PropagationDepth levels and never
+// above RootDir. A parent that has already started is left alone.
+func (ru *RangeUpdater) propagate(queue map[string]*indexWork, started map[string]bool, work *indexWork, change indexChange) {
+	if work.level >= ru.config.PropagationDepth {
+		return
+	}
+	created := work.changes.Added[work.path]
+	if !created && !change.significant() {
+		return
+	}
+
+	parent := findParentIndexMd(ru.fs, work.path, ru.config.RootDir)
+	if parent == "" || started[parent] {
+		return
+	}
+	parentWork, ok := queue[parent]
+	if !ok {
+		log.Printf("Propagating %s to %s", work.path, parent)
+		parentWork = &indexWork{path: parent, level: work.level + 1, files: work.files, changes: work.changes}
+		queue[parent] = parentWork
+	}
+	parentWork.children = append(parentWork.children, summarizeChildChange(parent, change, created))
+}
+
+// below reports whether the directory of index a lies strictly inside the directory of index b
+func below(a, b string) bool {
+	rel, err := filepath.Rel(filepath.Dir(b), filepath.Dir(a))
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// deeper orders index paths by directory depth, then by name for determinism
+func deeper(a, b string) bool {
+	depthA := strings.Count(filepath.ToSlash(a), "/")
+	depthB := strings.Count(filepath.ToSlash(b), "/")
+	if depthA != depthB {
+		return depthA > depthB
+	}
+	return a < b
+}
+
+// summarizeChildChange describes a child index update for its parent's prompt: the child
+// path relative to the parent and a bounded diff (or the new content when created)
+func summarizeChildChange(parentPath string, change indexChange, created bool) string {
+	rel, err := filepath.Rel(filepath.Dir(parentPath), change.path)
+	if err != nil {
+		rel = change.path
+	}
+
+	// A created index is new to the parent, so show all of it rather than this run's edit
+	label, before := "updated", change.before
+	if created {
+		label, before = "created", ""
+	}
+	diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:       splitIndexLines(before),
+		B:       splitIndexLines(change.after),
+		Context: 1,
+	})
+
+	hunk, _ := truncateDiff(strings.TrimRight(diff, "\n"), maxDiffLinesPerFile)
+	if hunk == "" {
+		return fmt.Sprintf("- %s (%s)", rel, label)
+	}
+	return fmt.Sprintf("- %s (%s)\n```diff\n%s\n```", rel, label, hunk)
+}
+
+// splitIndexLines splits index content into lines; empty content has none
+func splitIndexLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return difflib.SplitLines(content)
+}
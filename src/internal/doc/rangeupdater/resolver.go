@@ -3,6 +3,7 @@ package rangeupdater
 import (
 	"path/filepath"
 	"sort"
+	"strings"
 
 	"github.com/spf13/afero"
 )
@@ -30,6 +31,12 @@ func ResolveAffectedIndexes(fs afero.Fs, changedFiles []string) ([]string, error
 	return indexes, nil
 }
 
+// NearestIndex returns the index.md a file is documented by: the nearest one in its
+// directory or above, or "" when there is none
+func NearestIndex(fs afero.Fs, filePath string) string {
+	return findNearestIndexMd(fs, filePath)
+}
+
 // findNearestIndexMd walks up the directory tree to find the nearest parent index.md.
 // This is adapted from indexupdater.go:98-127 for batch processing.
 func findNearestIndexMd(fs afero.Fs, filePath string) string {
@@ -40,7 +47,28 @@ func findNearestIndexMd(fs afero.Fs, filePath string) string {
 	}
 
 	// Start from the file's parent directory
-	dir := filepath.Dir(absPath)
+	return findIndexFrom(fs, filepath.Dir(absPath), "")
+}
+
+// findParentIndexMd returns the nearest index.md above the directory of indexPath,
+// not looking past rootDir (no bound when rootDir is empty)
+func findParentIndexMd(fs afero.Fs, indexPath, rootDir string) string {
+	indexDir := filepath.Dir(indexPath)
+	if rootDir != "" && filepath.Clean(indexDir) == filepath.Clean(rootDir) {
+		return ""
+	}
+	return findIndexFrom(fs, filepath.Dir(indexDir), rootDir)
+}
+
+// findIndexFrom walks up from dir to the first directory holding an index.md.
+// The walk stops at rootDir when set, otherwise at the filesystem root.
+func findIndexFrom(fs afero.Fs, dir, rootDir string) string {
+	if rootDir != "" {
+		rootDir = filepath.Clean(rootDir)
+		if rel, err := filepath.Rel(rootDir, dir); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return ""
+		}
+	}
 
 	// Walk up the directory tree
 	for {
@@ -50,10 +78,10 @@ func findNearestIndexMd(fs afero.Fs, filePath string) string {
 			return indexPath
 		}
 
-		// Check if we've reached the root
+		// Check if we've reached the bound or the root
 		parent := filepath.Dir(dir)
-		if parent == dir {
-			// Reached root, no index.md found
+		if parent == dir || dir == rootDir {
+			// No index.md found
 			break
 		}
 		dir = parent
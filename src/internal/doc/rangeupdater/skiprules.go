@@ -7,22 +7,32 @@ import (
 	"claudex/internal/services/env"
 )
 
+// skipDocsTag is the commit message tag that opts a commit's changes out of doc updates
+const skipDocsTag = "[skip-docs]"
+
+// reasonSkipDocsTag is the skip reason reported when a commit carries skipDocsTag
+const reasonSkipDocsTag = "commit message contains [skip-docs] tag"
+
+// reasonSkipDocsEnv is the skip reason reported when CLAUDEX_SKIP_DOCS=1
+const reasonSkipDocsEnv = "CLAUDEX_SKIP_DOCS environment variable is set"
+
 // ShouldSkip determines if documentation updates should be skipped based on skip rules.
 // Returns (skip=true, reason) if any rule matches, (false, "") otherwise.
 //
 // Rules (evaluated in order):
 //  1. Environment variable: CLAUDEX_SKIP_DOCS=1
-//  2. Commit message contains: [skip-docs]
+//  2. Commit message contains: [skip-docs] (commitMsg may hold several messages; the
+//     range updater passes them only when every change of the range comes from tagged commits)
 //  3. All changes are documentation files (*.md) - prevents infinite loops
 func ShouldSkip(files []string, commitMsg string, env env.Environment) (skip bool, reason string) {
 	// Rule 1: Environment variable
 	if env.Get("CLAUDEX_SKIP_DOCS") == "1" {
-		return true, "CLAUDEX_SKIP_DOCS environment variable is set"
+		return true, reasonSkipDocsEnv
 	}
 
 	// Rule 2: Commit message tag
-	if strings.Contains(commitMsg, "[skip-docs]") {
-		return true, "commit message contains [skip-docs] tag"
+	if strings.Contains(commitMsg, skipDocsTag) {
+		return true, reasonSkipDocsTag
 	}
 
 	// Rule 3: All changes are markdown files (docs-only)
@@ -5,6 +5,9 @@ package rangeupdater
 
 import "time"
 
+// DefaultWorkers is the index update parallelism used when none is configured
+const DefaultWorkers = 4
+
 // RangeUpdaterConfig holds configuration for the range-based doc updater
 type RangeUpdaterConfig struct {
 	// SessionPath is the absolute path to the Claudex session directory
@@ -15,9 +18,27 @@ type RangeUpdaterConfig struct {
 	// Typically "main" or "master"
 	DefaultBranch string
 
-	// SkipPatterns is a list of file path patterns to ignore
-	// Files matching these patterns won't trigger doc updates
-	SkipPatterns []string
+	// IncludePatterns are gitignore-style globs selecting the changed files that
+	// can trigger doc updates. Empty means every file is considered.
+	IncludePatterns []string
+
+	// ExcludePatterns are gitignore-style globs for changed files that never
+	// trigger doc updates (lockfiles, generated code, vendored dependencies)
+	ExcludePatterns []string
+
+	// PropagationDepth is how many parent index.md levels above a created or
+	// significantly changed index are refreshed too. Zero disables propagation.
+	PropagationDepth int
+
+	// RootDir bounds the parent index search during propagation, typically the
+	// repository root. Empty means no bound.
+	RootDir string
+
+	// Workers is the number of index updates run in parallel (DefaultWorkers when zero)
+	Workers int
+
+	// IndexTimeout bounds each index update; zero uses the LLM backend's default timeout
+	IndexTimeout time.Duration
 
 	// LockTimeout is the maximum time to wait for lock acquisition
 	// Zero means no waiting (immediate failure if locked)
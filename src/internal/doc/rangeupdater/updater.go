@@ -4,15 +4,19 @@
 package rangeupdater
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
-	"claudex/internal/services/commander"
 	"claudex/internal/services/doctracking"
 	"claudex/internal/services/env"
 	"claudex/internal/services/git"
+	"claudex/internal/services/llm"
 	"claudex/internal/services/lock"
 
 	"github.com/spf13/afero"
@@ -20,17 +24,43 @@ import (
 
 // UpdateResult represents the outcome of a range update operation
 type UpdateResult struct {
-	// Status indicates the outcome: "success", "skipped", "locked", or "error"
+	// Status indicates the outcome: "success", "partial" (some index updates failed),
+	// "skipped", "locked", or "error"
 	Status string
 
 	// Reason provides context for skipped or error statuses
 	Reason string
 
-	// AffectedIndexes lists the index.md files that were updated
+	// AffectedIndexes lists the index.md files whose update succeeded
 	AffectedIndexes []string
 
 	// ProcessedRange indicates the commit range that was processed
 	ProcessedRange string
+
+	// Indexes reports the outcome and duration of every index update attempted
+	Indexes []IndexResult
+
+	// Branch is the branch whose tracking entry was used
+	Branch string
+
+	// Unstaged lists the indexes RunPending rewrote but did not stage because they already
+	// had unstaged edits; staging them would pull those edits into the commit
+	Unstaged []string
+}
+
+// Index update outcomes reported in IndexResult.Status
+const (
+	IndexUpdated   = "updated"
+	IndexUnchanged = "unchanged"
+	IndexFailed    = "failed"
+)
+
+// IndexResult is the outcome of a single index.md update
+type IndexResult struct {
+	Path     string
+	Status   string
+	Error    string
+	Duration time.Duration
 }
 
 // RangeUpdater orchestrates range-based documentation updates
@@ -39,7 +69,7 @@ type RangeUpdater struct {
 	gitSvc      git.GitService
 	lockSvc     lock.LockService
 	trackingSvc doctracking.TrackingService
-	cmdr        commander.Commander
+	llm         llm.Client
 	fs          afero.Fs
 	env         env.Environment
 }
@@ -50,7 +80,7 @@ func New(
 	gitSvc git.GitService,
 	lockSvc lock.LockService,
 	trackingSvc doctracking.TrackingService,
-	cmdr commander.Commander,
+	client llm.Client,
 	fs afero.Fs,
 	env env.Environment,
 ) *RangeUpdater {
@@ -59,7 +89,7 @@ func New(
 		gitSvc:      gitSvc,
 		lockSvc:     lockSvc,
 		trackingSvc: trackingSvc,
-		cmdr:        cmdr,
+		llm:         client,
 		fs:          fs,
 		env:         env,
 	}
@@ -68,29 +98,25 @@ func New(
 // Run executes the main update flow
 func (ru *RangeUpdater) Run() (*UpdateResult, error) {
 	// Step 1: Acquire lock (skip if locked)
-	lockPath := filepath.Join(ru.config.SessionPath, "doc_update.lock")
-	isLocked, err := ru.lockSvc.IsLocked(lockPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check lock status: %w", err)
-	}
-	if isLocked {
-		return &UpdateResult{
-			Status: "locked",
-			Reason: "another update process is running",
-		}, nil
+	lock, locked, err := ru.acquireLock()
+	if lock == nil {
+		return locked, err
 	}
+	defer lock.Release()
 
-	lock, err := ru.lockSvc.Acquire(lockPath)
+	// Step 2: Read the current branch's tracking to get base SHA
+	branch, err := ru.gitSvc.GetCurrentBranch()
 	if err != nil {
+		return nil, fmt.Errorf("failed to get current branch: %w", err)
+	}
+	if branch == "" {
 		return &UpdateResult{
-			Status: "locked",
-			Reason: "failed to acquire lock",
+			Status: "skipped",
+			Reason: "detached HEAD; doc tracking is per branch",
 		}, nil
 	}
-	defer lock.Release()
 
-	// Step 2: Read tracking to get base SHA
-	tracking, err := ru.trackingSvc.Read()
+	tracking, err := ru.trackingSvc.Read(branch)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read tracking: %w", err)
 	}
@@ -101,100 +127,465 @@ func (ru *RangeUpdater) Run() (*UpdateResult, error) {
 		return nil, fmt.Errorf("failed to get current SHA: %w", err)
 	}
 
-	// Initialize tracking if this is the first run
+	// A branch without tracking starts from its merge-base with the default branch;
+	// tracking is initialized at HEAD on the first run or when there is no fork point
 	if tracking.LastProcessedCommit == "" {
-		log.Printf("No tracking found, initializing with HEAD: %s", headSHA)
-		if err := ru.trackingSvc.Initialize(headSHA); err != nil {
-			return nil, fmt.Errorf("failed to initialize tracking: %w", err)
+		seed, err := ru.seedBase(headSHA)
+		if err != nil {
+			return nil, err
 		}
-		return &UpdateResult{
-			Status: "success",
-			Reason: "initialized tracking",
-		}, nil
+		if seed == "" {
+			log.Printf("No tracking found for %s, initializing with HEAD: %s", branch, headSHA)
+			if err := ru.trackingSvc.Initialize(branch, headSHA); err != nil {
+				return nil, fmt.Errorf("failed to initialize tracking: %w", err)
+			}
+			return &UpdateResult{
+				Status: "success",
+				Reason: "initialized tracking",
+				Branch: branch,
+			}, nil
+		}
+		log.Printf("No tracking found for %s, starting from merge-base %s", branch, shortSHA(seed))
+		tracking.LastProcessedCommit = seed
 	}
 
-	// Check if HEAD has changed
-	if tracking.LastProcessedCommit == headSHA {
+	// Check if HEAD has changed; index updates that failed earlier are retried regardless
+	retries := tracking.FailedIndexes
+	if tracking.LastProcessedCommit == headSHA && len(retries) == 0 {
 		return &UpdateResult{
 			Status: "skipped",
 			Reason: "no new commits since last update",
+			Branch: branch,
 		}, nil
 	}
 
 	baseSHA := tracking.LastProcessedCommit
+	filter, err := NewFileFilter(ru.config.IncludePatterns, ru.config.ExcludePatterns)
+	if err != nil {
+		return nil, err
+	}
 
-	// Step 3: Validate SHA reachability (fallback if unreachable)
-	valid, err := ru.gitSvc.ValidateCommit(baseSHA)
-	if err != nil || !valid {
-		log.Printf("Base commit %s is unreachable, attempting fallback", baseSHA)
-		fallbackSHA, err := HandleUnreachableBase(ru.gitSvc, ru.config.DefaultBranch)
+	// Steps 3-6: Collect the changed files, skip rules and affected indexes of base..HEAD
+	queue := make(map[string]*indexWork)
+	if baseSHA != headSHA {
+		changed, skipped, err := ru.collectRange(baseSHA, headSHA, filter)
 		if err != nil {
-			return nil, fmt.Errorf("failed to handle unreachable base: %w", err)
+			return nil, err
+		}
+		switch {
+		case skipped != nil && (len(retries) == 0 || skipped.Reason == reasonSkipDocsEnv):
+			// A [skip-docs] commit stays in base..HEAD until tracking moves past it
+			if skipped.Reason == reasonSkipDocsTag {
+				if err := ru.updateTracking(branch, headSHA, retries); err != nil {
+					return nil, err
+				}
+			}
+			skipped.Branch = branch
+			return skipped, nil
+		case skipped != nil:
+			// Nothing in the range needs documenting, but earlier failures are still retried
+			log.Printf("Range skipped (%s); retrying %d failed index update(s)", skipped.Reason, len(retries))
+		default:
+			baseSHA = changed.Changes.Base
+			for _, indexPath := range changed.Indexes {
+				queue[indexPath] = &indexWork{path: indexPath, files: changed.Files, changes: changed.Changes}
+			}
 		}
-		baseSHA = fallbackSHA
-		log.Printf("Using fallback base: %s", baseSHA)
 	}
+	processedRange := fmt.Sprintf("%s..%s", shortSHA(baseSHA), shortSHA(headSHA))
 
-	// Step 4: Get changed files for base..HEAD
-	changedFiles, err := ru.gitSvc.GetChangedFiles(baseSHA, headSHA)
+	// Failed indexes are regenerated over the whole range since their last success
+	if err := ru.queueRetries(queue, retries, headSHA, filter); err != nil {
+		return nil, err
+	}
+
+	if len(queue) == 0 {
+		// No indexes affected, but still update tracking
+		if err := ru.updateTracking(branch, headSHA, nil); err != nil {
+			return nil, err
+		}
+		return &UpdateResult{
+			Status:         "success",
+			Reason:         "no indexes affected by changes",
+			ProcessedRange: processedRange,
+			Branch:         branch,
+		}, nil
+	}
+
+	// Step 7: Update the indexes through the worker pool, children before parents,
+	// queueing parents of created or significantly changed indexes when propagation is enabled
+	log.Printf("Updating %d index.md files", len(queue))
+	results := ru.updateIndexes(queue)
+
+	// Step 8: Advance tracking to HEAD; failed indexes keep the base of their range for a retry
+	failed := make(map[string]string)
+	var updated []string
+	for _, r := range results {
+		if r.Status == IndexFailed {
+			failed[r.Path] = queue[r.Path].changes.Base
+			continue
+		}
+		updated = append(updated, r.Path)
+	}
+	if err := ru.updateTracking(branch, headSHA, failed); err != nil {
+		return nil, err
+	}
+
+	result := &UpdateResult{
+		Status:          "success",
+		AffectedIndexes: updated,
+		Indexes:         results,
+		ProcessedRange:  processedRange,
+		Branch:          branch,
+	}
+	if len(failed) > 0 {
+		result.Status = "partial"
+		result.Reason = fmt.Sprintf("%d of %d index updates failed and will be retried on the next run", len(failed), len(results))
+	}
+	return result, nil
+}
+
+// RunPending updates the indexes affected by uncommitted changes: the staged changes or the
+// whole working tree. Tracking is left alone since the changes have no commit yet. With the
+// staged scope the updated index.md files are staged too, so a pre-commit hook lands them
+// in the commit being made.
+func (ru *RangeUpdater) RunPending(scope git.Scope) (*UpdateResult, error) {
+	lock, locked, err := ru.acquireLock()
+	if lock == nil {
+		return locked, err
+	}
+	defer lock.Release()
+
+	processedRange := string(scope) + " changes"
+	filter, err := NewFileFilter(ru.config.IncludePatterns, ru.config.ExcludePatterns)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get changed files: %w", err)
+		return nil, err
 	}
 
+	changedFiles, err := ru.gitSvc.GetPendingFiles(scope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s changes: %w", scope, err)
+	}
 	if len(changedFiles) == 0 {
 		return &UpdateResult{
-			Status: "skipped",
-			Reason: "no files changed",
+			Status:         "skipped",
+			Reason:         "no files changed",
+			ProcessedRange: processedRange,
 		}, nil
 	}
 
-	// Step 5: Apply skip rules
-	shouldSkip, reason := ShouldSkip(changedFiles, "", ru.env)
-	if shouldSkip {
+	changedFiles, dropped := filter.Apply(changedFiles)
+	if len(dropped) > 0 {
+		log.Printf("Ignoring %d changed file(s) filtered by include/exclude patterns", len(dropped))
+	}
+	if len(changedFiles) == 0 {
+		return &UpdateResult{
+			Status:         "skipped",
+			Reason:         "all changed files are filtered by include/exclude patterns",
+			ProcessedRange: processedRange,
+		}, nil
+	}
+
+	// There is no commit message yet, so only the env var and docs-only rules apply
+	if shouldSkip, reason := ShouldSkip(changedFiles, "", ru.env); shouldSkip {
 		return &UpdateResult{
 			Status:         "skipped",
 			Reason:         reason,
-			ProcessedRange: fmt.Sprintf("%s..%s", shortSHA(baseSHA), shortSHA(headSHA)),
+			ProcessedRange: processedRange,
 		}, nil
 	}
 
-	// Step 6: Map files to affected index.md
 	affectedIndexes, err := ResolveAffectedIndexes(ru.fs, changedFiles)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve affected indexes: %w", err)
 	}
-
 	if len(affectedIndexes) == 0 {
-		// No indexes affected, but still update tracking
-		if err := ru.updateTracking(headSHA); err != nil {
-			return nil, err
-		}
 		return &UpdateResult{
 			Status:         "success",
 			Reason:         "no indexes affected by changes",
-			ProcessedRange: fmt.Sprintf("%s..%s", shortSHA(baseSHA), shortSHA(headSHA)),
+			ProcessedRange: processedRange,
 		}, nil
 	}
 
-	// Step 7: Update each index via Claude
-	log.Printf("Updating %d index.md files", len(affectedIndexes))
+	changes := loadPendingChangeSet(ru.gitSvc, scope)
+	queue := make(map[string]*indexWork)
 	for _, indexPath := range affectedIndexes {
-		if err := ru.updateIndex(indexPath, changedFiles); err != nil {
-			log.Printf("Warning: failed to update %s: %v", indexPath, err)
-			// Continue with other indexes even if one fails
+		queue[indexPath] = &indexWork{path: indexPath, files: changedFiles, changes: changes}
+	}
+
+	// Checked before the update, whose own rewrite shows up as an unstaged change
+	var dirty map[string]bool
+	if scope == git.ScopeStaged {
+		dirty, err = ru.unstagedIndexes(queue)
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	// Step 8: Write tracking with new HEAD
-	if err := ru.updateTracking(headSHA); err != nil {
-		return nil, err
+	log.Printf("Updating %d index.md files from %s changes", len(queue), scope)
+	results := ru.updateIndexes(queue)
+
+	var updated, rewritten []string
+	failed := 0
+	for _, r := range results {
+		switch r.Status {
+		case IndexFailed:
+			failed++
+			continue
+		case IndexUpdated:
+			rewritten = append(rewritten, r.Path)
+		}
+		updated = append(updated, r.Path)
+	}
+
+	var unstaged []string
+	if scope == git.ScopeStaged {
+		var toStage []string
+		for _, indexPath := range rewritten {
+			if dirty[indexPath] {
+				log.Printf("Warning: not staging %s: it had unstaged changes before the update", indexPath)
+				unstaged = append(unstaged, indexPath)
+				continue
+			}
+			toStage = append(toStage, indexPath)
+		}
+		if err := ru.gitSvc.StageFiles(toStage); err != nil {
+			return nil, fmt.Errorf("failed to stage updated indexes: %w", err)
+		}
 	}
 
-	return &UpdateResult{
+	result := &UpdateResult{
 		Status:          "success",
-		AffectedIndexes: affectedIndexes,
-		ProcessedRange:  fmt.Sprintf("%s..%s", shortSHA(baseSHA), shortSHA(headSHA)),
-	}, nil
+		AffectedIndexes: updated,
+		Indexes:         results,
+		ProcessedRange:  processedRange,
+		Unstaged:        unstaged,
+	}
+	if failed > 0 {
+		result.Status = "partial"
+		result.Reason = fmt.Sprintf("%d of %d index updates failed", failed, len(results))
+	}
+	return result, nil
+}
+
+// acquireLock takes the doc update lock. When it returns no lock, the UpdateResult
+// reports that another update holds it.
+func (ru *RangeUpdater) acquireLock() (*lock.Lock, *UpdateResult, error) {
+	lockPath := filepath.Join(ru.config.SessionPath, "doc_update.lock")
+	isLocked, err := ru.lockSvc.IsLocked(lockPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to check lock status: %w", err)
+	}
+	if isLocked {
+		return nil, &UpdateResult{
+			Status: "locked",
+			Reason: "another update process is running",
+		}, nil
+	}
+
+	held, err := ru.lockSvc.Acquire(lockPath)
+	if err != nil {
+		reason := "failed to acquire lock"
+		if errors.Is(err, lock.ErrHeld) {
+			// Another update took the lock after the IsLocked check
+			reason = "another update process is running"
+		}
+		return nil, &UpdateResult{
+			Status: "locked",
+			Reason: reason,
+		}, nil
+	}
+	return held, nil, nil
+}
+
+// rangeChanges is what base..HEAD changed once filters and skip rules are applied
+type rangeChanges struct {
+	Files   []string
+	Changes *ChangeSet
+	Indexes []string
+}
+
+// collectRange resolves the base commit, the filtered changed files, the commit log and
+// the affected indexes of base..head. A non-nil UpdateResult means the range is skipped.
+func (ru *RangeUpdater) collectRange(baseSHA, headSHA string, filter *FileFilter) (*rangeChanges, *UpdateResult, error) {
+	// Step 3: Validate SHA reachability (fallback if unreachable)
+	baseSHA, err := ru.resolveBase(baseSHA)
+	if err != nil {
+		return nil, nil, err
+	}
+	processedRange := fmt.Sprintf("%s..%s", shortSHA(baseSHA), shortSHA(headSHA))
+
+	// Step 4: Get changed files for base..HEAD
+	changedFiles, err := ru.gitSvc.GetChangedFiles(baseSHA, headSHA)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get changed files: %w", err)
+	}
+
+	if len(changedFiles) == 0 {
+		return nil, &UpdateResult{
+			Status: "skipped",
+			Reason: "no files changed",
+		}, nil
+	}
+
+	// Step 5: Drop files excluded by the include/exclude patterns, then apply skip rules
+	changedFiles, dropped := filter.Apply(changedFiles)
+	if len(dropped) > 0 {
+		log.Printf("Ignoring %d changed file(s) filtered by include/exclude patterns", len(dropped))
+	}
+	if len(changedFiles) == 0 {
+		return nil, &UpdateResult{
+			Status:         "skipped",
+			Reason:         "all changed files are filtered by include/exclude patterns",
+			ProcessedRange: processedRange,
+		}, nil
+	}
+
+	changes, err := loadChangeSet(ru.gitSvc, baseSHA, headSHA)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// [skip-docs] opts out only the files its commits touched; files also changed by
+	// untagged commits are still documented
+	docFiles, err := ru.dropSkipDocsFiles(baseSHA, headSHA, changedFiles, changes.Commits)
+	if err != nil {
+		return nil, nil, err
+	}
+	commitMsg := ""
+	if len(docFiles) == 0 {
+		// Every change comes from tagged commits
+		docFiles, commitMsg = changedFiles, changes.Messages()
+	}
+
+	shouldSkip, reason := ShouldSkip(docFiles, commitMsg, ru.env)
+	if shouldSkip {
+		return nil, &UpdateResult{
+			Status:         "skipped",
+			Reason:         reason,
+			ProcessedRange: processedRange,
+		}, nil
+	}
+
+	if dropped := len(changedFiles) - len(docFiles); dropped > 0 {
+		log.Printf("Ignoring %d changed file(s) touched only by %s commits", dropped, skipDocsTag)
+	}
+	changedFiles = docFiles
+
+	// Step 6: Map files to affected index.md
+	affectedIndexes, err := ResolveAffectedIndexes(ru.fs, changedFiles)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve affected indexes: %w", err)
+	}
+
+	return &rangeChanges{Files: changedFiles, Changes: changes, Indexes: affectedIndexes}, nil, nil
+}
+
+// unstagedIndexes returns the queued indexes, and the parents propagation may rewrite,
+// whose working tree copy has changes that are not staged
+func (ru *RangeUpdater) unstagedIndexes(queue map[string]*indexWork) (map[string]bool, error) {
+	checked := make(map[string]bool)
+	dirty := make(map[string]bool)
+	for indexPath := range queue {
+		for level, path := 0, indexPath; path != "" && level <= ru.config.PropagationDepth; level, path = level+1, findParentIndexMd(ru.fs, path, ru.config.RootDir) {
+			if checked[path] {
+				continue
+			}
+			checked[path] = true
+			unstaged, err := ru.gitSvc.HasUnstagedChanges(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check %s for unstaged changes: %w", path, err)
+			}
+			if unstaged {
+				dirty[path] = true
+			}
+		}
+	}
+	return dirty, nil
+}
+
+// dropSkipDocsFiles returns the files of base..head changed by at least one commit
+// without the [skip-docs] tag
+func (ru *RangeUpdater) dropSkipDocsFiles(base, head string, files []string, commits []git.Commit) ([]string, error) {
+	tagged := 0
+	for _, commit := range commits {
+		if strings.Contains(commit.Message(), skipDocsTag) {
+			tagged++
+		}
+	}
+	if tagged == 0 {
+		return files, nil
+	}
+	if tagged == len(commits) {
+		return nil, nil
+	}
+
+	var kept []string
+	for _, file := range files {
+		fileCommits, err := ru.gitSvc.GetPathCommits(base, head, []string{file})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get commits of %s: %w", file, err)
+		}
+		for _, commit := range fileCommits {
+			if !strings.Contains(commit.Message(), skipDocsTag) {
+				kept = append(kept, file)
+				break
+			}
+		}
+	}
+	return kept, nil
+}
+
+// resolveBase returns baseSHA, or the merge-base fallback when it is no longer reachable
+func (ru *RangeUpdater) resolveBase(baseSHA string) (string, error) {
+	valid, err := ru.gitSvc.ValidateCommit(baseSHA)
+	if err == nil && valid {
+		return baseSHA, nil
+	}
+
+	log.Printf("Base commit %s is unreachable, attempting fallback", baseSHA)
+	fallbackSHA, err := HandleUnreachableBase(ru.gitSvc, ru.config.DefaultBranch)
+	if err != nil {
+		return "", fmt.Errorf("failed to handle unreachable base: %w", err)
+	}
+	log.Printf("Using fallback base: %s", fallbackSHA)
+	return fallbackSHA, nil
+}
+
+// queueRetries queues the indexes whose update failed in an earlier run with the changes
+// of their whole pending range, replacing any narrower entry for the same index.
+// Indexes that no longer exist are dropped.
+func (ru *RangeUpdater) queueRetries(queue map[string]*indexWork, retries map[string]string, headSHA string, filter *FileFilter) error {
+	byBase := make(map[string][]string)
+	for indexPath, base := range retries {
+		if exists, _ := afero.Exists(ru.fs, indexPath); !exists {
+			log.Printf("Dropping retry for %s: index no longer exists", indexPath)
+			continue
+		}
+		byBase[base] = append(byBase[base], indexPath)
+	}
+
+	for base, indexPaths := range byBase {
+		baseSHA, err := ru.resolveBase(base)
+		if err != nil {
+			return err
+		}
+		changedFiles, err := ru.gitSvc.GetChangedFiles(baseSHA, headSHA)
+		if err != nil {
+			return fmt.Errorf("failed to get changed files: %w", err)
+		}
+		changedFiles, _ = filter.Apply(changedFiles)
+		changes, err := loadChangeSet(ru.gitSvc, baseSHA, headSHA)
+		if err != nil {
+			return err
+		}
+
+		for _, indexPath := range indexPaths {
+			log.Printf("Retrying %s over %s..%s", indexPath, shortSHA(baseSHA), shortSHA(headSHA))
+			queue[indexPath] = &indexWork{path: indexPath, files: changedFiles, changes: changes}
+		}
+	}
+	return nil
 }
 
 // shortSHA returns a short version of the SHA (first 7 chars) or the full SHA if shorter
@@ -205,21 +596,57 @@ func shortSHA(sha string) string {
 	return sha
 }
 
-// updateIndex updates a single index.md file via Claude
-func (ru *RangeUpdater) updateIndex(indexPath string, changedFiles []string) error {
-	indexDir := filepath.Dir(indexPath)
+// updateIndex updates a single index.md file via the LLM backend and reports its
+// content before and after the update
+func (ru *RangeUpdater) updateIndex(work *indexWork) (indexChange, error) {
+	indexDir := filepath.Dir(work.path)
+	change := indexChange{path: work.path}
 
 	// Get directory listing for context
 	listing, err := ru.getDirectoryListing(indexDir)
 	if err != nil {
-		return fmt.Errorf("failed to get directory listing: %w", err)
+		return change, fmt.Errorf("failed to get directory listing: %w", err)
 	}
 
 	// Format changed files for context
-	filesContext := formatChangedFilesContext(changedFiles, indexDir)
+	filesContext := formatChangedFilesContext(work.files, indexDir)
+
+	// Summarize commit subjects, the diffs under this index and the child index updates
+	summary := work.changes.Summary(ru.gitSvc, indexDir, work.files)
+	if len(work.children) > 0 {
+		summary = strings.TrimSpace(summary + "\n\nINDEX UPDATES BELOW THIS DIRECTORY:\n" + strings.Join(work.children, "\n"))
+	}
+
+	before, err := afero.ReadFile(ru.fs, work.path)
+	if err != nil && !os.IsNotExist(err) {
+		return change, fmt.Errorf("failed to read %s: %w", work.path, err)
+	}
+	change.before = string(before)
+	change.after = change.before
+
+	// Ask the model for the updated index
+	content, err := InvokeClaudeForIndex(ru.llm, ru.env, IndexUpdate{
+		IndexPath:     work.path,
+		Current:       change.before,
+		Listing:       listing,
+		ModifiedFiles: filesContext,
+		ChangeSummary: summary,
+		Timeout:       ru.config.IndexTimeout,
+	})
+	if err != nil {
+		return change, err
+	}
+	if content == "" {
+		log.Printf("No changes needed for %s", work.path)
+		return change, nil
+	}
 
-	// Invoke Claude to update the index file directly
-	return InvokeClaudeForIndex(ru.cmdr, ru.env, indexPath, listing, filesContext)
+	change.after = content + "\n"
+	if err := afero.WriteFile(ru.fs, work.path, []byte(change.after), 0644); err != nil {
+		return change, fmt.Errorf("failed to write %s: %w", work.path, err)
+	}
+	log.Printf("Updated %s", work.path)
+	return change, nil
 }
 
 // getDirectoryListing returns a formatted listing of files in the directory
@@ -271,15 +698,85 @@ func formatChangedFilesContext(changedFiles []string, indexDir string) string {
 	return result
 }
 
-// updateTracking updates the tracking state with the new HEAD SHA
-func (ru *RangeUpdater) updateTracking(headSHA string) error {
+// updateTracking moves the branch's tracking state to the new HEAD SHA, records the
+// indexes whose update failed with the base commit their retry starts from, and drops
+// the entries of branches that no longer exist
+func (ru *RangeUpdater) updateTracking(branch, headSHA string, failed map[string]string) error {
 	tracking := doctracking.DocUpdateTracking{
 		LastProcessedCommit: headSHA,
 		UpdatedAt:           time.Now().Format(time.RFC3339),
 		StrategyVersion:     "v1",
 	}
-	if err := ru.trackingSvc.Write(tracking); err != nil {
+	if len(failed) > 0 {
+		tracking.FailedIndexes = failed
+	}
+	if err := ru.trackingSvc.Write(branch, tracking); err != nil {
 		return fmt.Errorf("failed to write tracking: %w", err)
 	}
+
+	pruned, err := PruneTracking(ru.gitSvc, ru.trackingSvc)
+	if err != nil {
+		log.Printf("Warning: failed to prune tracking of deleted branches: %v", err)
+	}
+	for _, name := range pruned {
+		log.Printf("Dropped doc tracking of deleted branch %s", name)
+	}
 	return nil
 }
+
+// seedBase returns the commit a branch without tracking starts from: its merge-base with
+// the default branch. It returns "" on the very first run (no branch is tracked yet), when
+// no merge-base is found, or when HEAD is the merge-base (e.g. on the default branch).
+func (ru *RangeUpdater) seedBase(headSHA string) (string, error) {
+	entries, err := ru.trackingSvc.List()
+	if err != nil {
+		return "", fmt.Errorf("failed to read tracking: %w", err)
+	}
+	if len(entries) == 0 {
+		return "", nil
+	}
+
+	seed, err := HandleUnreachableBase(ru.gitSvc, ru.config.DefaultBranch)
+	if err != nil {
+		log.Printf("No merge-base found for new branch: %v", err)
+		return "", nil
+	}
+	if seed == headSHA {
+		return "", nil
+	}
+	return seed, nil
+}
+
+// PruneTracking deletes the tracking entries of branches that no longer exist locally
+// and returns the pruned branch names, sorted
+func PruneTracking(gitSvc git.GitService, trackingSvc doctracking.TrackingService) ([]string, error) {
+	branches, err := gitSvc.ListBranches()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	if len(branches) == 0 {
+		// Never wipe tracking because git reported no branches
+		return nil, nil
+	}
+	existing := make(map[string]bool, len(branches))
+	for _, branch := range branches {
+		existing[branch] = true
+	}
+
+	entries, err := trackingSvc.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tracking: %w", err)
+	}
+	var pruned []string
+	for branch := range entries {
+		if existing[branch] {
+			continue
+		}
+		if err := trackingSvc.Delete(branch); err != nil {
+			return pruned, fmt.Errorf("failed to delete tracking of %s: %w", branch, err)
+		}
+		pruned = append(pruned, branch)
+	}
+	sort.Strings(pruned)
+	return pruned, nil
+}
@@ -4,10 +4,14 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"claudex/internal/services/doctracking"
+	"claudex/internal/services/git"
+	"claudex/internal/services/llm"
 	"claudex/internal/services/lock"
 
 	"github.com/spf13/afero"
@@ -23,6 +27,17 @@ type mockGitService struct {
 	validateError  error
 	changedError   error
 	mergeBaseError error
+	commits        []git.Commit
+	diffStat       []git.FileStat
+	fileDiffs      map[string]string
+	addedFiles     []string
+	branch         string // "main" when empty
+	detached       bool
+	branches       []string
+	pendingScopes  []git.Scope // scopes the pending-change methods were asked for
+	staged         []string
+	pathCommits    map[string][]git.Commit // commits that touched each file
+	unstaged       map[string]bool         // files with unstaged working tree changes
 }
 
 func (m *mockGitService) GetCurrentSHA() (string, error) {
@@ -47,6 +62,86 @@ func (m *mockGitService) GetMergeBase(branch string) (string, error) {
 	return m.mergeBase, nil
 }
 
+func (m *mockGitService) GetCommits(base, head string) ([]git.Commit, error) {
+	return m.commits, nil
+}
+
+func (m *mockGitService) GetDiffStat(base, head string) ([]git.FileStat, error) {
+	return m.diffStat, nil
+}
+
+func (m *mockGitService) GetFileDiff(base, head, path string) (string, error) {
+	return m.fileDiffs[path], nil
+}
+
+func (m *mockGitService) GetAddedFiles(base, head string) ([]string, error) {
+	return m.addedFiles, nil
+}
+
+func (m *mockGitService) GetCurrentBranch() (string, error) {
+	if m.detached {
+		return "", nil
+	}
+	if m.branch == "" {
+		return "main", nil
+	}
+	return m.branch, nil
+}
+
+func (m *mockGitService) ListBranches() ([]string, error) {
+	return m.branches, nil
+}
+
+func (m *mockGitService) GetDefaultBranch() (string, error) {
+	return "main", nil
+}
+
+func (m *mockGitService) GetRepoRoot() (string, error) {
+	return "/repo", nil
+}
+
+func (m *mockGitService) ListFiles() ([]string, error) {
+	return nil, nil
+}
+
+func (m *mockGitService) GetLastCommit(path string) (git.Commit, error) {
+	return git.Commit{}, nil
+}
+
+func (m *mockGitService) GetPathCommits(base, head string, paths []string) ([]git.Commit, error) {
+	var commits []git.Commit
+	for _, path := range paths {
+		commits = append(commits, m.pathCommits[path]...)
+	}
+	return commits, nil
+}
+
+func (m *mockGitService) GetPendingFiles(scope git.Scope) ([]string, error) {
+	m.pendingScopes = append(m.pendingScopes, scope)
+	return m.changedFiles, m.changedError
+}
+
+func (m *mockGitService) GetPendingDiffStat(scope git.Scope) ([]git.FileStat, error) {
+	return m.diffStat, nil
+}
+
+func (m *mockGitService) GetPendingFileDiff(scope git.Scope, path string) (string, error) {
+	return m.fileDiffs[path], nil
+}
+
+func (m *mockGitService) GetPendingAddedFiles(scope git.Scope) ([]string, error) {
+	return m.addedFiles, nil
+}
+
+func (m *mockGitService) HasUnstagedChanges(path string) (bool, error) {
+	return m.unstaged[path], nil
+}
+
+func (m *mockGitService) StageFiles(paths []string) error {
+	m.staged = append(m.staged, paths...)
+	return nil
+}
+
 type mockLockService struct {
 	isLocked     bool
 	acquireFails bool
@@ -94,26 +189,36 @@ func (m *mockFile) WriteString(s string) (ret int, err error) {
 	return len(s), nil
 }
 
+// mockTrackingService keeps the entry of the branch under test in tracking and the
+// entries of any other branch in others
 type mockTrackingService struct {
 	tracking    doctracking.DocUpdateTracking
+	others      map[string]doctracking.DocUpdateTracking
 	writeError  error
 	writeCalled bool
 }
 
-func (m *mockTrackingService) Read() (doctracking.DocUpdateTracking, error) {
+func (m *mockTrackingService) Read(branch string) (doctracking.DocUpdateTracking, error) {
+	if other, ok := m.others[branch]; ok {
+		return other, nil
+	}
 	return m.tracking, nil
 }
 
-func (m *mockTrackingService) Write(tracking doctracking.DocUpdateTracking) error {
+func (m *mockTrackingService) Write(branch string, tracking doctracking.DocUpdateTracking) error {
 	if m.writeError != nil {
 		return m.writeError
 	}
+	if _, ok := m.others[branch]; ok {
+		m.others[branch] = tracking
+		return nil
+	}
 	m.tracking = tracking
 	m.writeCalled = true
 	return nil
 }
 
-func (m *mockTrackingService) Initialize(headSHA string) error {
+func (m *mockTrackingService) Initialize(branch, headSHA string) error {
 	m.tracking = doctracking.DocUpdateTracking{
 		LastProcessedCommit: headSHA,
 		UpdatedAt:           time.Now().Format(time.RFC3339),
@@ -122,16 +227,19 @@ func (m *mockTrackingService) Initialize(headSHA string) error {
 	return nil
 }
 
-type mockCommander struct {
-	output []byte
-	err    error
-}
-
-func (m *mockCommander) Run(name string, args ...string) ([]byte, error) {
-	return m.output, m.err
+func (m *mockTrackingService) List() (map[string]doctracking.DocUpdateTracking, error) {
+	entries := make(map[string]doctracking.DocUpdateTracking)
+	for branch, tracking := range m.others {
+		entries[branch] = tracking
+	}
+	if m.tracking.LastProcessedCommit != "" {
+		entries["main"] = m.tracking
+	}
+	return entries, nil
 }
 
-func (m *mockCommander) Start(name string, stdin io.Reader, stdout, stderr io.Writer, args ...string) error {
+func (m *mockTrackingService) Delete(branch string) error {
+	delete(m.others, branch)
 	return nil
 }
 
@@ -162,7 +270,7 @@ func TestRangeUpdater_Run_FirstRun_Initializes(t *testing.T) {
 	}
 	lockSvc := newMockLockService()
 	trackingSvc := &mockTrackingService{}
-	cmdr := &mockCommander{}
+	client := llm.NewFake(noChangesMarker)
 	env := &mockEnvironment{vars: make(map[string]string)}
 
 	config := RangeUpdaterConfig{
@@ -170,7 +278,7 @@ func TestRangeUpdater_Run_FirstRun_Initializes(t *testing.T) {
 		DefaultBranch: "main",
 	}
 
-	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env)
+	updater := New(config, gitSvc, lockSvc, trackingSvc, client, fs, env)
 	result, err := updater.Run()
 
 	if err != nil {
@@ -200,7 +308,7 @@ func TestRangeUpdater_Run_NoNewCommits_Skips(t *testing.T) {
 			LastProcessedCommit: "abc123", // Same as current
 		},
 	}
-	cmdr := &mockCommander{}
+	client := llm.NewFake(noChangesMarker)
 	env := &mockEnvironment{vars: make(map[string]string)}
 
 	config := RangeUpdaterConfig{
@@ -208,7 +316,7 @@ func TestRangeUpdater_Run_NoNewCommits_Skips(t *testing.T) {
 		DefaultBranch: "main",
 	}
 
-	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env)
+	updater := New(config, gitSvc, lockSvc, trackingSvc, client, fs, env)
 	result, err := updater.Run()
 
 	if err != nil {
@@ -235,7 +343,7 @@ func TestRangeUpdater_Run_Locked_SkipsWithLockStatus(t *testing.T) {
 	lockSvc := newMockLockService()
 	lockSvc.isLocked = true // Already locked
 	trackingSvc := &mockTrackingService{}
-	cmdr := &mockCommander{}
+	client := llm.NewFake(noChangesMarker)
 	env := &mockEnvironment{vars: make(map[string]string)}
 
 	config := RangeUpdaterConfig{
@@ -243,7 +351,7 @@ func TestRangeUpdater_Run_Locked_SkipsWithLockStatus(t *testing.T) {
 		DefaultBranch: "main",
 	}
 
-	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env)
+	updater := New(config, gitSvc, lockSvc, trackingSvc, client, fs, env)
 	result, err := updater.Run()
 
 	if err != nil {
@@ -271,7 +379,7 @@ func TestRangeUpdater_Run_SkipRules_AllMarkdown(t *testing.T) {
 			LastProcessedCommit: "abc123",
 		},
 	}
-	cmdr := &mockCommander{}
+	client := llm.NewFake(noChangesMarker)
 	env := &mockEnvironment{vars: make(map[string]string)}
 
 	config := RangeUpdaterConfig{
@@ -279,7 +387,7 @@ func TestRangeUpdater_Run_SkipRules_AllMarkdown(t *testing.T) {
 		DefaultBranch: "main",
 	}
 
-	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env)
+	updater := New(config, gitSvc, lockSvc, trackingSvc, client, fs, env)
 	result, err := updater.Run()
 
 	if err != nil {
@@ -311,7 +419,7 @@ func TestRangeUpdater_Run_SkipRules_EnvVar(t *testing.T) {
 			LastProcessedCommit: "abc123",
 		},
 	}
-	cmdr := &mockCommander{}
+	client := llm.NewFake(noChangesMarker)
 	env := &mockEnvironment{
 		vars: map[string]string{
 			"CLAUDEX_SKIP_DOCS": "1",
@@ -323,7 +431,7 @@ func TestRangeUpdater_Run_SkipRules_EnvVar(t *testing.T) {
 		DefaultBranch: "main",
 	}
 
-	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env)
+	updater := New(config, gitSvc, lockSvc, trackingSvc, client, fs, env)
 	result, err := updater.Run()
 
 	if err != nil {
@@ -356,7 +464,7 @@ func TestRangeUpdater_Run_UnreachableBase_UsesFallback(t *testing.T) {
 			LastProcessedCommit: "unreachable",
 		},
 	}
-	cmdr := &mockCommander{}
+	client := llm.NewFake(noChangesMarker)
 	env := &mockEnvironment{vars: make(map[string]string)}
 
 	config := RangeUpdaterConfig{
@@ -368,7 +476,7 @@ func TestRangeUpdater_Run_UnreachableBase_UsesFallback(t *testing.T) {
 	fs.MkdirAll("/src", 0755)
 	afero.WriteFile(fs, "/src/index.md", []byte("# Index"), 0644)
 
-	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env)
+	updater := New(config, gitSvc, lockSvc, trackingSvc, client, fs, env)
 	result, err := updater.Run()
 
 	// Should succeed with fallback
@@ -402,7 +510,7 @@ func TestRangeUpdater_Run_NoAffectedIndexes_UpdatesTracking(t *testing.T) {
 			LastProcessedCommit: "abc123",
 		},
 	}
-	cmdr := &mockCommander{}
+	client := llm.NewFake(noChangesMarker)
 	env := &mockEnvironment{vars: make(map[string]string)}
 
 	config := RangeUpdaterConfig{
@@ -410,7 +518,7 @@ func TestRangeUpdater_Run_NoAffectedIndexes_UpdatesTracking(t *testing.T) {
 		DefaultBranch: "main",
 	}
 
-	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env)
+	updater := New(config, gitSvc, lockSvc, trackingSvc, client, fs, env)
 	result, err := updater.Run()
 
 	if err != nil {
@@ -431,168 +539,956 @@ func TestRangeUpdater_Run_NoAffectedIndexes_UpdatesTracking(t *testing.T) {
 	}
 }
 
-func TestResolveAffectedIndexes_MultipleIndexes(t *testing.T) {
+func TestRangeUpdater_Run_ExcludedFilesOnly_Skips(t *testing.T) {
 	fs := afero.NewMemMapFs()
+	sessionPath := "/session"
+	fs.MkdirAll(sessionPath, 0755)
 
-	// Create directory structure with indexes
-	fs.MkdirAll("/pkg/a", 0755)
-	fs.MkdirAll("/pkg/b", 0755)
-	afero.WriteFile(fs, "/pkg/a/index.md", []byte("# A"), 0644)
-	afero.WriteFile(fs, "/pkg/b/index.md", []byte("# B"), 0644)
+	gitSvc := &mockGitService{
+		currentSHA:     "def456",
+		changedFiles:   []string{"go.sum", "web/package-lock.json", "vendor/lib/a.go"},
+		validateResult: true,
+	}
+	lockSvc := newMockLockService()
+	trackingSvc := &mockTrackingService{
+		tracking: doctracking.DocUpdateTracking{
+			LastProcessedCommit: "abc123",
+		},
+	}
+	client := llm.NewFake(noChangesMarker)
+	env := &mockEnvironment{vars: make(map[string]string)}
 
-	changedFiles := []string{
-		"/pkg/a/foo.go",
-		"/pkg/a/bar.go",
-		"/pkg/b/baz.go",
+	config := RangeUpdaterConfig{
+		SessionPath:     sessionPath,
+		DefaultBranch:   "main",
+		ExcludePatterns: []string{"go.sum", "package-lock.json", "vendor/"},
 	}
 
-	indexes, err := ResolveAffectedIndexes(fs, changedFiles)
+	updater := New(config, gitSvc, lockSvc, trackingSvc, client, fs, env)
+	result, err := updater.Run()
+
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if len(indexes) != 2 {
-		t.Errorf("expected 2 affected indexes, got %d", len(indexes))
-	}
-
-	// Verify both indexes are present
-	hasA := false
-	hasB := false
-	for _, idx := range indexes {
-		if idx == "/pkg/a/index.md" {
-			hasA = true
-		}
-		if idx == "/pkg/b/index.md" {
-			hasB = true
-		}
+	if result.Status != "skipped" {
+		t.Errorf("expected status 'skipped', got '%s'", result.Status)
 	}
 
-	if !hasA || !hasB {
-		t.Errorf("expected both /pkg/a/index.md and /pkg/b/index.md, got %v", indexes)
+	if len(client.Requests()) != 0 {
+		t.Errorf("expected no model calls, got %d", len(client.Requests()))
 	}
 }
 
-func TestResolveAffectedIndexes_Deduplication(t *testing.T) {
+func TestRangeUpdater_Run_FiltersFilesBeforeResolvingIndexes(t *testing.T) {
 	fs := afero.NewMemMapFs()
+	sessionPath := "/session"
+	fs.MkdirAll(sessionPath, 0755)
+	afero.WriteFile(fs, "/repo/src/index.md", []byte("# Src"), 0644)
+	afero.WriteFile(fs, "/repo/gen/index.md", []byte("# Generated"), 0644)
 
-	// Create directory structure
-	fs.MkdirAll("/pkg/a", 0755)
-	afero.WriteFile(fs, "/pkg/a/index.md", []byte("# A"), 0644)
+	gitSvc := &mockGitService{
+		currentSHA:     "def456",
+		changedFiles:   []string{"/repo/src/a.go", "/repo/src/a_test.go", "/repo/gen/model_gen.go"},
+		validateResult: true,
+	}
+	lockSvc := newMockLockService()
+	trackingSvc := &mockTrackingService{
+		tracking: doctracking.DocUpdateTracking{
+			LastProcessedCommit: "abc123",
+		},
+	}
+	client := llm.NewFake(noChangesMarker)
+	env := &mockEnvironment{vars: make(map[string]string)}
 
-	changedFiles := []string{
-		"/pkg/a/foo.go",
-		"/pkg/a/bar.go",
-		"/pkg/a/baz.go",
+	config := RangeUpdaterConfig{
+		SessionPath:     sessionPath,
+		DefaultBranch:   "main",
+		IncludePatterns: []string{"*.go"},
+		ExcludePatterns: []string{"*_gen.go", "*_test.go"},
 	}
 
-	indexes, err := ResolveAffectedIndexes(fs, changedFiles)
+	updater := New(config, gitSvc, lockSvc, trackingSvc, client, fs, env)
+	result, err := updater.Run()
+
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if len(indexes) != 1 {
-		t.Errorf("expected 1 affected index (deduplicated), got %d", len(indexes))
+	if len(result.AffectedIndexes) != 1 || result.AffectedIndexes[0] != "/repo/src/index.md" {
+		t.Errorf("expected only /repo/src/index.md to be affected, got %v", result.AffectedIndexes)
 	}
 
-	if indexes[0] != "/pkg/a/index.md" {
-		t.Errorf("expected /pkg/a/index.md, got %s", indexes[0])
+	requests := client.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 model call, got %d", len(requests))
+	}
+	if strings.Contains(requests[0].Prompt, "a_test.go") {
+		t.Errorf("expected excluded files to be left out of the prompt")
 	}
 }
 
-func TestShouldSkip_EnvVar(t *testing.T) {
-	env := &mockEnvironment{
-		vars: map[string]string{
-			"CLAUDEX_SKIP_DOCS": "1",
+func TestRangeUpdater_Run_SkipDocsTagOnEveryCommit_SkipsAndAdvancesTracking(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	sessionPath := "/session"
+	fs.MkdirAll(sessionPath, 0755)
+	afero.WriteFile(fs, "/repo/src/index.md", []byte("# Src"), 0644)
+
+	gitSvc := &mockGitService{
+		currentSHA:     "def456",
+		changedFiles:   []string{"/repo/src/a.go"},
+		validateResult: true,
+		commits: []git.Commit{
+			{SHA: "def456", Subject: "chore: format [skip-docs]"},
+			{SHA: "ccc111", Subject: "chore: rename", Body: "Mechanical change [skip-docs]"},
 		},
 	}
-
-	skip, reason := ShouldSkip([]string{"foo.go"}, "", env)
-
-	if !skip {
-		t.Error("expected skip to be true for CLAUDEX_SKIP_DOCS=1")
+	lockSvc := newMockLockService()
+	trackingSvc := &mockTrackingService{
+		tracking: doctracking.DocUpdateTracking{
+			LastProcessedCommit: "abc123",
+		},
 	}
+	client := llm.NewFake(noChangesMarker)
+	env := &mockEnvironment{vars: make(map[string]string)}
 
-	if reason == "" {
-		t.Error("expected reason to be set")
+	config := RangeUpdaterConfig{
+		SessionPath:   sessionPath,
+		DefaultBranch: "main",
 	}
-}
 
-func TestShouldSkip_CommitMessageTag(t *testing.T) {
-	env := &mockEnvironment{vars: make(map[string]string)}
+	updater := New(config, gitSvc, lockSvc, trackingSvc, client, fs, env)
+	result, err := updater.Run()
 
-	skip, reason := ShouldSkip([]string{"foo.go"}, "fix: typo [skip-docs]", env)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
-	if !skip {
-		t.Error("expected skip to be true for [skip-docs] tag")
+	if result.Status != "skipped" || result.Reason != reasonSkipDocsTag {
+		t.Errorf("expected skip for [skip-docs] tag, got '%s': %s", result.Status, result.Reason)
 	}
 
-	if reason == "" {
-		t.Error("expected reason to be set")
+	if len(client.Requests()) != 0 {
+		t.Errorf("expected no model calls, got %d", len(client.Requests()))
+	}
+
+	if trackingSvc.tracking.LastProcessedCommit != "def456" {
+		t.Errorf("expected tracking to move past the skipped range, got '%s'", trackingSvc.tracking.LastProcessedCommit)
 	}
 }
 
-func TestShouldSkip_AllMarkdownFiles(t *testing.T) {
+func TestRangeUpdater_Run_SkipDocsTag_ExcludesOnlyTaggedCommitFiles(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	sessionPath := "/session"
+	fs.MkdirAll(sessionPath, 0755)
+	afero.WriteFile(fs, "/repo/src/index.md", []byte("# Src"), 0644)
+	afero.WriteFile(fs, "/repo/lib/index.md", []byte("# Lib"), 0644)
+
+	feature := git.Commit{SHA: "def456", Subject: "feat: add a"}
+	rename := git.Commit{SHA: "ccc111", Subject: "chore: rename", Body: "Mechanical change [skip-docs]"}
+	gitSvc := &mockGitService{
+		currentSHA:     "def456",
+		changedFiles:   []string{"/repo/src/a.go", "/repo/lib/b.go"},
+		validateResult: true,
+		commits:        []git.Commit{feature, rename},
+		pathCommits: map[string][]git.Commit{
+			"/repo/src/a.go": {feature},
+			"/repo/lib/b.go": {rename},
+		},
+	}
+	lockSvc := newMockLockService()
+	trackingSvc := &mockTrackingService{
+		tracking: doctracking.DocUpdateTracking{
+			LastProcessedCommit: "abc123",
+		},
+	}
+	client := llm.NewFake("# Src\n\nUpdated")
 	env := &mockEnvironment{vars: make(map[string]string)}
 
-	skip, reason := ShouldSkip([]string{"docs/readme.md", "docs/guide.md"}, "", env)
+	config := RangeUpdaterConfig{
+		SessionPath:   sessionPath,
+		DefaultBranch: "main",
+	}
 
-	if !skip {
-		t.Error("expected skip to be true for all markdown files")
+	updater := New(config, gitSvc, lockSvc, trackingSvc, client, fs, env)
+	result, err := updater.Run()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if reason == "" {
-		t.Error("expected reason to be set")
+	if result.Status != "success" {
+		t.Fatalf("expected status 'success', got '%s': %s", result.Status, result.Reason)
 	}
-}
 
-func TestShouldSkip_MixedFiles_NoSkip(t *testing.T) {
-	env := &mockEnvironment{vars: make(map[string]string)}
+	if len(result.AffectedIndexes) != 1 || result.AffectedIndexes[0] != "/repo/src/index.md" {
+		t.Errorf("expected only /repo/src/index.md to be affected, got %v", result.AffectedIndexes)
+	}
 
-	skip, _ := ShouldSkip([]string{"src/foo.go", "docs/readme.md"}, "", env)
+	requests := client.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 model call, got %d", len(requests))
+	}
+	if strings.Contains(requests[0].Prompt, "b.go") {
+		t.Errorf("expected files of the [skip-docs] commit to be left out of the prompt")
+	}
 
-	if skip {
-		t.Error("expected skip to be false for mixed file types")
+	if trackingSvc.tracking.LastProcessedCommit != "def456" {
+		t.Errorf("expected tracking to advance to HEAD, got '%s'", trackingSvc.tracking.LastProcessedCommit)
 	}
 }
 
-func TestHandleUnreachableBase_DefaultBranch(t *testing.T) {
+func TestRangeUpdater_Run_PromptIncludesCommitsAndDiffs(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	sessionPath := "/session"
+	fs.MkdirAll(sessionPath, 0755)
+	afero.WriteFile(fs, "/repo/src/index.md", []byte("# Src"), 0644)
+	afero.WriteFile(fs, "/repo/lib/index.md", []byte("# Lib"), 0644)
+
 	gitSvc := &mockGitService{
-		mergeBase: "fallback123",
+		currentSHA:     "def456",
+		changedFiles:   []string{"/repo/src/a.go", "/repo/lib/b.go"},
+		validateResult: true,
+		commits:        []git.Commit{{SHA: "def4567890", Subject: "feat: retry failed uploads"}},
+		diffStat: []git.FileStat{
+			{Path: "/repo/src/a.go", Added: 2, Deleted: 1},
+			{Path: "/repo/lib/b.go", Added: 1},
+		},
+		fileDiffs: map[string]string{
+			"/repo/src/a.go": "diff --git a/src/a.go b/src/a.go\n--- a/src/a.go\n+++ b/src/a.go\n@@ -1,2 +1,3 @@\n-func upload() {}\n+func upload() { retry() }\n+func retry() {}",
+			"/repo/lib/b.go": "@@ -0,0 +1 @@\n+func helper() {}",
+		},
+	}
+	lockSvc := newMockLockService()
+	trackingSvc := &mockTrackingService{
+		tracking: doctracking.DocUpdateTracking{
+			LastProcessedCommit: "abc123",
+		},
 	}
+	client := llm.NewFake(noChangesMarker)
+	env := &mockEnvironment{vars: make(map[string]string)}
 
-	sha, err := HandleUnreachableBase(gitSvc, "main")
+	config := RangeUpdaterConfig{
+		SessionPath:   sessionPath,
+		DefaultBranch: "main",
+	}
 
-	if err != nil {
+	updater := New(config, gitSvc, lockSvc, trackingSvc, client, fs, env)
+	if _, err := updater.Run(); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if sha != "fallback123" {
-		t.Errorf("expected 'fallback123', got '%s'", sha)
+	var srcPrompt string
+	for _, request := range client.Requests() {
+		if strings.Contains(request.Prompt, "/repo/src/index.md") {
+			srcPrompt = request.Prompt
+		}
+	}
+	if srcPrompt == "" {
+		t.Fatal("expected a model call for /repo/src/index.md")
 	}
-}
 
-func TestHandleUnreachableBase_FallsBackToMain(t *testing.T) {
-	// Create a mock that will fail for "develop" but succeed for "main"
-	gitSvc := &mockGitServiceWithCallback{
-		mergeBaseCallback: func(branch string) (string, error) {
-			if branch == "develop" {
-				return "", fmt.Errorf("branch not found")
-			}
-			if branch == "main" {
-				return "main-fallback", nil
-			}
-			return "", fmt.Errorf("branch not found")
-		},
+	for _, want := range []string{"def4567 feat: retry failed uploads", "/repo/src/a.go (+2 -1)", "+func retry() {}"} {
+		if !strings.Contains(srcPrompt, want) {
+			t.Errorf("expected prompt to contain %q", want)
+		}
+	}
+	if strings.Contains(srcPrompt, "diff --git") {
+		t.Errorf("expected diff headers to be dropped")
 	}
+	if strings.Contains(srcPrompt, "func helper") {
+		t.Errorf("expected diffs outside the index directory to be left out")
+	}
+}
 
-	sha, err := HandleUnreachableBase(gitSvc, "develop")
+func TestChangeSet_Summary_BoundsCommitsAndDiffs(t *testing.T) {
+	var commits []git.Commit
+	for i := 0; i < maxSummaryCommits+5; i++ {
+		commits = append(commits, git.Commit{SHA: fmt.Sprintf("sha%04d", i), Subject: fmt.Sprintf("commit %d", i)})
+	}
 
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+	var files []string
+	diffs := map[string]string{}
+	hunk := "@@ -1 +1 @@" + strings.Repeat("\n+line", maxDiffLinesPerFile*2)
+	for i := 0; i < 10; i++ {
+		file := fmt.Sprintf("/repo/src/f%d.go", i)
+		files = append(files, file)
+		diffs[file] = hunk
 	}
 
-	if sha != "main-fallback" {
-		t.Errorf("expected 'main-fallback', got '%s'", sha)
+	changes := &ChangeSet{Commits: commits, Stats: map[string]git.FileStat{}}
+	summary := changes.Summary(&mockGitService{fileDiffs: diffs}, "/repo/src", files)
+
+	if !strings.Contains(summary, "... and 5 more") {
+		t.Errorf("expected commit list to be truncated")
+	}
+	if strings.Contains(summary, fmt.Sprintf("commit %d", maxSummaryCommits)) {
+		t.Errorf("expected commits past the limit to be left out")
+	}
+	if lines := strings.Count(summary, "+line"); lines > maxDiffLinesTotal {
+		t.Errorf("expected at most %d diff lines, got %d", maxDiffLinesTotal, lines)
+	}
+	if !strings.Contains(summary, "/repo/src/f9.go") {
+		t.Errorf("expected files past the diff budget to still be listed")
+	}
+}
+
+// newPropagationFixture sets up /repo with a root index, a package index and a new
+// subpackage whose index.md was created in the commit range
+func newPropagationFixture() (afero.Fs, *mockGitService) {
+	fs := afero.NewMemMapFs()
+	fs.MkdirAll("/session", 0755)
+	afero.WriteFile(fs, "/repo/index.md", []byte("# Repo\n\n- pkg/: packages\n"), 0644)
+	afero.WriteFile(fs, "/repo/pkg/index.md", []byte("# Packages\n\n- old/: old package\n"), 0644)
+	afero.WriteFile(fs, "/repo/pkg/new/index.md", []byte("# New\n\n- new.go: entry point\n"), 0644)
+
+	gitSvc := &mockGitService{
+		currentSHA:     "def456",
+		changedFiles:   []string{"/repo/pkg/new/new.go"},
+		validateResult: true,
+		addedFiles:     []string{"/repo/pkg/new/index.md", "/repo/pkg/new/new.go"},
+	}
+	return fs, gitSvc
+}
+
+func runPropagation(t *testing.T, fs afero.Fs, gitSvc *mockGitService, client *llm.Fake, depth int) *UpdateResult {
+	t.Helper()
+	trackingSvc := &mockTrackingService{
+		tracking: doctracking.DocUpdateTracking{
+			LastProcessedCommit: "abc123",
+		},
+	}
+	config := RangeUpdaterConfig{
+		SessionPath:      "/session",
+		DefaultBranch:    "main",
+		PropagationDepth: depth,
+		RootDir:          "/repo",
+	}
+
+	updater := New(config, gitSvc, newMockLockService(), trackingSvc, client, fs, &mockEnvironment{})
+	result, err := updater.Run()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+func TestRangeUpdater_Run_Propagation_Disabled_UpdatesNearestOnly(t *testing.T) {
+	fs, gitSvc := newPropagationFixture()
+	client := llm.NewFake(noChangesMarker)
+
+	result := runPropagation(t, fs, gitSvc, client, 0)
+
+	if len(result.AffectedIndexes) != 1 || result.AffectedIndexes[0] != "/repo/pkg/new/index.md" {
+		t.Errorf("expected only the nearest index, got %v", result.AffectedIndexes)
+	}
+}
+
+func TestRangeUpdater_Run_Propagation_CreatedIndexQueuesParentsUpToDepth(t *testing.T) {
+	fs, gitSvc := newPropagationFixture()
+	client := llm.NewFake(noChangesMarker).
+		When("/repo/pkg/index.md", "# Packages\n\n- old/: old package\n- new/: new package\n- a\n- b\n")
+
+	result := runPropagation(t, fs, gitSvc, client, 1)
+
+	expected := []string{"/repo/pkg/index.md", "/repo/pkg/new/index.md"}
+	if strings.Join(result.AffectedIndexes, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v, got %v", expected, result.AffectedIndexes)
+	}
+
+	requests := client.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 model calls, got %d", len(requests))
+	}
+	if !strings.Contains(requests[0].Prompt, "/repo/pkg/new/index.md") {
+		t.Errorf("expected the child index to be updated first")
+	}
+	parentPrompt := requests[1].Prompt
+	for _, want := range []string{"INDEX UPDATES BELOW THIS DIRECTORY", "new/index.md (created)", "+- new.go: entry point"} {
+		if !strings.Contains(parentPrompt, want) {
+			t.Errorf("expected parent prompt to contain %q", want)
+		}
+	}
+}
+
+func TestRangeUpdater_Run_Propagation_SignificantChangeReachesRoot(t *testing.T) {
+	fs, gitSvc := newPropagationFixture()
+	gitSvc.addedFiles = nil
+	client := llm.NewFake(noChangesMarker).
+		When("/repo/pkg/new/index.md", "# New\n\n- new.go: entry point\n- client.go: API client\n- retry.go: retries\n- auth.go: tokens\n").
+		When("/repo/pkg/index.md", "# Packages\n\n- old/: old package\n- new/: API client package\n- x\n- y\n")
+
+	result := runPropagation(t, fs, gitSvc, client, 5)
+
+	if len(result.AffectedIndexes) != 3 {
+		t.Errorf("expected propagation up to the root index, got %v", result.AffectedIndexes)
+	}
+	requests := client.Requests()
+	if len(requests) != 3 || !strings.Contains(requests[2].Prompt, "pkg/index.md (updated)") {
+		t.Errorf("expected the root index to receive the pkg/index.md update summary")
+	}
+}
+
+func TestRangeUpdater_Run_Propagation_MinorChangeStops(t *testing.T) {
+	fs, gitSvc := newPropagationFixture()
+	gitSvc.addedFiles = nil
+	client := llm.NewFake(noChangesMarker).
+		When("/repo/pkg/new/index.md", "# New\n\n- new.go: the entry point\n")
+
+	result := runPropagation(t, fs, gitSvc, client, 5)
+
+	if len(result.AffectedIndexes) != 1 {
+		t.Errorf("expected a one-line edit not to propagate, got %v", result.AffectedIndexes)
+	}
+}
+
+// newSiblingFixture sets up n sibling packages under /repo, each with an index.md and a changed file
+func newSiblingFixture(n int) (afero.Fs, *mockGitService, *mockTrackingService) {
+	fs := afero.NewMemMapFs()
+	fs.MkdirAll("/session", 0755)
+	var changed []string
+	for i := 0; i < n; i++ {
+		dir := fmt.Sprintf("/repo/pkg%d", i)
+		afero.WriteFile(fs, dir+"/index.md", []byte("# Package"), 0644)
+		changed = append(changed, dir+"/a.go")
+	}
+	gitSvc := &mockGitService{currentSHA: "def456", changedFiles: changed, validateResult: true}
+	trackingSvc := &mockTrackingService{
+		tracking: doctracking.DocUpdateTracking{
+			LastProcessedCommit: "abc123",
+		},
+	}
+	return fs, gitSvc, trackingSvc
+}
+
+func TestRangeUpdater_Run_FailedIndex_ReportedAndKeptForRetry(t *testing.T) {
+	fs, gitSvc, trackingSvc := newSiblingFixture(2)
+	client := llm.NewFake("# Package\n\nUpdated").WhenError("/repo/pkg1/index.md", fmt.Errorf("claude command timed out after 1s"))
+
+	config := RangeUpdaterConfig{SessionPath: "/session", DefaultBranch: "main", IndexTimeout: time.Second}
+	result, err := New(config, gitSvc, newMockLockService(), trackingSvc, client, fs, &mockEnvironment{}).Run()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != "partial" {
+		t.Errorf("expected status 'partial', got '%s'", result.Status)
+	}
+	if len(result.AffectedIndexes) != 1 || result.AffectedIndexes[0] != "/repo/pkg0/index.md" {
+		t.Errorf("expected only the successful index to be reported as affected, got %v", result.AffectedIndexes)
+	}
+	if len(result.Indexes) != 2 || result.Indexes[0].Status != IndexUpdated || result.Indexes[1].Status != IndexFailed {
+		t.Fatalf("unexpected per-index results: %+v", result.Indexes)
+	}
+	if !strings.Contains(result.Indexes[1].Error, "timed out") {
+		t.Errorf("expected the failure to carry the error, got %q", result.Indexes[1].Error)
+	}
+
+	for _, request := range client.Requests() {
+		if request.Timeout != time.Second {
+			t.Errorf("expected the index timeout on every request, got %s", request.Timeout)
+		}
+	}
+
+	if trackingSvc.tracking.LastProcessedCommit != "def456" {
+		t.Errorf("expected tracking to advance to HEAD, got '%s'", trackingSvc.tracking.LastProcessedCommit)
+	}
+	expected := map[string]string{"/repo/pkg1/index.md": "abc123"}
+	if fmt.Sprint(trackingSvc.tracking.FailedIndexes) != fmt.Sprint(expected) {
+		t.Errorf("expected failed index to keep its range base, got %v", trackingSvc.tracking.FailedIndexes)
+	}
+}
+
+func TestRangeUpdater_Run_RetriesFailedIndexWithoutNewCommits(t *testing.T) {
+	fs, gitSvc, trackingSvc := newSiblingFixture(2)
+	trackingSvc.tracking = doctracking.DocUpdateTracking{
+		LastProcessedCommit: "def456",
+		FailedIndexes:       map[string]string{"/repo/pkg1/index.md": "abc123", "/repo/gone/index.md": "abc123"},
+	}
+	client := llm.NewFake("# Package\n\nUpdated")
+
+	config := RangeUpdaterConfig{SessionPath: "/session", DefaultBranch: "main"}
+	result, err := New(config, gitSvc, newMockLockService(), trackingSvc, client, fs, &mockEnvironment{}).Run()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != "success" {
+		t.Errorf("expected status 'success', got '%s': %s", result.Status, result.Reason)
+	}
+	requests := client.Requests()
+	if len(requests) != 1 || !strings.Contains(requests[0].Prompt, "/repo/pkg1/index.md") {
+		t.Fatalf("expected only the failed index to be retried, got %d calls", len(requests))
+	}
+	if len(trackingSvc.tracking.FailedIndexes) != 0 {
+		t.Errorf("expected retries to be cleared, got %v", trackingSvc.tracking.FailedIndexes)
+	}
+}
+
+func TestRangeUpdater_Run_RetriesFailedIndexWhenRangeIsSkipped(t *testing.T) {
+	fs, gitSvc, trackingSvc := newSiblingFixture(2)
+	gitSvc.changedFiles = []string{"/repo/docs/guide.md"}
+	trackingSvc.tracking.FailedIndexes = map[string]string{"/repo/pkg1/index.md": "aaa000"}
+	client := llm.NewFake("# Package\n\nUpdated")
+
+	config := RangeUpdaterConfig{SessionPath: "/session", DefaultBranch: "main"}
+	result, err := New(config, gitSvc, newMockLockService(), trackingSvc, client, fs, &mockEnvironment{}).Run()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != "success" {
+		t.Errorf("expected status 'success', got '%s': %s", result.Status, result.Reason)
+	}
+	requests := client.Requests()
+	if len(requests) != 1 || !strings.Contains(requests[0].Prompt, "/repo/pkg1/index.md") {
+		t.Fatalf("expected the failed index to be retried despite the docs-only range, got %d calls", len(requests))
+	}
+	if len(trackingSvc.tracking.FailedIndexes) != 0 {
+		t.Errorf("expected retries to be cleared, got %v", trackingSvc.tracking.FailedIndexes)
+	}
+	if trackingSvc.tracking.LastProcessedCommit != "def456" {
+		t.Errorf("expected tracking to advance to HEAD, got '%s'", trackingSvc.tracking.LastProcessedCommit)
+	}
+}
+
+func TestRangeUpdater_Run_SkipDocsEnvVar_KeepsRetriesPending(t *testing.T) {
+	fs, gitSvc, trackingSvc := newSiblingFixture(2)
+	trackingSvc.tracking.FailedIndexes = map[string]string{"/repo/pkg1/index.md": "aaa000"}
+	client := llm.NewFake("# Package\n\nUpdated")
+	env := &mockEnvironment{vars: map[string]string{"CLAUDEX_SKIP_DOCS": "1"}}
+
+	config := RangeUpdaterConfig{SessionPath: "/session", DefaultBranch: "main"}
+	result, err := New(config, gitSvc, newMockLockService(), trackingSvc, client, fs, env).Run()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != "skipped" || result.Reason != reasonSkipDocsEnv {
+		t.Errorf("expected env var skip, got '%s': %s", result.Status, result.Reason)
+	}
+	if len(client.Requests()) != 0 {
+		t.Errorf("expected no model calls, got %d", len(client.Requests()))
+	}
+	if len(trackingSvc.tracking.FailedIndexes) != 1 {
+		t.Errorf("expected the retry to stay pending, got %v", trackingSvc.tracking.FailedIndexes)
+	}
+}
+
+// concurrencyClient records how many completions run at the same time
+type concurrencyClient struct {
+	mu      sync.Mutex
+	current int
+	peak    int
+}
+
+func (c *concurrencyClient) Complete(req llm.Request) (string, error) {
+	c.mu.Lock()
+	c.current++
+	c.peak = max(c.peak, c.current)
+	c.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	c.mu.Lock()
+	c.current--
+	c.mu.Unlock()
+	return noChangesMarker, nil
+}
+
+func TestRangeUpdater_Run_WorkerPoolBoundsParallelism(t *testing.T) {
+	fs, gitSvc, trackingSvc := newSiblingFixture(6)
+	client := &concurrencyClient{}
+
+	config := RangeUpdaterConfig{SessionPath: "/session", DefaultBranch: "main", Workers: 2}
+	result, err := New(config, gitSvc, newMockLockService(), trackingSvc, client, fs, &mockEnvironment{}).Run()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Indexes) != 6 {
+		t.Fatalf("expected 6 index results, got %d", len(result.Indexes))
+	}
+	for _, r := range result.Indexes {
+		if r.Status != IndexUnchanged || r.Duration <= 0 {
+			t.Errorf("unexpected result for %s: %+v", r.Path, r)
+		}
+	}
+	if client.peak != 2 {
+		t.Errorf("expected 2 updates in parallel, peak was %d", client.peak)
+	}
+}
+
+func TestRangeUpdater_RunPending_Staged_StagesUpdatedIndexes(t *testing.T) {
+	fs, gitSvc, trackingSvc := newSiblingFixture(2)
+	gitSvc.fileDiffs = map[string]string{"/repo/pkg0/a.go": "@@ -0,0 +1 @@\n+func staged() {}"}
+	client := llm.NewFake("# Package\n\nUpdated").When("/repo/pkg1/index.md", noChangesMarker)
+
+	config := RangeUpdaterConfig{SessionPath: "/session", DefaultBranch: "main"}
+	result, err := New(config, gitSvc, newMockLockService(), trackingSvc, client, fs, &mockEnvironment{}).RunPending(git.ScopeStaged)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != "success" || result.ProcessedRange != "staged changes" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if len(gitSvc.pendingScopes) != 1 || gitSvc.pendingScopes[0] != git.ScopeStaged {
+		t.Errorf("expected the staged changes to be read, got %v", gitSvc.pendingScopes)
+	}
+	if len(gitSvc.staged) != 1 || gitSvc.staged[0] != "/repo/pkg0/index.md" {
+		t.Errorf("expected only the rewritten index to be staged, got %v", gitSvc.staged)
+	}
+	if trackingSvc.writeCalled {
+		t.Error("expected tracking to be left alone for uncommitted changes")
+	}
+
+	found := false
+	for _, request := range client.Requests() {
+		if strings.Contains(request.Prompt, "+func staged() {}") {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected the staged diff in the index prompt")
+	}
+}
+
+func TestRangeUpdater_RunPending_Staged_LeavesIndexWithUnstagedEditsUnstaged(t *testing.T) {
+	fs, gitSvc, trackingSvc := newSiblingFixture(2)
+	gitSvc.unstaged = map[string]bool{"/repo/pkg1/index.md": true}
+	client := llm.NewFake("# Package\n\nUpdated")
+
+	config := RangeUpdaterConfig{SessionPath: "/session", DefaultBranch: "main"}
+	result, err := New(config, gitSvc, newMockLockService(), trackingSvc, client, fs, &mockEnvironment{}).RunPending(git.ScopeStaged)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != "success" || len(result.AffectedIndexes) != 2 {
+		t.Errorf("expected both indexes to be updated, got %+v", result)
+	}
+	if len(gitSvc.staged) != 1 || gitSvc.staged[0] != "/repo/pkg0/index.md" {
+		t.Errorf("expected only the clean index to be staged, got %v", gitSvc.staged)
+	}
+	if len(result.Unstaged) != 1 || result.Unstaged[0] != "/repo/pkg1/index.md" {
+		t.Errorf("expected the index with unstaged edits to be reported, got %v", result.Unstaged)
+	}
+}
+
+func TestRangeUpdater_RunPending_Worktree_DoesNotStage(t *testing.T) {
+	fs, gitSvc, trackingSvc := newSiblingFixture(1)
+	client := llm.NewFake("# Package\n\nUpdated")
+
+	config := RangeUpdaterConfig{SessionPath: "/session", DefaultBranch: "main"}
+	result, err := New(config, gitSvc, newMockLockService(), trackingSvc, client, fs, &mockEnvironment{}).RunPending(git.ScopeWorktree)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Indexes) != 1 || result.Indexes[0].Status != IndexUpdated {
+		t.Fatalf("unexpected per-index results: %+v", result.Indexes)
+	}
+	if len(gitSvc.staged) != 0 {
+		t.Errorf("expected nothing staged in worktree mode, got %v", gitSvc.staged)
+	}
+}
+
+func TestRangeUpdater_RunPending_DocsOnly_Skips(t *testing.T) {
+	fs, gitSvc, trackingSvc := newSiblingFixture(1)
+	gitSvc.changedFiles = []string{"/repo/pkg0/index.md"}
+	client := llm.NewFake("# Package\n\nUpdated")
+
+	config := RangeUpdaterConfig{SessionPath: "/session", DefaultBranch: "main"}
+	result, err := New(config, gitSvc, newMockLockService(), trackingSvc, client, fs, &mockEnvironment{}).RunPending(git.ScopeStaged)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != "skipped" {
+		t.Errorf("expected status 'skipped', got '%s' (%s)", result.Status, result.Reason)
+	}
+	if len(client.Requests()) != 0 {
+		t.Errorf("expected no model calls, got %d", len(client.Requests()))
+	}
+}
+
+func TestRangeUpdater_Run_DetachedHead_Skips(t *testing.T) {
+	gitSvc := &mockGitService{currentSHA: "def456", detached: true}
+	trackingSvc := &mockTrackingService{}
+
+	config := RangeUpdaterConfig{SessionPath: "/session", DefaultBranch: "main"}
+	result, err := New(config, gitSvc, newMockLockService(), trackingSvc, llm.NewFake(noChangesMarker), afero.NewMemMapFs(), &mockEnvironment{}).Run()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != "skipped" || !strings.Contains(result.Reason, "detached HEAD") {
+		t.Errorf("expected detached HEAD skip, got '%s': %s", result.Status, result.Reason)
+	}
+	if trackingSvc.writeCalled {
+		t.Error("expected tracking to be left alone")
+	}
+}
+
+func TestRangeUpdater_Run_NewBranch_SeedsFromMergeBase(t *testing.T) {
+	fs, gitSvc, _ := newSiblingFixture(1)
+	gitSvc.branch = "feature/auth"
+	gitSvc.mergeBase = "fork111"
+	trackingSvc := &mockTrackingService{
+		others: map[string]doctracking.DocUpdateTracking{
+			"main": {LastProcessedCommit: "main999"},
+		},
+	}
+	client := llm.NewFake(noChangesMarker)
+
+	config := RangeUpdaterConfig{SessionPath: "/session", DefaultBranch: "main"}
+	result, err := New(config, gitSvc, newMockLockService(), trackingSvc, client, fs, &mockEnvironment{}).Run()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ProcessedRange != "fork111..def456" {
+		t.Errorf("expected the range to start at the merge-base, got '%s'", result.ProcessedRange)
+	}
+	if result.Branch != "feature/auth" {
+		t.Errorf("expected branch 'feature/auth', got '%s'", result.Branch)
+	}
+	if trackingSvc.tracking.LastProcessedCommit != "def456" {
+		t.Errorf("expected the new branch entry at HEAD, got '%s'", trackingSvc.tracking.LastProcessedCommit)
+	}
+	if trackingSvc.others["main"].LastProcessedCommit != "main999" {
+		t.Errorf("expected main's entry to be untouched")
+	}
+}
+
+func TestRangeUpdater_Run_PrunesDeletedBranches(t *testing.T) {
+	fs, gitSvc, trackingSvc := newSiblingFixture(1)
+	gitSvc.branches = []string{"main", "feature/live"}
+	trackingSvc.others = map[string]doctracking.DocUpdateTracking{
+		"feature/live": {LastProcessedCommit: "live111"},
+		"feature/gone": {LastProcessedCommit: "gone222"},
+	}
+
+	config := RangeUpdaterConfig{SessionPath: "/session", DefaultBranch: "main"}
+	if _, err := New(config, gitSvc, newMockLockService(), trackingSvc, llm.NewFake(noChangesMarker), fs, &mockEnvironment{}).Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := trackingSvc.others["feature/gone"]; ok {
+		t.Error("expected the deleted branch entry to be pruned")
+	}
+	if _, ok := trackingSvc.others["feature/live"]; !ok {
+		t.Error("expected the existing branch entry to be kept")
+	}
+}
+
+func TestFindParentIndexMd_StopsAtRoot(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	afero.WriteFile(fs, "/index.md", []byte("# Outside"), 0644)
+	afero.WriteFile(fs, "/repo/a/b/index.md", []byte("# B"), 0644)
+	afero.WriteFile(fs, "/repo/index.md", []byte("# Repo"), 0644)
+
+	if got := findParentIndexMd(fs, "/repo/a/b/index.md", "/repo"); got != "/repo/index.md" {
+		t.Errorf("expected /repo/index.md, got %q", got)
+	}
+	if got := findParentIndexMd(fs, "/repo/index.md", "/repo"); got != "" {
+		t.Errorf("expected no parent above the root, got %q", got)
+	}
+	if got := findParentIndexMd(fs, "/repo/index.md", ""); got != "/index.md" {
+		t.Errorf("expected unbounded search to reach /index.md, got %q", got)
+	}
+}
+
+func TestRangeUpdater_Run_InvalidPattern_ReturnsError(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	gitSvc := &mockGitService{
+		currentSHA:     "def456",
+		changedFiles:   []string{"src/a.go"},
+		validateResult: true,
+	}
+	trackingSvc := &mockTrackingService{
+		tracking: doctracking.DocUpdateTracking{
+			LastProcessedCommit: "abc123",
+		},
+	}
+	env := &mockEnvironment{vars: make(map[string]string)}
+
+	config := RangeUpdaterConfig{
+		SessionPath:     "/session",
+		ExcludePatterns: []string{"[a-"},
+	}
+
+	updater := New(config, gitSvc, newMockLockService(), trackingSvc, llm.NewFake(noChangesMarker), fs, env)
+	_, err := updater.Run()
+
+	if err == nil || !strings.Contains(err.Error(), "invalid exclude pattern") {
+		t.Errorf("expected invalid exclude pattern error, got %v", err)
+	}
+}
+
+func TestResolveAffectedIndexes_MultipleIndexes(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	// Create directory structure with indexes
+	fs.MkdirAll("/pkg/a", 0755)
+	fs.MkdirAll("/pkg/b", 0755)
+	afero.WriteFile(fs, "/pkg/a/index.md", []byte("# A"), 0644)
+	afero.WriteFile(fs, "/pkg/b/index.md", []byte("# B"), 0644)
+
+	changedFiles := []string{
+		"/pkg/a/foo.go",
+		"/pkg/a/bar.go",
+		"/pkg/b/baz.go",
+	}
+
+	indexes, err := ResolveAffectedIndexes(fs, changedFiles)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(indexes) != 2 {
+		t.Errorf("expected 2 affected indexes, got %d", len(indexes))
+	}
+
+	// Verify both indexes are present
+	hasA := false
+	hasB := false
+	for _, idx := range indexes {
+		if idx == "/pkg/a/index.md" {
+			hasA = true
+		}
+		if idx == "/pkg/b/index.md" {
+			hasB = true
+		}
+	}
+
+	if !hasA || !hasB {
+		t.Errorf("expected both /pkg/a/index.md and /pkg/b/index.md, got %v", indexes)
+	}
+}
+
+func TestResolveAffectedIndexes_Deduplication(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	// Create directory structure
+	fs.MkdirAll("/pkg/a", 0755)
+	afero.WriteFile(fs, "/pkg/a/index.md", []byte("# A"), 0644)
+
+	changedFiles := []string{
+		"/pkg/a/foo.go",
+		"/pkg/a/bar.go",
+		"/pkg/a/baz.go",
+	}
+
+	indexes, err := ResolveAffectedIndexes(fs, changedFiles)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(indexes) != 1 {
+		t.Errorf("expected 1 affected index (deduplicated), got %d", len(indexes))
+	}
+
+	if indexes[0] != "/pkg/a/index.md" {
+		t.Errorf("expected /pkg/a/index.md, got %s", indexes[0])
+	}
+}
+
+func TestShouldSkip_EnvVar(t *testing.T) {
+	env := &mockEnvironment{
+		vars: map[string]string{
+			"CLAUDEX_SKIP_DOCS": "1",
+		},
+	}
+
+	skip, reason := ShouldSkip([]string{"foo.go"}, "", env)
+
+	if !skip {
+		t.Error("expected skip to be true for CLAUDEX_SKIP_DOCS=1")
+	}
+
+	if reason == "" {
+		t.Error("expected reason to be set")
+	}
+}
+
+func TestShouldSkip_CommitMessageTag(t *testing.T) {
+	env := &mockEnvironment{vars: make(map[string]string)}
+
+	skip, reason := ShouldSkip([]string{"foo.go"}, "fix: typo [skip-docs]", env)
+
+	if !skip {
+		t.Error("expected skip to be true for [skip-docs] tag")
+	}
+
+	if reason == "" {
+		t.Error("expected reason to be set")
+	}
+}
+
+func TestShouldSkip_AllMarkdownFiles(t *testing.T) {
+	env := &mockEnvironment{vars: make(map[string]string)}
+
+	skip, reason := ShouldSkip([]string{"docs/readme.md", "docs/guide.md"}, "", env)
+
+	if !skip {
+		t.Error("expected skip to be true for all markdown files")
+	}
+
+	if reason == "" {
+		t.Error("expected reason to be set")
+	}
+}
+
+func TestShouldSkip_MixedFiles_NoSkip(t *testing.T) {
+	env := &mockEnvironment{vars: make(map[string]string)}
+
+	skip, _ := ShouldSkip([]string{"src/foo.go", "docs/readme.md"}, "", env)
+
+	if skip {
+		t.Error("expected skip to be false for mixed file types")
+	}
+}
+
+func TestHandleUnreachableBase_DefaultBranch(t *testing.T) {
+	gitSvc := &mockGitService{
+		mergeBase: "fallback123",
+	}
+
+	sha, err := HandleUnreachableBase(gitSvc, "main")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if sha != "fallback123" {
+		t.Errorf("expected 'fallback123', got '%s'", sha)
+	}
+}
+
+func TestHandleUnreachableBase_FallsBackToMain(t *testing.T) {
+	// Create a mock that will fail for "develop" but succeed for "main"
+	gitSvc := &mockGitServiceWithCallback{
+		mergeBaseCallback: func(branch string) (string, error) {
+			if branch == "develop" {
+				return "", fmt.Errorf("branch not found")
+			}
+			if branch == "main" {
+				return "main-fallback", nil
+			}
+			return "", fmt.Errorf("branch not found")
+		},
+	}
+
+	sha, err := HandleUnreachableBase(gitSvc, "develop")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if sha != "main-fallback" {
+		t.Errorf("expected 'main-fallback', got '%s'", sha)
 	}
 }
 
@@ -620,6 +1516,95 @@ func (m *mockGitServiceWithCallback) GetMergeBase(branch string) (string, error)
 	return "", fmt.Errorf("not implemented")
 }
 
+func (m *mockGitServiceWithCallback) GetCommits(base, head string) ([]git.Commit, error) {
+	return nil, nil
+}
+
+func (m *mockGitServiceWithCallback) GetDiffStat(base, head string) ([]git.FileStat, error) {
+	return nil, nil
+}
+
+func (m *mockGitServiceWithCallback) GetFileDiff(base, head, path string) (string, error) {
+	return "", nil
+}
+
+func (m *mockGitServiceWithCallback) GetAddedFiles(base, head string) ([]string, error) {
+	return nil, nil
+}
+
+func (m *mockGitServiceWithCallback) GetCurrentBranch() (string, error) {
+	return "main", nil
+}
+
+func (m *mockGitServiceWithCallback) ListBranches() ([]string, error) {
+	return nil, nil
+}
+
+func (m *mockGitServiceWithCallback) GetDefaultBranch() (string, error) {
+	return "", nil
+}
+
+func (m *mockGitServiceWithCallback) GetRepoRoot() (string, error) {
+	return "", nil
+}
+
+func (m *mockGitServiceWithCallback) ListFiles() ([]string, error) {
+	return nil, nil
+}
+
+func (m *mockGitServiceWithCallback) GetLastCommit(path string) (git.Commit, error) {
+	return git.Commit{}, nil
+}
+
+func (m *mockGitServiceWithCallback) GetPathCommits(base, head string, paths []string) ([]git.Commit, error) {
+	return nil, nil
+}
+
+func (m *mockGitServiceWithCallback) GetPendingFiles(scope git.Scope) ([]string, error) {
+	return nil, nil
+}
+
+func (m *mockGitServiceWithCallback) GetPendingDiffStat(scope git.Scope) ([]git.FileStat, error) {
+	return nil, nil
+}
+
+func (m *mockGitServiceWithCallback) GetPendingFileDiff(scope git.Scope, path string) (string, error) {
+	return "", nil
+}
+
+func (m *mockGitServiceWithCallback) GetPendingAddedFiles(scope git.Scope) ([]string, error) {
+	return nil, nil
+}
+
+func (m *mockGitServiceWithCallback) HasUnstagedChanges(path string) (bool, error) {
+	return false, nil
+}
+
+func (m *mockGitServiceWithCallback) StageFiles(paths []string) error {
+	return nil
+}
+
+func TestInvokeClaudeForIndex_ReturnsContentWithoutWriting(t *testing.T) {
+	env := &mockEnvironment{vars: make(map[string]string)}
+	client := llm.NewFake(noChangesMarker).When("/repo/pkg/index.md", "```markdown\n# Pkg\n```")
+
+	content, err := InvokeClaudeForIndex(client, env, IndexUpdate{IndexPath: "/repo/pkg/index.md", Current: "# Old"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content != "# Pkg" {
+		t.Errorf("expected the unfenced reply, got %q", content)
+	}
+
+	content, err = InvokeClaudeForIndex(client, env, IndexUpdate{IndexPath: "/repo/other/index.md"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content != "" {
+		t.Errorf("expected no content for %s, got %q", noChangesMarker, content)
+	}
+}
+
 func TestHandleUnreachableBase_AllFail_ReturnsError(t *testing.T) {
 	gitSvc := &mockGitService{
 		mergeBaseError: fmt.Errorf("no merge base found"),
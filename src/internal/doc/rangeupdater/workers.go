@@ -0,0 +1,106 @@
+package rangeupdater
+
+import (
+	"log"
+	"sort"
+	"time"
+)
+
+// indexOutcome is what a worker reports back for one index update
+type indexOutcome struct {
+	work     *indexWork
+	change   indexChange
+	err      error
+	duration time.Duration
+}
+
+// updateIndexes runs the queued index updates on a pool of Workers goroutines and waits
+// for all of them. An index only starts once no queued or running index lies below it,
+// so parents see their children's updates and propagation can still queue them. Each
+// update is bounded by IndexTimeout. Results are sorted by path.
+func (ru *RangeUpdater) updateIndexes(queue map[string]*indexWork) []IndexResult {
+	workers := ru.config.Workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+
+	outcomes := make(chan indexOutcome)
+	started := make(map[string]bool, len(queue))
+	finished := make(map[string]bool, len(queue))
+	running := 0
+	var results []IndexResult
+
+	for {
+		for running < workers {
+			work := nextReady(queue, started, finished)
+			if work == nil {
+				break
+			}
+			started[work.path] = true
+			running++
+			go func(work *indexWork) {
+				start := time.Now()
+				change, err := ru.updateIndex(work)
+				outcomes <- indexOutcome{work: work, change: change, err: err, duration: time.Since(start)}
+			}(work)
+		}
+		if running == 0 {
+			break
+		}
+
+		outcome := <-outcomes
+		running--
+		finished[outcome.work.path] = true
+		results = append(results, resultOf(outcome))
+
+		if outcome.err != nil {
+			log.Printf("Warning: failed to update %s: %v", outcome.work.path, outcome.err)
+			// Continue with other indexes even if one fails
+			continue
+		}
+		ru.propagate(queue, started, outcome.work, outcome.change)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Path < results[j].Path })
+	return results
+}
+
+// nextReady returns the deepest queued index that has not started and has no unfinished
+// index below it, or nil when none is ready
+func nextReady(queue map[string]*indexWork, started, finished map[string]bool) *indexWork {
+	var next *indexWork
+	for indexPath, work := range queue {
+		if started[indexPath] || hasPendingBelow(queue, finished, indexPath) {
+			continue
+		}
+		if next == nil || deeper(indexPath, next.path) {
+			next = work
+		}
+	}
+	return next
+}
+
+// hasPendingBelow reports whether an unfinished queued index lies below indexPath
+func hasPendingBelow(queue map[string]*indexWork, finished map[string]bool, indexPath string) bool {
+	for other := range queue {
+		if !finished[other] && below(other, indexPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// resultOf converts a worker outcome into the reported IndexResult
+func resultOf(outcome indexOutcome) IndexResult {
+	result := IndexResult{Path: outcome.work.path, Duration: outcome.duration}
+	switch {
+	case outcome.err != nil:
+		result.Status = IndexFailed
+		result.Error = outcome.err.Error()
+	case outcome.change.before != outcome.change.after:
+		result.Status = IndexUpdated
+	default:
+		result.Status = IndexUnchanged
+	}
+	return result
+}
@@ -0,0 +1,144 @@
+package doc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"claudex/internal/services/config"
+	"claudex/internal/services/secrets"
+)
+
+// RedactionRules selects what is masked in transcript entries before they reach the prompt.
+// Credentials (the secrets package's built-in patterns) and tokens are always masked.
+type RedactionRules struct {
+	Emails           bool               `json:"emails"`            // Mask email addresses
+	EntropyThreshold float64            `json:"entropy_threshold"` // Mask random-looking tokens at this entropy (0 disables)
+	Patterns         []RedactionPattern `json:"patterns,omitempty"`
+}
+
+// RedactionPattern is a custom pattern to mask; the last capture group, if any, is masked
+type RedactionPattern struct {
+	Name  string `json:"name"`
+	Regex string `json:"regex"`
+}
+
+// RedactionRulesFor converts the [autodoc.redaction] settings, returning nil when redaction is disabled
+func RedactionRulesFor(cfg config.AutodocRedaction) *RedactionRules {
+	if !cfg.Enabled {
+		return nil
+	}
+	rules := &RedactionRules{Emails: cfg.Emails, EntropyThreshold: cfg.EntropyThreshold}
+	for _, p := range cfg.Patterns {
+		rules.Patterns = append(rules.Patterns, RedactionPattern{Name: p.Name, Regex: p.Regex})
+	}
+	return rules
+}
+
+// tokenPatterns are masked in addition to the credential patterns
+var tokenPatterns = []config.SecretPattern{
+	{Name: "jwt", Regex: `\beyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`},
+	{Name: "bearer-token", Regex: `(?i)\bbearer\s+([A-Za-z0-9._~+/=-]{16,})`},
+	{Name: "credential-assignment", Regex: `(?i)\b(?:password|passwd|secret|api[_-]?key|access[_-]?token|auth[_-]?token)["']?\s*[:=]\s*["']?([^\s"']{6,})`},
+}
+
+// emailPattern matches email addresses
+var emailPattern = config.SecretPattern{Name: "email", Regex: `\b[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}\b`}
+
+// RedactionStats counts masked values per rule
+type RedactionStats map[string]int
+
+// Total returns the number of masked values
+func (s RedactionStats) Total() int {
+	total := 0
+	for _, n := range s {
+		total += n
+	}
+	return total
+}
+
+// String formats the counts for job logs, rules in alphabetical order
+func (s RedactionStats) String() string {
+	if s.Total() == 0 {
+		return "nothing masked"
+	}
+	rules := make([]string, 0, len(s))
+	for rule := range s {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	counts := make([]string, len(rules))
+	for i, rule := range rules {
+		counts[i] = fmt.Sprintf("%s=%d", rule, s[rule])
+	}
+	return fmt.Sprintf("%d masked (%s)", s.Total(), strings.Join(counts, ", "))
+}
+
+// Redactor masks sensitive values in transcript entries
+type Redactor struct {
+	scanner *secrets.Scanner
+}
+
+// NewRedactor compiles the rules; an invalid custom pattern is an error
+func NewRedactor(rules RedactionRules) (*Redactor, error) {
+	patterns := append([]config.SecretPattern{}, tokenPatterns...)
+	if rules.Emails {
+		patterns = append(patterns, emailPattern)
+	}
+	for _, p := range rules.Patterns {
+		patterns = append(patterns, config.SecretPattern{Name: p.Name, Regex: p.Regex})
+	}
+
+	scanner, err := secrets.New(config.Secrets{EntropyThreshold: rules.EntropyThreshold, Patterns: patterns})
+	if err != nil {
+		return nil, err
+	}
+	return &Redactor{scanner: scanner}, nil
+}
+
+// RedactEntries returns copies of the entries with their content masked, and the counts
+func (r *Redactor) RedactEntries(entries []TranscriptEntry) ([]TranscriptEntry, RedactionStats) {
+	stats := RedactionStats{}
+	redacted := make([]TranscriptEntry, len(entries))
+	for i, entry := range entries {
+		content := make([]string, len(entry.Content))
+		for j, text := range entry.Content {
+			content[j] = r.redact(text, stats)
+		}
+		entry.Content = content
+		redacted[i] = entry
+	}
+	return redacted, stats
+}
+
+// Redact masks a single text and returns the counts
+func (r *Redactor) Redact(text string) (string, RedactionStats) {
+	stats := RedactionStats{}
+	return r.redact(text, stats), stats
+}
+
+// redact replaces each finding with [REDACTED:<rule>]. Overlapping findings are masked
+// once, under the rule of the one starting first.
+func (r *Redactor) redact(text string, stats RedactionStats) string {
+	findings := r.scanner.Scan(text)
+	if len(findings) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, f := range findings {
+		if f.Start < last {
+			if f.End > last {
+				last = f.End
+			}
+			continue
+		}
+		b.WriteString(text[last:f.Start])
+		fmt.Fprintf(&b, "[REDACTED:%s]", f.Rule)
+		stats[f.Rule]++
+		last = f.End
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
@@ -0,0 +1,84 @@
+package doc
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// defaultRedaction mirrors the [autodoc.redaction] defaults
+var defaultRedaction = RedactionRules{Emails: true, EntropyThreshold: 4.5}
+
+// TestRedactEntries_Fixture verifies the fixture transcript is formatted without any of its secrets
+func TestRedactEntries_Fixture(t *testing.T) {
+	// Setup
+	entries, _, err := ParseTranscript(afero.NewOsFs(), "../../testdata/transcripts/redaction.jsonl", 1)
+	require.NoError(t, err)
+	expected, err := os.ReadFile("../../testdata/transcripts/redaction.expected.md")
+	require.NoError(t, err)
+	rules := defaultRedaction
+	rules.Patterns = []RedactionPattern{{Name: "customer-id", Regex: `CUST-\d{6}`}}
+	redactor, err := NewRedactor(rules)
+	require.NoError(t, err)
+
+	// Exercise
+	redacted, stats := redactor.RedactEntries(entries)
+
+	// Verify
+	assert.Equal(t, string(expected), FormatTranscriptForPrompt(redacted))
+	assert.Equal(t, RedactionStats{
+		"aws-access-key-id":     1,
+		"email":                 1,
+		"github-token":          1,
+		"bearer-token":          1,
+		"credential-assignment": 1,
+		"jwt":                   1,
+		"customer-id":           1,
+	}, stats)
+	assert.Equal(t, "7 masked (aws-access-key-id=1, bearer-token=1, credential-assignment=1, customer-id=1, email=1, github-token=1, jwt=1)", stats.String())
+	assert.Contains(t, entries[0].Content[0], "AKIA", "the parsed entries are not modified")
+}
+
+// TestRedact_EmailsAndEntropyCanBeDisabled verifies the optional rules
+func TestRedact_EmailsAndEntropyCanBeDisabled(t *testing.T) {
+	// Setup
+	text := "mail ops@example.com, token Zx8Qm2Lp7Vr4Tn9Ks3Wd6Hf1Jb5Gc0Ya"
+	enabled, err := NewRedactor(defaultRedaction)
+	require.NoError(t, err)
+	disabled, err := NewRedactor(RedactionRules{})
+	require.NoError(t, err)
+
+	// Exercise
+	masked, maskedStats := enabled.Redact(text)
+	kept, keptStats := disabled.Redact(text)
+
+	// Verify
+	assert.Equal(t, "mail [REDACTED:email], token [REDACTED:high-entropy-string]", masked)
+	assert.Equal(t, 2, maskedStats.Total())
+	assert.Equal(t, text, kept)
+	assert.Equal(t, "nothing masked", keptStats.String())
+}
+
+// TestRedact_OverlappingFindingsMaskedOnce verifies a value matched by several rules yields one marker
+func TestRedact_OverlappingFindingsMaskedOnce(t *testing.T) {
+	// Setup
+	redactor, err := NewRedactor(RedactionRules{Patterns: []RedactionPattern{{Name: "aws-prefix", Regex: `AKIA\w+`}}})
+	require.NoError(t, err)
+
+	// Exercise
+	masked, stats := redactor.Redact("key=" + "AKIA" + "IOSFODNN7EXAMPLE.")
+
+	// Verify
+	assert.Equal(t, "key=[REDACTED:aws-access-key-id].", masked)
+	assert.Equal(t, RedactionStats{"aws-access-key-id": 1}, stats)
+}
+
+// TestNewRedactor_InvalidPattern verifies invalid custom patterns are reported
+func TestNewRedactor_InvalidPattern(t *testing.T) {
+	_, err := NewRedactor(RedactionRules{Patterns: []RedactionPattern{{Name: "broken", Regex: "("}}})
+
+	assert.ErrorContains(t, err, `invalid secret pattern "broken"`)
+}
@@ -0,0 +1,103 @@
+package skeleton
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+// goPackage collects the package name and doc comment across a directory's Go files
+type goPackage struct {
+	name string
+	doc  string
+	// docFromDocGo reports whether doc came from doc.go, which wins over other files
+	docFromDocGo bool
+}
+
+// add parses one Go file and returns its entry. Files that don't parse are listed
+// with the heuristics used for other languages.
+func (p *goPackage) add(name string, src []byte) File {
+	f, err := parser.ParseFile(token.NewFileSet(), name, src, parser.ParseComments)
+	if err != nil {
+		return heuristicFile(name, string(src))
+	}
+
+	isTest := strings.HasSuffix(name, "_test.go")
+	if !isTest {
+		if p.name == "" {
+			p.name = f.Name.Name
+		}
+		if f.Doc != nil && (p.doc == "" || (name == "doc.go" && !p.docFromDocGo)) {
+			p.doc = firstParagraph(f.Doc.Text())
+			p.docFromDocGo = name == "doc.go"
+		}
+	}
+
+	file := File{Name: name}
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if isTest && strings.HasPrefix(d.Name.Name, "Test") && d.Recv == nil {
+				file.Tests++
+				continue
+			}
+			if symbol := funcSymbol(d); symbol != "" {
+				file.Symbols = append(file.Symbols, symbol)
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if s.Name.IsExported() {
+						file.Symbols = append(file.Symbols, s.Name.Name)
+					}
+				case *ast.ValueSpec:
+					for _, ident := range s.Names {
+						if ident.IsExported() {
+							file.Symbols = append(file.Symbols, ident.Name)
+						}
+					}
+				}
+			}
+		}
+	}
+	return file
+}
+
+// funcSymbol names an exported function as Name() and an exported method of an
+// exported type as Type.Name(); everything else yields ""
+func funcSymbol(d *ast.FuncDecl) string {
+	if !d.Name.IsExported() {
+		return ""
+	}
+	if d.Recv == nil || len(d.Recv.List) == 0 {
+		return d.Name.Name + "()"
+	}
+	recv := receiverType(d.Recv.List[0].Type)
+	if recv == "" || !ast.IsExported(recv) {
+		return ""
+	}
+	return recv + "." + d.Name.Name + "()"
+}
+
+// receiverType returns the type name of a method receiver (*T, T, T[K])
+func receiverType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverType(t.X)
+	case *ast.IndexExpr:
+		return receiverType(t.X)
+	case *ast.IndexListExpr:
+		return receiverType(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// firstParagraph returns the first paragraph of a doc comment on one line
+func firstParagraph(text string) string {
+	paragraph, _, _ := strings.Cut(strings.TrimSpace(text), "\n\n")
+	return strings.Join(strings.Fields(paragraph), " ")
+}
@@ -0,0 +1,119 @@
+package skeleton
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// exportPatterns find exported top-level symbols per extension; the last group is the name
+var exportPatterns = map[string][]*regexp.Regexp{
+	".ts":  jsExports,
+	".tsx": jsExports,
+	".js":  jsExports,
+	".jsx": jsExports,
+	".mjs": jsExports,
+	".cjs": jsExports,
+	".py": {
+		regexp.MustCompile(`^(?:async\s+)?def\s+([A-Za-z]\w*)\s*\(`),
+		regexp.MustCompile(`^class\s+([A-Za-z]\w*)`),
+	},
+	".rs": {
+		regexp.MustCompile(`^pub\s+(?:async\s+)?(?:unsafe\s+)?(?:fn|struct|enum|trait|type|const|static|mod)\s+([A-Za-z_]\w*)`),
+	},
+}
+
+var jsExports = []*regexp.Regexp{
+	regexp.MustCompile(`^export\s+(?:default\s+)?(?:declare\s+)?(?:abstract\s+)?(?:async\s+)?(?:function\*?|class|const|let|var|interface|type|enum|namespace)\s+([A-Za-z_$][\w$]*)`),
+}
+
+// hashComments are the extensions whose line comments start with "#"
+var hashComments = map[string]bool{".py": true, ".rb": true}
+
+// heuristicFile describes a non-Go file by its header comment and exported symbols
+func heuristicFile(name, content string) File {
+	file := File{Name: name, Header: fileHeader(filepath.Ext(name), content)}
+	for _, line := range strings.Split(content, "\n") {
+		for _, pattern := range exportPatterns[filepath.Ext(name)] {
+			if m := pattern.FindStringSubmatch(line); m != nil {
+				file.Symbols = append(file.Symbols, m[len(m)-1])
+				break
+			}
+		}
+	}
+	return file
+}
+
+// fileHeader returns the first sentence of the comment block (or Python docstring) at the
+// top of a file. Shebangs, directives such as "use strict" and license headers are skipped.
+func fileHeader(ext, content string) string {
+	lines := strings.Split(content, "\n")
+	i := 0
+	for i < len(lines) {
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#!") || isDirective(line) {
+			i++
+			continue
+		}
+
+		var block []string
+		switch {
+		case strings.HasPrefix(line, "/*"):
+			for ; i < len(lines); i++ {
+				text := strings.TrimSpace(lines[i])
+				done := strings.Contains(text, "*/")
+				text = strings.TrimSuffix(strings.TrimSpace(strings.SplitN(text, "*/", 2)[0]), "*/")
+				text = strings.TrimLeft(strings.TrimPrefix(text, "/*"), "*! ")
+				block = append(block, text)
+				if done {
+					i++
+					break
+				}
+			}
+		case strings.HasPrefix(line, "//"):
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "//"); i++ {
+				block = append(block, strings.TrimLeft(strings.TrimSpace(lines[i]), "/! "))
+			}
+		case hashComments[ext] && strings.HasPrefix(line, "#"):
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "#"); i++ {
+				block = append(block, strings.TrimLeft(strings.TrimSpace(lines[i]), "# "))
+			}
+		case ext == ".py" && (strings.HasPrefix(line, `"""`) || strings.HasPrefix(line, `'''`)):
+			quote := line[:3]
+			text := strings.TrimPrefix(line, quote)
+			for {
+				before, _, closed := strings.Cut(text, quote)
+				block = append(block, before)
+				i++
+				if closed || i >= len(lines) {
+					break
+				}
+				text = strings.TrimSpace(lines[i])
+			}
+		default:
+			// Code before any comment: the file has no header
+			return ""
+		}
+
+		text := strings.TrimSpace(strings.Join(block, " "))
+		if text == "" || isLicense(text) {
+			continue
+		}
+		return firstSentence(text)
+	}
+	return ""
+}
+
+// isDirective reports whether a line is a prologue directive rather than code
+func isDirective(line string) bool {
+	line = strings.TrimSuffix(line, ";")
+	return line == `"use strict"` || line == `'use strict'` || line == `"use client"` || line == `'use client'` ||
+		strings.HasPrefix(line, "# -*-") || strings.HasPrefix(line, "# frozen_string_literal")
+}
+
+// isLicense reports whether a comment block is a copyright or license header
+func isLicense(text string) bool {
+	lower := strings.ToLower(text)
+	return strings.Contains(lower, "copyright") || strings.Contains(lower, "spdx-license-identifier") ||
+		strings.Contains(lower, "licensed under")
+}
@@ -0,0 +1,251 @@
+// Package skeleton builds a deterministic index.md skeleton for a directory without
+// calling a model. Go files are parsed with go/parser for the package doc comment and
+// exported identifiers; other languages fall back to file header comments and exported
+// symbol heuristics. Subdirectories are linked to their own index.md. The same tree
+// always yields the same skeleton, which the model can later enrich.
+package skeleton
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// maxSymbols is how many exported identifiers a file entry lists before summarizing the rest
+const maxSymbols = 8
+
+// placeholder stands in for a summary no source file provides
+const placeholder = "TODO: describe the purpose of this directory."
+
+// sourceExtensions are the files documented in the skeleton
+var sourceExtensions = map[string]bool{
+	".go": true, ".ts": true, ".tsx": true, ".js": true, ".jsx": true, ".mjs": true, ".cjs": true,
+	".py": true, ".rs": true, ".java": true, ".kt": true, ".swift": true, ".c": true, ".cpp": true,
+	".h": true, ".rb": true, ".php": true,
+}
+
+// skippedDirs are subdirectories that never get an entry
+var skippedDirs = map[string]bool{"node_modules": true, "vendor": true, "testdata": true}
+
+// File describes one source file of the directory
+type File struct {
+	Name string
+	// Header is the first sentence of the file's leading comment (non-Go files)
+	Header string
+	// Symbols are the exported identifiers in source order
+	Symbols []string
+	// Tests is the number of test functions in a Go test file
+	Tests int
+}
+
+// Subdir describes one subdirectory
+type Subdir struct {
+	Name string
+	// HasIndex reports whether the subdirectory has its own index.md
+	HasIndex bool
+	// Summary is the first sentence of the subdirectory's index.md
+	Summary string
+}
+
+// Skeleton is the structured content of a generated index.md
+type Skeleton struct {
+	Title   string
+	Summary string
+	Files   []File
+	Tests   []File
+	Subdirs []Subdir
+}
+
+// Build scans dir (not recursively) and returns its skeleton
+func Build(fs afero.Fs, dir string) (*Skeleton, error) {
+	entries, err := afero.ReadDir(fs, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+	// ReadDir sorts by name; sort again so the order never depends on the filesystem
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	s := &Skeleton{Title: filepath.Base(dir)}
+	var goPkg goPackage
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		if entry.IsDir() {
+			if !skippedDirs[name] && !strings.HasPrefix(name, "_") {
+				s.Subdirs = append(s.Subdirs, subdir(fs, filepath.Join(dir, name), name))
+			}
+			continue
+		}
+		if !sourceExtensions[filepath.Ext(name)] {
+			continue
+		}
+
+		data, err := afero.ReadFile(fs, filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		var file File
+		if filepath.Ext(name) == ".go" {
+			file = goPkg.add(name, data)
+		} else {
+			file = heuristicFile(name, string(data))
+		}
+		if file.Tests > 0 || isTestFile(name) {
+			s.Tests = append(s.Tests, file)
+		} else {
+			s.Files = append(s.Files, file)
+		}
+	}
+
+	if goPkg.name != "" && goPkg.name != "main" {
+		s.Title = goPkg.name
+	}
+	s.Summary = goPkg.doc
+	if s.Summary == "" {
+		s.Summary = entryPointHeader(s.Files)
+	}
+	return s, nil
+}
+
+// Markdown renders the skeleton in the layout of the project's index.md files
+func (s *Skeleton) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", s.Title)
+	if s.Summary != "" {
+		fmt.Fprintf(&b, "%s\n", s.Summary)
+	} else {
+		fmt.Fprintf(&b, "%s\n", placeholder)
+	}
+
+	if len(s.Files) > 0 {
+		b.WriteString("\n## Files\n\n")
+		for _, f := range s.Files {
+			writeFile(&b, f)
+		}
+	}
+
+	if len(s.Subdirs) > 0 {
+		b.WriteString("\n## Subdirectories\n\n")
+		for _, d := range s.Subdirs {
+			switch {
+			case d.HasIndex && d.Summary != "":
+				fmt.Fprintf(&b, "- [%s/](./%s/index.md) - %s\n", d.Name, d.Name, d.Summary)
+			case d.HasIndex:
+				fmt.Fprintf(&b, "- [%s/](./%s/index.md)\n", d.Name, d.Name)
+			default:
+				fmt.Fprintf(&b, "- `%s/`\n", d.Name)
+			}
+		}
+	}
+
+	if len(s.Tests) > 0 {
+		b.WriteString("\n## Tests\n\n")
+		for _, f := range s.Tests {
+			writeFile(&b, f)
+		}
+	}
+	return b.String()
+}
+
+// writeFile renders a file entry: its header, else its exported symbols
+func writeFile(b *strings.Builder, f File) {
+	var desc string
+	switch {
+	case f.Tests > 0:
+		desc = fmt.Sprintf("%d tests", f.Tests)
+		if f.Tests == 1 {
+			desc = "1 test"
+		}
+	case f.Header != "":
+		desc = f.Header
+	case len(f.Symbols) > 0:
+		desc = formatSymbols(f.Symbols)
+	}
+	if desc == "" {
+		fmt.Fprintf(b, "- `%s`\n", f.Name)
+		return
+	}
+	fmt.Fprintf(b, "- `%s` - %s\n", f.Name, desc)
+}
+
+// formatSymbols lists identifiers as code spans, summarizing those beyond maxSymbols
+func formatSymbols(symbols []string) string {
+	shown := symbols
+	if len(shown) > maxSymbols {
+		shown = shown[:maxSymbols]
+	}
+	spans := make([]string, len(shown))
+	for i, symbol := range shown {
+		spans[i] = "`" + symbol + "`"
+	}
+	list := strings.Join(spans, ", ")
+	if len(symbols) > maxSymbols {
+		list += fmt.Sprintf(" (+%d more)", len(symbols)-maxSymbols)
+	}
+	return list
+}
+
+// subdir describes a subdirectory through its index.md, if any
+func subdir(fs afero.Fs, path, name string) Subdir {
+	d := Subdir{Name: name}
+	data, err := afero.ReadFile(fs, filepath.Join(path, "index.md"))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			// Unreadable indexes are still linked
+			d.HasIndex = true
+		}
+		return d
+	}
+	d.HasIndex = true
+	d.Summary = indexSummary(string(data))
+	return d
+}
+
+// indexSummary returns the first sentence of an index.md's first paragraph
+func indexSummary(content string) string {
+	for _, paragraph := range strings.Split(content, "\n\n") {
+		text := strings.TrimSpace(paragraph)
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, "-") ||
+			strings.HasPrefix(text, "```") || strings.HasPrefix(text, "|") {
+			continue
+		}
+		return firstSentence(text)
+	}
+	return ""
+}
+
+// entryPointHeader returns the header of the file that usually describes a directory
+// (index.*, main.*, __init__.py, mod.rs, lib.rs)
+func entryPointHeader(files []File) string {
+	for _, f := range files {
+		base := strings.TrimSuffix(f.Name, filepath.Ext(f.Name))
+		if f.Header != "" && (base == "index" || base == "main" || base == "__init__" || base == "mod" || base == "lib") {
+			return f.Header
+		}
+	}
+	return ""
+}
+
+// isTestFile reports whether a file name follows a common test naming convention
+func isTestFile(name string) bool {
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+	return strings.HasSuffix(base, "_test") || strings.HasSuffix(base, ".test") ||
+		strings.HasSuffix(base, ".spec") || strings.HasPrefix(base, "test_")
+}
+
+// firstSentence collapses whitespace and cuts text after its first sentence
+func firstSentence(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	for i := 0; i < len(text); i++ {
+		if text[i] == '.' && (i+1 == len(text) || text[i+1] == ' ') {
+			return text[:i+1]
+		}
+	}
+	return text
+}
@@ -0,0 +1,161 @@
+package skeleton
+
+import (
+	"testing"
+
+	"claudex/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild_GoPackage(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.WriteFile("/repo/lock/lock.go", `// Package lock provides file-based locking
+// for concurrent process coordination.
+//
+// Details nobody reads.
+package lock
+
+// ErrHeld is returned when the lock is held
+var ErrHeld = errors.New("held")
+
+type Lock struct{ Path string }
+
+func (l *Lock) Release() error { return nil }
+func (l *Lock) close() {}
+
+type unexported struct{}
+
+func (u unexported) Exported() {}
+`)
+	h.WriteFile("/repo/lock/filelock.go", `package lock
+
+const DefaultTTL = time.Hour
+
+func New(fs afero.Fs) LockService { return nil }
+func processAlive(pid int) bool { return true }
+`)
+	h.WriteFile("/repo/lock/filelock_test.go", `package lock
+
+func TestAcquire(t *testing.T) {}
+func TestRelease(t *testing.T) {}
+func helper() {}
+`)
+	h.WriteFile("/repo/lock/README.txt", "not source")
+
+	s, err := Build(h.FS, "/repo/lock")
+
+	require.NoError(t, err)
+	assert.Equal(t, `# lock
+
+Package lock provides file-based locking for concurrent process coordination.
+
+## Files
+
+- `+"`filelock.go` - `DefaultTTL`, `New()`"+`
+- `+"`lock.go` - `ErrHeld`, `Lock`, `Lock.Release()`"+`
+
+## Tests
+
+- `+"`filelock_test.go`"+` - 2 tests
+`, s.Markdown())
+}
+
+func TestBuild_TypeScriptHeadersAndExports(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.WriteFile("/repo/web/index.ts", `/**
+ * Copyright 2024 Example Corp.
+ */
+
+/**
+ * Entry point of the web client. Wires the router.
+ */
+export { router } from "./router";
+`)
+	h.WriteFile("/repo/web/api.ts", `"use strict";
+import { get } from "./http";
+
+export async function fetchUser(id: string) {}
+export const BASE_URL = "/api";
+export default class ApiClient {}
+export interface User { id: string }
+function internal() {}
+`)
+	h.WriteFile("/repo/web/api.spec.ts", `// Tests for the API client
+describe("api", () => {});
+`)
+
+	s, err := Build(h.FS, "/repo/web")
+
+	require.NoError(t, err)
+	assert.Equal(t, "web", s.Title)
+	assert.Equal(t, "Entry point of the web client.", s.Summary)
+	require.Len(t, s.Files, 2)
+	assert.Equal(t, File{Name: "api.ts", Symbols: []string{"fetchUser", "BASE_URL", "ApiClient", "User"}}, s.Files[0])
+	assert.Equal(t, "Entry point of the web client.", s.Files[1].Header)
+	require.Len(t, s.Tests, 1)
+	assert.Equal(t, "Tests for the API client", s.Tests[0].Header)
+}
+
+func TestBuild_PythonDocstringAndRust(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.WriteFile("/repo/tools/__init__.py", `#!/usr/bin/env python
+# -*- coding: utf-8 -*-
+"""Helpers for the release pipeline.
+
+More text.
+"""
+
+def publish(): pass
+def _private(): pass
+class Release: pass
+`)
+	h.WriteFile("/repo/tools/lib.rs", `pub fn build() {}
+pub(crate) fn hidden() {}
+pub struct Config;
+`)
+
+	s, err := Build(h.FS, "/repo/tools")
+
+	require.NoError(t, err)
+	assert.Equal(t, "Helpers for the release pipeline.", s.Summary)
+	assert.Equal(t, []string{"publish", "Release"}, s.Files[0].Symbols)
+	assert.Equal(t, []string{"build", "Config"}, s.Files[1].Symbols)
+}
+
+func TestBuild_SubdirectoriesAndPlaceholder(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.WriteFile("/repo/app/api/index.md", "# API\n\nHTTP handlers for the public API. Versioned by path.\n\n## Files\n")
+	h.WriteFile("/repo/app/worker/job.go", "package worker\n")
+	h.WriteFile("/repo/app/node_modules/x/index.js", "")
+	h.WriteFile("/repo/app/.cache/a.go", "package cache\n")
+
+	s, err := Build(h.FS, "/repo/app")
+
+	require.NoError(t, err)
+	assert.Equal(t, `# app
+
+TODO: describe the purpose of this directory.
+
+## Subdirectories
+
+- [api/](./api/index.md) - HTTP handlers for the public API.
+- `+"`worker/`"+`
+`, s.Markdown())
+}
+
+func TestBuild_IsDeterministic(t *testing.T) {
+	h := testutil.NewTestHarness()
+	for _, name := range []string{"c.go", "a.go", "b.go"} {
+		h.WriteFile("/repo/pkg/"+name, "package pkg\n\nfunc "+string(name[0]-32)+"() {}\n")
+	}
+
+	first, err := Build(h.FS, "/repo/pkg")
+	require.NoError(t, err)
+	second, err := Build(h.FS, "/repo/pkg")
+	require.NoError(t, err)
+
+	assert.Equal(t, first.Markdown(), second.Markdown())
+	assert.Contains(t, first.Markdown(), "- `a.go` - `A()`\n- `b.go` - `B()`\n- `c.go` - `C()`\n")
+}
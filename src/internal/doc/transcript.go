@@ -4,138 +4,179 @@
 package doc
 
 import (
-	"bufio"
-	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
 
+	"claudex/internal/services/transcript"
+
 	"github.com/spf13/afero"
 )
 
+// Transcript entry types included in documentation increments
+const (
+	EntryUserPrompt       = "user_prompt"
+	EntryAssistantMessage = "assistant_message"
+	EntryAgentResult      = "agent_result"
+)
+
 // TranscriptEntry represents a parsed line from JSONL transcript
 type TranscriptEntry struct {
-	Type      string   `json:"type"`      // "assistant_message" or "agent_result"
+	Type      string   `json:"type"`      // EntryUserPrompt, EntryAssistantMessage or EntryAgentResult
 	Timestamp string   `json:"timestamp"` // ISO 8601 timestamp
 	AgentID   string   `json:"agentId,omitempty"`
-	Content   []string `json:"content"` // Text content extracted
-}
-
-// rawTranscriptLine represents the raw JSONL structure we're parsing
-type rawTranscriptLine struct {
-	Type          string            `json:"type"`
-	Timestamp     string            `json:"timestamp"`
-	Message       *rawMessage       `json:"message,omitempty"`
-	ToolUseResult *rawToolUseResult `json:"toolUseResult,omitempty"`
-}
-
-type rawMessage struct {
-	Content []rawContent `json:"content"`
+	Content   []string `json:"content"`         // Text content extracted
+	Files     []string `json:"files,omitempty"` // Files edited by the assistant turn
 }
 
-type rawToolUseResult struct {
-	Status  string       `json:"status"`
-	AgentID string       `json:"agentId"`
-	Content []rawContent `json:"content"`
-}
-
-type rawContent struct {
-	Type string `json:"type"`
-	Text string `json:"text,omitempty"`
+// ReadResult is where a transcript read stopped and what it had to skip
+type ReadResult struct {
+	transcript.Position
+	Skipped []transcript.SkippedLine
 }
 
 // ParseTranscript reads JSONL transcript and extracts relevant entries.
-// It filters for assistant messages and completed agent results.
+// It keeps user prompts, assistant messages (with the files they edited) and completed agent results.
 // startLine: line number to start from (1-indexed)
 // Returns entries and the last line number processed
 func ParseTranscript(fs afero.Fs, transcriptPath string, startLine int) ([]TranscriptEntry, int, error) {
-	file, err := fs.Open(transcriptPath)
+	entries, result, err := ParseTranscriptFrom(fs, transcriptPath, transcript.Position{Line: startLine - 1})
+	return entries, result.Line, err
+}
+
+// ParseTranscriptFrom reads the entries after start.
+// The file is opened at start.Offset, so resuming does not rescan earlier lines.
+// A line-only position (Offset 0, Line > 0) skips that many lines from the top.
+func ParseTranscriptFrom(fs afero.Fs, transcriptPath string, start transcript.Position) ([]TranscriptEntry, ReadResult, error) {
+	return ParseTranscriptChunk(fs, transcriptPath, start, 0)
+}
+
+// ParseTranscriptChunk reads at most maxEntries entries after start (0 reads to the end).
+// The returned position is just past the line of the last entry, so the next chunk starts there.
+func ParseTranscriptChunk(fs afero.Fs, transcriptPath string, start transcript.Position, maxEntries int) ([]TranscriptEntry, ReadResult, error) {
+	reader, err := transcript.Open(fs, transcriptPath, start)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to open transcript: %w", err)
+		return nil, ReadResult{Position: start}, err
 	}
-	defer file.Close()
+	defer reader.Close()
 
-	return parseTranscriptFromReader(file, startLine)
+	return parseTranscriptFromReader(reader, maxEntries)
 }
 
-// parseTranscriptFromReader parses transcript from an io.Reader
-// This allows for easier testing with in-memory data
-func parseTranscriptFromReader(r io.Reader, startLine int) ([]TranscriptEntry, int, error) {
-	scanner := bufio.NewScanner(r)
-
-	// Increase buffer size to handle large lines (default is 64KB)
-	const maxCapacity = 1024 * 1024 // 1MB
-	buf := make([]byte, maxCapacity)
-	scanner.Buffer(buf, maxCapacity)
+// editRef locates the file edits of an entry so failed tool_results can retract them
+type editRef struct {
+	entry int
+	path  string
+}
 
+// parseTranscriptFromReader converts typed transcript entries to documentation entries.
+// Edits whose tool_result reports an error are dropped, so Files lists only edits that happened.
+// A path stays listed as long as one of its tool_uses in the entry succeeded.
+func parseTranscriptFromReader(reader *transcript.Reader, maxEntries int) ([]TranscriptEntry, ReadResult, error) {
 	entries := []TranscriptEntry{}
-	lineNum := 0
-
-	for scanner.Scan() {
-		lineNum++
-
-		// Skip lines before startLine
-		if lineNum < startLine {
-			continue
+	edits := map[string]bool{}     // tool_use IDs of file edits
+	uses := map[editRef][]string{} // tool_use IDs per entry and edited path
+	failed := map[string]bool{}    // tool_use IDs whose tool_result is an error
+
+	for {
+		raw, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, ReadResult{Position: reader.Position(), Skipped: reader.Skipped()}, err
 		}
 
-		line := scanner.Text()
-		if strings.TrimSpace(line) == "" {
-			continue
+		for _, result := range raw.ToolResults() {
+			if edits[result.ToolUseID] && result.IsError {
+				failed[result.ToolUseID] = true
+			}
 		}
 
-		// Parse the raw JSONL line
-		var raw rawTranscriptLine
-		if err := json.Unmarshal([]byte(line), &raw); err != nil {
-			// Skip malformed JSON lines gracefully
+		entry := extractEntry(raw)
+		if entry == nil {
 			continue
 		}
-
-		// Extract relevant entries based on type
-		entry := extractEntry(&raw)
-		if entry != nil {
-			entries = append(entries, *entry)
+		for _, use := range raw.ToolUses() {
+			if path := use.EditedFile(); path != "" {
+				edits[use.ID] = true
+				ref := editRef{entry: len(entries), path: path}
+				uses[ref] = append(uses[ref], use.ID)
+			}
+		}
+		entries = append(entries, *entry)
+		if maxEntries > 0 && len(entries) >= maxEntries {
+			break
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, lineNum, fmt.Errorf("error reading transcript: %w", err)
+	result := ReadResult{Position: reader.Position(), Skipped: reader.Skipped()}
+	if len(failed) == 0 {
+		return entries, result, nil
 	}
 
-	return entries, lineNum, nil
+	// Retract failed edits; drop assistant turns left with nothing to report
+	kept := []TranscriptEntry{}
+	for i, entry := range entries {
+		files := []string{}
+		for _, path := range entry.Files {
+			for _, id := range uses[editRef{entry: i, path: path}] {
+				if !failed[id] {
+					files = append(files, path)
+					break
+				}
+			}
+		}
+		if len(entry.Files) > 0 {
+			entry.Files = files
+		}
+		if entry.Type == EntryAssistantMessage && len(entry.Content) == 0 && len(entry.Files) == 0 {
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	return kept, result, nil
 }
 
-// extractEntry converts a raw transcript line to a TranscriptEntry if relevant
-// Returns nil if the line should be filtered out
-func extractEntry(raw *rawTranscriptLine) *TranscriptEntry {
-	// Filter 1: Assistant messages with content
-	if raw.Type == "assistant" && raw.Message != nil && len(raw.Message.Content) > 0 {
-		textContent := extractTextContent(raw.Message.Content)
-		if len(textContent) == 0 {
+// extractEntry converts a transcript entry to a TranscriptEntry if relevant
+// Returns nil if the entry should be filtered out
+func extractEntry(raw *transcript.Entry) *TranscriptEntry {
+	// Filter 1: Prompts typed by the user (sub-agent instructions are not user intent)
+	if raw.IsUserPrompt() && !raw.IsSidechain {
+		return &TranscriptEntry{
+			Type:      EntryUserPrompt,
+			Timestamp: raw.Timestamp,
+			Content:   raw.Text(),
+		}
+	}
+
+	// Filter 2: Assistant messages with text or file edits
+	if raw.Type == transcript.TypeAssistant {
+		textContent := raw.Text()
+		files := raw.EditedFiles()
+		if len(textContent) == 0 && len(files) == 0 {
 			return nil
 		}
 
 		return &TranscriptEntry{
-			Type:      "assistant_message",
+			Type:      EntryAssistantMessage,
 			Timestamp: raw.Timestamp,
 			Content:   textContent,
+			Files:     files,
 		}
 	}
 
-	// Filter 2: Completed tool results with agentId (sub-agent results)
-	if raw.Type == "user" && raw.ToolUseResult != nil &&
-		raw.ToolUseResult.Status == "completed" &&
-		raw.ToolUseResult.AgentID != "" {
-
-		textContent := extractTextContent(raw.ToolUseResult.Content)
+	// Filter 3: Completed tool results with agentId (sub-agent results)
+	if result, ok := raw.AgentResult(); ok && raw.Type == transcript.TypeUser && result.Status == "completed" {
+		textContent := result.Content.Text()
 		if len(textContent) == 0 {
 			return nil
 		}
 
 		return &TranscriptEntry{
-			Type:      "agent_result",
+			Type:      EntryAgentResult,
 			Timestamp: raw.Timestamp,
-			AgentID:   raw.ToolUseResult.AgentID,
+			AgentID:   result.AgentID,
 			Content:   textContent,
 		}
 	}
@@ -143,17 +184,6 @@ func extractEntry(raw *rawTranscriptLine) *TranscriptEntry {
 	return nil
 }
 
-// extractTextContent filters content array for text-only items
-func extractTextContent(content []rawContent) []string {
-	texts := []string{}
-	for _, c := range content {
-		if c.Type == "text" && strings.TrimSpace(c.Text) != "" {
-			texts = append(texts, c.Text)
-		}
-	}
-	return texts
-}
-
 // FormatTranscriptForPrompt converts entries to markdown for Claude prompt
 func FormatTranscriptForPrompt(entries []TranscriptEntry) string {
 	if len(entries) == 0 {
@@ -163,17 +193,37 @@ func FormatTranscriptForPrompt(entries []TranscriptEntry) string {
 	var sb strings.Builder
 	sb.WriteString("# Transcript Increment\n\n")
 
+	var edited []string
+	seen := map[string]bool{}
+
 	for _, entry := range entries {
 		switch entry.Type {
-		case "assistant_message":
+		case EntryUserPrompt:
+			sb.WriteString("## User Prompt\n")
+			sb.WriteString(fmt.Sprintf("**Timestamp**: %s\n\n", entry.Timestamp))
+			for _, text := range entry.Content {
+				sb.WriteString(text)
+				sb.WriteString("\n\n")
+			}
+
+		case EntryAssistantMessage:
 			sb.WriteString("## Assistant Message\n")
 			sb.WriteString(fmt.Sprintf("**Timestamp**: %s\n\n", entry.Timestamp))
 			for _, text := range entry.Content {
 				sb.WriteString(text)
 				sb.WriteString("\n\n")
 			}
+			if len(entry.Files) > 0 {
+				sb.WriteString(fmt.Sprintf("**Files edited**: %s\n\n", strings.Join(entry.Files, ", ")))
+			}
+			for _, path := range entry.Files {
+				if !seen[path] {
+					seen[path] = true
+					edited = append(edited, path)
+				}
+			}
 
-		case "agent_result":
+		case EntryAgentResult:
 			sb.WriteString("## Agent Result\n")
 			sb.WriteString(fmt.Sprintf("**Timestamp**: %s\n", entry.Timestamp))
 			sb.WriteString(fmt.Sprintf("**Agent ID**: %s\n\n", entry.AgentID))
@@ -186,5 +236,13 @@ func FormatTranscriptForPrompt(entries []TranscriptEntry) string {
 		sb.WriteString("---\n\n")
 	}
 
+	if len(edited) > 0 {
+		sb.WriteString("## Files Edited\n")
+		for _, path := range edited {
+			sb.WriteString(fmt.Sprintf("- %s\n", path))
+		}
+		sb.WriteString("\n")
+	}
+
 	return sb.String()
 }
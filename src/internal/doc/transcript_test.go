@@ -4,6 +4,8 @@ import (
 	"strings"
 	"testing"
 
+	"claudex/internal/services/transcript"
+
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -255,150 +257,245 @@ func TestFormatTranscriptForPrompt_Mixed(t *testing.T) {
 	assert.Less(t, assistantIdx, agentIdx, "Assistant message should come before agent result")
 }
 
-func TestExtractTextContent(t *testing.T) {
+func TestExtractEntry(t *testing.T) {
 	tests := []struct {
 		name     string
-		content  []rawContent
-		expected []string
+		raw      string
+		expected *TranscriptEntry
 	}{
 		{
-			name: "text only",
-			content: []rawContent{
-				{Type: "text", Text: "Hello"},
+			name: "valid assistant message",
+			raw:  `{"type":"assistant","timestamp":"2024-01-15T10:30:00Z","message":{"role":"assistant","content":[{"type":"text","text":"Hello"}]}}`,
+			expected: &TranscriptEntry{
+				Type:      EntryAssistantMessage,
+				Timestamp: "2024-01-15T10:30:00Z",
+				Content:   []string{"Hello"},
 			},
-			expected: []string{"Hello"},
 		},
 		{
-			name: "mixed types",
-			content: []rawContent{
-				{Type: "text", Text: "First"},
-				{Type: "tool_use", Text: "should be ignored"},
-				{Type: "text", Text: "Second"},
-			},
-			expected: []string{"First", "Second"},
+			name:     "assistant message without content",
+			raw:      `{"type":"assistant","timestamp":"2024-01-15T10:30:00Z","message":{"role":"assistant","content":[]}}`,
+			expected: nil,
 		},
 		{
-			name: "empty text filtered",
-			content: []rawContent{
-				{Type: "text", Text: ""},
-				{Type: "text", Text: "   "},
-				{Type: "text", Text: "Valid"},
+			name: "assistant file edit without text",
+			raw:  `{"type":"assistant","timestamp":"2024-01-15T10:30:00Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Edit","input":{"file_path":"/repo/main.go"}},{"type":"tool_use","id":"t2","name":"Read","input":{"file_path":"/repo/go.mod"}}]}}`,
+			expected: &TranscriptEntry{
+				Type:      EntryAssistantMessage,
+				Timestamp: "2024-01-15T10:30:00Z",
+				Content:   []string{},
+				Files:     []string{"/repo/main.go"},
 			},
-			expected: []string{"Valid"},
 		},
 		{
-			name:     "no text content",
-			content:  []rawContent{{Type: "tool_use"}},
-			expected: []string{},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := extractTextContent(tt.content)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
-func TestExtractEntry(t *testing.T) {
-	tests := []struct {
-		name     string
-		raw      *rawTranscriptLine
-		expected *TranscriptEntry
-	}{
-		{
-			name: "valid assistant message",
-			raw: &rawTranscriptLine{
-				Type:      "assistant",
-				Timestamp: "2024-01-15T10:30:00Z",
-				Message: &rawMessage{
-					Content: []rawContent{
-						{Type: "text", Text: "Hello"},
-					},
-				},
-			},
+			name: "user prompt",
+			raw:  `{"type":"user","timestamp":"2024-01-15T10:30:00Z","message":{"role":"user","content":"Add a login page"}}`,
 			expected: &TranscriptEntry{
-				Type:      "assistant_message",
+				Type:      EntryUserPrompt,
 				Timestamp: "2024-01-15T10:30:00Z",
-				Content:   []string{"Hello"},
+				Content:   []string{"Add a login page"},
 			},
 		},
 		{
-			name: "assistant message without content",
-			raw: &rawTranscriptLine{
-				Type:      "assistant",
-				Timestamp: "2024-01-15T10:30:00Z",
-				Message: &rawMessage{
-					Content: []rawContent{},
-				},
-			},
+			name:     "meta user message",
+			raw:      `{"type":"user","isMeta":true,"timestamp":"2024-01-15T10:30:00Z","message":{"role":"user","content":"Caveat: local commands"}}`,
+			expected: nil,
+		},
+		{
+			name:     "sidechain prompt",
+			raw:      `{"type":"user","isSidechain":true,"timestamp":"2024-01-15T10:30:00Z","message":{"role":"user","content":"Research the API"}}`,
 			expected: nil,
 		},
 		{
 			name: "valid agent result",
-			raw: &rawTranscriptLine{
-				Type:      "user",
-				Timestamp: "2024-01-15T10:30:00Z",
-				ToolUseResult: &rawToolUseResult{
-					Status:  "completed",
-					AgentID: "agent-123",
-					Content: []rawContent{
-						{Type: "text", Text: "Done"},
-					},
-				},
-			},
+			raw:  `{"type":"user","timestamp":"2024-01-15T10:30:00Z","toolUseResult":{"status":"completed","agentId":"agent-123","content":[{"type":"text","text":"Done"}]}}`,
 			expected: &TranscriptEntry{
-				Type:      "agent_result",
+				Type:      EntryAgentResult,
 				Timestamp: "2024-01-15T10:30:00Z",
 				AgentID:   "agent-123",
 				Content:   []string{"Done"},
 			},
 		},
 		{
-			name: "agent result without agent ID",
-			raw: &rawTranscriptLine{
-				Type:      "user",
-				Timestamp: "2024-01-15T10:30:00Z",
-				ToolUseResult: &rawToolUseResult{
-					Status:  "completed",
-					AgentID: "",
-					Content: []rawContent{
-						{Type: "text", Text: "Should be filtered"},
-					},
-				},
-			},
+			name:     "agent result without agent ID",
+			raw:      `{"type":"user","timestamp":"2024-01-15T10:30:00Z","toolUseResult":{"status":"completed","agentId":"","content":[{"type":"text","text":"Should be filtered"}]}}`,
 			expected: nil,
 		},
 		{
-			name: "agent result not completed",
-			raw: &rawTranscriptLine{
-				Type:      "user",
-				Timestamp: "2024-01-15T10:30:00Z",
-				ToolUseResult: &rawToolUseResult{
-					Status:  "failed",
-					AgentID: "agent-123",
-					Content: []rawContent{
-						{Type: "text", Text: "Should be filtered"},
-					},
-				},
-			},
+			name:     "agent result not completed",
+			raw:      `{"type":"user","timestamp":"2024-01-15T10:30:00Z","toolUseResult":{"status":"failed","agentId":"agent-123","content":[{"type":"text","text":"Should be filtered"}]}}`,
 			expected: nil,
 		},
 		{
-			name: "unrelated type",
-			raw: &rawTranscriptLine{
-				Type:      "other",
-				Timestamp: "2024-01-15T10:30:00Z",
-			},
+			name:     "unrelated type",
+			raw:      `{"type":"other","timestamp":"2024-01-15T10:30:00Z"}`,
 			expected: nil,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := extractEntry(tt.raw)
+			raw, err := transcript.ParseEntry([]byte(tt.raw))
+			require.NoError(t, err)
+
+			result := extractEntry(raw)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
 }
+
+func TestParseTranscript_UserPromptsAndEditedFiles(t *testing.T) {
+	// Setup: a prompt, an edit that succeeds, an edit that fails and its error result
+	fs := afero.NewMemMapFs()
+	transcriptPath := "/test/transcript.jsonl"
+	content := `{"type":"user","timestamp":"2024-01-15T10:30:00Z","message":{"role":"user","content":"Rename the config loader"}}
+{"type":"assistant","timestamp":"2024-01-15T10:31:00Z","message":{"role":"assistant","content":[{"type":"text","text":"Renaming it."},{"type":"tool_use","id":"t1","name":"Edit","input":{"file_path":"/repo/config.go"}},{"type":"tool_use","id":"t2","name":"Write","input":{"file_path":"/repo/missing.go"}}]}}
+{"type":"user","timestamp":"2024-01-15T10:32:00Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"},{"type":"tool_result","tool_use_id":"t2","content":"permission denied","is_error":true}]}}
+`
+	afero.WriteFile(fs, transcriptPath, []byte(content), 0644)
+
+	// Exercise
+	entries, lastLine, err := ParseTranscript(fs, transcriptPath, 1)
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, 3, lastLine)
+	require.Len(t, entries, 2)
+	assert.Equal(t, EntryUserPrompt, entries[0].Type)
+	assert.Equal(t, []string{"Rename the config loader"}, entries[0].Content)
+	assert.Equal(t, EntryAssistantMessage, entries[1].Type)
+	assert.Equal(t, []string{"/repo/config.go"}, entries[1].Files, "failed edits are not reported")
+}
+
+func TestParseTranscript_FailedEditThenSuccessfulEditToSameFile(t *testing.T) {
+	// Setup: a failed Edit retried in the same turn, then a failed Edit retried in a later turn
+	fs := afero.NewMemMapFs()
+	transcriptPath := "/test/transcript.jsonl"
+	content := `{"type":"assistant","timestamp":"2024-01-15T10:31:00Z","message":{"role":"assistant","content":[{"type":"text","text":"Editing the loader."},{"type":"tool_use","id":"t1","name":"Edit","input":{"file_path":"/repo/config.go"}},{"type":"tool_use","id":"t2","name":"Edit","input":{"file_path":"/repo/config.go"}}]}}
+{"type":"user","timestamp":"2024-01-15T10:32:00Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"old_string not found","is_error":true},{"type":"tool_result","tool_use_id":"t2","content":"ok"}]}}
+{"type":"assistant","timestamp":"2024-01-15T10:33:00Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"t3","name":"Edit","input":{"file_path":"/repo/main.go"}}]}}
+{"type":"user","timestamp":"2024-01-15T10:34:00Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t3","content":"old_string not found","is_error":true}]}}
+{"type":"assistant","timestamp":"2024-01-15T10:35:00Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"t4","name":"Edit","input":{"file_path":"/repo/main.go"}}]}}
+{"type":"user","timestamp":"2024-01-15T10:36:00Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t4","content":"ok"}]}}
+`
+	afero.WriteFile(fs, transcriptPath, []byte(content), 0644)
+
+	// Exercise
+	entries, _, err := ParseTranscript(fs, transcriptPath, 1)
+
+	// Verify
+	require.NoError(t, err)
+	require.Len(t, entries, 2, "the turn whose only edit failed is dropped")
+	assert.Equal(t, []string{"/repo/config.go"}, entries[0].Files, "a successful retry in the same turn keeps the file")
+	assert.Equal(t, "2024-01-15T10:35:00Z", entries[1].Timestamp)
+	assert.Equal(t, []string{"/repo/main.go"}, entries[1].Files, "a successful retry in a later turn keeps the file")
+}
+
+func TestFormatTranscriptForPrompt_UserPromptAndFiles(t *testing.T) {
+	entries := []TranscriptEntry{
+		{Type: EntryUserPrompt, Timestamp: "2024-01-15T10:30:00Z", Content: []string{"Fix the parser"}},
+		{Type: EntryAssistantMessage, Timestamp: "2024-01-15T10:31:00Z", Content: []string{"Fixed."}, Files: []string{"/repo/parser.go"}},
+		{Type: EntryAssistantMessage, Timestamp: "2024-01-15T10:32:00Z", Files: []string{"/repo/parser.go", "/repo/parser_test.go"}},
+	}
+
+	result := FormatTranscriptForPrompt(entries)
+
+	assert.Contains(t, result, "## User Prompt")
+	assert.Contains(t, result, "Fix the parser")
+	assert.Contains(t, result, "**Files edited**: /repo/parser.go")
+	assert.Contains(t, result, "## Files Edited\n- /repo/parser.go\n- /repo/parser_test.go\n")
+	assert.Less(t, strings.Index(result, "## User Prompt"), strings.Index(result, "## Assistant Message"))
+}
+
+func TestParseTranscript_SkipsOversizedLine(t *testing.T) {
+	// Setup: a tool result far larger than the old 1MB scanner limit
+	fs := afero.NewMemMapFs()
+	transcriptPath := "/test/transcript.jsonl"
+	huge := `{"type":"user","toolUseResult":"` + strings.Repeat("x", transcript.DefaultMaxLineBytes) + `"}`
+	content := huge + "\n" +
+		`{"type":"assistant","timestamp":"2024-01-15T10:30:00Z","message":{"content":[{"type":"text","text":"After the big result."}]}}` + "\n"
+	afero.WriteFile(fs, transcriptPath, []byte(content), 0644)
+
+	// Exercise
+	entries, result, err := ParseTranscriptFrom(fs, transcriptPath, transcript.Position{})
+
+	// Verify
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, []string{"After the big result."}, entries[0].Content)
+	assert.Equal(t, 2, result.Line)
+	assert.Equal(t, int64(len(content)), result.Offset)
+	require.Len(t, result.Skipped, 1)
+	assert.Equal(t, transcript.SkippedLine{Line: 1, Offset: 0, Size: int64(len(huge) + 1)}, result.Skipped[0])
+}
+
+func TestParseTranscriptFrom_SeeksToOffset(t *testing.T) {
+	// Setup
+	fs := afero.NewMemMapFs()
+	transcriptPath := "/test/transcript.jsonl"
+	first := `{"type":"assistant","message":{"content":[{"type":"text","text":"First"}]}}` + "\n"
+	second := `{"type":"assistant","message":{"content":[{"type":"text","text":"Second"}]}}` + "\n"
+	afero.WriteFile(fs, transcriptPath, []byte(first+second), 0644)
+
+	// Exercise
+	entries, result, err := ParseTranscriptFrom(fs, transcriptPath, transcript.Position{Line: 1, Offset: int64(len(first))})
+
+	// Verify
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, []string{"Second"}, entries[0].Content)
+	assert.Equal(t, transcript.Position{Line: 2, Offset: int64(len(first + second))}, result.Position)
+}
+
+func TestParseTranscriptChunk_StopsAfterMaxEntries(t *testing.T) {
+	// Setup: a skipped tool-only line sits between the second and third entries
+	fs := afero.NewMemMapFs()
+	transcriptPath := "/test/transcript.jsonl"
+	first := `{"type":"assistant","message":{"content":[{"type":"text","text":"One"}]}}` + "\n"
+	second := `{"type":"assistant","message":{"content":[{"type":"text","text":"Two"}]}}` + "\n"
+	noise := `{"type":"system","content":"noise"}` + "\n"
+	third := `{"type":"assistant","message":{"content":[{"type":"text","text":"Three"}]}}` + "\n"
+	afero.WriteFile(fs, transcriptPath, []byte(first+second+noise+third), 0644)
+
+	// Exercise
+	entries, result, err := ParseTranscriptChunk(fs, transcriptPath, transcript.Position{}, 2)
+	require.NoError(t, err)
+	rest, _, err := ParseTranscriptChunk(fs, transcriptPath, result.Position, 2)
+	require.NoError(t, err)
+
+	// Verify: the next chunk starts right after the last returned entry
+	require.Len(t, entries, 2)
+	assert.Equal(t, transcript.Position{Line: 2, Offset: int64(len(first + second))}, result.Position)
+	require.Len(t, rest, 1)
+	assert.Equal(t, []string{"Three"}, rest[0].Content)
+}
+
+func TestParseTranscriptFrom_LeavesPartialTrailingLine(t *testing.T) {
+	// Setup: the last line is still being written
+	fs := afero.NewMemMapFs()
+	transcriptPath := "/test/transcript.jsonl"
+	complete := `{"type":"assistant","message":{"content":[{"type":"text","text":"Done"}]}}` + "\n"
+	partial := `{"type":"assistant","message":{"content":[{"type":"te`
+	afero.WriteFile(fs, transcriptPath, []byte(complete+partial), 0644)
+
+	// Exercise
+	entries, result, err := ParseTranscriptFrom(fs, transcriptPath, transcript.Position{})
+
+	// Verify: the fragment is left for the next read
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, transcript.Position{Line: 1, Offset: int64(len(complete))}, result.Position)
+}
+
+func TestParseTranscriptFrom_ConsumesCompleteUnterminatedLine(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	transcriptPath := "/test/transcript.jsonl"
+	content := `{"type":"assistant","message":{"content":[{"type":"text","text":"No newline"}]}}`
+	afero.WriteFile(fs, transcriptPath, []byte(content), 0644)
+
+	entries, result, err := ParseTranscriptFrom(fs, transcriptPath, transcript.Position{})
+
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, transcript.Position{Line: 1, Offset: int64(len(content))}, result.Position)
+}
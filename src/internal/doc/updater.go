@@ -1,56 +1,86 @@
 package doc
 
 import (
-	"bytes"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
-	"syscall"
+	"path/filepath"
+	"strings"
 
+	"claudex/internal/services/clock"
 	"claudex/internal/services/commander"
+	"claudex/internal/services/cursor"
 	"claudex/internal/services/env"
+	"claudex/internal/services/history"
+	"claudex/internal/services/jobs"
+	"claudex/internal/services/llm"
+	"claudex/internal/services/transcript"
 
 	"github.com/spf13/afero"
 )
 
 // UpdaterConfig holds configuration for documentation updates
 type UpdaterConfig struct {
-	SessionPath    string // Absolute path to session folder
-	TranscriptPath string // Path to transcript JSONL file
-	OutputFile     string // Target file (e.g., session-overview.md)
-	PromptTemplate string // Path to prompt template file
-	SessionContext string // Additional session context to include
-	Model          string // Claude model to use (e.g., "haiku")
-	StartLine      int    // Line number to start reading transcript (1-indexed)
+	SessionPath    string           // Absolute path to session folder
+	TranscriptPath string           // Path to transcript JSONL file
+	OutputFile     string           // Target file (e.g., session-overview.md), defaults to DefaultOutputFile
+	PromptTemplate string           // Path to prompt template file
+	SessionContext string           // Additional session context to include
+	Model          string           // Claude model to use (e.g., "haiku")
+	StartLine      int              // Line to start reading the transcript from (1-indexed); 0 resumes from the transcript's cursor
+	Trigger        string           // What requested the update (progress, subagent, session_end), recorded in snapshots
+	HistoryLimit   int              // Snapshots of the output file kept in <session>/.history before overwriting (0 disables)
+	Validation     *ValidationRules // Checks the new content must pass before replacing the output file (nil disables)
+	Redaction      *RedactionRules  // What is masked in transcript entries before they are formatted (nil disables)
+	MaxEntries     int              // Entries sent to the model in one run; the cursor stops after the last one (0 sends all)
 }
 
-// Updater handles background Claude invocations for doc updates
+// DefaultOutputFile is the session document updated when UpdaterConfig.OutputFile is empty
+const DefaultOutputFile = "session-overview.md"
+
+// MaxRejectedUpdates is the number of updates in a row rejected by validation from the
+// same transcript position before that range is dropped and the cursor moves past it
+const MaxRejectedUpdates = 2
+
+// ErrValidationFailed is wrapped by Run when the generated document still fails
+// validation after the corrective attempt. Retrying the same input would not help.
+var ErrValidationFailed = errors.New("not retrying the same input")
+
+// Updater handles background model invocations for doc updates
 type Updater struct {
 	fs  afero.Fs
 	cmd commander.Commander
 	env env.Environment
+	llm llm.Client
+
+	// spawnWorker starts the detached job worker; tests replace it to avoid exec
+	spawnWorker func(hooksBin, sessionPath string) error
 }
 
-// NewUpdater creates a new Updater instance
-func NewUpdater(fs afero.Fs, cmd commander.Commander, env env.Environment) *Updater {
+// NewUpdater creates a new Updater instance.
+// client may be nil for updaters that only call RunBackground (the detached
+// doc-update subprocess creates its own client).
+func NewUpdater(fs afero.Fs, cmd commander.Commander, env env.Environment, client llm.Client) *Updater {
 	return &Updater{
 		fs:  fs,
 		cmd: cmd,
 		env: env,
+		llm: client,
+
+		spawnWorker: jobs.SpawnWorker,
 	}
 }
 
-// RunBackground starts doc update as a detached subprocess
-// Returns immediately, update happens asynchronously in a separate process
-// that survives the parent process exit
+// RunBackground queues the doc update in <session>/jobs/ and starts a detached
+// job worker. Returns immediately; the worker runs one update at a time per
+// session, records each attempt's status and retries failures with backoff.
 func (u *Updater) RunBackground(config UpdaterConfig) error {
 	// Validate configuration
 	if err := u.validateConfig(config); err != nil {
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
 
-	// Prepare input JSON for the subprocess
+	// Payload for the `claudex-hooks doc-update` child run by the worker
 	input := docUpdateInput{
 		SessionPath:    config.SessionPath,
 		TranscriptPath: config.TranscriptPath,
@@ -59,11 +89,15 @@ func (u *Updater) RunBackground(config UpdaterConfig) error {
 		SessionContext: config.SessionContext,
 		Model:          config.Model,
 		StartLine:      config.StartLine,
+		Trigger:        config.Trigger,
+		HistoryLimit:   config.HistoryLimit,
+		Validation:     config.Validation,
+		Redaction:      config.Redaction,
 	}
 
-	inputJSON, err := json.Marshal(input)
-	if err != nil {
-		return fmt.Errorf("failed to marshal input: %w", err)
+	queue := jobs.NewQueue(u.fs, clock.New(), config.SessionPath)
+	if _, err := queue.Enqueue(jobs.KindDocUpdate, input); err != nil {
+		return fmt.Errorf("failed to queue doc update: %w", err)
 	}
 
 	// Find the claudex-hooks binary
@@ -72,44 +106,11 @@ func (u *Updater) RunBackground(config UpdaterConfig) error {
 		hooksBin = "claudex-hooks"
 	}
 
-	// Create command for the detached subprocess
-	cmd := exec.Command(hooksBin, "doc-update")
-
-	// Set up stdin pipe to pass the config
-	stdin, err := cmd.StdinPipe()
-	if err != nil {
-		return fmt.Errorf("failed to create stdin pipe: %w", err)
-	}
-
-	// Inherit environment (recursion guard will be set by invokeClaude, not here)
-	cmd.Env = os.Environ()
-
-	// Detach the process so it survives parent exit
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		Setpgid: true, // Create new process group
-	}
-
-	// Discard stdout/stderr (subprocess logs to file via logger)
-	cmd.Stdout = nil
-	cmd.Stderr = nil
-
-	// Start the subprocess (non-blocking)
-	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start doc-update subprocess: %w", err)
-	}
-
-	// Write input synchronously and close stdin
-	// This must complete before we return, otherwise the subprocess won't receive input
-	if _, err := stdin.Write(inputJSON); err != nil {
-		return fmt.Errorf("failed to write input to subprocess: %w", err)
+	// A worker that is already draining this session exits immediately and
+	// leaves the new job to the running one
+	if err := u.spawnWorker(hooksBin, config.SessionPath); err != nil {
+		return fmt.Errorf("failed to start doc-update worker: %w", err)
 	}
-	stdin.Close()
-
-	// Don't wait for the subprocess - let it run independently
-	// The process will be orphaned and adopted by init/launchd
-	go func() {
-		_ = cmd.Wait() // Reap the zombie when done
-	}()
 
 	return nil
 }
@@ -117,13 +118,17 @@ func (u *Updater) RunBackground(config UpdaterConfig) error {
 // docUpdateInput matches the shared.DocUpdateInput structure
 // Defined here to avoid circular imports
 type docUpdateInput struct {
-	SessionPath    string `json:"session_path"`
-	TranscriptPath string `json:"transcript_path"`
-	OutputFile     string `json:"output_file"`
-	PromptTemplate string `json:"prompt_template"`
-	SessionContext string `json:"session_context"`
-	Model          string `json:"model"`
-	StartLine      int    `json:"start_line"`
+	SessionPath    string           `json:"session_path"`
+	TranscriptPath string           `json:"transcript_path"`
+	OutputFile     string           `json:"output_file"`
+	PromptTemplate string           `json:"prompt_template"`
+	SessionContext string           `json:"session_context"`
+	Model          string           `json:"model"`
+	StartLine      int              `json:"start_line"`
+	Trigger        string           `json:"trigger"`
+	HistoryLimit   int              `json:"history_limit"`
+	Validation     *ValidationRules `json:"validation,omitempty"`
+	Redaction      *RedactionRules  `json:"redaction,omitempty"`
 }
 
 // Run executes doc update synchronously (for testing)
@@ -134,15 +139,48 @@ func (u *Updater) Run(config UpdaterConfig) error {
 		return fmt.Errorf("recursion guard: CLAUDE_HOOK_INTERNAL is set")
 	}
 
-	// Parse transcript from startLine
-	entries, lastLine, err := ParseTranscript(u.fs, config.TranscriptPath, config.StartLine)
+	// Resolve the start position from this transcript's cursor unless the caller forces a line.
+	// The cursor is read when the job runs, so a job queued behind another update
+	// continues where that update stopped.
+	cursors := cursor.New(u.fs, clock.New(), config.SessionPath)
+	start := transcript.Position{Line: config.StartLine - 1}
+	if config.StartLine == 0 {
+		resume, state, err := cursors.Resume(config.TranscriptPath)
+		if err != nil {
+			return fmt.Errorf("failed to read transcript cursor: %w", err)
+		}
+		if state == cursor.StateTruncated || state == cursor.StateRotated {
+			// Stderr is captured in the job log
+			fmt.Fprintf(os.Stderr, "transcript %s was %s since the last update; reading it from the start\n", config.TranscriptPath, state)
+		}
+		start = transcript.Position{Line: resume.Line, Offset: resume.Offset}
+	}
+
+	entries, result, err := ParseTranscriptChunk(u.fs, config.TranscriptPath, start, config.MaxEntries)
 	if err != nil {
 		return fmt.Errorf("failed to parse transcript: %w", err)
 	}
+	for _, skipped := range result.Skipped {
+		fmt.Fprintf(os.Stderr, "skipped transcript line %d (%d bytes at offset %d): larger than %d bytes\n",
+			skipped.Line, skipped.Size, skipped.Offset, transcript.DefaultMaxLineBytes)
+	}
+	pos := result.Position
 
-	// Nothing to process
+	// Nothing to process - still advance past the lines that had no relevant content
 	if len(entries) == 0 {
-		return nil
+		return u.advanceCursor(cursors, config.TranscriptPath, pos)
+	}
+
+	// Mask secrets, emails and tokens before anything reaches the model or the document
+	if config.Redaction != nil {
+		redactor, err := NewRedactor(*config.Redaction)
+		if err != nil {
+			return fmt.Errorf("invalid redaction rules: %w", err)
+		}
+		var stats RedactionStats
+		entries, stats = redactor.RedactEntries(entries)
+		// Stderr is captured in the job log
+		fmt.Fprintf(os.Stderr, "redaction: %s\n", stats)
 	}
 
 	// Format transcript for prompt
@@ -154,20 +192,112 @@ func (u *Updater) Run(config UpdaterConfig) error {
 		return fmt.Errorf("failed to load prompt template: %w", err)
 	}
 
+	outputFile := config.OutputFile
+	if outputFile == "" {
+		outputFile = DefaultOutputFile
+	}
+	outputPath := filepath.Join(config.SessionPath, outputFile)
+
+	// Existing document is passed to the model so it can update rather than rewrite
+	existing, err := afero.ReadFile(u.fs, outputPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", outputFile, err)
+	}
+
 	// Build final prompt
 	prompt := BuildDocumentationPrompt(template, transcriptContent, config.SessionContext, config.SessionPath)
+	prompt += BuildOutputInstructions(outputPath, string(existing))
 
-	// Invoke Claude with recursion guard
-	if err := u.invokeClaude(prompt, config.Model); err != nil {
-		return fmt.Errorf("failed to invoke Claude: %w", err)
+	// Invoke the configured LLM backend
+	content, err := u.invokeLLM(prompt, config.Model)
+	if err != nil {
+		return fmt.Errorf("failed to invoke model: %w", err)
 	}
 
-	// Update last processed line marker
-	lastLineFile := fmt.Sprintf("%s/.last-processed-line-overview", config.SessionPath)
-	if err := afero.WriteFile(u.fs, lastLineFile, []byte(fmt.Sprintf("%d", lastLine)), 0644); err != nil {
-		return fmt.Errorf("failed to update last processed line: %w", err)
+	// Reject content that breaks the document's structure. The model gets one
+	// corrective attempt with the issues listed; if that fails too, the previous
+	// version stays in place and the next update reads the same range again,
+	// until MaxRejectedUpdates in a row drop it.
+	if config.Validation != nil {
+		if issues := u.validateOutput(config, string(existing), content); len(issues) > 0 {
+			logValidationIssues(outputFile, issues)
+			correction := prompt + BuildCorrectionInstructions(outputPath, content, issues)
+			content, err = u.invokeLLM(correction, config.Model)
+			if err != nil {
+				return fmt.Errorf("failed to invoke model for the corrective attempt: %w", err)
+			}
+			if issues := u.validateOutput(config, string(existing), content); len(issues) > 0 {
+				logValidationIssues(outputFile, issues)
+				if err := u.rejectRange(cursors, config, start, pos); err != nil {
+					return err
+				}
+				return fmt.Errorf("generated %s failed validation (%d issues) after a corrective attempt; kept the previous version: %w",
+					outputFile, len(issues), ErrValidationFailed)
+			}
+		}
 	}
 
+	// Keep the previous version so a bad rewrite can be diffed and restored
+	if config.HistoryLimit > 0 {
+		snapshots := history.New(u.fs, clock.New(), config.SessionPath)
+		if _, err := snapshots.Snapshot(outputFile, config.Trigger, config.HistoryLimit); err != nil {
+			return fmt.Errorf("failed to snapshot %s: %w", outputFile, err)
+		}
+	}
+
+	if err := afero.WriteFile(u.fs, outputPath, []byte(content+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputFile, err)
+	}
+
+	// Advance the transcript cursor
+	return u.advanceCursor(cursors, config.TranscriptPath, pos)
+}
+
+// validateOutput returns the validation issues the new content introduces.
+// Issues the previous version already had are ignored, so an older document
+// that never had a required section does not block every update.
+func (u *Updater) validateOutput(config UpdaterConfig, previous, content string) []ValidationIssue {
+	after := ValidateDocument(u.fs, config.SessionPath, content, *config.Validation)
+	if strings.TrimSpace(previous) == "" {
+		return after
+	}
+	before := ValidateDocument(u.fs, config.SessionPath, previous, *config.Validation)
+	return NewIssues(before, after)
+}
+
+// rejectRange counts a rejected update of the transcript range start..end. Once
+// MaxRejectedUpdates in a row started at the same position, the range is dropped:
+// the cursor moves to end so later updates stop resending it. Ranges read from a
+// forced StartLine are not counted.
+func (u *Updater) rejectRange(cursors *cursor.Store, config UpdaterConfig, start, end transcript.Position) error {
+	if config.StartLine != 0 {
+		return nil
+	}
+	rejections, err := cursors.Reject(config.TranscriptPath, start.Offset, start.Line)
+	if err != nil {
+		return fmt.Errorf("failed to record rejected update: %w", err)
+	}
+	if rejections < MaxRejectedUpdates {
+		return nil
+	}
+	// Stderr is captured in the job log
+	fmt.Fprintf(os.Stderr, "dropped transcript lines %d-%d of %s after %d rejected updates\n",
+		start.Line+1, end.Line, config.TranscriptPath, rejections)
+	return u.advanceCursor(cursors, config.TranscriptPath, end)
+}
+
+// logValidationIssues reports each issue; stderr is captured in the job log
+func logValidationIssues(outputFile string, issues []ValidationIssue) {
+	for _, issue := range issues {
+		fmt.Fprintf(os.Stderr, "%s validation: %s\n", outputFile, issue)
+	}
+}
+
+// advanceCursor records the position reached in the transcript
+func (u *Updater) advanceCursor(cursors *cursor.Store, transcriptPath string, pos transcript.Position) error {
+	if err := cursors.Advance(transcriptPath, pos.Offset, pos.Line); err != nil {
+		return fmt.Errorf("failed to update transcript cursor: %w", err)
+	}
 	return nil
 }
 
@@ -185,47 +315,31 @@ func (u *Updater) validateConfig(config UpdaterConfig) error {
 	if config.Model == "" {
 		return fmt.Errorf("Model is required")
 	}
-	if config.StartLine < 1 {
-		return fmt.Errorf("StartLine must be >= 1")
+	if config.StartLine < 0 {
+		return fmt.Errorf("StartLine must be >= 0")
+	}
+	if config.MaxEntries < 0 {
+		return fmt.Errorf("MaxEntries must be >= 0")
 	}
 	return nil
 }
 
-// invokeClaude calls the claude CLI with the given prompt
-// Sets CLAUDE_HOOK_INTERNAL=1 to prevent recursion
-func (u *Updater) invokeClaude(prompt string, model string) error {
-	// Set recursion guard in environment
-	originalValue := u.env.Get("CLAUDE_HOOK_INTERNAL")
-	u.env.Set("CLAUDE_HOOK_INTERNAL", "1")
-	defer func() {
-		if originalValue == "" {
-			// Restore by setting to empty (best effort, depends on env implementation)
-			u.env.Set("CLAUDE_HOOK_INTERNAL", "")
-		} else {
-			u.env.Set("CLAUDE_HOOK_INTERNAL", originalValue)
-		}
-	}()
-
-	// Create command with recursion guard via actual exec.Command
-	// We need to use exec.Command directly here to set custom environment
-	// Note: We don't use --output-format stream-json as it requires --verbose with -p
-	cmd := exec.Command("claude", "-p", prompt, "--model", model)
-
-	// Set environment with recursion guard
-	cmdEnv := os.Environ()
-	cmdEnv = append(cmdEnv, "CLAUDE_HOOK_INTERNAL=1")
-	cmd.Env = cmdEnv
-
-	// Capture output for potential logging
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+// invokeLLM sends the prompt to the configured backend and returns the document content.
+// An empty response is an error so the existing document is never clobbered.
+func (u *Updater) invokeLLM(prompt string, model string) (string, error) {
+	if u.llm == nil {
+		return "", fmt.Errorf("no LLM client configured")
+	}
 
-	// Execute command
-	err := cmd.Run()
+	response, err := u.llm.Complete(llm.Request{Prompt: prompt, Model: model})
 	if err != nil {
-		return fmt.Errorf("claude command failed: %w (stderr: %s)", err, stderr.String())
+		return "", err
 	}
 
-	return nil
+	content := llm.StripCodeFence(response)
+	if content == "" {
+		return "", fmt.Errorf("model returned an empty response")
+	}
+
+	return content, nil
 }
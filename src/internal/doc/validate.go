@@ -0,0 +1,180 @@
+package doc
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"claudex/internal/services/config"
+
+	"github.com/spf13/afero"
+)
+
+// Validation issue kinds
+const (
+	IssueMissingSection = "missing_section"
+	IssueBrokenLink     = "broken_link"
+	IssueOutsideLink    = "outside_link"
+)
+
+// ValidationRules describes the structure a generated session document must keep
+type ValidationRules struct {
+	RequiredSections []string `json:"required_sections"` // Section names that must appear as a heading or a **Name**: field
+	CheckLinks       bool     `json:"check_links"`       // Links must point to existing files inside the session folder
+}
+
+// ValidationRulesFor converts the [autodoc.validation] settings, returning nil when validation is disabled
+func ValidationRulesFor(cfg config.OverviewValidation) *ValidationRules {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &ValidationRules{
+		RequiredSections: cfg.RequiredSections,
+		CheckLinks:       cfg.CheckLinks,
+	}
+}
+
+// ValidationIssue is one structural problem found in a session document
+type ValidationIssue struct {
+	Kind   string `json:"kind"`           // IssueMissingSection, IssueBrokenLink or IssueOutsideLink
+	Target string `json:"target"`         // Section name or link target
+	Line   int    `json:"line,omitempty"` // 1-indexed line of a broken or outside link
+}
+
+// String formats the issue for logs and lint output
+func (i ValidationIssue) String() string {
+	switch i.Kind {
+	case IssueMissingSection:
+		return fmt.Sprintf("missing section %q", i.Target)
+	case IssueBrokenLink:
+		return fmt.Sprintf("line %d: link target %q does not exist", i.Line, i.Target)
+	case IssueOutsideLink:
+		return fmt.Sprintf("line %d: link target %q is outside the session folder", i.Line, i.Target)
+	default:
+		return fmt.Sprintf("%s: %s", i.Kind, i.Target)
+	}
+}
+
+var (
+	headingPattern = regexp.MustCompile(`^#{1,6}\s+(.+?)\s*#*\s*$`)
+	fieldPattern   = regexp.MustCompile(`^\s*(?:[-*]\s+)?\*\*([^*]+)\*\*\s*:`)
+	linkPattern    = regexp.MustCompile(`!?\[[^\]]*\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)`)
+	schemePattern  = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+)
+
+// ValidateDocument checks a session document against the rules.
+// A required section is present when a heading contains its name (case-insensitive),
+// e.g. "Documents" matches "## Key Documents", or when a **Name**: field line names it,
+// e.g. "**Status**: In progress". Links inside code fences, URLs and anchors are not checked.
+func ValidateDocument(fs afero.Fs, sessionPath, content string, rules ValidationRules) []ValidationIssue {
+	var titles []string
+	var broken []ValidationIssue
+
+	inFence := false
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		if m := headingPattern.FindStringSubmatch(line); m != nil {
+			titles = append(titles, strings.ToLower(m[1]))
+		} else if m := fieldPattern.FindStringSubmatch(line); m != nil {
+			titles = append(titles, strings.ToLower(m[1]))
+		}
+
+		if rules.CheckLinks {
+			for _, m := range linkPattern.FindAllStringSubmatch(line, -1) {
+				target, ok := localLinkTarget(m[1])
+				if !ok {
+					continue
+				}
+				resolved, inside := resolveLink(sessionPath, target)
+				switch {
+				case !inside:
+					broken = append(broken, ValidationIssue{Kind: IssueOutsideLink, Target: m[1], Line: i + 1})
+				case !linkExists(fs, resolved):
+					broken = append(broken, ValidationIssue{Kind: IssueBrokenLink, Target: m[1], Line: i + 1})
+				}
+			}
+		}
+	}
+
+	issues := []ValidationIssue{}
+	for _, section := range rules.RequiredSections {
+		name := strings.ToLower(strings.TrimSpace(section))
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, title := range titles {
+			if strings.Contains(title, name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			issues = append(issues, ValidationIssue{Kind: IssueMissingSection, Target: section})
+		}
+	}
+
+	return append(issues, broken...)
+}
+
+// NewIssues returns the issues in after that were not already present in before.
+// Used so an update is only rejected for problems it introduced.
+func NewIssues(before, after []ValidationIssue) []ValidationIssue {
+	seen := map[string]bool{}
+	for _, issue := range before {
+		seen[issue.Kind+"\x00"+issue.Target] = true
+	}
+
+	introduced := []ValidationIssue{}
+	for _, issue := range after {
+		if !seen[issue.Kind+"\x00"+issue.Target] {
+			introduced = append(introduced, issue)
+		}
+	}
+	return introduced
+}
+
+// localLinkTarget returns the file path of a link, or false for URLs and in-page anchors
+func localLinkTarget(target string) (string, bool) {
+	if strings.HasPrefix(target, "#") || schemePattern.MatchString(target) {
+		return "", false
+	}
+	if i := strings.IndexAny(target, "#?"); i >= 0 {
+		target = target[:i]
+	}
+	if unescaped, err := url.PathUnescape(target); err == nil {
+		target = unescaped
+	}
+	return target, target != ""
+}
+
+// resolveLink returns the cleaned path of a link target, resolving relative targets from
+// the session folder, and whether it lies inside the session folder
+func resolveLink(sessionPath, target string) (string, bool) {
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(sessionPath, target)
+	}
+	target = filepath.Clean(target)
+	rel, err := filepath.Rel(filepath.Clean(sessionPath), target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return target, false
+	}
+	return target, true
+}
+
+// linkExists reports whether a resolved link target exists
+func linkExists(fs afero.Fs, path string) bool {
+	_, err := fs.Stat(path)
+	return err == nil || !os.IsNotExist(err)
+}
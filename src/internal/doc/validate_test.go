@@ -0,0 +1,101 @@
+package doc
+
+import (
+	"testing"
+
+	"claudex/internal/services/config"
+	"claudex/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateDocument_RequiredSections(t *testing.T) {
+	h := testutil.NewTestHarness()
+	content := `# Session Overview
+
+**Status**: In progress
+
+## Key Documents
+
+## Progress Timeline
+
+- **2024-01-15** - Session created
+`
+	rules := ValidationRules{RequiredSections: []string{"Status", "Key Decisions", "Documents", "progress timeline"}}
+
+	issues := ValidateDocument(h.FS, "/session", content, rules)
+
+	require.Len(t, issues, 1)
+	assert.Equal(t, ValidationIssue{Kind: IssueMissingSection, Target: "Key Decisions"}, issues[0])
+	assert.Equal(t, `missing section "Key Decisions"`, issues[0].String())
+}
+
+func TestValidateDocument_Links(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.WriteFile("/session/research-auth.md", "notes")
+	h.WriteFile("/session/diagrams/flow.png", "png")
+	content := "# Overview\n" +
+		"- [Research](research-auth.md#findings)\n" +
+		"- ![Flow](diagrams/flow.png \"flow\")\n" +
+		"- [Plan](execution-plan.md)\n" +
+		"- [Docs](https://example.com/missing.md) and [top](#overview) and [mail](mailto:a@b.c)\n" +
+		"```\n[Example](not-checked.md)\n```\n"
+
+	issues := ValidateDocument(h.FS, "/session", content, ValidationRules{CheckLinks: true})
+
+	require.Len(t, issues, 1)
+	assert.Equal(t, ValidationIssue{Kind: IssueBrokenLink, Target: "execution-plan.md", Line: 4}, issues[0])
+}
+
+func TestValidateDocument_LinksOutsideSessionFolder(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.WriteFile("/session/notes.md", "notes")
+	h.WriteFile("/etc/passwd", "root")
+	h.WriteFile("/project/README.md", "readme")
+	content := "# Overview\n" +
+		"- [Notes](/session/notes.md) and [Again](../session/notes.md)\n" +
+		"- [Passwd](/etc/passwd)\n" +
+		"- [Readme](../project/README.md)\n" +
+		"- [Sneaky](notes/../../etc/passwd)\n"
+
+	issues := ValidateDocument(h.FS, "/session", content, ValidationRules{CheckLinks: true})
+
+	assert.Equal(t, []ValidationIssue{
+		{Kind: IssueOutsideLink, Target: "/etc/passwd", Line: 3},
+		{Kind: IssueOutsideLink, Target: "../project/README.md", Line: 4},
+		{Kind: IssueOutsideLink, Target: "notes/../../etc/passwd", Line: 5},
+	}, issues)
+	assert.Equal(t, `line 3: link target "/etc/passwd" is outside the session folder`, issues[0].String())
+}
+
+func TestValidateDocument_LinksNotCheckedWhenDisabled(t *testing.T) {
+	h := testutil.NewTestHarness()
+
+	issues := ValidateDocument(h.FS, "/session", "[Plan](missing.md)\n", ValidationRules{})
+
+	assert.Empty(t, issues)
+}
+
+func TestNewIssues(t *testing.T) {
+	before := []ValidationIssue{
+		{Kind: IssueMissingSection, Target: "Key Decisions"},
+		{Kind: IssueBrokenLink, Target: "old.md", Line: 3},
+	}
+	after := []ValidationIssue{
+		{Kind: IssueMissingSection, Target: "Key Decisions"},
+		{Kind: IssueMissingSection, Target: "Status"},
+		{Kind: IssueBrokenLink, Target: "old.md", Line: 8},
+	}
+
+	assert.Equal(t, []ValidationIssue{{Kind: IssueMissingSection, Target: "Status"}}, NewIssues(before, after))
+}
+
+func TestValidationRulesFor(t *testing.T) {
+	assert.Nil(t, ValidationRulesFor(config.OverviewValidation{Enabled: false, CheckLinks: true}))
+
+	rules := ValidationRulesFor(config.DefaultOverviewValidation())
+	require.NotNil(t, rules)
+	assert.Equal(t, []string{"Status", "Key Decisions", "Documents", "Progress Timeline"}, rules.RequiredSections)
+	assert.True(t, rules.CheckLinks)
+}
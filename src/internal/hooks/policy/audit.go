@@ -0,0 +1,75 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// AuditFile is the session file every policy decision is appended to, one JSON object per line
+const AuditFile = "policy-audit.jsonl"
+
+// maxSummary bounds the length of the call summary kept in the audit log
+const maxSummary = 500
+
+// AuditEntry records one policy decision
+type AuditEntry struct {
+	Time      time.Time `json:"time"`
+	SessionID string    `json:"session_id,omitempty"`
+	ToolUseID string    `json:"tool_use_id,omitempty"`
+	Tool      string    `json:"tool"`
+	Summary   string    `json:"summary,omitempty"` // Bash command, file path or subagent type
+	Decision  Decision  `json:"decision"`
+	Rule      string    `json:"rule,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// NewAuditEntry describes a call and its result for the audit log
+func NewAuditEntry(now time.Time, call Call, result Result) AuditEntry {
+	return AuditEntry{
+		Time:     now.UTC(),
+		Tool:     call.Tool,
+		Summary:  Summarize(call),
+		Decision: result.Decision,
+		Rule:     result.Rule,
+		Reason:   result.Reason,
+	}
+}
+
+// Summarize returns the part of a call's input that identifies what it does
+func Summarize(call Call) string {
+	var summary string
+	for _, key := range []string{"command", "file_path", "notebook_path", "path", "subagent_type", "url", "pattern"} {
+		if value, ok := call.Input[key].(string); ok && value != "" {
+			summary = value
+			break
+		}
+	}
+	if len(summary) > maxSummary {
+		summary = summary[:maxSummary] + "..."
+	}
+	return summary
+}
+
+// AppendAudit appends an entry to the audit log in the session folder
+func AppendAudit(fs afero.Fs, sessionPath string, entry AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	file, err := fs.OpenFile(filepath.Join(sessionPath, AuditFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,373 @@
+// Package policy decides whether a tool call may run. Rules from the project's
+// .claudex/config.toml ([policy] and [[policy.rules]]) plus the built-in rules match on
+// tool name, Bash command, file paths and subagent type; each returns allow, ask or deny.
+// The PreToolUse hook evaluates every call and records the decision in the session's
+// audit log.
+package policy
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"claudex/internal/services/config"
+	"claudex/internal/services/glob"
+)
+
+// Decision is the outcome of evaluating a tool call
+type Decision string
+
+const (
+	Allow Decision = "allow"
+	Ask   Decision = "ask"
+	Deny  Decision = "deny"
+)
+
+// rank orders decisions by restrictiveness
+func (d Decision) rank() int {
+	switch d {
+	case Deny:
+		return 2
+	case Ask:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// parseDecision validates a configured decision
+func parseDecision(s string) (Decision, error) {
+	switch d := Decision(strings.ToLower(strings.TrimSpace(s))); d {
+	case Allow, Ask, Deny:
+		return d, nil
+	default:
+		return "", fmt.Errorf("invalid decision %q (want allow, ask or deny)", s)
+	}
+}
+
+// Call is a tool invocation as seen by the PreToolUse hook
+type Call struct {
+	Tool  string
+	Input map[string]interface{}
+	CWD   string // resolves relative paths; the project root when empty
+}
+
+// Result is the decision for a call and the rule that produced it.
+// Rule is empty when no rule matched and the default applied.
+type Result struct {
+	Decision Decision
+	Rule     string
+	Reason   string
+}
+
+// String formats the result for hook logs
+func (r Result) String() string {
+	if r.Rule == "" {
+		return fmt.Sprintf("Policy: %s (default)", r.Decision)
+	}
+	return fmt.Sprintf("Policy: %s by rule %s (%s)", r.Decision, r.Rule, r.Reason)
+}
+
+// Strictest returns the most restrictive result; the first one wins ties
+func Strictest(results ...Result) Result {
+	strictest := Result{Decision: Allow}
+	for i, r := range results {
+		if i == 0 || r.Decision.rank() > strictest.Decision.rank() {
+			strictest = r
+		}
+	}
+	return strictest
+}
+
+// rule is a compiled config.PolicyRule
+type rule struct {
+	name          string
+	tools         []string
+	commands      []*regexp.Regexp
+	paths         *glob.Matcher
+	outside       bool
+	subagentTypes []string
+	decision      Decision
+	reason        string
+}
+
+// Engine evaluates tool calls against compiled rules
+type Engine struct {
+	rules       []rule
+	fallback    Decision
+	projectRoot string
+	home        string
+}
+
+// Compile builds an Engine from a policy. Paths are matched relative to projectRoot;
+// home expands "~" and $HOME in Bash arguments.
+func Compile(p config.Policy, projectRoot, home string) (*Engine, error) {
+	fallback := Allow
+	if p.Default != "" {
+		d, err := parseDecision(p.Default)
+		if err != nil {
+			return nil, fmt.Errorf("invalid policy default: %w", err)
+		}
+		fallback = d
+	}
+
+	e := &Engine{fallback: fallback, projectRoot: filepath.Clean(projectRoot), home: home}
+	var rules []config.PolicyRule
+	if p.BuiltinRules {
+		rules = append(rules, config.DefaultPolicyRules()...)
+	}
+	rules = append(rules, p.Rules...)
+	for i, r := range rules {
+		compiled, err := compileRule(r)
+		if err != nil {
+			name := r.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i+1)
+			}
+			return nil, fmt.Errorf("invalid policy rule %s: %w", name, err)
+		}
+		if compiled.name == "" {
+			compiled.name = fmt.Sprintf("rule-%d", i+1)
+		}
+		e.rules = append(e.rules, compiled)
+	}
+	return e, nil
+}
+
+// compileRule validates a rule and compiles its matchers
+func compileRule(r config.PolicyRule) (rule, error) {
+	if len(r.Tools) == 0 && len(r.Commands) == 0 && len(r.Paths) == 0 && !r.OutsideProject && len(r.SubagentTypes) == 0 {
+		return rule{}, fmt.Errorf("no matchers set")
+	}
+	decision, err := parseDecision(r.Decision)
+	if err != nil {
+		return rule{}, err
+	}
+
+	compiled := rule{
+		name:          r.Name,
+		tools:         r.Tools,
+		outside:       r.OutsideProject,
+		subagentTypes: r.SubagentTypes,
+		decision:      decision,
+		reason:        r.Reason,
+	}
+	for _, tool := range r.Tools {
+		if _, err := path.Match(tool, ""); err != nil {
+			return rule{}, fmt.Errorf("invalid tool pattern %q: %w", tool, err)
+		}
+	}
+	for _, command := range r.Commands {
+		re, err := regexp.Compile(command)
+		if err != nil {
+			return rule{}, fmt.Errorf("invalid command pattern %q: %w", command, err)
+		}
+		compiled.commands = append(compiled.commands, re)
+	}
+	if len(r.Paths) > 0 {
+		compiled.paths, err = glob.New(r.Paths)
+		if err != nil {
+			return rule{}, err
+		}
+	}
+	if compiled.reason == "" {
+		compiled.reason = fmt.Sprintf("%s by policy rule %s", decision, compiled.name)
+	}
+	return compiled, nil
+}
+
+// Evaluate returns the most restrictive decision of the rules matching the call;
+// among rules with that decision the first one gives the reason. Calls no rule
+// matches get the policy default.
+func (e *Engine) Evaluate(call Call) Result {
+	cwd := call.CWD
+	if cwd == "" {
+		cwd = e.projectRoot
+	}
+	targets := e.targets(call)
+	subagentType, _ := call.Input["subagent_type"].(string)
+
+	var result *Result
+	for _, r := range e.rules {
+		if !r.matchesTool(call.Tool) || !r.matchesSubagent(subagentType) {
+			continue
+		}
+		matched := false
+		for _, t := range targets {
+			if e.matchesTarget(r, t, cwd) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		if result == nil || r.decision.rank() > result.Decision.rank() {
+			result = &Result{Decision: r.decision, Rule: r.name, Reason: r.reason}
+		}
+	}
+	if result == nil {
+		return Result{Decision: e.fallback}
+	}
+	return *result
+}
+
+// target is one unit a rule's command and path matchers are checked against together:
+// a single Bash command with its arguments, or the file paths of another tool
+type target struct {
+	command string
+	paths   []string
+}
+
+// targets splits a call into what its rules are matched against. Bash command lines
+// are split at &&, ||, ; and | so every matcher of a rule applies to the same command.
+func (e *Engine) targets(call Call) []target {
+	if command, ok := call.Input["command"].(string); ok && call.Tool == "Bash" {
+		var targets []target
+		for _, segment := range splitCommand(command) {
+			targets = append(targets, target{command: segment, paths: commandArgs(segment)})
+		}
+		if len(targets) == 0 {
+			targets = append(targets, target{})
+		}
+		return targets
+	}
+
+	var filePaths []string
+	for _, key := range []string{"file_path", "notebook_path", "path"} {
+		if p, ok := call.Input[key].(string); ok && p != "" {
+			filePaths = append(filePaths, p)
+		}
+	}
+	return []target{{paths: filePaths}}
+}
+
+// matchesTarget reports whether the rule's command, path and location matchers all match
+func (e *Engine) matchesTarget(r rule, t target, cwd string) bool {
+	if len(r.commands) > 0 {
+		matched := false
+		for _, re := range r.commands {
+			if t.command != "" && re.MatchString(t.command) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if r.paths != nil && !e.anyPath(t.paths, cwd, func(rel string, _ bool) bool { return r.paths.Match(rel) }) {
+		return false
+	}
+	if r.outside && !e.anyPath(t.paths, cwd, func(_ string, outside bool) bool { return outside }) {
+		return false
+	}
+	return true
+}
+
+// anyPath resolves each path against the project root and reports whether match accepts
+// one. Paths inside the project are passed relative to it, others as absolute paths.
+func (e *Engine) anyPath(paths []string, cwd string, match func(rel string, outside bool) bool) bool {
+	for _, p := range paths {
+		abs, ok := e.resolve(p, cwd)
+		if !ok {
+			// An unexpanded variable can point anywhere
+			if match(p, true) {
+				return true
+			}
+			continue
+		}
+		rel, err := filepath.Rel(e.projectRoot, abs)
+		outside := err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+		if outside {
+			rel = abs
+		}
+		if match(filepath.ToSlash(rel), outside) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve turns a path argument into a clean absolute path. It reports false for
+// paths starting with a variable other than $HOME, or with ~ when home is unknown.
+func (e *Engine) resolve(p, cwd string) (string, bool) {
+	switch {
+	case e.home == "" && (strings.HasPrefix(p, "~") || strings.HasPrefix(p, "$")):
+		return "", false
+	case p == "~" || strings.HasPrefix(p, "~/"):
+		p = e.home + p[1:]
+	case strings.HasPrefix(p, "$HOME"):
+		p = e.home + strings.TrimPrefix(p, "$HOME")
+	case strings.HasPrefix(p, "${HOME}"):
+		p = e.home + strings.TrimPrefix(p, "${HOME}")
+	case strings.HasPrefix(p, "$"):
+		return "", false
+	}
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(cwd, p)
+	}
+	return filepath.Clean(p), true
+}
+
+// matchesTool reports whether the tool name matches one of the rule's globs
+func (r rule) matchesTool(tool string) bool {
+	if len(r.tools) == 0 {
+		return true
+	}
+	for _, pattern := range r.tools {
+		if ok, _ := path.Match(pattern, tool); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesSubagent reports whether the Task subagent type is one of the rule's types
+func (r rule) matchesSubagent(subagentType string) bool {
+	if len(r.subagentTypes) == 0 {
+		return true
+	}
+	for _, t := range r.subagentTypes {
+		if strings.EqualFold(t, subagentType) {
+			return true
+		}
+	}
+	return false
+}
+
+// commandSeparators split a Bash command line into single commands
+var commandSeparators = regexp.MustCompile(`&&|\|\||[;|\n]`)
+
+// splitCommand splits a command line into its commands. Quoting is not parsed, so a
+// separator inside quotes splits too; rules see smaller commands, never larger ones.
+func splitCommand(command string) []string {
+	var segments []string
+	for _, segment := range commandSeparators.Split(command, -1) {
+		if segment = strings.TrimSpace(segment); segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+// commandArgs returns the arguments of a command that may be paths: words after the
+// command name that aren't flags, with quotes and redirections stripped
+func commandArgs(command string) []string {
+	fields := strings.Fields(command)
+	var args []string
+	for i, field := range fields {
+		if j := strings.IndexAny(field, "<>"); j >= 0 && strings.Trim(field[:j], "0123456789&") == "" {
+			// Redirection such as >file, 2>>file or &>file
+			field = strings.TrimLeft(field[j:], "<>&")
+		}
+		field = strings.Trim(field, `"'`)
+		if i == 0 || field == "" || strings.HasPrefix(field, "-") {
+			continue
+		}
+		args = append(args, field)
+	}
+	return args
+}
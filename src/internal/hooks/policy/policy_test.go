@@ -0,0 +1,180 @@
+package policy
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"claudex/internal/services/config"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const projectRoot = "/home/dev/project"
+
+// newEngine compiles the default policy plus extra rules
+func newEngine(t *testing.T, rules ...config.PolicyRule) *Engine {
+	t.Helper()
+	p := config.DefaultPolicy()
+	p.Rules = rules
+	engine, err := Compile(p, projectRoot, "/home/dev")
+	require.NoError(t, err)
+	return engine
+}
+
+// bash builds a Bash call run from the project root
+func bash(command string) Call {
+	return Call{Tool: "Bash", Input: map[string]interface{}{"command": command}, CWD: projectRoot}
+}
+
+// Test_Evaluate_BuiltinRules verifies the built-in rules block force pushes, recursive
+// deletes outside the project and .env edits, and nothing else
+func Test_Evaluate_BuiltinRules(t *testing.T) {
+	engine := newEngine(t)
+
+	tests := []struct {
+		name string
+		call Call
+		want Decision
+		rule string
+	}{
+		{"force push", bash("git push --force origin main"), Deny, "no-force-push"},
+		{"short force flag", bash("git add . && git push -f"), Deny, "no-force-push"},
+		{"plus refspec", bash("git push origin +main"), Deny, "no-force-push"},
+		{"force with lease", bash("git push --force-with-lease"), Allow, ""},
+		{"plain push", bash("git push -u origin feature-branch"), Allow, ""},
+		{"rm -rf absolute outside", bash("rm -rf /var/lib/data"), Deny, "no-rm-rf-outside-project"},
+		{"rm -rf home", bash("rm -rf ~/"), Deny, "no-rm-rf-outside-project"},
+		{"rm -rf parent", bash("rm -fr ../other"), Deny, "no-rm-rf-outside-project"},
+		{"rm -rf variable", bash(`rm -rf "$TARGET"`), Deny, "no-rm-rf-outside-project"},
+		{"rm -rf inside", bash("rm -rf build node_modules"), Allow, ""},
+		{"rm -rf absolute inside", bash("rm -rf /home/dev/project/dist"), Allow, ""},
+		{"cd elsewhere then rm inside", bash("cd /tmp && rm -rf build"), Allow, ""},
+		{"non-recursive rm outside", bash("rm /tmp/file.txt"), Allow, ""},
+		{"edit .env", Call{Tool: "Edit", Input: map[string]interface{}{"file_path": "/home/dev/project/.env"}}, Deny, "no-env-edits"},
+		{"write nested .env.local", Call{Tool: "Write", Input: map[string]interface{}{"file_path": "api/.env.local"}, CWD: projectRoot}, Deny, "no-env-edits"},
+		{"edit .env.example", Call{Tool: "Edit", Input: map[string]interface{}{"file_path": "/home/dev/project/.env.example"}}, Allow, ""},
+		{"read .env", Call{Tool: "Read", Input: map[string]interface{}{"file_path": "/home/dev/project/.env"}}, Allow, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := engine.Evaluate(tt.call)
+
+			assert.Equal(t, tt.want, result.Decision)
+			assert.Equal(t, tt.rule, result.Rule)
+		})
+	}
+}
+
+// Test_Evaluate_MostRestrictiveWins verifies deny beats ask beats allow regardless of order
+func Test_Evaluate_MostRestrictiveWins(t *testing.T) {
+	// Setup
+	engine := newEngine(t,
+		config.PolicyRule{Name: "allow-go", Tools: []string{"Edit"}, Paths: []string{"*.go"}, Decision: "allow"},
+		config.PolicyRule{Name: "ask-internal", Tools: []string{"Edit"}, Paths: []string{"internal/**"}, Decision: "ask", Reason: "internal code"},
+		config.PolicyRule{Name: "deny-generated", Paths: []string{"*_gen.go"}, Decision: "deny", Reason: "generated"},
+	)
+	edit := func(file string) Call {
+		return Call{Tool: "Edit", Input: map[string]interface{}{"file_path": file}, CWD: projectRoot}
+	}
+
+	// Exercise & Verify
+	assert.Equal(t, Result{Decision: Allow, Rule: "allow-go", Reason: "allow by policy rule allow-go"}, engine.Evaluate(edit("main.go")))
+	assert.Equal(t, Result{Decision: Ask, Rule: "ask-internal", Reason: "internal code"}, engine.Evaluate(edit("internal/app.go")))
+	assert.Equal(t, Result{Decision: Deny, Rule: "deny-generated", Reason: "generated"}, engine.Evaluate(edit("internal/api_gen.go")))
+}
+
+// Test_Evaluate_ToolGlobsAndSubagentTypes verifies tool globs and case-insensitive subagent types
+func Test_Evaluate_ToolGlobsAndSubagentTypes(t *testing.T) {
+	// Setup
+	engine := newEngine(t,
+		config.PolicyRule{Name: "no-github-mcp", Tools: []string{"mcp__github__*"}, Decision: "deny"},
+		config.PolicyRule{Name: "confirm-agents", Tools: []string{"Task"}, SubagentTypes: []string{"general-purpose"}, Decision: "ask"},
+	)
+
+	// Exercise
+	mcp := engine.Evaluate(Call{Tool: "mcp__github__create_pr", Input: map[string]interface{}{}})
+	agent := engine.Evaluate(Call{Tool: "Task", Input: map[string]interface{}{"subagent_type": "General-Purpose"}})
+	explore := engine.Evaluate(Call{Tool: "Task", Input: map[string]interface{}{"subagent_type": "Explore"}})
+
+	// Verify
+	assert.Equal(t, Deny, mcp.Decision)
+	assert.Equal(t, Ask, agent.Decision)
+	assert.Equal(t, Allow, explore.Decision)
+}
+
+// Test_Evaluate_DefaultAndBuiltinToggle verifies the default decision and disabling built-in rules
+func Test_Evaluate_DefaultAndBuiltinToggle(t *testing.T) {
+	// Setup
+	p := config.Policy{Enabled: true, Default: "ask", BuiltinRules: false, Rules: []config.PolicyRule{
+		{Name: "tests", Tools: []string{"Bash"}, Commands: []string{`^go test\b`}, Decision: "allow"},
+	}}
+	engine, err := Compile(p, projectRoot, "/home/dev")
+	require.NoError(t, err)
+
+	// Exercise & Verify
+	assert.Equal(t, Result{Decision: Ask}, engine.Evaluate(bash("git push --force")))
+	assert.Equal(t, Allow, engine.Evaluate(bash("go test ./...")).Decision)
+	assert.Equal(t, "Policy: ask (default)", engine.Evaluate(bash("ls")).String())
+}
+
+// Test_Compile_InvalidRules verifies invalid rules are reported with their name
+func Test_Compile_InvalidRules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule config.PolicyRule
+		want string
+	}{
+		{"bad decision", config.PolicyRule{Name: "r", Tools: []string{"Bash"}, Decision: "block"}, `invalid policy rule r: invalid decision "block"`},
+		{"bad regexp", config.PolicyRule{Name: "r", Commands: []string{"("}, Decision: "deny"}, "invalid command pattern"},
+		{"no matchers", config.PolicyRule{Decision: "deny"}, "invalid policy rule #1: no matchers set"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(config.Policy{Rules: []config.PolicyRule{tt.rule}}, projectRoot, "")
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+
+	_, err := Compile(config.Policy{Default: "maybe"}, projectRoot, "")
+	assert.ErrorContains(t, err, "invalid policy default")
+}
+
+// Test_Strictest verifies deny beats ask beats allow and the first result wins ties
+func Test_Strictest(t *testing.T) {
+	ask := Result{Decision: Ask, Rule: "first"}
+
+	assert.Equal(t, Result{Decision: Allow}, Strictest())
+	assert.Equal(t, ask, Strictest(Result{Decision: Allow}, ask, Result{Decision: Ask, Rule: "second"}))
+	assert.Equal(t, Deny, Strictest(ask, Result{Decision: Deny}).Decision)
+}
+
+// Test_AppendAudit_WritesJSONLines verifies decisions are appended one JSON object per line
+func Test_AppendAudit_WritesJSONLines(t *testing.T) {
+	// Setup
+	fs := afero.NewMemMapFs()
+	sessionPath := "/project/.claudex/sessions/s1"
+	require.NoError(t, fs.MkdirAll(sessionPath, 0755))
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	call := bash("git push --force")
+
+	// Exercise
+	require.NoError(t, AppendAudit(fs, sessionPath, NewAuditEntry(now, call, Result{Decision: Deny, Rule: "no-force-push", Reason: "history"})))
+	require.NoError(t, AppendAudit(fs, sessionPath, NewAuditEntry(now, bash("ls"), Result{Decision: Allow})))
+
+	// Verify
+	data, err := afero.ReadFile(fs, filepath.Join(sessionPath, AuditFile))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	var entry AuditEntry
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, AuditEntry{Time: now, Tool: "Bash", Summary: "git push --force", Decision: Deny, Rule: "no-force-push", Reason: "history"}, entry)
+	assert.JSONEq(t, `{"time":"2025-01-02T03:04:05Z","tool":"Bash","summary":"ls","decision":"allow"}`, lines[1])
+}
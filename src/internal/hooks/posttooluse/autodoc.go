@@ -7,6 +7,7 @@ import (
 
 	"claudex/internal/doc"
 	"claudex/internal/hooks/shared"
+	"claudex/internal/hooks/trigger"
 	"claudex/internal/services/env"
 	"claudex/internal/services/session"
 
@@ -15,25 +16,25 @@ import (
 
 // AutoDocHandler implements frequency-controlled documentation updates
 type AutoDocHandler struct {
-	fs        afero.Fs
-	env       env.Environment
-	updater   doc.DocumentationUpdater
-	logger    *shared.Logger
-	frequency int
+	fs       afero.Fs
+	env      env.Environment
+	updater  doc.DocumentationUpdater
+	logger   *shared.Logger
+	triggers *trigger.Resolver
 }
 
 // NewAutoDocHandler creates a new AutoDocHandler instance
-func NewAutoDocHandler(fs afero.Fs, env env.Environment, updater doc.DocumentationUpdater, logger *shared.Logger, frequency int) *AutoDocHandler {
+func NewAutoDocHandler(fs afero.Fs, env env.Environment, updater doc.DocumentationUpdater, logger *shared.Logger) *AutoDocHandler {
 	return &AutoDocHandler{
-		fs:        fs,
-		env:       env,
-		updater:   updater,
-		logger:    logger,
-		frequency: frequency,
+		fs:       fs,
+		env:      env,
+		updater:  updater,
+		logger:   logger,
+		triggers: trigger.New(fs, env),
 	}
 }
 
-// Handle checks counter and triggers doc update if threshold reached
+// Handle checks the progress trigger policy and triggers doc update if threshold reached
 func (h *AutoDocHandler) Handle(input *shared.PostToolUseInput) (*shared.HookOutput, error) {
 	// Skip processing for internal Claude invocations (e.g., from doc-update subprocess)
 	// Only the main user session should trigger documentation updates
@@ -49,39 +50,25 @@ func (h *AutoDocHandler) Handle(input *shared.PostToolUseInput) (*shared.HookOut
 		return h.allowOutput(), nil
 	}
 
-	// Increment counter
-	newCount, err := session.IncrementCounter(h.fs, sessionPath)
+	// Find project root to load trigger policy and build absolute template path
+	projectRoot, err := trigger.FindProjectRoot(h.fs, sessionPath)
 	if err != nil {
-		_ = h.logger.LogError(fmt.Errorf("failed to increment counter: %w", err))
-		return h.allowOutput(), nil
-	}
-
-	_ = h.logger.LogInfo(fmt.Sprintf("Auto-doc counter: %d/%d", newCount, h.frequency))
-
-	// Check if we've reached the threshold
-	if newCount < h.frequency {
+		_ = h.logger.LogError(fmt.Errorf("failed to find project root: %w", err))
 		return h.allowOutput(), nil
 	}
 
-	// Reset counter
-	if err := session.ResetCounter(h.fs, sessionPath); err != nil {
-		_ = h.logger.LogError(fmt.Errorf("failed to reset counter: %w", err))
-		// Continue anyway - better to update docs than to fail
-	}
-
-	_ = h.logger.LogInfo("Auto-doc threshold reached, triggering documentation update")
-
-	// Read last processed line for incremental updates
-	startLine, err := session.ReadLastProcessedLine(h.fs, sessionPath)
+	policy, err := h.triggers.Resolve(trigger.Progress, projectRoot)
 	if err != nil {
-		_ = h.logger.LogError(fmt.Errorf("failed to read last processed line: %w", err))
-		startLine = 0 // Start from beginning if we can't read the marker
+		_ = h.logger.LogError(fmt.Errorf("failed to resolve trigger policy, using defaults: %w", err))
 	}
 
-	// Find project root to build absolute template path
-	projectRoot, err := h.findProjectRoot(sessionPath)
+	// Count this tool execution and check if we've reached the threshold
+	decision, err := h.triggers.Evaluate(policy, sessionPath)
 	if err != nil {
-		_ = h.logger.LogError(fmt.Errorf("failed to find project root: %w", err))
+		_ = h.logger.LogError(err)
+	}
+	_ = h.logger.LogInfo(decision.String())
+	if !decision.Fire {
 		return h.allowOutput(), nil
 	}
 
@@ -99,11 +86,14 @@ func (h *AutoDocHandler) Handle(input *shared.PostToolUseInput) (*shared.HookOut
 	config := doc.UpdaterConfig{
 		SessionPath:    sessionPath,
 		TranscriptPath: input.TranscriptPath,
-		OutputFile:     "session-overview.md",
+		OutputFile:     policy.OutputFile,
 		PromptTemplate: templatePath,
 		SessionContext: sessionContext,
-		Model:          "haiku",
-		StartLine:      startLine + 1, // Start from next line (1-indexed)
+		Model:          policy.Model,
+		Trigger:        string(policy.Kind),
+		HistoryLimit:   policy.HistoryLimit,
+		Validation:     doc.ValidationRulesFor(policy.Validation),
+		Redaction:      doc.RedactionRulesFor(policy.Redaction),
 	}
 
 	if err := h.updater.RunBackground(config); err != nil {
@@ -124,25 +114,6 @@ func (h *AutoDocHandler) allowOutput() *shared.HookOutput {
 	}
 }
 
-// findProjectRoot walks up from sessionPath to find the project root (where .claude directory exists)
-func (h *AutoDocHandler) findProjectRoot(sessionPath string) (string, error) {
-	current := sessionPath
-	for {
-		claudeDir := filepath.Join(current, ".claude")
-		exists, err := afero.DirExists(h.fs, claudeDir)
-		if err == nil && exists {
-			return current, nil
-		}
-
-		parent := filepath.Dir(current)
-		if parent == current {
-			// Reached filesystem root
-			return "", fmt.Errorf("could not find .claude directory in any parent of %s", sessionPath)
-		}
-		current = parent
-	}
-}
-
 // readSessionContext reads existing markdown files from session folder and builds context string
 func (h *AutoDocHandler) readSessionContext(sessionPath string) (string, error) {
 	files, err := afero.ReadDir(h.fs, sessionPath)
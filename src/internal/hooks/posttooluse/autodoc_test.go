@@ -9,6 +9,7 @@ import (
 	"claudex/internal/hooks/shared"
 	"claudex/internal/testutil"
 
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -59,7 +60,7 @@ func TestAutoDocHandler_UsesAbsoluteTemplatePath(t *testing.T) {
 	h.Env.Set("HOME", "/Users/test")
 
 	// Create handler with frequency=5
-	handler := NewAutoDocHandler(h.FS, h.Env, mockUpdater, logger, 5)
+	handler := NewAutoDocHandler(h.FS, h.Env, mockUpdater, logger)
 
 	// Create input
 	input := &shared.PostToolUseInput{
@@ -118,7 +119,7 @@ func TestAutoDocHandler_PopulatesSessionContext(t *testing.T) {
 	h.Env.Set("HOME", "/Users/test")
 
 	// Create handler with frequency=5
-	handler := NewAutoDocHandler(h.FS, h.Env, mockUpdater, logger, 5)
+	handler := NewAutoDocHandler(h.FS, h.Env, mockUpdater, logger)
 
 	// Create input
 	input := &shared.PostToolUseInput{
@@ -153,3 +154,44 @@ func TestAutoDocHandler_PopulatesSessionContext(t *testing.T) {
 			"Expected SessionContext to mention existing markdown files")
 	}
 }
+
+// TestAutoDocHandler_DisabledByConfig tests that autodoc_session_progress = false stops doc updates
+func TestAutoDocHandler_DisabledByConfig(t *testing.T) {
+	h := testutil.NewTestHarness()
+	mockUpdater := &MockUpdater{}
+	logger := shared.NewLogger(h.FS, h.Env, "autodoc-test")
+
+	// Counter is at threshold - the update would fire if the trigger were enabled
+	sessionPath := "/Users/test/.claudex/sessions/test-session-off"
+	h.CreateSessionWithFiles(sessionPath, map[string]string{
+		".doc-update-counter": "4",
+	})
+	h.CreateDir("/Users/test/.claude/hooks/prompts")
+	h.WriteFile("/Users/test/.claudex/config.toml", "[features]\nautodoc_session_progress = false\n")
+
+	h.Env.Set("CLAUDEX_SESSION_PATH", sessionPath)
+	h.Env.Set("CLAUDEX_LOG_FILE", "/Users/test/.claudex/logs/test.log")
+
+	handler := NewAutoDocHandler(h.FS, h.Env, mockUpdater, logger)
+
+	input := &shared.PostToolUseInput{
+		HookInput: shared.HookInput{
+			SessionID:      "test-session-off",
+			TranscriptPath: "/tmp/transcript3.jsonl",
+			CWD:            sessionPath,
+		},
+		ToolName: "Write",
+		Status:   "success",
+	}
+
+	output, err := handler.Handle(input)
+	require.NoError(t, err)
+	assert.Equal(t, "allow", output.HookSpecificOutput.PermissionDecision)
+
+	// Verify updater was not called and the decision was logged
+	assert.Nil(t, mockUpdater.capturedConfig, "Expected updater not to be called when trigger is disabled")
+
+	logContent, err := afero.ReadFile(h.FS, "/Users/test/.claudex/logs/test.log")
+	require.NoError(t, err)
+	assert.Contains(t, string(logContent), "Auto-doc trigger progress: skip (disabled by config)")
+}
@@ -9,7 +9,12 @@ import (
 	"strings"
 
 	"claudex"
+	"claudex/internal/hooks/policy"
 	"claudex/internal/hooks/shared"
+	"claudex/internal/hooks/trigger"
+	"claudex/internal/services/clock"
+	"claudex/internal/services/config"
+	"claudex/internal/services/paths"
 	"claudex/internal/services/session"
 	"claudex/internal/services/stackdetect"
 
@@ -17,11 +22,12 @@ import (
 )
 
 // Handler processes PreToolUse hook events
-// It injects session context into Task tool invocations
+// It applies the project's tool policy and injects session context into Task tool invocations
 type Handler struct {
 	fs     afero.Fs
 	env    shared.Environment
 	logger *shared.Logger
+	clock  clock.Clock
 }
 
 // NewHandler creates a new Handler instance
@@ -30,37 +36,59 @@ func NewHandler(fs afero.Fs, env shared.Environment, logger *shared.Logger) *Han
 		fs:     fs,
 		env:    env,
 		logger: logger,
+		clock:  clock.New(),
 	}
 }
 
 // Handle processes PreToolUse events
+// Returns the policy decision with its reason when a rule asks or denies
 // Returns updatedInput for Task tools with session context injected
-// Returns allow with no modification for non-Task tools
+// Returns allow for other tools only when a policy rule allows them; otherwise no
+// decision, so Claude Code's own permission settings apply
 func (h *Handler) Handle(input *shared.PreToolUseInput) (*shared.HookOutput, error) {
 	// Log the tool being invoked
 	if h.logger != nil {
 		_ = h.logger.Logf("Processing PreToolUse for tool: %s", input.ToolName)
 	}
 
+	// Find session folder
+	sessionPath, sessionErr := session.FindSessionFolder(h.fs, h.env, input.SessionID)
+
+	// Policy rules and the secret scan decide before any context is injected
+	result := h.checkTool(input, sessionPath)
+	if result.Decision != policy.Allow {
+		return &shared.HookOutput{
+			HookSpecificOutput: shared.HookSpecificOutput{
+				HookEventName:            "PreToolUse",
+				PermissionDecision:       string(result.Decision),
+				PermissionDecisionReason: fmt.Sprintf("claudex policy %s: %s", result.Rule, result.Reason),
+			},
+		}, nil
+	}
+
 	// Only modify Task tool invocations
 	if input.ToolName != "Task" {
 		if h.logger != nil {
 			_ = h.logger.Logf("Tool %s is not Task, passing through unchanged", input.ToolName)
 		}
+		// Every tool is routed through this hook: answering allow without a matching
+		// rule would skip the permission prompts of all tools
+		decision := ""
+		if result.Rule != "" {
+			decision = string(policy.Allow)
+		}
 		return &shared.HookOutput{
 			HookSpecificOutput: shared.HookSpecificOutput{
 				HookEventName:      "PreToolUse",
-				PermissionDecision: "allow",
+				PermissionDecision: decision,
 			},
 		}, nil
 	}
 
-	// Find session folder
-	sessionPath, err := session.FindSessionFolder(h.fs, h.env, input.SessionID)
-	if err != nil {
+	if sessionErr != nil {
 		// No session found - return allow without modification
 		if h.logger != nil {
-			_ = h.logger.Logf("No session folder found: %v", err)
+			_ = h.logger.Logf("No session folder found: %v", sessionErr)
 		}
 		return &shared.HookOutput{
 			HookSpecificOutput: shared.HookSpecificOutput{
@@ -185,6 +213,69 @@ func (h *Handler) Handle(input *shared.PreToolUseInput) (*shared.HookOutput, err
 	}, nil
 }
 
+// checkTool applies the project's tool policy and secret scan to a call and records
+// the stricter decision in the session's audit log
+func (h *Handler) checkTool(input *shared.PreToolUseInput, sessionPath string) policy.Result {
+	projectRoot := h.projectRoot(input, sessionPath)
+	cfg, err := config.Load(h.fs, filepath.Join(projectRoot, paths.ConfigFile))
+	if err != nil {
+		if h.logger != nil {
+			_ = h.logger.LogError(fmt.Errorf("failed to load config: %w", err))
+		}
+		cfg = &config.Config{Policy: config.DefaultPolicy(), Secrets: config.DefaultSecrets()}
+	}
+
+	call := policy.Call{Tool: input.ToolName, Input: input.ToolInput, CWD: input.CWD}
+	secretsResult, findings := h.checkSecrets(cfg.Secrets, call, projectRoot)
+	result := policy.Strictest(h.checkPolicy(cfg.Policy, call, projectRoot), secretsResult)
+	if h.logger != nil {
+		_ = h.logger.LogInfo(result.String())
+	}
+
+	if cfg.Policy.Audit && sessionPath != "" {
+		entry := policy.NewAuditEntry(h.clock.Now(), maskSecrets(call, findings), result)
+		entry.SessionID = input.SessionID
+		entry.ToolUseID = input.ToolUseID
+		if err := policy.AppendAudit(h.fs, sessionPath, entry); err != nil && h.logger != nil {
+			_ = h.logger.LogError(err)
+		}
+	}
+	return result
+}
+
+// checkPolicy evaluates the call against the [policy] rules. Invalid rules fall back to
+// the built-in ones; a disabled policy allows everything.
+func (h *Handler) checkPolicy(cfg config.Policy, call policy.Call, projectRoot string) policy.Result {
+	if !cfg.Enabled {
+		return policy.Result{Decision: policy.Allow}
+	}
+
+	engine, err := policy.Compile(cfg, projectRoot, h.env.Get("HOME"))
+	if err != nil {
+		if h.logger != nil {
+			_ = h.logger.LogError(fmt.Errorf("%w; using the built-in rules", err))
+		}
+		engine, _ = policy.Compile(config.DefaultPolicy(), projectRoot, h.env.Get("HOME"))
+	}
+	return engine.Evaluate(call)
+}
+
+// projectRoot returns the directory holding .claude above the session folder, else
+// above the tool call's working directory, else the working directory itself
+func (h *Handler) projectRoot(input *shared.PreToolUseInput, sessionPath string) string {
+	if sessionPath != "" {
+		if root, err := trigger.FindProjectRoot(h.fs, sessionPath); err == nil {
+			return root
+		}
+	}
+	if input.CWD != "" {
+		if root, err := trigger.FindProjectRoot(h.fs, input.CWD); err == nil {
+			return root
+		}
+	}
+	return input.CWD
+}
+
 // buildSessionContext creates the markdown context block
 func (h *Handler) buildSessionContext(sessionPath string, docPaths []string, projectRoot string) (string, error) {
 	var sb strings.Builder
@@ -267,10 +358,10 @@ func (h *Handler) listSessionFiles(sessionPath string) ([]string, error) {
 		return nil, fmt.Errorf("failed to read session directory: %w", err)
 	}
 
-	// Collect file names (exclude directories)
+	// Collect file names (exclude directories and the policy audit log)
 	var files []string
 	for _, entry := range entries {
-		if !entry.IsDir() {
+		if !entry.IsDir() && entry.Name() != policy.AuditFile {
 			files = append(files, entry.Name())
 		}
 	}
@@ -422,5 +513,13 @@ func (h *Handler) HandleFromBuilder(input *shared.PreToolUseInput, builder *shar
 	if err != nil {
 		return err
 	}
+
+	hso := output.HookSpecificOutput
+	switch hso.PermissionDecision {
+	case string(policy.Deny):
+		return builder.BuildDeny(hso.HookEventName, hso.PermissionDecisionReason)
+	case string(policy.Ask):
+		return builder.BuildAsk(hso.HookEventName, hso.PermissionDecisionReason)
+	}
 	return builder.BuildCustom(*output)
 }
@@ -34,7 +34,7 @@ func TestHandler_NonTaskTool(t *testing.T) {
 	// Assert
 	require.NoError(t, err)
 	assert.Equal(t, "PreToolUse", output.HookSpecificOutput.HookEventName)
-	assert.Equal(t, "allow", output.HookSpecificOutput.PermissionDecision)
+	assert.Empty(t, output.HookSpecificOutput.PermissionDecision, "no rule matched: Claude Code's permissions decide")
 	assert.Nil(t, output.HookSpecificOutput.UpdatedInput)
 }
 
@@ -1224,3 +1224,158 @@ func TestHandler_PlanAgent_NoSessionContext(t *testing.T) {
 	// Should contain Plan-specific context
 	assert.Contains(t, modifiedPrompt, "## PLAN AGENT ENHANCEMENTS")
 }
+
+func TestHandler_Policy_DeniesAndAudits(t *testing.T) {
+	// Arrange
+	fs := afero.NewMemMapFs()
+	env := shared.NewMockEnv()
+	sessionPath := "/workspace/.claudex/sessions/test-session-abc123"
+	require.NoError(t, fs.MkdirAll(sessionPath, 0755))
+	require.NoError(t, fs.MkdirAll("/workspace/.claude", 0755))
+	env.Set("CLAUDEX_SESSION_PATH", sessionPath)
+
+	logger := shared.NewLogger(fs, env, "test")
+	handler := NewHandler(fs, env, logger)
+
+	input := &shared.PreToolUseInput{
+		HookInput: shared.HookInput{SessionID: "abc123", CWD: "/workspace"},
+		ToolName:  "Bash",
+		ToolInput: map[string]interface{}{"command": "git push --force origin main"},
+		ToolUseID: "toolu_1",
+	}
+
+	// Act
+	output, err := handler.Handle(input)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "deny", output.HookSpecificOutput.PermissionDecision)
+	assert.Equal(t, "claudex policy no-force-push: force pushes rewrite shared history", output.HookSpecificOutput.PermissionDecisionReason)
+
+	audit, err := afero.ReadFile(fs, sessionPath+"/policy-audit.jsonl")
+	require.NoError(t, err)
+	assert.Contains(t, string(audit), `"tool_use_id":"toolu_1"`)
+	assert.Contains(t, string(audit), `"decision":"deny","rule":"no-force-push"`)
+}
+
+func TestHandler_Policy_ProjectRulesAsk(t *testing.T) {
+	// Arrange
+	fs := afero.NewMemMapFs()
+	env := shared.NewMockEnv()
+	sessionPath := "/workspace/.claudex/sessions/test-session-abc123"
+	require.NoError(t, fs.MkdirAll(sessionPath, 0755))
+	require.NoError(t, fs.MkdirAll("/workspace/.claude", 0755))
+	require.NoError(t, afero.WriteFile(fs, "/workspace/.claudex/config.toml", []byte(`[[policy.rules]]
+name = "confirm-migrations"
+tools = ["Write", "Edit"]
+paths = ["db/migrations/"]
+decision = "ask"
+reason = "migrations need review"
+`), 0644))
+	env.Set("CLAUDEX_SESSION_PATH", sessionPath)
+
+	handler := NewHandler(fs, env, shared.NewLogger(fs, env, "test"))
+
+	// Act
+	asked, err := handler.Handle(&shared.PreToolUseInput{
+		HookInput: shared.HookInput{SessionID: "abc123", CWD: "/workspace"},
+		ToolName:  "Write",
+		ToolInput: map[string]interface{}{"file_path": "/workspace/db/migrations/001_init.sql"},
+	})
+	require.NoError(t, err)
+	allowed, err := handler.Handle(&shared.PreToolUseInput{
+		HookInput: shared.HookInput{SessionID: "abc123", CWD: "/workspace"},
+		ToolName:  "Write",
+		ToolInput: map[string]interface{}{"file_path": "/workspace/db/schema.sql"},
+	})
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, "ask", asked.HookSpecificOutput.PermissionDecision)
+	assert.Equal(t, "claudex policy confirm-migrations: migrations need review", asked.HookSpecificOutput.PermissionDecisionReason)
+	assert.Empty(t, allowed.HookSpecificOutput.PermissionDecision, "no rule matched: Claude Code's permissions decide")
+
+	audit, err := afero.ReadFile(fs, sessionPath+"/policy-audit.jsonl")
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(audit), "\n"), "allowed calls are audited too")
+}
+
+func TestHandler_Policy_Disabled(t *testing.T) {
+	// Arrange
+	fs := afero.NewMemMapFs()
+	env := shared.NewMockEnv()
+	require.NoError(t, fs.MkdirAll("/workspace/.claude", 0755))
+	require.NoError(t, afero.WriteFile(fs, "/workspace/.claudex/config.toml", []byte("[policy]\nenabled = false\n"), 0644))
+
+	handler := NewHandler(fs, env, shared.NewLogger(fs, env, "test"))
+
+	// Act
+	output, err := handler.Handle(&shared.PreToolUseInput{
+		HookInput: shared.HookInput{SessionID: "abc123", CWD: "/workspace"},
+		ToolName:  "Edit",
+		ToolInput: map[string]interface{}{"file_path": "/workspace/.env"},
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, output.HookSpecificOutput.PermissionDecision)
+}
+
+func TestHandler_Policy_InvalidRulesFallBackToBuiltins(t *testing.T) {
+	// Arrange
+	fs := afero.NewMemMapFs()
+	env := shared.NewMockEnv()
+	require.NoError(t, fs.MkdirAll("/workspace/.claude", 0755))
+	require.NoError(t, afero.WriteFile(fs, "/workspace/.claudex/config.toml", []byte(`[policy]
+builtin_rules = false
+
+[[policy.rules]]
+commands = ["("]
+decision = "allow"
+`), 0644))
+
+	handler := NewHandler(fs, env, shared.NewLogger(fs, env, "test"))
+
+	// Act
+	output, err := handler.Handle(&shared.PreToolUseInput{
+		HookInput: shared.HookInput{SessionID: "abc123", CWD: "/workspace"},
+		ToolName:  "Edit",
+		ToolInput: map[string]interface{}{"file_path": ".env"},
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "deny", output.HookSpecificOutput.PermissionDecision)
+}
+
+func TestHandler_Policy_AllowRuleApproves(t *testing.T) {
+	// Arrange
+	fs := afero.NewMemMapFs()
+	env := shared.NewMockEnv()
+	require.NoError(t, fs.MkdirAll("/workspace/.claude", 0755))
+	require.NoError(t, afero.WriteFile(fs, "/workspace/.claudex/config.toml", []byte(`[[policy.rules]]
+name = "tests"
+tools = ["Bash"]
+commands = ['^go test\b']
+decision = "allow"
+`), 0644))
+
+	handler := NewHandler(fs, env, shared.NewLogger(fs, env, "test"))
+	bash := func(command string) *shared.PreToolUseInput {
+		return &shared.PreToolUseInput{
+			HookInput: shared.HookInput{SessionID: "abc123", CWD: "/workspace"},
+			ToolName:  "Bash",
+			ToolInput: map[string]interface{}{"command": command},
+		}
+	}
+
+	// Act
+	tests, err := handler.Handle(bash("go test ./..."))
+	require.NoError(t, err)
+	other, err := handler.Handle(bash("make build"))
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, "allow", tests.HookSpecificOutput.PermissionDecision)
+	assert.Empty(t, other.HookSpecificOutput.PermissionDecision)
+}
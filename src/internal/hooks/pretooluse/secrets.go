@@ -0,0 +1,122 @@
+package pretooluse
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"claudex/internal/hooks/policy"
+	"claudex/internal/services/config"
+	"claudex/internal/services/secrets"
+)
+
+// secretsRule names secret scan results in hook output and the audit log
+const secretsRule = "secrets"
+
+// checkSecrets scans the content a Write, Edit, MultiEdit or Bash call would write or run
+// for credentials. Findings deny the call, or ask when [secrets] action is "ask", and are
+// returned so the audit log can mask them. Files under allow_paths are not scanned.
+func (h *Handler) checkSecrets(cfg config.Secrets, call policy.Call, projectRoot string) (policy.Result, []secrets.Finding) {
+	allow := policy.Result{Decision: policy.Allow}
+	if !cfg.Enabled {
+		return allow, nil
+	}
+	content, filePath := toolContent(call)
+	if content == "" {
+		return allow, nil
+	}
+
+	scanner, err := secrets.New(cfg)
+	if err != nil {
+		if h.logger != nil {
+			_ = h.logger.LogError(fmt.Errorf("%w; scanning with the built-in patterns", err))
+		}
+		cfg.Patterns = nil
+		if scanner, err = secrets.New(cfg); err != nil {
+			scanner, _ = secrets.New(config.DefaultSecrets())
+		}
+	}
+
+	if filePath != "" && scanner.AllowedPath(projectPath(projectRoot, call.CWD, filePath)) {
+		return allow, nil
+	}
+	findings := scanner.Scan(content)
+	if len(findings) == 0 {
+		return allow, nil
+	}
+
+	described := make([]string, len(findings))
+	for i, f := range findings {
+		described[i] = f.String()
+	}
+	decision := policy.Deny
+	if strings.EqualFold(strings.TrimSpace(cfg.Action), string(policy.Ask)) {
+		decision = policy.Ask
+	}
+	return policy.Result{
+		Decision: decision,
+		Rule:     secretsRule,
+		Reason: fmt.Sprintf("possible secret in %s input: %s; if it is not a secret, add its fingerprint to [secrets] allow_fingerprints",
+			call.Tool, strings.Join(described, ", ")),
+	}, findings
+}
+
+// maskSecrets returns a copy of the call whose string inputs have every finding replaced
+// by its masked preview, so the audit log never stores a secret the scan blocked
+func maskSecrets(call policy.Call, findings []secrets.Finding) policy.Call {
+	if len(findings) == 0 {
+		return call
+	}
+	masked := make(map[string]interface{}, len(call.Input))
+	for key, value := range call.Input {
+		if text, ok := value.(string); ok {
+			for _, f := range findings {
+				text = strings.ReplaceAll(text, f.Secret, f.Masked())
+			}
+			value = text
+		}
+		masked[key] = value
+	}
+	call.Input = masked
+	return call
+}
+
+// toolContent returns the text a call writes or runs and the file it writes to
+func toolContent(call policy.Call) (string, string) {
+	filePath, _ := call.Input["file_path"].(string)
+	switch call.Tool {
+	case "Bash":
+		command, _ := call.Input["command"].(string)
+		return command, ""
+	case "Write":
+		content, _ := call.Input["content"].(string)
+		return content, filePath
+	case "Edit":
+		newString, _ := call.Input["new_string"].(string)
+		return newString, filePath
+	case "MultiEdit":
+		edits, _ := call.Input["edits"].([]interface{})
+		var parts []string
+		for _, e := range edits {
+			if edit, ok := e.(map[string]interface{}); ok {
+				if newString, ok := edit["new_string"].(string); ok {
+					parts = append(parts, newString)
+				}
+			}
+		}
+		return strings.Join(parts, "\n"), filePath
+	}
+	return "", ""
+}
+
+// projectPath returns a file path relative to the project root, slash-separated, or the
+// absolute path when the file lies outside the project
+func projectPath(projectRoot, cwd, filePath string) string {
+	if !filepath.IsAbs(filePath) {
+		filePath = filepath.Join(cwd, filePath)
+	}
+	if rel, err := filepath.Rel(projectRoot, filePath); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(filepath.Clean(filePath))
+}
@@ -0,0 +1,165 @@
+package pretooluse
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"claudex/internal/hooks/shared"
+	"claudex/internal/services/secrets"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKeyID is the AWS documentation example key id, split so the source holds no token
+var fakeKeyID = "AKIA" + "IOSFODNN7EXAMPLE"
+
+// newSecretsHandler creates a Handler for a project at /workspace with an optional config
+func newSecretsHandler(t *testing.T, configToml string) (*Handler, afero.Fs) {
+	t.Helper()
+	fs := afero.NewMemMapFs()
+	env := shared.NewMockEnv()
+	require.NoError(t, fs.MkdirAll("/workspace/.claude", 0755))
+	if configToml != "" {
+		require.NoError(t, afero.WriteFile(fs, "/workspace/.claudex/config.toml", []byte(configToml), 0644))
+	}
+	return NewHandler(fs, env, shared.NewLogger(fs, env, "test")), fs
+}
+
+// toolCall builds a PreToolUseInput run from the project root
+func toolCall(tool string, toolInput map[string]interface{}) *shared.PreToolUseInput {
+	return &shared.PreToolUseInput{
+		HookInput: shared.HookInput{SessionID: "abc123", CWD: "/workspace"},
+		ToolName:  tool,
+		ToolInput: toolInput,
+	}
+}
+
+func TestHandler_Secrets_DeniesCredentialsInToolInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input *shared.PreToolUseInput
+	}{
+		{"write", toolCall("Write", map[string]interface{}{"file_path": "docs/setup.md", "content": "key: " + fakeKeyID})},
+		{"edit", toolCall("Edit", map[string]interface{}{"file_path": "config.yml", "old_string": "x", "new_string": fakeKeyID})},
+		{"multi edit", toolCall("MultiEdit", map[string]interface{}{"file_path": "a.go", "edits": []interface{}{
+			map[string]interface{}{"old_string": "a", "new_string": "b"},
+			map[string]interface{}{"old_string": "c", "new_string": `id := "` + fakeKeyID + `"`},
+		}})},
+		{"bash", toolCall("Bash", map[string]interface{}{"command": "aws configure set aws_access_key_id " + fakeKeyID})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler, _ := newSecretsHandler(t, "")
+
+			// Act
+			output, err := handler.Handle(tt.input)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, "deny", output.HookSpecificOutput.PermissionDecision)
+			reason := output.HookSpecificOutput.PermissionDecisionReason
+			assert.Contains(t, reason, "claudex policy secrets: possible secret in "+tt.input.ToolName+" input: aws-access-key-id AKIA…")
+			assert.Contains(t, reason, secrets.Fingerprint(fakeKeyID))
+			assert.NotContains(t, reason, fakeKeyID, "the secret itself is never echoed")
+		})
+	}
+}
+
+func TestHandler_Secrets_AuditMasksDeniedSecret(t *testing.T) {
+	// Arrange
+	handler, fs := newSecretsHandler(t, "")
+	sessionPath := "/workspace/.claudex/sessions/test-session-abc123"
+	require.NoError(t, fs.MkdirAll(sessionPath, 0755))
+	handler.env.Set("CLAUDEX_SESSION_PATH", sessionPath)
+	token := "ghp_" + "abcdefghijklmnopqrstuvwxyz0123456789"
+
+	// Act
+	output, err := handler.Handle(toolCall("Bash", map[string]interface{}{"command": "curl -H 'Authorization: token " + token + "' https://api.github.com/user"}))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "deny", output.HookSpecificOutput.PermissionDecision)
+	audit, err := afero.ReadFile(fs, sessionPath+"/policy-audit.jsonl")
+	require.NoError(t, err)
+	assert.Contains(t, string(audit), `"decision":"deny","rule":"secrets"`)
+	assert.Contains(t, string(audit), "token ghp_…")
+	assert.NotContains(t, string(audit), token)
+}
+
+func TestHandler_Secrets_AskAction(t *testing.T) {
+	// Arrange
+	handler, _ := newSecretsHandler(t, "[secrets]\naction = \"ask\"\n")
+
+	// Act
+	output, err := handler.Handle(toolCall("Write", map[string]interface{}{"file_path": "notes.md", "content": fakeKeyID}))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "ask", output.HookSpecificOutput.PermissionDecision)
+}
+
+func TestHandler_Secrets_Allowlists(t *testing.T) {
+	// Arrange
+	handler, _ := newSecretsHandler(t, `[secrets]
+allow_paths = ["testdata/"]
+
+[[secrets.patterns]]
+name = "internal-token"
+regex = 'itk_[a-z0-9]{12}'
+`)
+
+	// Act
+	fixture, err := handler.Handle(toolCall("Write", map[string]interface{}{"file_path": "/workspace/pkg/testdata/creds.json", "content": fakeKeyID}))
+	require.NoError(t, err)
+	custom, err := handler.Handle(toolCall("Bash", map[string]interface{}{"command": "curl -H 'X-Token: itk_abc123def456' api"}))
+	require.NoError(t, err)
+
+	// Assert
+	assert.Empty(t, fixture.HookSpecificOutput.PermissionDecision)
+	assert.Equal(t, "deny", custom.HookSpecificOutput.PermissionDecision)
+	assert.Contains(t, custom.HookSpecificOutput.PermissionDecisionReason, "internal-token")
+
+	// Arrange: allowlist the fingerprint from the deny reason
+	allowed, _ := newSecretsHandler(t, "[secrets]\nallow_fingerprints = [\""+secrets.Fingerprint(fakeKeyID)+"\"]\n")
+
+	// Act
+	output, err := allowed.Handle(toolCall("Write", map[string]interface{}{"file_path": "docs/aws.md", "content": "Example key: " + fakeKeyID}))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, output.HookSpecificOutput.PermissionDecision)
+}
+
+func TestHandler_Secrets_Disabled(t *testing.T) {
+	// Arrange
+	handler, _ := newSecretsHandler(t, "[secrets]\nenabled = false\n")
+
+	// Act
+	output, err := handler.Handle(toolCall("Write", map[string]interface{}{"file_path": "notes.md", "content": fakeKeyID}))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, output.HookSpecificOutput.PermissionDecision)
+}
+
+func TestHandleFromBuilder_DenyUsesBuildDeny(t *testing.T) {
+	// Arrange
+	handler, _ := newSecretsHandler(t, "")
+	var buf bytes.Buffer
+
+	// Act
+	err := handler.HandleFromBuilder(toolCall("Write", map[string]interface{}{"file_path": "notes.md", "content": fakeKeyID}), shared.NewBuilder(&buf))
+
+	// Assert
+	require.NoError(t, err)
+	var output shared.HookOutput
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &output))
+	assert.Equal(t, "PreToolUse", output.HookSpecificOutput.HookEventName)
+	assert.Equal(t, "deny", output.HookSpecificOutput.PermissionDecision)
+	assert.Contains(t, output.HookSpecificOutput.PermissionDecisionReason, "aws-access-key-id")
+	assert.Nil(t, output.HookSpecificOutput.UpdatedInput)
+}
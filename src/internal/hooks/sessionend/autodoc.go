@@ -6,6 +6,7 @@ import (
 
 	"claudex/internal/doc"
 	"claudex/internal/hooks/shared"
+	"claudex/internal/hooks/trigger"
 	"claudex/internal/services/env"
 	"claudex/internal/services/session"
 
@@ -14,19 +15,21 @@ import (
 
 // Handler implements final documentation update on session end
 type Handler struct {
-	fs      afero.Fs
-	env     env.Environment
-	updater doc.DocumentationUpdater
-	logger  *shared.Logger
+	fs       afero.Fs
+	env      env.Environment
+	updater  doc.DocumentationUpdater
+	logger   *shared.Logger
+	triggers *trigger.Resolver
 }
 
 // NewHandler creates a new Handler instance
 func NewHandler(fs afero.Fs, env env.Environment, updater doc.DocumentationUpdater, logger *shared.Logger) *Handler {
 	return &Handler{
-		fs:      fs,
-		env:     env,
-		updater: updater,
-		logger:  logger,
+		fs:       fs,
+		env:      env,
+		updater:  updater,
+		logger:   logger,
+		triggers: trigger.New(fs, env),
 	}
 }
 
@@ -40,68 +43,76 @@ func (h *Handler) Handle(input *shared.SessionEndInput) error {
 	}
 
 	_ = h.logger.LogInfo(fmt.Sprintf("Session ending: %s", input.Reason))
+	h.update(input.HookInput, string(trigger.SessionEnd))
+	return nil
+}
+
+// HandlePreCompact documents the transcript before Claude Code compacts the conversation,
+// using the session_end trigger's settings. The snapshot is tagged "pre_compact".
+// Returns nil on success (no JSON output is needed for PreCompact hooks).
+func (h *Handler) HandlePreCompact(input *shared.PreCompactInput) error {
+	if h.env.Get("CLAUDE_HOOK_INTERNAL") == "1" {
+		return nil
+	}
 
+	_ = h.logger.LogInfo(fmt.Sprintf("Compacting conversation (%s)", input.Trigger))
+	h.update(input.HookInput, preCompactTrigger)
+	return nil
+}
+
+// preCompactTrigger tags overview snapshots written before a compaction
+const preCompactTrigger = "pre_compact"
+
+// update evaluates the session_end trigger and starts a background doc update whose
+// snapshot is tagged with snapshotTrigger. Errors are logged, never returned.
+func (h *Handler) update(input shared.HookInput, snapshotTrigger string) {
 	// Find session folder
 	sessionPath, err := session.FindSessionFolderWithCwd(h.fs, h.env, input.SessionID, input.CWD)
 	if err != nil {
 		// Log error but allow execution to continue
 		_ = h.logger.LogError(fmt.Errorf("failed to find session folder: %w", err))
-		return nil
+		return
 	}
 
-	_ = h.logger.LogInfo("Triggering final documentation update")
+	// Find project root to load trigger policy and build absolute template path
+	projectRoot, err := trigger.FindProjectRoot(h.fs, sessionPath)
+	if err != nil {
+		_ = h.logger.LogError(fmt.Errorf("failed to find project root: %w", err))
+		return
+	}
 
-	// Read last processed line for incremental updates
-	startLine, err := session.ReadLastProcessedLine(h.fs, sessionPath)
+	policy, err := h.triggers.Resolve(trigger.SessionEnd, projectRoot)
 	if err != nil {
-		_ = h.logger.LogError(fmt.Errorf("failed to read last processed line: %w", err))
-		startLine = 0 // Start from beginning if we can't read the marker
+		_ = h.logger.LogError(fmt.Errorf("failed to resolve trigger policy, using defaults: %w", err))
 	}
 
-	// Find project root to build absolute template path
-	projectRoot, err := h.findProjectRoot(sessionPath)
+	decision, err := h.triggers.Evaluate(policy, sessionPath)
 	if err != nil {
-		_ = h.logger.LogError(fmt.Errorf("failed to find project root: %w", err))
-		return nil
+		_ = h.logger.LogError(err)
+	}
+	_ = h.logger.LogInfo(decision.String())
+	if !decision.Fire {
+		return
 	}
 
 	// Build absolute path to template
 	templatePath := filepath.Join(projectRoot, ".claude", "hooks", "prompts", "session-overview-documenter.md")
 
 	// Trigger documentation update (background, non-blocking)
-	// This is the final update, so we always run it
 	config := doc.UpdaterConfig{
 		SessionPath:    sessionPath,
 		TranscriptPath: input.TranscriptPath,
-		OutputFile:     "session-overview.md",
+		OutputFile:     policy.OutputFile,
 		PromptTemplate: templatePath,
-		Model:          "haiku",
-		StartLine:      startLine + 1, // Start from next line (1-indexed)
+		Model:          policy.Model,
+		Trigger:        snapshotTrigger,
+		HistoryLimit:   policy.HistoryLimit,
+		Validation:     doc.ValidationRulesFor(policy.Validation),
+		Redaction:      doc.RedactionRulesFor(policy.Redaction),
 	}
 
 	if err := h.updater.RunBackground(config); err != nil {
 		_ = h.logger.LogError(fmt.Errorf("failed to start background doc update: %w", err))
 		// Don't fail - log and continue
 	}
-
-	return nil
-}
-
-// findProjectRoot walks up from sessionPath to find the project root (where .claude directory exists)
-func (h *Handler) findProjectRoot(sessionPath string) (string, error) {
-	current := sessionPath
-	for {
-		claudeDir := filepath.Join(current, ".claude")
-		exists, err := afero.DirExists(h.fs, claudeDir)
-		if err == nil && exists {
-			return current, nil
-		}
-
-		parent := filepath.Dir(current)
-		if parent == current {
-			// Reached filesystem root
-			return "", fmt.Errorf("could not find .claude directory in any parent of %s", sessionPath)
-		}
-		current = parent
-	}
 }
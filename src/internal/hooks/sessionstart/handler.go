@@ -0,0 +1,167 @@
+package sessionstart
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"claudex/internal/hooks/shared"
+	"claudex/internal/hooks/trigger"
+	"claudex/internal/services/config"
+	"claudex/internal/services/env"
+	"claudex/internal/services/session"
+
+	"github.com/spf13/afero"
+)
+
+// Sources reported by Claude Code in SessionStart events
+const (
+	SourceStartup = "startup"
+	SourceResume  = "resume"
+	SourceClear   = "clear"
+	SourceCompact = "compact"
+)
+
+// MaxOverviewBytes is the largest overview injected inline; larger ones are referenced by path
+const MaxOverviewBytes = 16 * 1024
+
+// teamLeadCommand is the team-lead profile installed by setup, relative to the project root
+var teamLeadCommand = filepath.Join(".claude", "commands", "agents", "team-lead.md")
+
+// Handler injects the claudex session context when Claude Code starts, resumes,
+// clears or compacts a session
+type Handler struct {
+	fs       afero.Fs
+	env      env.Environment
+	logger   *shared.Logger
+	triggers *trigger.Resolver
+}
+
+// NewHandler creates a new Handler instance
+func NewHandler(fs afero.Fs, env env.Environment, logger *shared.Logger) *Handler {
+	return &Handler{
+		fs:       fs,
+		env:      env,
+		logger:   logger,
+		triggers: trigger.New(fs, env),
+	}
+}
+
+// Handle returns the session context as additionalContext. Sessions without a claudex
+// folder (ephemeral sessions, internal invocations) get no output (nil).
+func (h *Handler) Handle(input *shared.SessionStartInput) (*shared.HookOutput, error) {
+	if h.env.Get("CLAUDE_HOOK_INTERNAL") == "1" {
+		return nil, nil
+	}
+
+	sessionPath, err := session.FindSessionFolderWithCwd(h.fs, h.env, input.SessionID, input.CWD)
+	if err != nil {
+		_ = h.logger.LogInfo(fmt.Sprintf("SessionStart (%s): no claudex session folder: %v", input.Source, err))
+		return nil, nil
+	}
+
+	context, err := h.buildContext(input.Source, sessionPath)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = h.logger.LogInfo(fmt.Sprintf("SessionStart (%s): injected context for %s", input.Source, sessionPath))
+	return &shared.HookOutput{
+		HookSpecificOutput: shared.HookSpecificOutput{
+			HookEventName:     "SessionStart",
+			AdditionalContext: context,
+		},
+	}, nil
+}
+
+// buildContext creates the markdown context block: the session folder, the overview
+// (inline, or its path when too large or missing), the role profile and the doc entry points
+func (h *Handler) buildContext(source, sessionPath string) (string, error) {
+	var sb strings.Builder
+
+	sb.WriteString("## CLAUDEX SESSION\n\n")
+	sb.WriteString(sourceNote(source))
+	sb.WriteString(fmt.Sprintf("**Session Folder (Absolute Path)**: `%s`\n\n", sessionPath))
+	sb.WriteString("ALL documentation, plans and artifacts MUST be saved to the session folder, using absolute paths.\n\n")
+
+	overviewFile := config.DefaultAutodoc().Progress.OutputFile
+	if projectRoot, err := trigger.FindProjectRoot(h.fs, sessionPath); err == nil {
+		profile := filepath.Join(projectRoot, teamLeadCommand)
+		if exists, _ := afero.Exists(h.fs, profile); exists {
+			sb.WriteString(fmt.Sprintf("**Role**: act as the team-lead agent described in `%s`.\n\n", profile))
+		}
+		// The overview is the document the progress trigger maintains
+		if policy, err := h.triggers.Resolve(trigger.Progress, projectRoot); err == nil && policy.OutputFile != "" {
+			overviewFile = policy.OutputFile
+		}
+	}
+
+	overviewPath := filepath.Join(sessionPath, overviewFile)
+	overview, err := h.readOverview(overviewPath)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case strings.TrimSpace(overview) == "":
+		sb.WriteString("### Session Overview\n")
+		sb.WriteString(fmt.Sprintf("No overview yet; `%s` is created as the session progresses.\n\n", overviewPath))
+	case len(overview) > MaxOverviewBytes:
+		sb.WriteString("### Session Overview\n")
+		sb.WriteString(fmt.Sprintf("Read `%s` before starting any task, then load the documents it references when the task needs them.\n\n", overviewPath))
+	default:
+		sb.WriteString(fmt.Sprintf("### Session Overview (`%s`)\n\n", overviewPath))
+		sb.WriteString(strings.TrimRight(overview, "\n"))
+		sb.WriteString("\n\nLoad the documents it references when the task needs them.\n\n")
+	}
+
+	if docPaths := h.docPaths(); len(docPaths) > 0 {
+		sb.WriteString("### Documentation Entry Points\n")
+		for _, docPath := range docPaths {
+			sb.WriteString(fmt.Sprintf("- %s\n", docPath))
+		}
+		sb.WriteString("\nEach file links to documentation in subdirectories; follow only the links relevant to the task.\n")
+	}
+
+	return strings.TrimRight(sb.String(), "\n") + "\n", nil
+}
+
+// docPaths returns the doc entry points claudex exported in CLAUDEX_DOC_PATHS
+func (h *Handler) docPaths() []string {
+	var docPaths []string
+	for _, docPath := range strings.Split(h.env.Get("CLAUDEX_DOC_PATHS"), ":") {
+		if docPath != "" {
+			docPaths = append(docPaths, docPath)
+		}
+	}
+	return docPaths
+}
+
+// sourceNote explains why the context is (re)injected
+func sourceNote(source string) string {
+	switch source {
+	case SourceResume:
+		return "This conversation continues an existing claudex session.\n\n"
+	case SourceClear:
+		return "The conversation was cleared; the session folder below holds everything done so far.\n\n"
+	case SourceCompact:
+		return "The conversation was compacted; the session folder below holds the details the summary left out.\n\n"
+	default:
+		return "You are working within an active claudex session.\n\n"
+	}
+}
+
+// readOverview returns the overview contents, or "" when it does not exist yet
+func (h *Handler) readOverview(overviewPath string) (string, error) {
+	exists, err := afero.Exists(h.fs, overviewPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to check for %s: %w", filepath.Base(overviewPath), err)
+	}
+	if !exists {
+		return "", nil
+	}
+	content, err := afero.ReadFile(h.fs, overviewPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", filepath.Base(overviewPath), err)
+	}
+	return string(content), nil
+}
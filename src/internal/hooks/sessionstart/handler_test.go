@@ -0,0 +1,108 @@
+package sessionstart
+
+import (
+	"strings"
+	"testing"
+
+	"claudex/internal/hooks/shared"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sessionPath = "/project/.claudex/sessions/api-refactor-abc123"
+
+// newHandler creates a Handler for a claudex project at /project with one session folder
+func newHandler(t *testing.T) (*Handler, afero.Fs, *shared.MockEnv) {
+	t.Helper()
+	fs := afero.NewMemMapFs()
+	env := shared.NewMockEnv()
+	require.NoError(t, fs.MkdirAll("/project/.claude/commands/agents", 0755))
+	require.NoError(t, fs.MkdirAll(sessionPath, 0755))
+	return NewHandler(fs, env, shared.NewLogger(fs, env, "test")), fs, env
+}
+
+// start builds a SessionStartInput for the session folder above
+func start(source string) *shared.SessionStartInput {
+	return &shared.SessionStartInput{
+		HookInput: shared.HookInput{SessionID: "abc123", CWD: "/project", HookEventName: "SessionStart"},
+		Source:    source,
+	}
+}
+
+func TestHandle_InjectsOverviewForEverySource(t *testing.T) {
+	for _, source := range []string{SourceStartup, SourceResume, SourceClear, SourceCompact} {
+		t.Run(source, func(t *testing.T) {
+			// Arrange
+			handler, fs, env := newHandler(t)
+			env.Set("CLAUDEX_DOC_PATHS", "/project/docs/index.md:/project/src/index.md")
+			require.NoError(t, afero.WriteFile(fs, sessionPath+"/session-overview.md", []byte("# Session: API Refactor\n\n## Status\nPhase 2\n"), 0644))
+			require.NoError(t, afero.WriteFile(fs, "/project/.claude/commands/agents/team-lead.md", []byte("team lead"), 0644))
+
+			// Act
+			output, err := handler.Handle(start(source))
+
+			// Assert
+			require.NoError(t, err)
+			require.NotNil(t, output)
+			assert.Equal(t, "SessionStart", output.HookSpecificOutput.HookEventName)
+			context := output.HookSpecificOutput.AdditionalContext
+			assert.Contains(t, context, "**Session Folder (Absolute Path)**: `"+sessionPath+"`")
+			assert.Contains(t, context, "## Status\nPhase 2")
+			assert.Contains(t, context, "/project/.claude/commands/agents/team-lead.md")
+			assert.Contains(t, context, "- /project/docs/index.md\n- /project/src/index.md\n")
+			assert.NotContains(t, context, "/agents:team-lead activate")
+		})
+	}
+}
+
+func TestHandle_PointsToLargeOrMissingOverview(t *testing.T) {
+	// Arrange
+	handler, fs, _ := newHandler(t)
+
+	// Act
+	missing, err := handler.Handle(start(SourceStartup))
+	require.NoError(t, err)
+	require.NoError(t, afero.WriteFile(fs, sessionPath+"/session-overview.md", []byte(strings.Repeat("x", MaxOverviewBytes+1)), 0644))
+	large, err := handler.Handle(start(SourceClear))
+	require.NoError(t, err)
+
+	// Assert
+	assert.Contains(t, missing.HookSpecificOutput.AdditionalContext, "No overview yet")
+	assert.NotContains(t, missing.HookSpecificOutput.AdditionalContext, "**Role**", "no team-lead profile installed")
+	assert.Contains(t, large.HookSpecificOutput.AdditionalContext, "Read `"+sessionPath+"/session-overview.md` before starting any task")
+	assert.NotContains(t, large.HookSpecificOutput.AdditionalContext, "xxxx")
+}
+
+func TestHandle_UsesConfiguredOutputFile(t *testing.T) {
+	// Arrange
+	handler, fs, _ := newHandler(t)
+	require.NoError(t, afero.WriteFile(fs, "/project/.claudex/config.toml", []byte("[autodoc.progress]\noutput_file = \"status.md\"\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, sessionPath+"/status.md", []byte("## Status\nDone\n"), 0644))
+
+	// Act
+	output, err := handler.Handle(start(SourceResume))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, output.HookSpecificOutput.AdditionalContext, "### Session Overview (`"+sessionPath+"/status.md`)\n\n## Status\nDone")
+}
+
+func TestHandle_NoOutputOutsideClaudexSessions(t *testing.T) {
+	// Arrange
+	handler, _, env := newHandler(t)
+	ephemeral := start(SourceStartup)
+	ephemeral.SessionID = "ephemeral-uuid"
+
+	// Act
+	output, err := handler.Handle(ephemeral)
+	require.NoError(t, err)
+	env.Set("CLAUDE_HOOK_INTERNAL", "1")
+	internal, err := handler.Handle(start(SourceStartup))
+	require.NoError(t, err)
+
+	// Assert
+	assert.Nil(t, output)
+	assert.Nil(t, internal)
+}
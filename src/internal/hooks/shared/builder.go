@@ -51,6 +51,18 @@ func (b *Builder) BuildDeny(hookEventName, reason string) error {
 	return b.write(output)
 }
 
+// BuildAsk builds an "ask" response with a reason, prompting the user to confirm the call
+func (b *Builder) BuildAsk(hookEventName, reason string) error {
+	output := HookOutput{
+		HookSpecificOutput: HookSpecificOutput{
+			HookEventName:            hookEventName,
+			PermissionDecision:       "ask",
+			PermissionDecisionReason: reason,
+		},
+	}
+	return b.write(output)
+}
+
 // BuildWithUpdatedInput builds a response with updated tool input
 func (b *Builder) BuildWithUpdatedInput(hookEventName string, updatedInput map[string]interface{}) error {
 	output := HookOutput{
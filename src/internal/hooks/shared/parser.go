@@ -103,6 +103,72 @@ func (p *Parser) ParseSubagentStop() (*SubagentStopInput, error) {
 	return &input, nil
 }
 
+// ParseSessionStart parses SessionStart input from JSON
+func (p *Parser) ParseSessionStart() (*SessionStartInput, error) {
+	var input SessionStartInput
+	if err := json.NewDecoder(p.reader).Decode(&input); err != nil {
+		return nil, fmt.Errorf("failed to parse SessionStart input: %w", err)
+	}
+
+	// Validate required fields
+	if input.SessionID == "" {
+		return nil, fmt.Errorf("session_id is required")
+	}
+	if input.Source == "" {
+		return nil, fmt.Errorf("source is required")
+	}
+
+	return &input, nil
+}
+
+// ParseUserPromptSubmit parses UserPromptSubmit input from JSON
+func (p *Parser) ParseUserPromptSubmit() (*UserPromptSubmitInput, error) {
+	var input UserPromptSubmitInput
+	if err := json.NewDecoder(p.reader).Decode(&input); err != nil {
+		return nil, fmt.Errorf("failed to parse UserPromptSubmit input: %w", err)
+	}
+
+	// Validate required fields
+	if input.SessionID == "" {
+		return nil, fmt.Errorf("session_id is required")
+	}
+
+	return &input, nil
+}
+
+// ParsePreCompact parses PreCompact input from JSON
+func (p *Parser) ParsePreCompact() (*PreCompactInput, error) {
+	var input PreCompactInput
+	if err := json.NewDecoder(p.reader).Decode(&input); err != nil {
+		return nil, fmt.Errorf("failed to parse PreCompact input: %w", err)
+	}
+
+	// Validate required fields
+	if input.SessionID == "" {
+		return nil, fmt.Errorf("session_id is required")
+	}
+	if input.Trigger == "" {
+		return nil, fmt.Errorf("trigger is required")
+	}
+
+	return &input, nil
+}
+
+// ParseStop parses Stop input from JSON
+func (p *Parser) ParseStop() (*StopInput, error) {
+	var input StopInput
+	if err := json.NewDecoder(p.reader).Decode(&input); err != nil {
+		return nil, fmt.Errorf("failed to parse Stop input: %w", err)
+	}
+
+	// Validate required fields
+	if input.SessionID == "" {
+		return nil, fmt.Errorf("session_id is required")
+	}
+
+	return &input, nil
+}
+
 // ParseDocUpdate parses DocUpdate input from JSON
 func (p *Parser) ParseDocUpdate() (*DocUpdateInput, error) {
 	var input DocUpdateInput
@@ -123,8 +189,8 @@ func (p *Parser) ParseDocUpdate() (*DocUpdateInput, error) {
 	if input.Model == "" {
 		return nil, fmt.Errorf("model is required")
 	}
-	if input.StartLine < 1 {
-		return nil, fmt.Errorf("start_line must be >= 1")
+	if input.StartLine < 0 {
+		return nil, fmt.Errorf("start_line must be >= 0")
 	}
 
 	return &input, nil
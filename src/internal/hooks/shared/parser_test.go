@@ -2,6 +2,7 @@ package shared
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -193,3 +194,90 @@ func TestPostToolUseInput_ToolResponseMarshalRoundtrip(t *testing.T) {
 		})
 	}
 }
+
+// TestParser_LifecycleEvents verifies the SessionStart, UserPromptSubmit, PreCompact and Stop
+// inputs are decoded and their required fields validated
+func TestParser_LifecycleEvents(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		parse   func(*Parser) (interface{}, error)
+		want    interface{}
+		wantErr string
+	}{
+		{
+			name:  "session start",
+			json:  `{"session_id":"s1","transcript_path":"/tmp/t.jsonl","cwd":"/tmp","hook_event_name":"SessionStart","source":"resume"}`,
+			parse: func(p *Parser) (interface{}, error) { return p.ParseSessionStart() },
+			want: &SessionStartInput{
+				HookInput: HookInput{SessionID: "s1", TranscriptPath: "/tmp/t.jsonl", CWD: "/tmp", HookEventName: "SessionStart"},
+				Source:    "resume",
+			},
+		},
+		{
+			name:    "session start without source",
+			json:    `{"session_id":"s1","hook_event_name":"SessionStart"}`,
+			parse:   func(p *Parser) (interface{}, error) { return p.ParseSessionStart() },
+			wantErr: "source is required",
+		},
+		{
+			name:  "user prompt submit",
+			json:  `{"session_id":"s1","hook_event_name":"UserPromptSubmit","prompt":"fix the login bug"}`,
+			parse: func(p *Parser) (interface{}, error) { return p.ParseUserPromptSubmit() },
+			want: &UserPromptSubmitInput{
+				HookInput: HookInput{SessionID: "s1", HookEventName: "UserPromptSubmit"},
+				Prompt:    "fix the login bug",
+			},
+		},
+		{
+			name:    "user prompt submit without session",
+			json:    `{"hook_event_name":"UserPromptSubmit","prompt":"hi"}`,
+			parse:   func(p *Parser) (interface{}, error) { return p.ParseUserPromptSubmit() },
+			wantErr: "session_id is required",
+		},
+		{
+			name:  "pre compact",
+			json:  `{"session_id":"s1","hook_event_name":"PreCompact","trigger":"manual","custom_instructions":"keep the API notes"}`,
+			parse: func(p *Parser) (interface{}, error) { return p.ParsePreCompact() },
+			want: &PreCompactInput{
+				HookInput:          HookInput{SessionID: "s1", HookEventName: "PreCompact"},
+				Trigger:            "manual",
+				CustomInstructions: "keep the API notes",
+			},
+		},
+		{
+			name:    "pre compact without trigger",
+			json:    `{"session_id":"s1","hook_event_name":"PreCompact"}`,
+			parse:   func(p *Parser) (interface{}, error) { return p.ParsePreCompact() },
+			wantErr: "trigger is required",
+		},
+		{
+			name:  "stop",
+			json:  `{"session_id":"s1","hook_event_name":"Stop","stop_hook_active":true}`,
+			parse: func(p *Parser) (interface{}, error) { return p.ParseStop() },
+			want: &StopInput{
+				HookInput:      HookInput{SessionID: "s1", HookEventName: "Stop"},
+				StopHookActive: true,
+			},
+		},
+		{
+			name:    "stop with invalid json",
+			json:    `{"session_id":`,
+			parse:   func(p *Parser) (interface{}, error) { return p.ParseStop() },
+			wantErr: "failed to parse Stop input",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse(NewParser(strings.NewReader(tt.json)))
+
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
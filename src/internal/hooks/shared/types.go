@@ -52,16 +52,64 @@ type SubagentStopInput struct {
 	CompletionReason    string `json:"completion_reason,omitempty"`
 }
 
+// SessionStartInput extends HookInput for SessionStart events
+type SessionStartInput struct {
+	HookInput
+	Source string `json:"source"` // startup, resume, clear or compact
+}
+
+// UserPromptSubmitInput extends HookInput for UserPromptSubmit events
+type UserPromptSubmitInput struct {
+	HookInput
+	Prompt string `json:"prompt"`
+}
+
+// PreCompactInput extends HookInput for PreCompact events
+type PreCompactInput struct {
+	HookInput
+	Trigger            string `json:"trigger"` // manual (/compact) or auto (context window full)
+	CustomInstructions string `json:"custom_instructions,omitempty"`
+}
+
+// StopInput extends HookInput for Stop events
+type StopInput struct {
+	HookInput
+	StopHookActive bool `json:"stop_hook_active"` // Claude is already continuing because of a Stop hook
+}
+
 // DocUpdateInput represents input for the doc-update command
 // This is used to pass configuration to the detached subprocess
 type DocUpdateInput struct {
-	SessionPath    string `json:"session_path"`
-	TranscriptPath string `json:"transcript_path"`
-	OutputFile     string `json:"output_file"`
-	PromptTemplate string `json:"prompt_template"`
-	SessionContext string `json:"session_context"`
-	Model          string `json:"model"`
-	StartLine      int    `json:"start_line"`
+	SessionPath    string         `json:"session_path"`
+	TranscriptPath string         `json:"transcript_path"`
+	OutputFile     string         `json:"output_file"`
+	PromptTemplate string         `json:"prompt_template"`
+	SessionContext string         `json:"session_context"`
+	Model          string         `json:"model"`
+	StartLine      int            `json:"start_line"`           // 0 resumes from the transcript cursor
+	Trigger        string         `json:"trigger"`              // Recorded in the overview snapshot
+	HistoryLimit   int            `json:"history_limit"`        // Snapshots kept in <session>/.history (0 disables)
+	Validation     *DocValidation `json:"validation,omitempty"` // Checks the new document must pass (nil disables)
+	Redaction      *DocRedaction  `json:"redaction,omitempty"`  // Masking of transcript entries (nil disables)
+}
+
+// DocValidation mirrors doc.ValidationRules for the doc-update payload
+type DocValidation struct {
+	RequiredSections []string `json:"required_sections"`
+	CheckLinks       bool     `json:"check_links"`
+}
+
+// DocRedaction mirrors doc.RedactionRules for the doc-update payload
+type DocRedaction struct {
+	Emails           bool                  `json:"emails"`
+	EntropyThreshold float64               `json:"entropy_threshold"`
+	Patterns         []DocRedactionPattern `json:"patterns,omitempty"`
+}
+
+// DocRedactionPattern mirrors doc.RedactionPattern
+type DocRedactionPattern struct {
+	Name  string `json:"name"`
+	Regex string `json:"regex"`
 }
 
 // HookOutput represents the response structure for all hooks
@@ -75,4 +123,5 @@ type HookSpecificOutput struct {
 	PermissionDecision       string                 `json:"permissionDecision,omitempty"`
 	PermissionDecisionReason string                 `json:"permissionDecisionReason,omitempty"`
 	UpdatedInput             map[string]interface{} `json:"updatedInput,omitempty"`
+	AdditionalContext        string                 `json:"additionalContext,omitempty"` // SessionStart and UserPromptSubmit context for Claude
 }
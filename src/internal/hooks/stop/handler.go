@@ -0,0 +1,93 @@
+package stop
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"claudex/internal/doc"
+	"claudex/internal/hooks/shared"
+	"claudex/internal/hooks/trigger"
+	"claudex/internal/services/env"
+	"claudex/internal/services/session"
+
+	"github.com/spf13/afero"
+)
+
+// Handler processes Stop events, sent each time Claude finishes responding
+type Handler struct {
+	fs       afero.Fs
+	env      env.Environment
+	updater  doc.DocumentationUpdater
+	logger   *shared.Logger
+	triggers *trigger.Resolver
+}
+
+// NewHandler creates a new Handler instance
+func NewHandler(fs afero.Fs, env env.Environment, updater doc.DocumentationUpdater, logger *shared.Logger) *Handler {
+	return &Handler{
+		fs:       fs,
+		env:      env,
+		updater:  updater,
+		logger:   logger,
+		triggers: trigger.New(fs, env),
+	}
+}
+
+// Handle catches the session overview up when a response ends with as many tool uses
+// pending as the progress frequency requires, so the overview does not wait for the
+// next tool call. Fewer pending tool uses are left to the progress trigger. It never blocks Claude from stopping, and does
+// nothing when Claude is already continuing because of a Stop hook.
+// Returns nil on success (no JSON output is needed).
+func (h *Handler) Handle(input *shared.StopInput) error {
+	if h.env.Get("CLAUDE_HOOK_INTERNAL") == "1" || input.StopHookActive {
+		return nil
+	}
+
+	sessionPath, err := session.FindSessionFolderWithCwd(h.fs, h.env, input.SessionID, input.CWD)
+	if err != nil {
+		_ = h.logger.LogInfo(fmt.Sprintf("Stop: no claudex session folder: %v", err))
+		return nil
+	}
+
+	projectRoot, err := trigger.FindProjectRoot(h.fs, sessionPath)
+	if err != nil {
+		_ = h.logger.LogError(fmt.Errorf("failed to find project root: %w", err))
+		return nil
+	}
+
+	policy, err := h.triggers.Resolve(trigger.Progress, projectRoot)
+	if err != nil {
+		_ = h.logger.LogError(fmt.Errorf("failed to resolve trigger policy, using defaults: %w", err))
+	}
+
+	decision, err := h.triggers.CatchUp(policy, sessionPath)
+	if err != nil {
+		_ = h.logger.LogError(err)
+	}
+	_ = h.logger.LogInfo("Stop: " + decision.String())
+	if !decision.Fire {
+		return nil
+	}
+
+	// Trigger documentation update (background, non-blocking)
+	config := doc.UpdaterConfig{
+		SessionPath:    sessionPath,
+		TranscriptPath: input.TranscriptPath,
+		OutputFile:     policy.OutputFile,
+		PromptTemplate: filepath.Join(projectRoot, ".claude", "hooks", "prompts", "session-overview-documenter.md"),
+		Model:          policy.Model,
+		Trigger:        stopTrigger,
+		HistoryLimit:   policy.HistoryLimit,
+		Validation:     doc.ValidationRulesFor(policy.Validation),
+		Redaction:      doc.RedactionRulesFor(policy.Redaction),
+	}
+
+	if err := h.updater.RunBackground(config); err != nil {
+		_ = h.logger.LogError(fmt.Errorf("failed to start background doc update: %w", err))
+		// Don't fail - log and continue
+	}
+	return nil
+}
+
+// stopTrigger tags overview snapshots written when a response ends
+const stopTrigger = "stop"
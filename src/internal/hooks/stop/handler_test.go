@@ -0,0 +1,141 @@
+package stop
+
+import (
+	"path/filepath"
+	"testing"
+
+	"claudex/internal/doc"
+	"claudex/internal/hooks/shared"
+	"claudex/internal/services/session"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sessionPath = "/project/.claudex/sessions/api-refactor-abc123"
+
+// mockUpdater records the configs passed to RunBackground
+type mockUpdater struct {
+	configs []doc.UpdaterConfig
+}
+
+func (m *mockUpdater) RunBackground(config doc.UpdaterConfig) error {
+	m.configs = append(m.configs, config)
+	return nil
+}
+
+func (m *mockUpdater) Run(config doc.UpdaterConfig) error {
+	m.configs = append(m.configs, config)
+	return nil
+}
+
+// newHandler creates a Handler for a claudex project at /project with one session folder
+// whose progress counter holds pending tool uses
+func newHandler(t *testing.T, pending string) (*Handler, *mockUpdater, afero.Fs, *shared.MockEnv) {
+	t.Helper()
+	fs := afero.NewMemMapFs()
+	env := shared.NewMockEnv()
+	require.NoError(t, fs.MkdirAll("/project/.claude/hooks/prompts", 0755))
+	require.NoError(t, fs.MkdirAll(sessionPath, 0755))
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(sessionPath, session.DocUpdateCounterFile), []byte(pending), 0644))
+	updater := &mockUpdater{}
+	return NewHandler(fs, env, updater, shared.NewLogger(fs, env, "test")), updater, fs, env
+}
+
+// stopInput builds a StopInput for the session folder above
+func stopInput() *shared.StopInput {
+	return &shared.StopInput{
+		HookInput: shared.HookInput{
+			SessionID:      "abc123",
+			TranscriptPath: "/tmp/transcript.jsonl",
+			CWD:            "/project",
+			HookEventName:  "Stop",
+		},
+	}
+}
+
+func TestHandle_CatchesUpPendingToolUses(t *testing.T) {
+	// Arrange
+	handler, updater, fs, env := newHandler(t, "3")
+	env.Set("CLAUDEX_AUTODOC_FREQUENCY", "3")
+
+	// Act
+	err := handler.Handle(stopInput())
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, updater.configs, 1)
+	config := updater.configs[0]
+	assert.Equal(t, sessionPath, config.SessionPath)
+	assert.Equal(t, "/tmp/transcript.jsonl", config.TranscriptPath)
+	assert.Equal(t, "/project/.claude/hooks/prompts/session-overview-documenter.md", config.PromptTemplate)
+	assert.Equal(t, "session-overview.md", config.OutputFile)
+	assert.Equal(t, "stop", config.Trigger)
+	count, err := session.ReadCounter(fs, sessionPath)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count, "the catch-up should reset the progress counter")
+}
+
+func TestHandle_NothingPending(t *testing.T) {
+	// Arrange
+	handler, updater, _, _ := newHandler(t, "0")
+
+	// Act
+	err := handler.Handle(stopInput())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, updater.configs)
+}
+
+func TestHandle_LeavesFewerPendingThanFrequency(t *testing.T) {
+	// Arrange
+	handler, updater, fs, env := newHandler(t, "3")
+	env.Set("CLAUDEX_AUTODOC_FREQUENCY", "5")
+
+	// Act
+	err := handler.Handle(stopInput())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, updater.configs, "a response must not bypass the progress frequency")
+	count, err := session.ReadCounter(fs, sessionPath)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count, "pending tool uses stay counted for the progress trigger")
+}
+
+func TestHandle_SkipsWhenDisabledOrContinuing(t *testing.T) {
+	// Arrange
+	handler, updater, fs, env := newHandler(t, "3")
+	active := stopInput()
+	active.StopHookActive = true
+
+	// Act
+	require.NoError(t, handler.Handle(active))
+	env.Set("CLAUDEX_AUTODOC_SESSION_PROGRESS", "false")
+	require.NoError(t, handler.Handle(stopInput()))
+	env.Set("CLAUDEX_AUTODOC_SESSION_PROGRESS", "")
+	env.Set("CLAUDE_HOOK_INTERNAL", "1")
+	require.NoError(t, handler.Handle(stopInput()))
+
+	// Assert
+	assert.Empty(t, updater.configs)
+	count, err := session.ReadCounter(fs, sessionPath)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count, "skipped events must leave the pending count alone")
+}
+
+func TestHandle_NoClaudexSession(t *testing.T) {
+	// Arrange
+	handler, updater, _, _ := newHandler(t, "3")
+	ephemeral := stopInput()
+	ephemeral.SessionID = "ephemeral-uuid"
+
+	// Act
+	err := handler.Handle(ephemeral)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, updater.configs)
+}
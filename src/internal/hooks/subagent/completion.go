@@ -2,9 +2,11 @@ package subagent
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"claudex/internal/doc"
 	"claudex/internal/hooks/shared"
+	"claudex/internal/hooks/trigger"
 	"claudex/internal/notify"
 	"claudex/internal/services/env"
 	"claudex/internal/services/session"
@@ -19,6 +21,7 @@ type Handler struct {
 	updater  doc.DocumentationUpdater
 	notifier notify.Notifier
 	logger   *shared.Logger
+	triggers *trigger.Resolver
 }
 
 // NewHandler creates a new Handler instance
@@ -35,71 +38,92 @@ func NewHandler(
 		updater:  updater,
 		notifier: notifier,
 		logger:   logger,
+		triggers: trigger.New(fs, env),
 	}
 }
 
-// Handle processes subagent completion: resets counter, updates docs, and sends notification
+// Handle processes subagent completion: updates docs per the subagent trigger policy and sends notification
 func (h *Handler) Handle(input *shared.SubagentStopInput) (*shared.HookOutput, error) {
 	_ = h.logger.LogInfo(fmt.Sprintf("Subagent stopped: %s (reason: %s)", input.AgentID, input.CompletionReason))
 
+	h.updateDocs(input)
+
+	// Send notification
+	title := "Agent Complete"
+	message := fmt.Sprintf("Agent %s finished", input.AgentID)
+	sound := "Glass"
+
+	if err := h.notifier.Send(title, message, sound); err != nil {
+		_ = h.logger.LogError(fmt.Errorf("failed to send notification: %w", err))
+		// Don't fail - notification is nice-to-have
+	}
+
+	return h.allowOutput(), nil
+}
+
+// allowOutput creates a standard "allow" response for SubagentStop events
+func (h *Handler) allowOutput() *shared.HookOutput {
+	return &shared.HookOutput{
+		HookSpecificOutput: shared.HookSpecificOutput{
+			HookEventName:      "SubagentStop",
+			PermissionDecision: "allow",
+		},
+	}
+}
+
+// updateDocs triggers a background documentation update when the subagent trigger policy fires
+func (h *Handler) updateDocs(input *shared.SubagentStopInput) {
 	// Find session folder
 	sessionPath, err := session.FindSessionFolderWithCwd(h.fs, h.env, input.SessionID, input.CWD)
 	if err != nil {
 		// Log error but allow execution to continue
 		_ = h.logger.LogError(fmt.Errorf("failed to find session folder: %w", err))
-		return h.allowOutput(), nil
+		return
 	}
 
-	// Reset counter to prevent duplicate updates
-	// (AutoDoc might have just run, we don't want it to run again immediately)
-	if err := session.ResetCounter(h.fs, sessionPath); err != nil {
-		_ = h.logger.LogError(fmt.Errorf("failed to reset counter: %w", err))
-		// Continue anyway - this is not critical
+	// Find project root to load trigger policy and build absolute template path
+	projectRoot, err := trigger.FindProjectRoot(h.fs, sessionPath)
+	if err != nil {
+		_ = h.logger.LogError(fmt.Errorf("failed to find project root: %w", err))
+		return
 	}
 
-	_ = h.logger.LogInfo("Triggering documentation update for agent completion")
+	policy, err := h.triggers.Resolve(trigger.Subagent, projectRoot)
+	if err != nil {
+		_ = h.logger.LogError(fmt.Errorf("failed to resolve trigger policy, using defaults: %w", err))
+	}
 
-	// Read last processed line for incremental updates
-	startLine, err := session.ReadLastProcessedLine(h.fs, sessionPath)
+	decision, err := h.triggers.Evaluate(policy, sessionPath)
 	if err != nil {
-		_ = h.logger.LogError(fmt.Errorf("failed to read last processed line: %w", err))
-		startLine = 0 // Start from beginning if we can't read the marker
+		_ = h.logger.LogError(err)
+	}
+	_ = h.logger.LogInfo(decision.String())
+	if !decision.Fire {
+		return
+	}
+
+	// Reset progress counter to prevent duplicate updates
+	// (AutoDoc might have just run, we don't want it to run again immediately)
+	if err := session.ResetCounter(h.fs, sessionPath); err != nil {
+		_ = h.logger.LogError(fmt.Errorf("failed to reset counter: %w", err))
+		// Continue anyway - this is not critical
 	}
 
 	// Trigger documentation update (background, non-blocking)
 	config := doc.UpdaterConfig{
 		SessionPath:    sessionPath,
 		TranscriptPath: input.TranscriptPath,
-		OutputFile:     "session-overview.md",
-		PromptTemplate: "session-overview-documenter.md",
-		Model:          "haiku",
-		StartLine:      startLine + 1, // Start from next line (1-indexed)
+		OutputFile:     policy.OutputFile,
+		PromptTemplate: filepath.Join(projectRoot, ".claude", "hooks", "prompts", "session-overview-documenter.md"),
+		Model:          policy.Model,
+		Trigger:        string(policy.Kind),
+		HistoryLimit:   policy.HistoryLimit,
+		Validation:     doc.ValidationRulesFor(policy.Validation),
+		Redaction:      doc.RedactionRulesFor(policy.Redaction),
 	}
 
 	if err := h.updater.RunBackground(config); err != nil {
 		_ = h.logger.LogError(fmt.Errorf("failed to start background doc update: %w", err))
 		// Don't fail - log and continue
 	}
-
-	// Send notification
-	title := "Agent Complete"
-	message := fmt.Sprintf("Agent %s finished", input.AgentID)
-	sound := "Glass"
-
-	if err := h.notifier.Send(title, message, sound); err != nil {
-		_ = h.logger.LogError(fmt.Errorf("failed to send notification: %w", err))
-		// Don't fail - notification is nice-to-have
-	}
-
-	return h.allowOutput(), nil
-}
-
-// allowOutput creates a standard "allow" response for SubagentStop events
-func (h *Handler) allowOutput() *shared.HookOutput {
-	return &shared.HookOutput{
-		HookSpecificOutput: shared.HookSpecificOutput{
-			HookEventName:      "SubagentStop",
-			PermissionDecision: "allow",
-		},
-	}
 }
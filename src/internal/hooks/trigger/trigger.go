@@ -0,0 +1,237 @@
+// Package trigger decides when hooks should start a background documentation update.
+// Each trigger (tool-count progress, subagent stop, session end) has its own policy
+// loaded from the project's .claudex/config.toml and the CLAUDEX_AUTODOC_* variables.
+package trigger
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"claudex/internal/services/config"
+	"claudex/internal/services/env"
+	"claudex/internal/services/paths"
+	"claudex/internal/services/session"
+
+	"github.com/spf13/afero"
+)
+
+// Kind identifies a documentation update trigger
+type Kind string
+
+const (
+	Progress   Kind = "progress"
+	Subagent   Kind = "subagent"
+	SessionEnd Kind = "session_end"
+)
+
+// Policy is the effective configuration of a single trigger
+type Policy struct {
+	Kind         Kind
+	Enabled      bool
+	Model        string
+	Frequency    int
+	OutputFile   string
+	HistoryLimit int                       // Document snapshots kept (0 disables history)
+	Validation   config.OverviewValidation // Checks the generated document must pass before it is written
+	Redaction    config.AutodocRedaction   // Masking of transcript entries before they reach the model
+}
+
+// Decision records whether a trigger fired and why
+type Decision struct {
+	Policy Policy
+	Fire   bool
+	Reason string
+}
+
+// String formats the decision for hook logs
+func (d Decision) String() string {
+	if !d.Fire {
+		return fmt.Sprintf("Auto-doc trigger %s: skip (%s)", d.Policy.Kind, d.Reason)
+	}
+	return fmt.Sprintf("Auto-doc trigger %s: fire (%s, model=%s, output=%s)",
+		d.Policy.Kind, d.Reason, d.Policy.Model, d.Policy.OutputFile)
+}
+
+// Resolver loads trigger policies and evaluates events against them
+type Resolver struct {
+	fs  afero.Fs
+	env env.Environment
+}
+
+// New creates a new Resolver instance
+func New(fs afero.Fs, env env.Environment) *Resolver {
+	return &Resolver{fs: fs, env: env}
+}
+
+// Resolve returns the effective policy for a trigger.
+// Values come from <projectRoot>/.claudex/config.toml (defaults when missing or unreadable),
+// then the CLAUDEX_AUTODOC_* variables exported by claudex take precedence.
+func (r *Resolver) Resolve(kind Kind, projectRoot string) (Policy, error) {
+	autodoc := config.DefaultAutodoc()
+	var loadErr error
+	if projectRoot != "" {
+		cfg, err := config.Load(r.fs, filepath.Join(projectRoot, paths.ConfigFile))
+		if err != nil {
+			loadErr = fmt.Errorf("failed to load config: %w", err)
+		} else {
+			autodoc = cfg.Autodoc
+		}
+	}
+
+	var t config.AutodocTrigger
+	switch kind {
+	case Progress:
+		t = autodoc.Progress
+		t.Enabled = r.envBool("CLAUDEX_AUTODOC_SESSION_PROGRESS", t.Enabled)
+		t.Frequency = r.envInt("CLAUDEX_AUTODOC_FREQUENCY", t.Frequency)
+	case Subagent:
+		t = autodoc.Subagent
+	case SessionEnd:
+		t = autodoc.SessionEnd
+		t.Enabled = r.envBool("CLAUDEX_AUTODOC_SESSION_END", t.Enabled)
+	default:
+		return Policy{}, fmt.Errorf("unknown trigger: %s", kind)
+	}
+
+	policy := Policy{
+		Kind:         kind,
+		Enabled:      t.Enabled,
+		Model:        t.Model,
+		Frequency:    t.Frequency,
+		OutputFile:   t.OutputFile,
+		HistoryLimit: autodoc.HistoryLimit,
+		Validation:   autodoc.Validation,
+		Redaction:    autodoc.Redaction,
+	}
+	defaults := defaultPolicy(kind)
+	if policy.Model == "" {
+		policy.Model = defaults.Model
+	}
+	if policy.OutputFile == "" {
+		policy.OutputFile = defaults.OutputFile
+	}
+	if policy.Frequency < 1 {
+		policy.Frequency = 1
+	}
+
+	return policy, loadErr
+}
+
+// Evaluate counts an event against the policy's per-trigger counter in the session
+// folder and decides whether a documentation update should run now.
+// Disabled triggers never touch the counter.
+func (r *Resolver) Evaluate(policy Policy, sessionPath string) (Decision, error) {
+	if !policy.Enabled {
+		return Decision{Policy: policy, Reason: "disabled by config"}, nil
+	}
+
+	counterFile := counterFileFor(policy.Kind)
+	count, err := session.IncrementCounterFile(r.fs, sessionPath, counterFile)
+	if err != nil {
+		return Decision{Policy: policy, Reason: "counter unavailable"}, fmt.Errorf("failed to increment %s counter: %w", policy.Kind, err)
+	}
+
+	decision := Decision{Policy: policy, Reason: fmt.Sprintf("counter %d/%d", count, policy.Frequency)}
+	if count < policy.Frequency {
+		return decision, nil
+	}
+
+	decision.Fire = true
+	if err := session.ResetCounterFile(r.fs, sessionPath, counterFile); err != nil {
+		// Still fire - better to update docs than to skip
+		return decision, fmt.Errorf("failed to reset %s counter: %w", policy.Kind, err)
+	}
+
+	return decision, nil
+}
+
+// CatchUp decides whether the events the policy has counted but not yet documented
+// should be documented now, without counting a new event. It fires only when the
+// counter has reached the policy's frequency, so catching up never documents more
+// often than Evaluate would, and resets it so the next Evaluate starts a fresh count.
+func (r *Resolver) CatchUp(policy Policy, sessionPath string) (Decision, error) {
+	if !policy.Enabled {
+		return Decision{Policy: policy, Reason: "disabled by config"}, nil
+	}
+
+	counterFile := counterFileFor(policy.Kind)
+	count, err := session.ReadCounterFile(r.fs, sessionPath, counterFile)
+	if err != nil {
+		return Decision{Policy: policy, Reason: "counter unavailable"}, fmt.Errorf("failed to read %s counter: %w", policy.Kind, err)
+	}
+	if count == 0 {
+		return Decision{Policy: policy, Reason: "nothing pending"}, nil
+	}
+	if count < policy.Frequency {
+		return Decision{Policy: policy, Reason: fmt.Sprintf("%d/%d pending", count, policy.Frequency)}, nil
+	}
+
+	decision := Decision{Policy: policy, Fire: true, Reason: fmt.Sprintf("catch-up after %d pending", count)}
+	if err := session.ResetCounterFile(r.fs, sessionPath, counterFile); err != nil {
+		return decision, fmt.Errorf("failed to reset %s counter: %w", policy.Kind, err)
+	}
+	return decision, nil
+}
+
+// FindProjectRoot walks up from sessionPath to find the project root (where .claude directory exists)
+func FindProjectRoot(fs afero.Fs, sessionPath string) (string, error) {
+	current := sessionPath
+	for {
+		claudeDir := filepath.Join(current, ".claude")
+		exists, err := afero.DirExists(fs, claudeDir)
+		if err == nil && exists {
+			return current, nil
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			// Reached filesystem root
+			return "", fmt.Errorf("could not find .claude directory in any parent of %s", sessionPath)
+		}
+		current = parent
+	}
+}
+
+// defaultPolicy returns the built-in policy for a trigger
+func defaultPolicy(kind Kind) config.AutodocTrigger {
+	defaults := config.DefaultAutodoc()
+	switch kind {
+	case Subagent:
+		return defaults.Subagent
+	case SessionEnd:
+		return defaults.SessionEnd
+	default:
+		return defaults.Progress
+	}
+}
+
+// counterFileFor returns the session counter file used by a trigger
+func counterFileFor(kind Kind) string {
+	switch kind {
+	case Subagent:
+		return session.SubagentCounterFile
+	case SessionEnd:
+		return session.SessionEndCounterFile
+	default:
+		return session.DocUpdateCounterFile
+	}
+}
+
+// envBool returns the env var value if set, otherwise the default
+func (r *Resolver) envBool(key string, defaultVal bool) bool {
+	if val := r.env.Get(key); val != "" {
+		return val == "true"
+	}
+	return defaultVal
+}
+
+// envInt returns the env var value if set to a valid integer, otherwise the default
+func (r *Resolver) envInt(key string, defaultVal int) int {
+	if val := r.env.Get(key); val != "" {
+		if i, err := strconv.Atoi(val); err == nil {
+			return i
+		}
+	}
+	return defaultVal
+}
@@ -0,0 +1,183 @@
+package trigger
+
+import (
+	"path/filepath"
+	"testing"
+
+	"claudex/internal/services/config"
+	"claudex/internal/services/session"
+	"claudex/internal/testutil"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const projectRoot = "/project"
+
+// writeConfig writes a project config.toml into the test filesystem
+func writeConfig(h *testutil.TestHarness, content string) {
+	h.WriteFile(filepath.Join(projectRoot, ".claudex", "config.toml"), content)
+}
+
+// Test_Resolve_DefaultsWithoutConfig verifies built-in policies apply when no config exists
+func Test_Resolve_DefaultsWithoutConfig(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	resolver := New(h.FS, h.Env)
+
+	// Exercise
+	policy, err := resolver.Resolve(Progress, projectRoot)
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, Policy{Kind: Progress, Enabled: true, Model: "haiku", Frequency: 5, OutputFile: "session-overview.md", HistoryLimit: 20, Validation: config.DefaultOverviewValidation(), Redaction: config.DefaultAutodocRedaction()}, policy)
+}
+
+// Test_Resolve_PerTriggerConfig verifies each trigger reads its own [autodoc.*] section
+func Test_Resolve_PerTriggerConfig(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	writeConfig(h, `[autodoc.subagent]
+enabled = true
+model = "sonnet"
+frequency = 3
+output_file = "agents.md"
+
+[autodoc.session_end]
+enabled = false`)
+	resolver := New(h.FS, h.Env)
+
+	// Exercise
+	subagent, err := resolver.Resolve(Subagent, projectRoot)
+	require.NoError(t, err)
+	sessionEnd, err := resolver.Resolve(SessionEnd, projectRoot)
+	require.NoError(t, err)
+
+	// Verify
+	assert.Equal(t, Policy{Kind: Subagent, Enabled: true, Model: "sonnet", Frequency: 3, OutputFile: "agents.md", HistoryLimit: 20, Validation: config.DefaultOverviewValidation(), Redaction: config.DefaultAutodocRedaction()}, subagent)
+	assert.False(t, sessionEnd.Enabled)
+}
+
+// Test_Resolve_EnvOverridesConfig verifies CLAUDEX_AUTODOC_* variables take precedence over config
+func Test_Resolve_EnvOverridesConfig(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	writeConfig(h, `[features]
+autodoc_session_progress = true
+autodoc_frequency = 5`)
+	h.Env.Set("CLAUDEX_AUTODOC_SESSION_PROGRESS", "false")
+	h.Env.Set("CLAUDEX_AUTODOC_FREQUENCY", "9")
+	resolver := New(h.FS, h.Env)
+
+	// Exercise
+	policy, err := resolver.Resolve(Progress, projectRoot)
+
+	// Verify
+	require.NoError(t, err)
+	assert.False(t, policy.Enabled)
+	assert.Equal(t, 9, policy.Frequency)
+}
+
+// Test_Resolve_InvalidConfigFallsBackToDefaults verifies a broken config returns an error with default policy
+func Test_Resolve_InvalidConfigFallsBackToDefaults(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	writeConfig(h, `[autodoc.progress`)
+	resolver := New(h.FS, h.Env)
+
+	// Exercise
+	policy, err := resolver.Resolve(Progress, projectRoot)
+
+	// Verify
+	require.Error(t, err)
+	assert.True(t, policy.Enabled)
+	assert.Equal(t, 5, policy.Frequency)
+}
+
+// Test_Evaluate_FiresAtFrequencyAndResets verifies the trigger fires every N events using its own counter
+func Test_Evaluate_FiresAtFrequencyAndResets(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	sessionPath := "/project/.claudex/sessions/s1"
+	h.CreateDir(sessionPath)
+	resolver := New(h.FS, h.Env)
+	policy := Policy{Kind: Subagent, Enabled: true, Model: "haiku", Frequency: 2, OutputFile: "session-overview.md"}
+
+	// Exercise
+	first, err := resolver.Evaluate(policy, sessionPath)
+	require.NoError(t, err)
+	second, err := resolver.Evaluate(policy, sessionPath)
+	require.NoError(t, err)
+
+	// Verify
+	assert.False(t, first.Fire)
+	assert.Equal(t, "counter 1/2", first.Reason)
+	assert.True(t, second.Fire)
+
+	count, err := session.ReadCounter(h.FS, sessionPath)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count, "subagent trigger must not touch the progress counter")
+	content, err := afero.ReadFile(h.FS, filepath.Join(sessionPath, session.SubagentCounterFile))
+	require.NoError(t, err)
+	assert.Equal(t, "0", string(content), "subagent counter should reset after firing")
+}
+
+// Test_Evaluate_DisabledDoesNotCount verifies disabled triggers skip without touching counters
+func Test_Evaluate_DisabledDoesNotCount(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	sessionPath := "/project/.claudex/sessions/s1"
+	h.CreateDir(sessionPath)
+	resolver := New(h.FS, h.Env)
+	policy := Policy{Kind: Progress, Enabled: false, Frequency: 1}
+
+	// Exercise
+	decision, err := resolver.Evaluate(policy, sessionPath)
+
+	// Verify
+	require.NoError(t, err)
+	assert.False(t, decision.Fire)
+	assert.Contains(t, decision.String(), "skip (disabled by config)")
+	count, err := session.ReadCounter(h.FS, sessionPath)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+// Test_CatchUp_FiresAtFrequencyOnly verifies catch-up documents counted events without
+// counting one, and only once as many are pending as the frequency requires
+func Test_CatchUp_FiresAtFrequencyOnly(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	sessionPath := "/project/.claudex/sessions/s1"
+	h.CreateDir(sessionPath)
+	resolver := New(h.FS, h.Env)
+	policy := Policy{Kind: Progress, Enabled: true, Model: "haiku", Frequency: 5, OutputFile: "session-overview.md"}
+
+	// Exercise
+	empty, err := resolver.CatchUp(policy, sessionPath)
+	require.NoError(t, err)
+	_, err = resolver.Evaluate(policy, sessionPath)
+	require.NoError(t, err)
+	_, err = resolver.Evaluate(policy, sessionPath)
+	require.NoError(t, err)
+	below, err := resolver.CatchUp(policy, sessionPath)
+	require.NoError(t, err)
+	belowCount, err := session.ReadCounter(h.FS, sessionPath)
+	require.NoError(t, err)
+	policy.Frequency = 2
+	reached, err := resolver.CatchUp(policy, sessionPath)
+	require.NoError(t, err)
+
+	// Verify
+	assert.False(t, empty.Fire)
+	assert.Equal(t, "nothing pending", empty.Reason)
+	assert.False(t, below.Fire)
+	assert.Equal(t, "2/5 pending", below.Reason)
+	assert.Equal(t, 2, belowCount, "pending events below the frequency stay counted")
+	assert.True(t, reached.Fire)
+	assert.Equal(t, "catch-up after 2 pending", reached.Reason)
+	count, err := session.ReadCounter(h.FS, sessionPath)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count, "progress counter should reset after catching up")
+}
@@ -0,0 +1,33 @@
+package userprompt
+
+import (
+	"fmt"
+
+	"claudex/internal/hooks/shared"
+	"claudex/internal/services/env"
+)
+
+// Handler processes UserPromptSubmit events
+type Handler struct {
+	env    env.Environment
+	logger *shared.Logger
+}
+
+// NewHandler creates a new Handler instance
+func NewHandler(env env.Environment, logger *shared.Logger) *Handler {
+	return &Handler{
+		env:    env,
+		logger: logger,
+	}
+}
+
+// Handle logs the submitted prompt's size; the prompt text itself is not logged.
+// Returns nil on success (no JSON output is needed, the prompt proceeds unchanged).
+func (h *Handler) Handle(input *shared.UserPromptSubmitInput) error {
+	if h.env.Get("CLAUDE_HOOK_INTERNAL") == "1" {
+		return nil
+	}
+
+	_ = h.logger.LogInfo(fmt.Sprintf("UserPromptSubmit: %d characters", len([]rune(input.Prompt))))
+	return nil
+}
@@ -1,21 +1,28 @@
 package app
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"claudex"
+	"claudex/internal/doc"
 	"claudex/internal/services/config"
+	"claudex/internal/services/git"
+	"claudex/internal/services/hooksetup"
+	"claudex/internal/services/llm"
 	"claudex/internal/services/mcpconfig"
 	"claudex/internal/services/paths"
 	"claudex/internal/services/profile"
 	"claudex/internal/services/session"
 	createindexuc "claudex/internal/usecases/createindex"
 	migrateuc "claudex/internal/usecases/migrate"
+	overviewuc "claudex/internal/usecases/overview"
 	setupuc "claudex/internal/usecases/setup"
 	setuphookuc "claudex/internal/usecases/setuphook"
 	setupmcpuc "claudex/internal/usecases/setupmcp"
@@ -37,11 +44,11 @@ const (
 
 // SessionInfo holds session state passed between methods
 type SessionInfo struct {
-	Name         string
-	Path         string
-	ClaudeID     string
-	Mode         LaunchMode
-	OriginalName string // For fork/fresh operations
+	Name         string     `json:"name"`
+	Path         string     `json:"path"`
+	ClaudeID     string     `json:"claude_session_id"`
+	Mode         LaunchMode `json:"mode"`
+	OriginalName string     `json:"original_name,omitempty"` // For fork/fresh operations
 }
 
 // App is the main application container
@@ -53,48 +60,49 @@ type App struct {
 	docPaths        []string
 	noOverwrite     bool
 	updateDocs      bool
+	updateDocsScope git.Scope
 	setupMCP        bool
 	createIndex     string
+	offline         bool
+	force           bool
 	logFile         afero.File
 	logFilePath     string
 	version         string
 	showVersion     *bool
 	noOverwriteFlag *bool
 	updateDocsFlag  *bool
+	stagedFlag      *bool
+	worktreeFlag    *bool
 	setupMCPFlag    *bool
 	createIndexFlag *string
+	offlineFlag     *bool
+	forceFlag       *bool
 	docPathsFlag    []string
 }
 
 // New creates a new App instance with production dependencies
-func New(version string, showVersion *bool, noOverwrite *bool, updateDocs *bool, setupMCP *bool, createIndex *string, docPaths []string) *App {
+func New(version string, showVersion *bool, noOverwrite *bool, updateDocs *bool, staged *bool, worktree *bool, setupMCP *bool, createIndex *string, offline *bool, force *bool, docPaths []string) *App {
 	return &App{
 		deps:            NewDependencies(),
 		version:         version,
 		showVersion:     showVersion,
 		noOverwriteFlag: noOverwrite,
 		updateDocsFlag:  updateDocs,
+		stagedFlag:      staged,
+		worktreeFlag:    worktree,
 		setupMCPFlag:    setupMCP,
 		createIndexFlag: createIndex,
+		offlineFlag:     offline,
+		forceFlag:       force,
 		docPathsFlag:    docPaths,
 	}
 }
 
 // Init initializes the application (parse flags, load config, setup logging)
 func (a *App) Init() error {
-	// Run migration to ensure .claudex/ folder exists and migrate legacy artifacts
-	migrator := migrateuc.New(a.deps.FS)
-	if err := migrator.Run(); err != nil {
-		return fmt.Errorf("migration failed: %w", err)
-	}
-
-	// Load config file from new location (after migration)
-	cfg, err := config.Load(a.deps.FS, paths.ConfigFile)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: Failed to load config: %v\n", err)
-		cfg = &config.Config{Doc: []string{}, NoOverwrite: false}
+	if err := a.loadConfig(); err != nil {
+		return err
 	}
-	a.cfg = cfg
 
 	flag.Parse()
 
@@ -104,20 +112,102 @@ func (a *App) Init() error {
 	}
 
 	// Apply precedence: CLI flags > config > defaults
-	if !isFlagSet("doc") && len(cfg.Doc) > 0 {
-		a.docPaths = cfg.Doc
+	if !isFlagSet("doc") && len(a.cfg.Doc) > 0 {
+		a.docPaths = a.cfg.Doc
 	} else {
 		a.docPaths = a.docPathsFlag
 	}
-	if !isFlagSet("no-overwrite") && cfg.NoOverwrite {
-		a.noOverwrite = cfg.NoOverwrite
+	if !isFlagSet("no-overwrite") && a.cfg.NoOverwrite {
+		a.noOverwrite = a.cfg.NoOverwrite
 	} else {
 		a.noOverwrite = *a.noOverwriteFlag
 	}
 	a.updateDocs = *a.updateDocsFlag
+	scope, err := updateDocsScope(a.updateDocs, a.stagedFlag, a.worktreeFlag)
+	if err != nil {
+		return err
+	}
+	a.updateDocsScope = scope
 	a.setupMCP = *a.setupMCPFlag
 	a.createIndex = *a.createIndexFlag
+	a.offline = a.offlineFlag != nil && *a.offlineFlag
+	if a.offline && a.createIndex == "" {
+		return fmt.Errorf("--offline only applies to --create-index")
+	}
+	a.force = a.forceFlag != nil && *a.forceFlag
+	if a.force && !a.offline {
+		return fmt.Errorf("--force only applies to --create-index --offline")
+	}
+
+	if err := a.initProjectDirs(); err != nil {
+		return err
+	}
+
+	if err := a.setupClaudeDir(); err != nil {
+		return err
+	}
+
+	a.setupLogging()
+
+	return nil
+}
+
+// updateDocsScope maps --staged and --worktree to the uncommitted changes --update-docs
+// works on; "" keeps the committed range
+func updateDocsScope(updateDocs bool, staged, worktree *bool) (git.Scope, error) {
+	isStaged := staged != nil && *staged
+	isWorktree := worktree != nil && *worktree
+	switch {
+	case isStaged && isWorktree:
+		return "", fmt.Errorf("--staged and --worktree cannot be combined")
+	case (isStaged || isWorktree) && !updateDocs:
+		return "", fmt.Errorf("--staged and --worktree only apply to --update-docs")
+	case isStaged:
+		return git.ScopeStaged, nil
+	case isWorktree:
+		return git.ScopeWorktree, nil
+	default:
+		return "", nil
+	}
+}
+
+// loadConfig runs the .claudex/ migration and loads the project configuration
+func (a *App) loadConfig() error {
+	// Run migration to ensure .claudex/ folder exists and migrate legacy artifacts
+	migrator := migrateuc.New(a.deps.FS)
+	if err := migrator.Run(); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+
+	// Load config file from new location (after migration)
+	cfg, err := config.Load(a.deps.FS, paths.ConfigFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to load config: %v\n", err)
+		cfg = &config.Config{Doc: []string{}, NoOverwrite: false, Autodoc: config.DefaultAutodoc(), Docs: config.DefaultDocs(), LLM: config.DefaultLLM()}
+	}
+	a.cfg = cfg
+
+	return nil
+}
+
+// newLLMClient builds the model backend from the [llm] config section,
+// falling back to the Claude CLI when the configured backend is unusable
+func (a *App) newLLMClient() llm.Client {
+	cfg := config.DefaultLLM()
+	if a.cfg != nil {
+		cfg = a.cfg.LLM
+	}
+
+	client, err := llm.New(cfg, a.deps.Cmd, a.deps.Env)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v; falling back to the Claude CLI\n", err)
+		return llm.NewCLI(a.deps.Cmd, a.deps.Env, cfg.Model, time.Duration(cfg.TimeoutSeconds)*time.Second)
+	}
+	return client
+}
 
+// initProjectDirs resolves the project directory and ensures the sessions directory exists
+func (a *App) initProjectDirs() error {
 	projectDir, err := os.Getwd()
 	if err != nil {
 		return fmt.Errorf("failed to get working directory: %w", err)
@@ -125,14 +215,27 @@ func (a *App) Init() error {
 	a.projectDir = projectDir
 	a.sessionsDir = filepath.Join(projectDir, paths.SessionsDir)
 
-	// Ensure .claude directory is set up using setup usecase
+	// Create sessions directory
+	if err := a.deps.FS.MkdirAll(a.sessionsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create sessions directory: %w", err)
+	}
+
+	return nil
+}
+
+// setupClaudeDir ensures the .claude directory is set up using the setup usecase
+func (a *App) setupClaudeDir() error {
 	setupUC := setupuc.New(a.deps.FS, a.deps.Env)
-	if err := setupUC.Execute(projectDir, a.noOverwrite); err != nil {
+	if err := setupUC.Execute(a.projectDir, a.noOverwrite); err != nil {
 		return fmt.Errorf("failed to setup .claude directory: %w", err)
 	}
+	return nil
+}
 
-	// Setup centralized logging
-	logsDir := filepath.Join(projectDir, paths.LogsDir)
+// setupLogging creates a unique log file for this execution and points the
+// Go logger and CLAUDEX_LOG_FILE at it
+func (a *App) setupLogging() {
+	logsDir := filepath.Join(a.projectDir, paths.LogsDir)
 	if err := a.deps.FS.MkdirAll(logsDir, 0755); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: Could not create logs directory: %v\n", err)
 	}
@@ -146,26 +249,20 @@ func (a *App) Init() error {
 	logFile, err := a.deps.FS.OpenFile(logFilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: Could not open log file: %v\n", err)
-	} else {
-		a.logFile = logFile
-		a.logFilePath = logFilePath
-		// Configure Go logger with [claudex] prefix
-		log.SetOutput(logFile)
-		log.SetPrefix("[claudex] ")
-		log.SetFlags(log.Ldate | log.Ltime | log.Lmicroseconds)
-
-		// Set environment variable for hooks
-		a.deps.Env.Set("CLAUDEX_LOG_FILE", logFilePath)
-
-		log.Printf("Claudex started (log file: %s)", logFileName)
+		return
 	}
 
-	// Create sessions directory
-	if err := a.deps.FS.MkdirAll(a.sessionsDir, 0755); err != nil {
-		return fmt.Errorf("failed to create sessions directory: %w", err)
-	}
+	a.logFile = logFile
+	a.logFilePath = logFilePath
+	// Configure Go logger with [claudex] prefix
+	log.SetOutput(logFile)
+	log.SetPrefix("[claudex] ")
+	log.SetFlags(log.Ldate | log.Ltime | log.Lmicroseconds)
 
-	return nil
+	// Set environment variable for hooks
+	a.deps.Env.Set("CLAUDEX_LOG_FILE", logFilePath)
+
+	log.Printf("Claudex started (log file: %s)", logFileName)
 }
 
 // Close cleans up resources (close log file)
@@ -234,6 +331,11 @@ func (a *App) renameLogFileForSession(si SessionInfo) {
 
 // Run executes the main application logic
 func (a *App) Run() error {
+	// Offline --create-index needs neither the Claude CLI nor a model (air-gapped CI)
+	if a.createIndex != "" && a.offline {
+		return createindexuc.New(a.deps.FS, nil, a.deps.Env).ExecuteOffline(a.createIndex, a.force)
+	}
+
 	// Check if Claude CLI is installed
 	if !a.isClaudeInstalled() {
 		fmt.Println("\n❌ Claude Code CLI not found")
@@ -263,13 +365,16 @@ func (a *App) Run() error {
 
 	// Early exit for --update-docs mode
 	if a.updateDocs {
-		uc := updatedocsuc.New(a.deps.FS, a.deps.Cmd, a.deps.Env)
+		uc := updatedocsuc.New(a.deps.FS, a.deps.Cmd, a.newLLMClient(), a.deps.Env)
+		if a.updateDocsScope != "" {
+			return uc.ExecutePending(a.projectDir, a.updateDocsScope)
+		}
 		return uc.Execute(a.projectDir)
 	}
 
 	// Early exit for --create-index mode
 	if a.createIndex != "" {
-		uc := createindexuc.New(a.deps.FS, a.deps.Cmd, a.deps.Env)
+		uc := createindexuc.New(a.deps.FS, a.newLLMClient(), a.deps.Env)
 		return uc.Execute(a.createIndex)
 	}
 
@@ -339,6 +444,11 @@ func (a *App) Run() error {
 		return err
 	}
 
+	// Offer to document work a killed SessionEnd hook never recorded
+	if si.Mode == LaunchModeResume {
+		a.promptOverviewCatchUp(si)
+	}
+
 	// Rename log file to match session (skip for ephemeral)
 	a.renameLogFileForSession(si)
 
@@ -364,7 +474,11 @@ func (a *App) promptHookSetup() {
 
 	switch strings.ToLower(strings.TrimSpace(response)) {
 	case "y", "yes":
-		if err := uc.Install(); err != nil {
+		if err := uc.Install(); errors.Is(err, hooksetup.ErrHookManager) {
+			manager := hooksetup.New(a.deps.FS, a.projectDir, a.deps.Cmd).Status().Manager
+			fmt.Printf("○ Git hooks are managed by %s. Add this to enable auto-docs:\n\n%s", manager,
+				hooksetup.Snippet(manager, []hooksetup.Trigger{hooksetup.TriggerPostCommit}))
+		} else if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: Could not install hook: %v\n", err)
 		} else {
 			fmt.Println("✓ Git hook installed. Docs will auto-update after commits.")
@@ -373,13 +487,60 @@ func (a *App) promptHookSetup() {
 		if err := uc.SaveDeclined(); err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: Could not save preference: %v\n", err)
 		}
-		fmt.Println("○ Won't ask again. Run 'claudex --setup-hook' to enable later.")
+		fmt.Println("○ Won't ask again. Run 'claudex hooks git install' to enable later.")
 	default:
 		fmt.Println("○ Skipped for now.")
 	}
 	fmt.Println()
 }
 
+// promptOverviewCatchUp offers to document transcript entries no background update
+// processed (e.g. the SessionEnd hook was killed) before a session is resumed
+func (a *App) promptOverviewCatchUp(si SessionInfo) {
+	autodoc := config.DefaultAutodoc()
+	if a.cfg != nil {
+		autodoc = a.cfg.Autodoc
+	}
+	if !autodoc.Progress.Enabled && !autodoc.SessionEnd.Enabled {
+		return // Overview is not maintained automatically
+	}
+
+	flags := overviewFlags{file: autodoc.SessionEnd.OutputFile, chunk: overviewuc.DefaultChunkEntries}
+	if flags.file == "" {
+		flags.file = doc.DefaultOutputFile
+	}
+	uc := a.overviewUseCase()
+	opts := a.overviewOptions(si.Name, si.Path, flags)
+
+	tails, err := uc.Pending(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not check the session overview: %v\n", err)
+		return
+	}
+	if len(tails) == 0 {
+		return
+	}
+
+	pending := overviewuc.Result{Transcripts: tails}.Entries()
+	fmt.Printf("\n📝 %d transcript entries are not in %s yet. Catch up before launching? [y/n]: ", pending, flags.file)
+
+	var response string
+	fmt.Scanln(&response)
+
+	switch strings.ToLower(strings.TrimSpace(response)) {
+	case "y", "yes":
+		fmt.Println("Updating session overview...")
+		if result, err := uc.Update(opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Could not update the session overview: %v\n", err)
+		} else {
+			fmt.Printf("✓ Documented %d entries in %s\n", result.Entries(), flags.file)
+		}
+	default:
+		fmt.Println("○ Skipped. Run 'claudex overview update' to catch up later.")
+	}
+	fmt.Println()
+}
+
 // promptMCPSetup checks if we should offer MCP configuration
 func (a *App) promptMCPSetup() {
 	uc := setupmcpuc.New(a.deps.FS)
@@ -4,6 +4,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"claudex/internal/services/git"
 	"claudex/internal/testutil"
 
 	"github.com/spf13/afero"
@@ -709,3 +710,36 @@ func TestInit_MigrationIdempotent(t *testing.T) {
 	require.NoError(t, err)
 	assert.True(t, exists, ".claudex/sessions should still exist")
 }
+
+// TestUpdateDocsScope verifies --staged and --worktree select the uncommitted changes --update-docs works on
+func TestUpdateDocsScope(t *testing.T) {
+	on, off := true, false
+	tests := []struct {
+		name       string
+		updateDocs bool
+		staged     *bool
+		worktree   *bool
+		want       git.Scope
+		wantErr    string
+	}{
+		{"committed range", true, &off, &off, "", ""},
+		{"flags not wired", true, nil, nil, "", ""},
+		{"staged", true, &on, &off, git.ScopeStaged, ""},
+		{"worktree", true, &off, &on, git.ScopeWorktree, ""},
+		{"both", true, &on, &on, "", "cannot be combined"},
+		{"without --update-docs", false, &on, &off, "", "only apply to --update-docs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scope, err := updateDocsScope(tt.updateDocs, tt.staged, tt.worktree)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, scope)
+		})
+	}
+}
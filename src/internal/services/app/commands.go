@@ -0,0 +1,66 @@
+package app
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// RunCommand executes a scriptable subcommand (e.g. `claudex session list`)
+// without going through the interactive session selector
+func (a *App) RunCommand(name string, args []string) error {
+	if err := a.loadConfig(); err != nil {
+		return err
+	}
+	a.docPaths = a.cfg.Doc
+	a.noOverwrite = a.cfg.NoOverwrite
+
+	if err := a.initProjectDirs(); err != nil {
+		return err
+	}
+
+	switch name {
+	case "session":
+		return a.runSessionCommand(args, os.Stdout)
+	case "jobs":
+		return a.runJobsCommand(args, os.Stdout)
+	case "overview":
+		return a.runOverviewCommand(args, os.Stdout)
+	case "docs":
+		return a.runDocsCommand(args, os.Stdout)
+	case "hooks":
+		return a.runHooksCommand(args, os.Stdout)
+	default:
+		return fmt.Errorf("unknown command: %s (run 'claudex --help' for usage)", name)
+	}
+}
+
+// parseInterspersed parses flags that may appear before, between or after
+// positional arguments, returning the positional arguments in order.
+// The standard flag package stops at the first non-flag argument.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// writeJSON writes v as indented JSON followed by a newline
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("failed to encode JSON output: %w", err)
+	}
+	return nil
+}
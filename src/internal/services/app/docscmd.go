@@ -0,0 +1,331 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"claudex/internal/doc/rangeupdater"
+	"claudex/internal/services/config"
+	"claudex/internal/services/doctracking"
+	"claudex/internal/services/git"
+	"claudex/internal/services/paths"
+	"claudex/internal/usecases/docscheck"
+)
+
+const docsUsage = `Usage: claudex docs <command> [flags] [args]
+
+Commands:
+  check                     Fail when an index.md lags behind the code it documents or a
+                            large directory has none (offline, for CI)
+  tracking [list]           Show the commit --update-docs last processed on each branch
+  tracking reset [branch]   Forget a branch's entry (the current branch when omitted); the next
+                            run starts it again from its merge-base with the default branch
+  tracking prune            Drop the entries of branches that no longer exist
+
+Flags:
+  --max-commits <n>    Commits to documented files an index may lag behind (check; 0 disables)
+  --max-age-days <n>   Days between an index's last commit and the newest change it documents (check; 0 disables)
+  --min-files <n>      Files that make a directory need an index.md (check; 0 disables)
+  --sarif              Print the check as a SARIF log (check)
+  --all                Forget every branch (tracking reset)
+  --json               Print machine-readable JSON
+`
+
+// TrackingEntry is the scriptable view of a branch's doc tracking printed by `docs tracking`
+type TrackingEntry struct {
+	Branch  string `json:"branch"`
+	Current bool   `json:"current"`
+	doctracking.DocUpdateTracking
+}
+
+// docsFlags holds the flags shared by the docs subcommands
+type docsFlags struct {
+	json       bool
+	sarif      bool
+	all        bool
+	maxCommits int
+	maxAgeDays int
+	minFiles   int
+}
+
+// runDocsCommand dispatches `claudex docs <subcommand>` to its handler
+func (a *App) runDocsCommand(args []string, out io.Writer) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(os.Stderr, docsUsage)
+		if len(args) == 0 {
+			return fmt.Errorf("missing docs subcommand")
+		}
+		return nil
+	}
+
+	check := config.DefaultDocsCheck()
+	if a.cfg != nil {
+		check = a.cfg.Docs.Check
+	}
+
+	fset := flag.NewFlagSet("docs "+args[0], flag.ContinueOnError)
+	fset.SetOutput(os.Stderr)
+	fset.Usage = func() { fmt.Fprint(os.Stderr, docsUsage) }
+
+	var flags docsFlags
+	fset.BoolVar(&flags.json, "json", false, "Print machine-readable JSON")
+	fset.BoolVar(&flags.sarif, "sarif", false, "Print the check as a SARIF log")
+	fset.BoolVar(&flags.all, "all", false, "Forget every branch")
+	fset.IntVar(&flags.maxCommits, "max-commits", check.MaxCommits, "Commits an index may lag behind")
+	fset.IntVar(&flags.maxAgeDays, "max-age-days", check.MaxAgeDays, "Days an index may lag behind")
+	fset.IntVar(&flags.minFiles, "min-files", check.MinFiles, "Files that make a directory need an index.md")
+
+	positional, err := parseInterspersed(fset, args[1:])
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "check":
+		if len(positional) > 0 {
+			return fmt.Errorf("usage: claudex docs check [--json|--sarif]")
+		}
+		return a.docsCheck(out, flags)
+	case "tracking":
+		return a.runDocsTracking(out, flags, positional)
+	default:
+		fmt.Fprint(os.Stderr, docsUsage)
+		return fmt.Errorf("unknown docs subcommand: %s", args[0])
+	}
+}
+
+// runDocsTracking dispatches `claudex docs tracking <subcommand>` to its handler
+func (a *App) runDocsTracking(out io.Writer, flags docsFlags, positional []string) error {
+	sub := "list"
+	if len(positional) > 0 {
+		sub, positional = positional[0], positional[1:]
+	}
+
+	switch sub {
+	case "list":
+		if len(positional) > 0 {
+			return fmt.Errorf("usage: claudex docs tracking list")
+		}
+		return a.docsTrackingList(out, flags)
+	case "reset":
+		if len(positional) > 1 || (flags.all && len(positional) > 0) {
+			return fmt.Errorf("usage: claudex docs tracking reset [branch] | --all")
+		}
+		branch := ""
+		if len(positional) == 1 {
+			branch = positional[0]
+		}
+		return a.docsTrackingReset(out, flags, branch)
+	case "prune":
+		if len(positional) > 0 {
+			return fmt.Errorf("usage: claudex docs tracking prune")
+		}
+		return a.docsTrackingPrune(out, flags)
+	default:
+		fmt.Fprint(os.Stderr, docsUsage)
+		return fmt.Errorf("unknown docs tracking subcommand: %s", sub)
+	}
+}
+
+// docsCheck reports stale and missing index.md files and fails when there are any
+func (a *App) docsCheck(out io.Writer, flags docsFlags) error {
+	update := config.DefaultDocsUpdate()
+	if a.cfg != nil {
+		update = a.cfg.Docs.Update
+	}
+
+	report, err := docscheck.New(a.deps.FS, a.deps.Cmd).Execute(docscheck.Options{
+		ProjectDir: a.projectDir,
+		MaxCommits: flags.maxCommits,
+		MaxAge:     time.Duration(flags.maxAgeDays) * 24 * time.Hour,
+		MinFiles:   flags.minFiles,
+		Include:    update.Include,
+		Exclude:    update.Exclude,
+	})
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case flags.sarif:
+		err = writeJSON(out, report.SARIF())
+	case flags.json:
+		err = writeJSON(out, report)
+	default:
+		printDocsCheck(out, report)
+	}
+	if err != nil {
+		return err
+	}
+
+	if report.Failed() {
+		return fmt.Errorf("documentation check failed: %d stale, %d missing index.md file(s)", len(report.Stale), len(report.Missing))
+	}
+	return nil
+}
+
+// printDocsCheck prints a check report for humans
+func printDocsCheck(out io.Writer, report *docscheck.Report) {
+	for _, stale := range report.Stale {
+		fmt.Fprintf(out, "✗ %s is stale: %s\n", stale.Index, strings.Join(stale.Reasons, "; "))
+		fmt.Fprintf(out, "    last updated %s (%s), newest change %s (%s)\n",
+			shortSHA(stale.IndexCommit), stale.IndexUpdatedAt.Format("2006-01-02"),
+			shortSHA(stale.LatestCommit), stale.LatestAt.Format("2006-01-02"))
+		files := stale.Files
+		more := ""
+		if len(files) > 5 {
+			files, more = files[:5], fmt.Sprintf(" (+%d more)", len(stale.Files)-5)
+		}
+		fmt.Fprintf(out, "    changed: %s%s\n", strings.Join(files, ", "), more)
+	}
+	for _, missing := range report.Missing {
+		covered := "no index.md above it"
+		if missing.CoveredBy != "" {
+			covered = "covered only by " + missing.CoveredBy
+		}
+		fmt.Fprintf(out, "✗ %s has %d files and no index.md (%s)\n", missing.Dir, missing.Files, covered)
+	}
+
+	if !report.Failed() {
+		fmt.Fprintf(out, "✓ %d index.md file(s) up to date\n", report.Checked)
+		return
+	}
+	fmt.Fprintf(out, "Checked %d index.md file(s): %d stale, %d missing\n", report.Checked, len(report.Stale), len(report.Missing))
+}
+
+// shortSHA abbreviates a commit SHA for display
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// docsTracking returns the tracking store used by --update-docs. Tracking written
+// before it was per branch belongs to the default branch: [docs.update] default_branch,
+// else the detected one.
+func (a *App) docsTracking() doctracking.TrackingService {
+	defaultBranch := ""
+	if a.cfg != nil {
+		defaultBranch = a.cfg.Docs.Update.DefaultBranch
+	}
+	if defaultBranch == "" {
+		// Outside a git repo the store falls back to "main"
+		defaultBranch, _ = git.New(a.deps.Cmd).GetDefaultBranch()
+	}
+	return doctracking.New(a.deps.FS, filepath.Join(a.projectDir, paths.ClaudexDir), defaultBranch)
+}
+
+// docsTrackingList prints every branch's entry, sorted by branch name
+func (a *App) docsTrackingList(out io.Writer, flags docsFlags) error {
+	entries, err := a.docsTracking().List()
+	if err != nil {
+		return fmt.Errorf("failed to read doc tracking: %w", err)
+	}
+	// The current branch only decorates the output; outside a git repo it stays empty
+	current, _ := git.New(a.deps.Cmd).GetCurrentBranch()
+
+	list := make([]TrackingEntry, 0, len(entries))
+	for branch, tracking := range entries {
+		list = append(list, TrackingEntry{Branch: branch, Current: branch == current, DocUpdateTracking: tracking})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Branch < list[j].Branch })
+
+	if flags.json {
+		return writeJSON(out, list)
+	}
+
+	if len(list) == 0 {
+		fmt.Fprintln(out, "No branches tracked yet; run 'claudex --update-docs' to start")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "\tBRANCH\tCOMMIT\tUPDATED\tFAILED")
+	for _, entry := range list {
+		marker := ""
+		if entry.Current {
+			marker = "*"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\n", marker, entry.Branch, shortSHA(entry.LastProcessedCommit), entry.UpdatedAt, len(entry.FailedIndexes))
+	}
+	return tw.Flush()
+}
+
+// docsTrackingReset forgets the entry of one branch (the current one by default) or of all branches
+func (a *App) docsTrackingReset(out io.Writer, flags docsFlags, branch string) error {
+	store := a.docsTracking()
+
+	var branches []string
+	switch {
+	case flags.all:
+		entries, err := store.List()
+		if err != nil {
+			return fmt.Errorf("failed to read doc tracking: %w", err)
+		}
+		for name := range entries {
+			branches = append(branches, name)
+		}
+		sort.Strings(branches)
+	case branch != "":
+		branches = []string{branch}
+	default:
+		current, err := git.New(a.deps.Cmd).GetCurrentBranch()
+		if err != nil {
+			return fmt.Errorf("failed to get current branch: %w", err)
+		}
+		if current == "" {
+			return fmt.Errorf("detached HEAD; name the branch to reset")
+		}
+		branches = []string{current}
+	}
+
+	for _, name := range branches {
+		if err := store.Delete(name); err != nil {
+			return fmt.Errorf("failed to reset %s: %w", name, err)
+		}
+	}
+
+	if flags.json {
+		return writeJSON(out, map[string][]string{"reset": nonNil(branches)})
+	}
+	for _, name := range branches {
+		fmt.Fprintf(out, "✓ Reset doc tracking for %s\n", name)
+	}
+	return nil
+}
+
+// docsTrackingPrune drops the entries of branches that no longer exist locally
+func (a *App) docsTrackingPrune(out io.Writer, flags docsFlags) error {
+	pruned, err := rangeupdater.PruneTracking(git.New(a.deps.Cmd), a.docsTracking())
+	if err != nil {
+		return err
+	}
+
+	if flags.json {
+		return writeJSON(out, map[string][]string{"pruned": nonNil(pruned)})
+	}
+	if len(pruned) == 0 {
+		fmt.Fprintln(out, "Every tracked branch still exists")
+		return nil
+	}
+	for _, name := range pruned {
+		fmt.Fprintf(out, "✓ Dropped doc tracking for deleted branch %s\n", name)
+	}
+	return nil
+}
+
+// nonNil returns an empty slice for nil so JSON output prints [] instead of null
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
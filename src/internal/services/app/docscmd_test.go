@@ -0,0 +1,165 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"claudex/internal/services/doctracking"
+	"claudex/internal/services/paths"
+	"claudex/internal/testutil"
+	"claudex/internal/usecases/docscheck"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDocsApp creates an app whose doc tracking has entries for main and feature/login
+func newDocsApp(t *testing.T, h *testutil.TestHarness) (*App, doctracking.TrackingService) {
+	t.Helper()
+	app := newSessionCommandApp(h, "/project/.claudex/sessions")
+	store := app.docsTracking()
+	require.NoError(t, store.Write("main", doctracking.DocUpdateTracking{LastProcessedCommit: "aaaaaaaaaaaa", UpdatedAt: "2024-06-01T10:00:00Z"}))
+	require.NoError(t, store.Write("feature/login", doctracking.DocUpdateTracking{
+		LastProcessedCommit: "bbbbbbbbbbbb",
+		UpdatedAt:           "2024-06-02T10:00:00Z",
+		FailedIndexes:       map[string]string{"/project/src/index.md": "aaaaaaaaaaaa"},
+	}))
+	h.Commander.OnPattern("git", "--abbrev-ref").Return([]byte("feature/login\n"), nil)
+	return app, store
+}
+
+// TestDocsCommand_TrackingListJSON verifies entries are listed by branch with the current one flagged
+func TestDocsCommand_TrackingListJSON(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	app, _ := newDocsApp(t, h)
+
+	// Exercise
+	var out bytes.Buffer
+	err := app.runDocsCommand([]string{"tracking", "--json"}, &out)
+
+	// Verify
+	require.NoError(t, err)
+	var entries []TrackingEntry
+	require.NoError(t, json.Unmarshal(out.Bytes(), &entries))
+	require.Len(t, entries, 2)
+	assert.Equal(t, "feature/login", entries[0].Branch)
+	assert.True(t, entries[0].Current)
+	assert.Len(t, entries[0].FailedIndexes, 1)
+	assert.Equal(t, "main", entries[1].Branch)
+	assert.False(t, entries[1].Current)
+}
+
+// TestDocsCommand_TrackingResetCurrentBranch verifies reset defaults to the checked-out branch
+func TestDocsCommand_TrackingResetCurrentBranch(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	app, store := newDocsApp(t, h)
+
+	// Exercise
+	var out bytes.Buffer
+	err := app.runDocsCommand([]string{"tracking", "reset"}, &out)
+
+	// Verify
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "Reset doc tracking for feature/login")
+	entries, err := store.List()
+	require.NoError(t, err)
+	assert.Contains(t, entries, "main")
+	assert.NotContains(t, entries, "feature/login")
+}
+
+// TestDocsCommand_TrackingResetAll verifies --all forgets every branch
+func TestDocsCommand_TrackingResetAll(t *testing.T) {
+	h := testutil.NewTestHarness()
+	app, store := newDocsApp(t, h)
+
+	var out bytes.Buffer
+	err := app.runDocsCommand([]string{"tracking", "reset", "--all"}, &out)
+
+	require.NoError(t, err)
+	entries, err := store.List()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+// TestDocsCommand_TrackingResetAllClearsLegacyState verifies --all also forgets tracking
+// written before it was per branch
+func TestDocsCommand_TrackingResetAllClearsLegacyState(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	app := newSessionCommandApp(h, "/project/.claudex/sessions")
+	h.WriteFile(filepath.Join(app.projectDir, paths.ClaudexDir, "doc_update_tracking.json"), `{"last_processed_commit":"legacy1","strategy_version":"v1"}`)
+	h.Commander.OnPattern("git", "symbolic-ref").Return([]byte("origin/develop\n"), nil)
+
+	// Exercise
+	var out bytes.Buffer
+	err := app.runDocsCommand([]string{"tracking", "reset", "--all"}, &out)
+
+	// Verify
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "Reset doc tracking for develop")
+	entries, err := app.docsTracking().List()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	tracking, err := app.docsTracking().Read("develop")
+	require.NoError(t, err)
+	assert.Empty(t, tracking.LastProcessedCommit)
+}
+
+// TestDocsCommand_TrackingPrune verifies entries of deleted branches are dropped
+func TestDocsCommand_TrackingPrune(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	app, store := newDocsApp(t, h)
+	h.Commander.OnPattern("git", "for-each-ref").Return([]byte("main\n"), nil)
+
+	// Exercise
+	var out bytes.Buffer
+	err := app.runDocsCommand([]string{"tracking", "prune", "--json"}, &out)
+
+	// Verify
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"pruned":["feature/login"]}`, out.String())
+	entries, err := store.List()
+	require.NoError(t, err)
+	assert.Contains(t, entries, "main")
+	assert.Len(t, entries, 1)
+}
+
+// TestDocsCommand_CheckFailsWithReport verifies findings are printed as JSON and fail the command
+func TestDocsCommand_CheckFailsWithReport(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	app := newSessionCommandApp(h, "/project/.claudex/sessions")
+	h.WriteFile(filepath.Join(app.projectDir, "index.md"), "# Project")
+	h.Commander.OnPattern("git", "rev-parse", "HEAD").Return([]byte("head\n"), nil)
+	h.Commander.OnPattern("git", "ls-files").Return([]byte("index.md\npkg/a.go\npkg/b.go\n"), nil)
+
+	// Exercise
+	var out bytes.Buffer
+	err := app.runDocsCommand([]string{"check", "--json", "--min-files", "2"}, &out)
+
+	// Verify
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "0 stale, 1 missing")
+	var report docscheck.Report
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.Equal(t, 1, report.Checked)
+	require.Len(t, report.Missing, 1)
+	assert.Equal(t, "pkg", report.Missing[0].Dir)
+	assert.Equal(t, "index.md", report.Missing[0].CoveredBy)
+}
+
+// TestDocsCommand_UnknownSubcommand verifies typos are reported
+func TestDocsCommand_UnknownSubcommand(t *testing.T) {
+	h := testutil.NewTestHarness()
+	app := newSessionCommandApp(h, "/project/.claudex/sessions")
+
+	err := app.runDocsCommand([]string{"tracking", "wipe"}, &bytes.Buffer{})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown docs tracking subcommand")
+}
@@ -0,0 +1,238 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"claudex/internal/services/hooksetup"
+)
+
+const hooksUsage = `Usage: claudex hooks git <command> [flags]
+
+Commands:
+  install     Add the claudex block to the git hooks so commits run --update-docs
+  uninstall   Remove the claudex block, deleting hooks that held nothing else
+  status      Show where git runs hooks from and the state of every claudex hook
+
+Flags:
+  --trigger <list>     Comma-separated hooks: post-commit, post-merge, post-rewrite or all
+                       (install defaults to post-commit; uninstall and status to all)
+  --print <manager>    Print the snippet for husky or lefthook instead of writing hooks (install)
+  --force              Write to the hooks directory even when husky or lefthook manages it (install)
+  --json               Print machine-readable JSON
+`
+
+// hooksFlags holds the flags of the hooks subcommands
+type hooksFlags struct {
+	trigger string
+	print   string
+	force   bool
+	json    bool
+}
+
+// runHooksCommand dispatches `claudex hooks git <subcommand>` to its handler
+func (a *App) runHooksCommand(args []string, out io.Writer) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(os.Stderr, hooksUsage)
+		if len(args) == 0 {
+			return fmt.Errorf("missing hooks subcommand")
+		}
+		return nil
+	}
+	if args[0] != "git" {
+		fmt.Fprint(os.Stderr, hooksUsage)
+		return fmt.Errorf("unknown hooks subcommand: %s (only git hooks are supported)", args[0])
+	}
+	if len(args) == 1 {
+		fmt.Fprint(os.Stderr, hooksUsage)
+		return fmt.Errorf("missing hooks git subcommand")
+	}
+
+	fset := flag.NewFlagSet("hooks git "+args[1], flag.ContinueOnError)
+	fset.SetOutput(os.Stderr)
+	fset.Usage = func() { fmt.Fprint(os.Stderr, hooksUsage) }
+
+	var flags hooksFlags
+	fset.StringVar(&flags.trigger, "trigger", "", "Comma-separated hooks or all")
+	fset.StringVar(&flags.print, "print", "", "Print the snippet for husky or lefthook")
+	fset.BoolVar(&flags.force, "force", false, "Write hooks even when a hook manager is detected")
+	fset.BoolVar(&flags.json, "json", false, "Print machine-readable JSON")
+
+	positional, err := parseInterspersed(fset, args[2:])
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("usage: claudex hooks git %s [flags]", args[1])
+	}
+
+	svc := hooksetup.New(a.deps.FS, a.projectDir, a.deps.Cmd)
+	switch args[1] {
+	case "install":
+		triggers, err := parseTriggers(flags.trigger, []hooksetup.Trigger{hooksetup.TriggerPostCommit})
+		if err != nil {
+			return err
+		}
+		return hooksInstall(out, svc, flags, triggers)
+	case "uninstall":
+		triggers, err := parseTriggers(flags.trigger, hooksetup.Triggers)
+		if err != nil {
+			return err
+		}
+		return hooksUninstall(out, svc, flags, triggers)
+	case "status":
+		triggers, err := parseTriggers(flags.trigger, hooksetup.Triggers)
+		if err != nil {
+			return err
+		}
+		return hooksStatus(out, svc, flags, triggers)
+	default:
+		fmt.Fprint(os.Stderr, hooksUsage)
+		return fmt.Errorf("unknown hooks git subcommand: %s", args[1])
+	}
+}
+
+// parseTriggers parses a comma-separated --trigger value, returning defaults when it is empty
+func parseTriggers(value string, defaults []hooksetup.Trigger) ([]hooksetup.Trigger, error) {
+	if strings.TrimSpace(value) == "" {
+		return defaults, nil
+	}
+	if strings.TrimSpace(value) == "all" {
+		return hooksetup.Triggers, nil
+	}
+
+	var triggers []hooksetup.Trigger
+	for _, name := range strings.Split(value, ",") {
+		trigger := hooksetup.Trigger(strings.TrimSpace(name))
+		valid := false
+		for _, known := range hooksetup.Triggers {
+			valid = valid || trigger == known
+		}
+		if !valid {
+			return nil, fmt.Errorf("unknown trigger %q (expected post-commit, post-merge, post-rewrite or all)", name)
+		}
+		triggers = append(triggers, trigger)
+	}
+	return triggers, nil
+}
+
+// hooksInstall writes the claudex block to the hooks of the triggers, or prints the
+// hook manager snippet when husky or lefthook owns the hooks
+func hooksInstall(out io.Writer, svc hooksetup.Service, flags hooksFlags, triggers []hooksetup.Trigger) error {
+	if flags.print != "" {
+		manager := hooksetup.Manager(flags.print)
+		if manager != hooksetup.ManagerHusky && manager != hooksetup.ManagerLefthook {
+			return fmt.Errorf("unknown hook manager %q (expected husky or lefthook)", flags.print)
+		}
+		fmt.Fprint(out, hooksetup.Snippet(manager, triggers))
+		return nil
+	}
+
+	if !svc.IsGitRepo() {
+		return fmt.Errorf("not a git repository")
+	}
+	status := svc.Status()
+	if status.Manager != hooksetup.ManagerNone && !flags.force {
+		fmt.Fprintf(out, "Git hooks are managed by %s; add this to its setup (or rerun with --force):\n\n%s",
+			status.Manager, hooksetup.Snippet(status.Manager, triggers))
+		return fmt.Errorf("%w: %s", hooksetup.ErrHookManager, status.Manager)
+	}
+
+	if err := svc.InstallHooks(triggers); err != nil {
+		return err
+	}
+
+	installed := make([]string, 0, len(triggers))
+	for _, trigger := range triggers {
+		installed = append(installed, filepath.Join(status.HooksDir, string(trigger)))
+	}
+	if flags.json {
+		return writeJSON(out, map[string][]string{"installed": installed})
+	}
+	for i, trigger := range triggers {
+		fmt.Fprintf(out, "✓ Installed %s hook: %s\n", trigger, installed[i])
+	}
+	return nil
+}
+
+// hooksUninstall removes the claudex block from the hooks of the triggers
+func hooksUninstall(out io.Writer, svc hooksetup.Service, flags hooksFlags, triggers []hooksetup.Trigger) error {
+	changed, err := svc.UninstallHooks(triggers)
+	if err != nil {
+		return err
+	}
+
+	if flags.json {
+		return writeJSON(out, map[string][]string{"uninstalled": nonNil(changed)})
+	}
+	if len(changed) == 0 {
+		fmt.Fprintln(out, "No claudex hooks to remove")
+		return nil
+	}
+	for _, path := range changed {
+		fmt.Fprintf(out, "✓ Removed claudex hook from %s\n", path)
+	}
+	if manager := svc.Status().Manager; manager == hooksetup.ManagerLefthook {
+		fmt.Fprintln(out, "Remove the claudex-docs-* commands from your lefthook config by hand")
+	}
+	return nil
+}
+
+// hooksStatus prints the hooks directory, hook manager and the state of every trigger
+func hooksStatus(out io.Writer, svc hooksetup.Service, flags hooksFlags, triggers []hooksetup.Trigger) error {
+	status := svc.Status()
+	hooks := status.Hooks[:0:0]
+	for _, hook := range status.Hooks {
+		if containsTrigger(triggers, hook.Trigger) {
+			hooks = append(hooks, hook)
+		}
+	}
+	status.Hooks = hooks
+
+	if flags.json {
+		return writeJSON(out, status)
+	}
+
+	fmt.Fprintf(out, "Hooks directory: %s\n", status.HooksDir)
+	if status.HooksPath != "" {
+		fmt.Fprintf(out, "core.hooksPath:  %s\n", status.HooksPath)
+	}
+	if status.Manager != hooksetup.ManagerNone {
+		fmt.Fprintf(out, "Hook manager:    %s\n", status.Manager)
+	}
+	fmt.Fprintln(out)
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TRIGGER\tINSTALLED\tEXECUTABLE\tPATH")
+	for _, hook := range status.Hooks {
+		path := hook.Path
+		if !hook.Exists {
+			path += " (missing)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", hook.Trigger, yesNo(hook.Installed), yesNo(hook.Executable), path)
+	}
+	return tw.Flush()
+}
+
+// containsTrigger reports whether triggers includes trigger
+func containsTrigger(triggers []hooksetup.Trigger, trigger hooksetup.Trigger) bool {
+	for _, t := range triggers {
+		if t == trigger {
+			return true
+		}
+	}
+	return false
+}
+
+// yesNo renders a boolean for table output
+func yesNo(v bool) string {
+	if v {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"claudex/internal/services/hooksetup"
+	"claudex/internal/testutil"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newHooksApp creates an app for a git repository whose core.hooksPath is .githooks
+func newHooksApp(h *testutil.TestHarness) *App {
+	app := newSessionCommandApp(h, "/project/.claudex/sessions")
+	h.Commander.OnPattern("git", "--git-dir").Return([]byte(".git\n"), nil)
+	h.Commander.OnPattern("git", "--git-path").Return([]byte(".githooks\n"), nil)
+	h.Commander.OnPattern("git", "core.hooksPath").Return([]byte(".githooks\n"), nil)
+	return app
+}
+
+// TestHooksCommand_InstallWritesToHooksPath verifies install follows core.hooksPath for every trigger
+func TestHooksCommand_InstallWritesToHooksPath(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	app := newHooksApp(h)
+
+	// Exercise
+	var out bytes.Buffer
+	err := app.runHooksCommand([]string{"git", "install", "--trigger", "post-commit,post-merge"}, &out)
+
+	// Verify
+	require.NoError(t, err)
+	hooksDir := filepath.Join(app.projectDir, ".githooks")
+	testutil.AssertFileContains(t, h.FS, filepath.Join(hooksDir, "post-commit"), "claudex --update-docs &")
+	testutil.AssertFileContains(t, h.FS, filepath.Join(hooksDir, "post-merge"), "claudex --update-docs &")
+	assert.Contains(t, out.String(), "Installed post-merge hook")
+	assert.NotContains(t, out.String(), "post-rewrite")
+}
+
+// TestHooksCommand_InstallPrintsSnippetForHusky verifies a husky project gets a snippet instead of a hook
+func TestHooksCommand_InstallPrintsSnippetForHusky(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	app := newHooksApp(h)
+	h.CreateDir(filepath.Join(app.projectDir, ".husky"))
+
+	// Exercise
+	var out bytes.Buffer
+	err := app.runHooksCommand([]string{"git", "install"}, &out)
+
+	// Verify
+	require.ErrorIs(t, err, hooksetup.ErrHookManager)
+	assert.Contains(t, out.String(), "# Append to .husky/post-commit")
+	exists, _ := afero.Exists(h.FS, filepath.Join(app.projectDir, ".githooks", "post-commit"))
+	assert.False(t, exists, "Should not write the hook without --force")
+
+	// --force writes it anyway
+	out.Reset()
+	require.NoError(t, app.runHooksCommand([]string{"git", "install", "--force"}, &out))
+	testutil.AssertFileContains(t, h.FS, filepath.Join(app.projectDir, ".githooks", "post-commit"), "claudex --update-docs &")
+}
+
+// TestHooksCommand_PrintLefthook verifies --print emits the lefthook snippet without touching hooks
+func TestHooksCommand_PrintLefthook(t *testing.T) {
+	h := testutil.NewTestHarness()
+	app := newHooksApp(h)
+
+	var out bytes.Buffer
+	err := app.runHooksCommand([]string{"git", "install", "--print", "lefthook", "--trigger", "all"}, &out)
+
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "post-rewrite:\n  commands:\n    claudex-docs-post-rewrite:")
+	assert.Empty(t, h.Commander.Invocations, "Printing a snippet needs no git")
+}
+
+// TestHooksCommand_UninstallAndStatus verifies uninstall removes the block and status reflects it
+func TestHooksCommand_UninstallAndStatus(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	app := newHooksApp(h)
+	require.NoError(t, app.runHooksCommand([]string{"git", "install", "--trigger", "all"}, &bytes.Buffer{}))
+
+	// Exercise
+	var out bytes.Buffer
+	err := app.runHooksCommand([]string{"git", "uninstall", "--trigger", "post-merge"}, &out)
+	require.NoError(t, err)
+	out.Reset()
+	err = app.runHooksCommand([]string{"git", "status", "--json"}, &out)
+
+	// Verify
+	require.NoError(t, err)
+	var status hooksetup.Status
+	require.NoError(t, json.Unmarshal(out.Bytes(), &status))
+	assert.Equal(t, ".githooks", status.HooksPath)
+	require.Len(t, status.Hooks, 3)
+	assert.True(t, status.Hooks[0].Installed, "post-commit")
+	assert.False(t, status.Hooks[1].Exists, "post-merge hook held only claudex and is removed")
+	assert.True(t, status.Hooks[2].Installed, "post-rewrite")
+}
+
+// TestHooksCommand_RejectsUnknownTrigger verifies --trigger is validated
+func TestHooksCommand_RejectsUnknownTrigger(t *testing.T) {
+	h := testutil.NewTestHarness()
+	app := newHooksApp(h)
+
+	err := app.runHooksCommand([]string{"git", "install", "--trigger", "pre-push"}, &bytes.Buffer{})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown trigger "pre-push"`)
+}
@@ -0,0 +1,257 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"claudex/internal/services/jobs"
+	"claudex/internal/services/session"
+
+	"github.com/spf13/afero"
+)
+
+const jobsUsage = `Usage: claudex jobs <command> [flags] [args]
+
+Commands:
+  list [session]     List background jobs (all sessions when omitted)
+  tail <job-id>      Print the captured stderr of a job
+  cancel <job-id>    Cancel a queued or running job
+
+Flags:
+  --session <session>  Session owning the job (tail, cancel); all sessions are searched when omitted
+  --json               Print machine-readable JSON (list, cancel)
+  -n <lines>           Number of log lines to print (tail, default 20)
+  -f                   Keep printing the log until the job finishes (tail)
+`
+
+// jobFollowInterval is how often `jobs tail -f` polls the job log
+const jobFollowInterval = 500 * time.Millisecond
+
+// JobListEntry is the scriptable view of a job printed by `jobs list`
+type JobListEntry struct {
+	Session string `json:"session"`
+	*jobs.Job
+}
+
+// jobsFlags holds the flags shared by the jobs subcommands
+type jobsFlags struct {
+	json    bool
+	session string
+	lines   int
+	follow  bool
+}
+
+// runJobsCommand dispatches `claudex jobs <subcommand>` to its handler
+func (a *App) runJobsCommand(args []string, out io.Writer) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(os.Stderr, jobsUsage)
+		if len(args) == 0 {
+			return fmt.Errorf("missing jobs subcommand")
+		}
+		return nil
+	}
+
+	sub, rest := args[0], args[1:]
+	fset := flag.NewFlagSet("jobs "+sub, flag.ContinueOnError)
+	fset.SetOutput(os.Stderr)
+	fset.Usage = func() { fmt.Fprint(os.Stderr, jobsUsage) }
+
+	var flags jobsFlags
+	fset.BoolVar(&flags.json, "json", false, "Print machine-readable JSON")
+	fset.StringVar(&flags.session, "session", "", "Session owning the job")
+	fset.IntVar(&flags.lines, "n", 20, "Number of log lines to print")
+	fset.BoolVar(&flags.follow, "f", false, "Follow the log until the job finishes")
+
+	positional, err := parseInterspersed(fset, rest)
+	if err != nil {
+		return err
+	}
+
+	switch sub {
+	case "list":
+		if len(positional) > 1 {
+			return fmt.Errorf("usage: claudex jobs list [session]")
+		}
+		if len(positional) == 1 {
+			flags.session = positional[0]
+		}
+		return a.jobsList(out, flags)
+	case "tail":
+		if len(positional) != 1 {
+			return fmt.Errorf("usage: claudex jobs tail <job-id>")
+		}
+		return a.jobsTail(out, flags, positional[0])
+	case "cancel":
+		if len(positional) != 1 {
+			return fmt.Errorf("usage: claudex jobs cancel <job-id>")
+		}
+		return a.jobsCancel(out, flags, positional[0])
+	default:
+		fmt.Fprint(os.Stderr, jobsUsage)
+		return fmt.Errorf("unknown jobs subcommand: %s", sub)
+	}
+}
+
+// jobSessions returns the session names to search: the --session one, or all sessions
+func (a *App) jobSessions(ref string) ([]string, error) {
+	if ref != "" {
+		name, err := session.ResolveSessionName(a.deps.FS, a.sessionsDir, ref)
+		if err != nil {
+			return nil, err
+		}
+		return []string{name}, nil
+	}
+
+	entries, err := afero.ReadDir(a.deps.FS, a.sessionsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sessions directory: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// jobQueue returns the job queue of a session
+func (a *App) jobQueue(sessionName string) *jobs.Queue {
+	return jobs.NewQueue(a.deps.FS, a.deps.Clock, filepath.Join(a.sessionsDir, sessionName))
+}
+
+// findJob locates a job by ID, failing when it is missing or ambiguous across sessions
+func (a *App) findJob(sessionRef, id string) (string, *jobs.Job, error) {
+	names, err := a.jobSessions(sessionRef)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var foundSession string
+	var found *jobs.Job
+	for _, name := range names {
+		job, err := a.jobQueue(name).Get(id)
+		if err != nil {
+			continue
+		}
+		if found != nil {
+			return "", nil, fmt.Errorf("job %s exists in several sessions (%s, %s); use --session", id, foundSession, name)
+		}
+		foundSession, found = name, job
+	}
+
+	if found == nil {
+		return "", nil, fmt.Errorf("job not found: %s", id)
+	}
+	return foundSession, found, nil
+}
+
+// jobsList prints jobs of one or all sessions in creation order
+func (a *App) jobsList(out io.Writer, flags jobsFlags) error {
+	names, err := a.jobSessions(flags.session)
+	if err != nil {
+		return err
+	}
+
+	entries := []JobListEntry{}
+	for _, name := range names {
+		list, err := a.jobQueue(name).List()
+		if err != nil {
+			return fmt.Errorf("failed to list jobs for %s: %w", name, err)
+		}
+		for _, job := range list {
+			entries = append(entries, JobListEntry{Session: name, Job: job})
+		}
+	}
+
+	if flags.json {
+		return writeJSON(out, entries)
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "JOB\tSESSION\tKIND\tSTATUS\tATTEMPTS\tEXIT\tSTARTED")
+	for _, e := range entries {
+		exit := "-"
+		if e.ExitCode != nil {
+			exit = fmt.Sprintf("%d", *e.ExitCode)
+		}
+		started := "-"
+		if e.StartedAt != nil {
+			started = e.StartedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d/%d\t%s\t%s\n", e.ID, e.Session, e.Kind, e.Status, e.Attempts, e.MaxAttempts, exit, started)
+	}
+	return tw.Flush()
+}
+
+// jobsTail prints the last lines of a job's stderr log, optionally following it
+func (a *App) jobsTail(out io.Writer, flags jobsFlags, id string) error {
+	sessionName, job, err := a.findJob(flags.session, id)
+	if err != nil {
+		return err
+	}
+	queue := a.jobQueue(sessionName)
+
+	data, err := afero.ReadFile(a.deps.FS, queue.LogPath(id))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read job log: %w", err)
+	}
+	fmt.Fprint(out, lastLines(string(data), flags.lines))
+
+	offset := int64(len(data))
+	for flags.follow && !job.Finished() {
+		time.Sleep(jobFollowInterval)
+
+		// Reload the status first: the worker finishes the log before saving a final status
+		if job, err = queue.Get(id); err != nil {
+			return err
+		}
+
+		data, err := afero.ReadFile(a.deps.FS, queue.LogPath(id))
+		if err == nil && int64(len(data)) > offset {
+			out.Write(data[offset:])
+			offset = int64(len(data))
+		}
+	}
+	return nil
+}
+
+// jobsCancel cancels a queued or running job
+func (a *App) jobsCancel(out io.Writer, flags jobsFlags, id string) error {
+	sessionName, _, err := a.findJob(flags.session, id)
+	if err != nil {
+		return err
+	}
+
+	job, err := a.jobQueue(sessionName).Cancel(id, jobs.Terminate)
+	if err != nil {
+		return err
+	}
+
+	if flags.json {
+		return writeJSON(out, JobListEntry{Session: sessionName, Job: job})
+	}
+	fmt.Fprintf(out, "Cancelled job %s (%s)\n", id, sessionName)
+	return nil
+}
+
+// lastLines returns the last n lines of text (all of it when n <= 0)
+func lastLines(text string, n int) string {
+	if n <= 0 || text == "" {
+		return text
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) <= n {
+		return text
+	}
+	return strings.Join(lines[len(lines)-n:], "")
+}
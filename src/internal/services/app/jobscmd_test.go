@@ -0,0 +1,99 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"claudex/internal/services/jobs"
+	"claudex/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestJobsCommand_ListJSON verifies jobs from every session are listed with their session
+func TestJobsCommand_ListJSON(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	h.FixedTime = time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	sessionsDir := "/project/.claudex/sessions"
+	app := newSessionCommandApp(h, sessionsDir)
+
+	sessionName := "task-aaaa1111-2222-3333-4444-555566667777"
+	h.CreateDir(filepath.Join(sessionsDir, "other-bbbb1111-2222-3333-4444-555566667777"))
+	queue := jobs.NewQueue(h.FS, h, filepath.Join(sessionsDir, sessionName))
+	job, err := queue.Enqueue(jobs.KindDocUpdate, struct{}{})
+	require.NoError(t, err)
+
+	// Exercise
+	var out bytes.Buffer
+	err = app.runJobsCommand([]string{"list", "--json"}, &out)
+
+	// Verify
+	require.NoError(t, err)
+	var entries []JobListEntry
+	require.NoError(t, json.Unmarshal(out.Bytes(), &entries))
+	require.Len(t, entries, 1)
+	assert.Equal(t, sessionName, entries[0].Session)
+	assert.Equal(t, job.ID, entries[0].ID)
+	assert.Equal(t, jobs.StatusQueued, entries[0].Status)
+}
+
+// TestJobsCommand_TailPrintsLastLines verifies tail prints the end of the job log
+func TestJobsCommand_TailPrintsLastLines(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	sessionsDir := "/project/.claudex/sessions"
+	app := newSessionCommandApp(h, sessionsDir)
+
+	queue := jobs.NewQueue(h.FS, h, filepath.Join(sessionsDir, "task-aaaa1111-2222-3333-4444-555566667777"))
+	job, err := queue.Enqueue(jobs.KindDocUpdate, struct{}{})
+	require.NoError(t, err)
+	h.WriteFile(queue.LogPath(job.ID), "line 1\nline 2\nline 3\n")
+
+	// Exercise
+	var out bytes.Buffer
+	err = app.runJobsCommand([]string{"tail", job.ID, "-n", "2"}, &out)
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, "line 2\nline 3\n", out.String())
+}
+
+// TestJobsCommand_CancelQueuedJob verifies cancel marks a queued job cancelled
+func TestJobsCommand_CancelQueuedJob(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	sessionsDir := "/project/.claudex/sessions"
+	app := newSessionCommandApp(h, sessionsDir)
+
+	queue := jobs.NewQueue(h.FS, h, filepath.Join(sessionsDir, "task-aaaa1111-2222-3333-4444-555566667777"))
+	job, err := queue.Enqueue(jobs.KindDocUpdate, struct{}{})
+	require.NoError(t, err)
+
+	// Exercise
+	var out bytes.Buffer
+	err = app.runJobsCommand([]string{"cancel", "--session", "task", job.ID}, &out)
+
+	// Verify
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "Cancelled job "+job.ID)
+	loaded, err := queue.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, jobs.StatusCancelled, loaded.Status)
+}
+
+// TestJobsCommand_UnknownJob verifies a helpful error for missing job IDs
+func TestJobsCommand_UnknownJob(t *testing.T) {
+	h := testutil.NewTestHarness()
+	app := newSessionCommandApp(h, "/project/.claudex/sessions")
+
+	var out bytes.Buffer
+	err := app.runJobsCommand([]string{"cancel", "20240101-000000-001"}, &out)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "job not found")
+}
@@ -3,7 +3,6 @@ package app
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"strconv"
 	"time"
 
@@ -87,18 +86,8 @@ func (a *App) launchNew(si SessionInfo) error {
 	// Small delay before launching
 	time.Sleep(300 * time.Millisecond)
 
-	// Use absolute session path for activation command
-	activationPrompt := fmt.Sprintf("/agents:team-lead activate in session %s", si.Path)
-	if len(a.docPaths) > 0 {
-		activationPrompt += "\n\nIMPORTANT - Required Documentation:\nBefore proceeding, you MUST read these documentation files:"
-		for _, docPath := range a.docPaths {
-			absPath, _ := filepath.Abs(docPath)
-			activationPrompt += fmt.Sprintf("\n- %s", absPath)
-		}
-	}
-
-	// Launch the Claude session with activation command
-	return launchClaude(a.deps, si.ClaudeID, activationPrompt)
+	// The SessionStart hook injects the session context
+	return launchClaude(a.deps, si.ClaudeID)
 }
 
 // launchResume resumes an existing Claude session
@@ -110,7 +99,7 @@ func (a *App) launchResume(si SessionInfo) error {
 	// Small delay before launching
 	time.Sleep(300 * time.Millisecond)
 
-	// For resume, continue existing session
+	// For resume, continue existing session; the SessionStart hook re-injects the session context
 	return resumeClaude(a.deps, si.ClaudeID)
 }
 
@@ -123,17 +112,8 @@ func (a *App) launchFork(si SessionInfo) error {
 	// Small delay before launching
 	time.Sleep(300 * time.Millisecond)
 
-	// For fork, start a new session with activation command
-	activationPrompt := fmt.Sprintf("/agents:team-lead activate in session %s", si.Path)
-	if len(a.docPaths) > 0 {
-		activationPrompt += "\n\nIMPORTANT - Required Documentation:\nBefore proceeding, you MUST read these documentation files:"
-		for _, docPath := range a.docPaths {
-			absPath, _ := filepath.Abs(docPath)
-			activationPrompt += fmt.Sprintf("\n- %s", absPath)
-		}
-	}
-
-	return launchClaude(a.deps, si.ClaudeID, activationPrompt)
+	// For fork, start a new session; the SessionStart hook injects the session context
+	return launchClaude(a.deps, si.ClaudeID)
 }
 
 // launchFresh launches a fresh memory session
@@ -145,17 +125,8 @@ func (a *App) launchFresh(si SessionInfo) error {
 	// Small delay before launching
 	time.Sleep(300 * time.Millisecond)
 
-	// For fresh, start a new session with activation command
-	activationPrompt := fmt.Sprintf("/agents:team-lead activate in session %s", si.Path)
-	if len(a.docPaths) > 0 {
-		activationPrompt += "\n\nIMPORTANT - Required Documentation:\nBefore proceeding, you MUST read these documentation files:"
-		for _, docPath := range a.docPaths {
-			absPath, _ := filepath.Abs(docPath)
-			activationPrompt += fmt.Sprintf("\n- %s", absPath)
-		}
-	}
-
-	return launchClaude(a.deps, si.ClaudeID, activationPrompt)
+	// For fresh, start a new session; the SessionStart hook injects the session context
+	return launchClaude(a.deps, si.ClaudeID)
 }
 
 // launchEphemeral launches an ephemeral session
@@ -169,17 +140,13 @@ func (a *App) launchEphemeral(si SessionInfo) error {
 	fmt.Printf("🔄 Session ID: %s\n\n", claudeSessionID)
 	time.Sleep(500 * time.Millisecond)
 
-	// Launch Claude with NO activation prompt (ephemeral has no session folder)
-	return launchClaude(a.deps, claudeSessionID, "")
+	// Ephemeral has no session folder, so the SessionStart hook injects nothing
+	return launchClaude(a.deps, claudeSessionID)
 }
 
-// launchClaude launches a Claude CLI session with the provided session ID and activation prompt
-func launchClaude(deps *Dependencies, sessionID string, activationPrompt string) error {
-	args := []string{"--session-id", sessionID}
-	if activationPrompt != "" {
-		args = append(args, activationPrompt)
-	}
-	return deps.Cmd.Start("claude", os.Stdin, os.Stdout, os.Stderr, args...)
+// launchClaude launches a Claude CLI session with the provided session ID
+func launchClaude(deps *Dependencies, sessionID string) error {
+	return deps.Cmd.Start("claude", os.Stdin, os.Stdout, os.Stderr, "--session-id", sessionID)
 }
 
 // resumeClaude resumes an existing Claude CLI session
@@ -382,9 +382,9 @@ func TestLaunchEphemeral_NoLastUsedUpdate(t *testing.T) {
 }
 
 // TestLaunchEphemeral_CompareWithLaunchNew demonstrates the difference between ephemeral and new session
-// This test shows what ephemeral SHOULD do vs what new session DOES do
+// Neither sends an activation prompt: the SessionStart hook injects the context of sessions with a folder
 func TestLaunchEphemeral_CompareWithLaunchNew(t *testing.T) {
-	t.Run("New session creates directory and sends activation", func(t *testing.T) {
+	t.Run("New session uses its directory and sends no activation", func(t *testing.T) {
 		h := testutil.NewTestHarness()
 		h.UUIDs = []string{"new-session-uuid"}
 
@@ -415,12 +415,10 @@ func TestLaunchEphemeral_CompareWithLaunchNew(t *testing.T) {
 
 		_ = app.launchNew(si)
 
-		// New session DOES send activation prompt
+		// New session launches with its session ID only
 		require.NotEmpty(t, h.Commander.Invocations)
 		invocation := h.Commander.Invocations[0]
-		allArgs := strings.Join(invocation.Args, " ")
-		require.Contains(t, allArgs, "/agents:team-lead")
-		require.Contains(t, allArgs, "activate")
+		require.Equal(t, []string{"--session-id", "new-session-uuid"}, invocation.Args)
 	})
 
 	t.Run("Ephemeral session should NOT create directory or send activation", func(t *testing.T) {
@@ -731,3 +729,34 @@ func TestSetEnvironment_InvalidEnvVarValues(t *testing.T) {
 	require.Equal(t, "false", os.Getenv("CLAUDEX_AUTODOC_SESSION_PROGRESS")) // "not-a-bool" != "true" = false
 	require.Equal(t, "5", os.Getenv("CLAUDEX_AUTODOC_FREQUENCY"))            // Invalid int, uses config default
 }
+
+// TestLaunch_NoActivationPrompt verifies new, fork and fresh sessions start Claude without
+// a first user message; the SessionStart hook injects the session context instead
+func TestLaunch_NoActivationPrompt(t *testing.T) {
+	modes := map[LaunchMode]func(*App, SessionInfo) error{
+		LaunchModeNew:   (*App).launchNew,
+		LaunchModeFork:  (*App).launchFork,
+		LaunchModeFresh: (*App).launchFresh,
+	}
+	for mode, launch := range modes {
+		t.Run(string(mode), func(t *testing.T) {
+			// Setup
+			h := testutil.NewTestHarness()
+			sessionPath := "/project/.claudex/sessions/task-abc"
+			h.CreateDir(sessionPath)
+			app := &App{
+				deps:        &Dependencies{FS: h.FS, Cmd: h.Commander, Clock: h, UUID: h, Env: h.Env},
+				projectDir:  "/project",
+				sessionsDir: "/project/.claudex/sessions",
+				docPaths:    []string{"docs/index.md"},
+			}
+
+			// Exercise
+			_ = launch(app, SessionInfo{Name: "task-abc", Path: sessionPath, ClaudeID: "abc", Mode: mode})
+
+			// Verify
+			require.Len(t, h.Commander.Invocations, 1)
+			require.Equal(t, []string{"--session-id", "abc"}, h.Commander.Invocations[0].Args)
+		})
+	}
+}
@@ -0,0 +1,380 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+
+	"claudex/internal/doc"
+	"claudex/internal/services/config"
+	"claudex/internal/services/history"
+	"claudex/internal/services/lock"
+	"claudex/internal/services/session"
+	overviewuc "claudex/internal/usecases/overview"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/afero"
+)
+
+const overviewUsage = `Usage: claudex overview <command> [flags] [args]
+
+Commands:
+  history [session]   List saved versions of the session overview (1 is the newest)
+  diff <n> [m]        Show changes from version n to version m (to the current overview when omitted)
+  restore <n>         Replace the overview with version n (the current one is saved first)
+  lint [session]      Check the overview for required sections and broken links
+  update [session]    Document the transcript entries no background update has processed yet
+  rebuild [session]   Regenerate the overview from the full transcripts (the current one is saved first)
+
+Flags:
+  --session <session>  Session to use (defaults to $CLAUDEX_SESSION inside a claudex session)
+  --file <name>        Session document to use (default session-overview.md)
+  --model <model>      Model for update and rebuild (default: the session_end trigger's model)
+  --chunk <n>          Transcript entries sent to the model per call (update, rebuild; default 200)
+  --json               Print machine-readable JSON (history, restore, lint, update, rebuild)
+`
+
+// overviewFlags holds the flags shared by the overview subcommands
+type overviewFlags struct {
+	json    bool
+	session string
+	file    string
+	model   string
+	chunk   int
+}
+
+// runOverviewCommand dispatches `claudex overview <subcommand>` to its handler
+func (a *App) runOverviewCommand(args []string, out io.Writer) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(os.Stderr, overviewUsage)
+		if len(args) == 0 {
+			return fmt.Errorf("missing overview subcommand")
+		}
+		return nil
+	}
+
+	sub, rest := args[0], args[1:]
+	fset := flag.NewFlagSet("overview "+sub, flag.ContinueOnError)
+	fset.SetOutput(os.Stderr)
+	fset.Usage = func() { fmt.Fprint(os.Stderr, overviewUsage) }
+
+	var flags overviewFlags
+	fset.BoolVar(&flags.json, "json", false, "Print machine-readable JSON")
+	fset.StringVar(&flags.session, "session", "", "Session to use")
+	fset.StringVar(&flags.file, "file", doc.DefaultOutputFile, "Session document to use")
+	fset.StringVar(&flags.model, "model", "", "Model for update and rebuild")
+	fset.IntVar(&flags.chunk, "chunk", overviewuc.DefaultChunkEntries, "Transcript entries per model call")
+
+	positional, err := parseInterspersed(fset, rest)
+	if err != nil {
+		return err
+	}
+
+	switch sub {
+	case "history":
+		if len(positional) > 1 {
+			return fmt.Errorf("usage: claudex overview history [session]")
+		}
+		if len(positional) == 1 {
+			flags.session = positional[0]
+		}
+		return a.overviewHistory(out, flags)
+	case "diff":
+		if len(positional) < 1 || len(positional) > 2 {
+			return fmt.Errorf("usage: claudex overview diff <n> [m]")
+		}
+		return a.overviewDiff(out, flags, positional)
+	case "restore":
+		if len(positional) != 1 {
+			return fmt.Errorf("usage: claudex overview restore <n>")
+		}
+		return a.overviewRestore(out, flags, positional[0])
+	case "lint":
+		if len(positional) > 1 {
+			return fmt.Errorf("usage: claudex overview lint [session]")
+		}
+		if len(positional) == 1 {
+			flags.session = positional[0]
+		}
+		return a.overviewLint(out, flags)
+	case "update", "rebuild":
+		if len(positional) > 1 {
+			return fmt.Errorf("usage: claudex overview %s [session]", sub)
+		}
+		if len(positional) == 1 {
+			flags.session = positional[0]
+		}
+		return a.overviewRefresh(out, flags, sub == "rebuild")
+	default:
+		fmt.Fprint(os.Stderr, overviewUsage)
+		return fmt.Errorf("unknown overview subcommand: %s", sub)
+	}
+}
+
+// overviewSession resolves the --session flag, falling back to the session claudex launched
+func (a *App) overviewSession(flags overviewFlags) (string, string, error) {
+	ref := flags.session
+	if ref == "" {
+		ref = a.deps.Env.Get("CLAUDEX_SESSION")
+	}
+	if ref == "" {
+		return "", "", fmt.Errorf("no session given; use --session <session>")
+	}
+
+	name, err := session.ResolveSessionName(a.deps.FS, a.sessionsDir, ref)
+	if err != nil {
+		return "", "", err
+	}
+	return name, filepath.Join(a.sessionsDir, name), nil
+}
+
+// historyLimit returns the configured number of snapshots to keep
+func (a *App) historyLimit() int {
+	if a.cfg != nil {
+		return a.cfg.Autodoc.HistoryLimit
+	}
+	return config.DefaultAutodoc().HistoryLimit
+}
+
+// validationRules returns the configured overview checks; lint applies them even when
+// validation of background updates is disabled
+func (a *App) validationRules() doc.ValidationRules {
+	validation := config.DefaultOverviewValidation()
+	if a.cfg != nil {
+		validation = a.cfg.Autodoc.Validation
+	}
+	return doc.ValidationRules{
+		RequiredSections: validation.RequiredSections,
+		CheckLinks:       validation.CheckLinks,
+	}
+}
+
+// overviewHistory lists the saved versions of a session document, newest first
+func (a *App) overviewHistory(out io.Writer, flags overviewFlags) error {
+	_, sessionPath, err := a.overviewSession(flags)
+	if err != nil {
+		return err
+	}
+
+	snapshots, err := history.New(a.deps.FS, a.deps.Clock, sessionPath).List(flags.file)
+	if err != nil {
+		return err
+	}
+
+	if flags.json {
+		return writeJSON(out, snapshots)
+	}
+
+	if len(snapshots) == 0 {
+		fmt.Fprintf(out, "No saved versions of %s\n", flags.file)
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tSAVED\tTRIGGER\tSIZE")
+	for _, snap := range snapshots {
+		trigger := snap.Trigger
+		if trigger == "" {
+			trigger = "-"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\n", snap.Number, snap.CreatedAt.Local().Format("2006-01-02 15:04:05"), trigger, snap.Size)
+	}
+	return tw.Flush()
+}
+
+// overviewDiff prints a unified diff between two versions of a session document
+func (a *App) overviewDiff(out io.Writer, flags overviewFlags, args []string) error {
+	_, sessionPath, err := a.overviewSession(flags)
+	if err != nil {
+		return err
+	}
+	store := history.New(a.deps.FS, a.deps.Clock, sessionPath)
+
+	fromText, fromLabel, err := a.overviewVersion(store, sessionPath, flags.file, args[0])
+	if err != nil {
+		return err
+	}
+	to := "current"
+	if len(args) == 2 {
+		to = args[1]
+	}
+	toText, toLabel, err := a.overviewVersion(store, sessionPath, flags.file, to)
+	if err != nil {
+		return err
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(fromText),
+		B:        difflib.SplitLines(toText),
+		FromFile: fromLabel,
+		ToFile:   toLabel,
+		Context:  3,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to diff %s: %w", flags.file, err)
+	}
+
+	if diff == "" {
+		fmt.Fprintln(out, "No differences")
+		return nil
+	}
+	fmt.Fprint(out, diff)
+	return nil
+}
+
+// overviewVersion returns the content and diff label of a snapshot number or "current"
+func (a *App) overviewVersion(store *history.Store, sessionPath, file, ref string) (string, string, error) {
+	if ref == "current" {
+		data, err := afero.ReadFile(a.deps.FS, filepath.Join(sessionPath, file))
+		if err != nil && !os.IsNotExist(err) {
+			return "", "", fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		return string(data), file + " (current)", nil
+	}
+
+	n, err := strconv.Atoi(ref)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid version %q: expected a number from 'claudex overview history'", ref)
+	}
+	snap, err := store.Get(file, n)
+	if err != nil {
+		return "", "", err
+	}
+	data, err := store.Read(snap)
+	if err != nil {
+		return "", "", err
+	}
+	label := fmt.Sprintf("%s #%d (%s, %s)", file, snap.Number, snap.CreatedAt.Local().Format("2006-01-02 15:04:05"), snap.Trigger)
+	return string(data), label, nil
+}
+
+// overviewRestore replaces a session document with a saved version
+func (a *App) overviewRestore(out io.Writer, flags overviewFlags, ref string) error {
+	sessionName, sessionPath, err := a.overviewSession(flags)
+	if err != nil {
+		return err
+	}
+
+	n, err := strconv.Atoi(ref)
+	if err != nil {
+		return fmt.Errorf("invalid version %q: expected a number from 'claudex overview history'", ref)
+	}
+
+	snap, err := history.New(a.deps.FS, a.deps.Clock, sessionPath).Restore(flags.file, n, a.historyLimit())
+	if err != nil {
+		return err
+	}
+
+	if flags.json {
+		return writeJSON(out, snap)
+	}
+	fmt.Fprintf(out, "Restored %s of %s to version %d (saved %s)\n",
+		flags.file, sessionName, n, snap.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	return nil
+}
+
+// overviewLint validates a session document and fails when it has issues
+func (a *App) overviewLint(out io.Writer, flags overviewFlags) error {
+	sessionName, sessionPath, err := a.overviewSession(flags)
+	if err != nil {
+		return err
+	}
+
+	content, err := afero.ReadFile(a.deps.FS, filepath.Join(sessionPath, flags.file))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", flags.file, err)
+	}
+
+	issues := doc.ValidateDocument(a.deps.FS, sessionPath, string(content), a.validationRules())
+	if flags.json {
+		if err := writeJSON(out, issues); err != nil {
+			return err
+		}
+	} else if len(issues) == 0 {
+		fmt.Fprintf(out, "%s of %s is valid\n", flags.file, sessionName)
+	} else {
+		for _, issue := range issues {
+			fmt.Fprintf(out, "%s: %s\n", flags.file, issue)
+		}
+	}
+
+	if len(issues) > 0 {
+		return fmt.Errorf("%s has %d validation issues", flags.file, len(issues))
+	}
+	return nil
+}
+
+// overviewUseCase builds the catch-up/rebuild use case backed by the project's [llm] backend
+func (a *App) overviewUseCase() *overviewuc.UseCase {
+	updater := doc.NewUpdater(a.deps.FS, a.deps.Cmd, a.deps.Env, a.newLLMClient())
+	return overviewuc.New(a.deps.FS, a.deps.Env, a.deps.Clock, lock.New(a.deps.FS), updater)
+}
+
+// overviewOptions returns the use case options for a session, using the session_end trigger's model by default
+func (a *App) overviewOptions(sessionName, sessionPath string, flags overviewFlags) overviewuc.Options {
+	autodoc := config.DefaultAutodoc()
+	if a.cfg != nil {
+		autodoc = a.cfg.Autodoc
+	}
+	model := flags.model
+	if model == "" {
+		model = autodoc.SessionEnd.Model
+	}
+	if model == "" {
+		model = config.DefaultAutodoc().SessionEnd.Model
+	}
+
+	return overviewuc.Options{
+		SessionName:  sessionName,
+		SessionPath:  sessionPath,
+		ProjectDir:   a.projectDir,
+		OutputFile:   flags.file,
+		Model:        model,
+		HistoryLimit: autodoc.HistoryLimit,
+		Validation:   doc.ValidationRulesFor(autodoc.Validation),
+		Redaction:    doc.RedactionRulesFor(autodoc.Redaction),
+		ChunkEntries: flags.chunk,
+	}
+}
+
+// overviewRefresh documents the unprocessed transcript tail, or regenerates the whole overview
+func (a *App) overviewRefresh(out io.Writer, flags overviewFlags, rebuild bool) error {
+	sessionName, sessionPath, err := a.overviewSession(flags)
+	if err != nil {
+		return err
+	}
+	if flags.chunk < 1 {
+		return fmt.Errorf("--chunk must be at least 1")
+	}
+
+	uc := a.overviewUseCase()
+	opts := a.overviewOptions(sessionName, sessionPath, flags)
+	var result overviewuc.Result
+	if rebuild {
+		result, err = uc.Rebuild(opts)
+	} else {
+		result, err = uc.Update(opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	if flags.json {
+		return writeJSON(out, result)
+	}
+	if result.Entries() == 0 {
+		fmt.Fprintf(out, "%s of %s is up to date\n", flags.file, sessionName)
+		return nil
+	}
+	action := "Updated"
+	if rebuild {
+		action = "Rebuilt"
+	}
+	fmt.Fprintf(out, "%s %s of %s from %d transcript entries (%d transcripts, %d model calls)\n",
+		action, flags.file, sessionName, result.Entries(), len(result.Transcripts), result.Chunks)
+	return nil
+}
@@ -0,0 +1,185 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"claudex/internal/services/history"
+	"claudex/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const overviewSession = "task-aaaa1111-2222-3333-4444-555566667777"
+
+// newOverviewApp creates an app with two saved overview versions and a current one
+func newOverviewApp(t *testing.T, h *testutil.TestHarness) (*App, string) {
+	t.Helper()
+	sessionsDir := "/project/.claudex/sessions"
+	app := newSessionCommandApp(h, sessionsDir)
+	sessionPath := filepath.Join(sessionsDir, overviewSession)
+	overviewPath := filepath.Join(sessionPath, "session-overview.md")
+	store := history.New(h.FS, h, sessionPath)
+
+	for _, v := range []string{"# Overview\n\n- step one\n", "# Overview\n\n- step one\n- step two\n"} {
+		h.WriteFile(overviewPath, v)
+		_, err := store.Snapshot("session-overview.md", "progress", history.DefaultLimit)
+		require.NoError(t, err)
+	}
+	h.WriteFile(overviewPath, "# Overview\n\n- hallucinated\n")
+	return app, overviewPath
+}
+
+// TestOverviewCommand_HistoryJSON verifies saved versions are listed newest first
+func TestOverviewCommand_HistoryJSON(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	app, _ := newOverviewApp(t, h)
+
+	// Exercise
+	var out bytes.Buffer
+	err := app.runOverviewCommand([]string{"history", "task", "--json"}, &out)
+
+	// Verify
+	require.NoError(t, err)
+	var snapshots []history.Snapshot
+	require.NoError(t, json.Unmarshal(out.Bytes(), &snapshots))
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, 1, snapshots[0].Number)
+	assert.Equal(t, "progress", snapshots[0].Trigger)
+	assert.Greater(t, snapshots[0].Size, snapshots[1].Size)
+}
+
+// TestOverviewCommand_DiffAgainstCurrent verifies diff shows what the latest update changed
+func TestOverviewCommand_DiffAgainstCurrent(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	app, _ := newOverviewApp(t, h)
+	h.Env.Set("CLAUDEX_SESSION", overviewSession)
+
+	// Exercise
+	var out bytes.Buffer
+	err := app.runOverviewCommand([]string{"diff", "1"}, &out)
+
+	// Verify
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "--- session-overview.md #1")
+	assert.Contains(t, out.String(), "+++ session-overview.md (current)")
+	assert.Contains(t, out.String(), "-- step two")
+	assert.Contains(t, out.String(), "+- hallucinated")
+}
+
+// TestOverviewCommand_DiffBetweenVersions verifies two snapshots can be compared
+func TestOverviewCommand_DiffBetweenVersions(t *testing.T) {
+	h := testutil.NewTestHarness()
+	app, _ := newOverviewApp(t, h)
+
+	var out bytes.Buffer
+	err := app.runOverviewCommand([]string{"diff", "--session", "task", "2", "1"}, &out)
+
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "+- step two")
+	assert.NotContains(t, out.String(), "hallucinated")
+}
+
+// TestOverviewCommand_Restore verifies restore rolls the overview back and keeps the replaced version
+func TestOverviewCommand_Restore(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	app, overviewPath := newOverviewApp(t, h)
+
+	// Exercise
+	var out bytes.Buffer
+	err := app.runOverviewCommand([]string{"restore", "1", "--session", "task"}, &out)
+
+	// Verify
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "Restored session-overview.md of "+overviewSession+" to version 1")
+	testutil.AssertFileContains(t, h.FS, overviewPath, "- step two")
+
+	snapshots, err := history.New(h.FS, h, filepath.Dir(overviewPath)).List("session-overview.md")
+	require.NoError(t, err)
+	require.Len(t, snapshots, 3)
+	assert.Equal(t, history.TriggerRestore, snapshots[0].Trigger)
+}
+
+// TestOverviewCommand_RequiresSession verifies a helpful error outside a claudex session
+func TestOverviewCommand_RequiresSession(t *testing.T) {
+	h := testutil.NewTestHarness()
+	app, _ := newOverviewApp(t, h)
+
+	var out bytes.Buffer
+	err := app.runOverviewCommand([]string{"history"}, &out)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--session")
+}
+
+// TestOverviewCommand_Lint verifies lint reports missing sections and broken links and fails
+func TestOverviewCommand_Lint(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	app, overviewPath := newOverviewApp(t, h)
+	h.WriteFile(overviewPath, "# Overview\n\n**Status**: Active\n\n## Key Decisions\n\n## Key Documents\n\n- [Plan](plan.md)\n")
+
+	// Exercise
+	var out bytes.Buffer
+	err := app.runOverviewCommand([]string{"lint", "task"}, &out)
+
+	// Verify
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 validation issues")
+	assert.Contains(t, out.String(), `missing section "Progress Timeline"`)
+	assert.Contains(t, out.String(), `line 9: link target "plan.md" does not exist`)
+}
+
+// TestOverviewCommand_LintValid verifies a complete overview passes
+func TestOverviewCommand_LintValid(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	app, overviewPath := newOverviewApp(t, h)
+	h.WriteFile(filepath.Join(filepath.Dir(overviewPath), "plan.md"), "plan")
+	h.WriteFile(overviewPath, "**Status**: Active\n\n## Key Decisions\n\n## Key Documents\n\n- [Plan](plan.md)\n\n## Progress Timeline\n")
+
+	// Exercise
+	var out bytes.Buffer
+	err := app.runOverviewCommand([]string{"lint", "--session", "task", "--json"}, &out)
+
+	// Verify
+	require.NoError(t, err)
+	assert.JSONEq(t, "[]", out.String())
+}
+
+// TestOverviewCommand_UpdateUpToDate verifies update reports when no transcript entries are pending
+func TestOverviewCommand_UpdateUpToDate(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	app, _ := newOverviewApp(t, h)
+
+	// Exercise
+	var out bytes.Buffer
+	err := app.runOverviewCommand([]string{"update", "task"}, &out)
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, "session-overview.md of "+overviewSession+" is up to date\n", out.String())
+}
+
+// TestOverviewCommand_RebuildWithoutTranscript verifies rebuild refuses to wipe an overview it cannot regenerate
+func TestOverviewCommand_RebuildWithoutTranscript(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	app, overviewPath := newOverviewApp(t, h)
+
+	// Exercise
+	var out bytes.Buffer
+	err := app.runOverviewCommand([]string{"rebuild", "task"}, &out)
+
+	// Verify
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no transcript")
+	testutil.AssertFileContains(t, h.FS, overviewPath, "hallucinated")
+}
@@ -81,7 +81,7 @@ func (a *App) handleNewSession() (SessionInfo, error) {
 	ui.ShowGenerating()
 
 	// Controller: route to usecase
-	newSessionUC := newuc.New(a.deps.FS, a.deps.Cmd, a.deps.UUID, a.deps.Clock, a.sessionsDir)
+	newSessionUC := newuc.New(a.deps.FS, a.newLLMClient(), a.deps.UUID, a.deps.Clock, a.sessionsDir)
 	sessionName, sessionPath, claudeSessionID, err := newSessionUC.Execute(description)
 	if err != nil {
 		return SessionInfo{}, fmt.Errorf("failed to create new session: %w", err)
@@ -183,7 +183,7 @@ func (a *App) handleResumeOrFork(fm *ui.Model) (SessionInfo, error) {
 		}
 
 		// Controller: route to usecase
-		forkUC := forkuc.New(a.deps.FS, a.deps.Cmd, a.deps.UUID, a.sessionsDir)
+		forkUC := forkuc.New(a.deps.FS, a.newLLMClient(), a.deps.UUID, a.sessionsDir)
 		newSessionName, newSessionPath, newClaudeSessionID, err := forkUC.Execute(fm.SessionName, forkDescription)
 		if err != nil {
 			return SessionInfo{}, fmt.Errorf("failed to fork session: %w", err)
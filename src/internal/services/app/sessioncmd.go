@@ -0,0 +1,336 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"claudex/internal/services/llm"
+	"claudex/internal/services/session"
+	deleteuc "claudex/internal/usecases/session/delete"
+	newuc "claudex/internal/usecases/session/new"
+	forkuc "claudex/internal/usecases/session/resume/fork"
+	freshuc "claudex/internal/usecases/session/resume/fresh"
+
+	"github.com/spf13/afero"
+)
+
+const sessionUsage = `Usage: claudex session <command> [flags] [args]
+
+Commands:
+  list                          List sessions (most recently used first)
+  new <description>             Create a new session (--name sets its name instead of a generated one)
+  resume <session>              Resume an existing session
+  fork <session> <description>  Fork a session into a new one with copied files
+  fresh <session>               Start a new session from a session's files (deletes the original)
+  delete <session>              Delete a session
+
+<session> is a full session name, a unique name prefix or a Claude session ID.
+
+Flags:
+  --json     Print machine-readable JSON instead of text
+  --launch   Launch Claude for the resulting session (new, resume, fork, fresh)
+  --name     Session name to use instead of generating one from the description (new)
+  --yes      Delete a session matched by prefix or Claude session ID (delete)
+`
+
+// SessionListEntry is the scriptable view of a session printed by `session list`
+type SessionListEntry struct {
+	Name            string `json:"name"`
+	Path            string `json:"path"`
+	Description     string `json:"description"`
+	ClaudeSessionID string `json:"claude_session_id,omitempty"`
+	Created         string `json:"created,omitempty"`
+	LastUsed        string `json:"last_used,omitempty"`
+}
+
+// sessionDeleteResult is the JSON output of `session delete`
+type sessionDeleteResult struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Deleted bool   `json:"deleted"`
+}
+
+// sessionFlags holds the flags shared by the session subcommands
+type sessionFlags struct {
+	json   bool
+	launch bool
+	yes    bool
+	name   string
+}
+
+// runSessionCommand dispatches `claudex session <subcommand>` to its handler
+func (a *App) runSessionCommand(args []string, out io.Writer) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(os.Stderr, sessionUsage)
+		if len(args) == 0 {
+			return fmt.Errorf("missing session subcommand")
+		}
+		return nil
+	}
+
+	sub, rest := args[0], args[1:]
+	fset := flag.NewFlagSet("session "+sub, flag.ContinueOnError)
+	fset.SetOutput(os.Stderr)
+	fset.Usage = func() { fmt.Fprint(os.Stderr, sessionUsage) }
+
+	var flags sessionFlags
+	fset.BoolVar(&flags.json, "json", false, "Print machine-readable JSON")
+	if sub != "list" && sub != "delete" {
+		fset.BoolVar(&flags.launch, "launch", false, "Launch Claude for the resulting session")
+	}
+	if sub == "new" {
+		fset.StringVar(&flags.name, "name", "", "Session name to use instead of generating one")
+	}
+	if sub == "delete" {
+		fset.BoolVar(&flags.yes, "yes", false, "Delete a session matched by prefix or Claude session ID")
+	}
+
+	positional, err := parseInterspersed(fset, rest)
+	if err != nil {
+		return err
+	}
+
+	switch sub {
+	case "list":
+		if len(positional) > 0 {
+			return fmt.Errorf("list takes no arguments")
+		}
+		return a.sessionList(out, flags)
+	case "new":
+		if len(positional) == 0 {
+			return fmt.Errorf("usage: claudex session new [--name <name>] <description>")
+		}
+		return a.sessionNew(out, flags, strings.Join(positional, " "))
+	case "resume":
+		if len(positional) != 1 {
+			return fmt.Errorf("usage: claudex session resume <session>")
+		}
+		return a.sessionResume(out, flags, positional[0])
+	case "fork":
+		if len(positional) < 2 {
+			return fmt.Errorf("usage: claudex session fork <session> <description>")
+		}
+		return a.sessionFork(out, flags, positional[0], strings.Join(positional[1:], " "))
+	case "fresh":
+		if len(positional) != 1 {
+			return fmt.Errorf("usage: claudex session fresh <session>")
+		}
+		return a.sessionFresh(out, flags, positional[0])
+	case "delete":
+		if len(positional) != 1 {
+			return fmt.Errorf("usage: claudex session delete <session>")
+		}
+		return a.sessionDelete(out, flags, positional[0])
+	default:
+		fmt.Fprint(os.Stderr, sessionUsage)
+		return fmt.Errorf("unknown session subcommand: %s", sub)
+	}
+}
+
+// sessionList prints all sessions, most recently used first.
+// Sessions whose metadata cannot be read are reported on stderr and left out.
+func (a *App) sessionList(out io.Writer, flags sessionFlags) error {
+	entries, err := afero.ReadDir(a.deps.FS, a.sessionsDir)
+	if err != nil {
+		return fmt.Errorf("failed to read sessions directory: %w", err)
+	}
+
+	sessions := []SessionListEntry{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		sessionPath := filepath.Join(a.sessionsDir, entry.Name())
+		metadata, err := session.ReadMetadata(a.deps.FS, sessionPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping session %s: %v\n", entry.Name(), err)
+			continue
+		}
+		sessions = append(sessions, SessionListEntry{
+			Name:            entry.Name(),
+			Path:            sessionPath,
+			Description:     metadata.Description,
+			ClaudeSessionID: session.ExtractClaudeSessionID(entry.Name()),
+			Created:         metadata.Created,
+			LastUsed:        metadata.LastUsed,
+		})
+	}
+
+	// Sort by last used (falling back to created) in descending order
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return lastActivity(sessions[i]).After(lastActivity(sessions[j]))
+	})
+
+	if flags.json {
+		return writeJSON(out, sessions)
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tLAST USED\tDESCRIPTION")
+	for _, s := range sessions {
+		lastUsed := "-"
+		if t := lastActivity(s); !t.IsZero() {
+			lastUsed = t.Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Name, lastUsed, s.Description)
+	}
+	return tw.Flush()
+}
+
+// lastActivity returns the last used time of a session, falling back to its creation time
+func lastActivity(s SessionListEntry) time.Time {
+	for _, ts := range []string{s.LastUsed, s.Created} {
+		if t, err := time.Parse(time.RFC3339, ts); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// sessionNew creates a new session from the given description. With --name the
+// session is named as given and no model is asked for a name.
+func (a *App) sessionNew(out io.Writer, flags sessionFlags, description string) error {
+	var client llm.Client
+	if flags.name == "" {
+		client = a.newLLMClient()
+	}
+	newSessionUC := newuc.New(a.deps.FS, client, a.deps.UUID, a.deps.Clock, a.sessionsDir)
+	sessionName, sessionPath, claudeSessionID, err := newSessionUC.ExecuteNamed(description, flags.name)
+	if err != nil {
+		return fmt.Errorf("failed to create new session: %w", err)
+	}
+
+	return a.finishSessionCommand(out, flags, SessionInfo{
+		Name:     sessionName,
+		Path:     sessionPath,
+		ClaudeID: claudeSessionID,
+		Mode:     LaunchModeNew,
+	})
+}
+
+// sessionResume resolves an existing session and optionally resumes it in Claude
+func (a *App) sessionResume(out io.Writer, flags sessionFlags, ref string) error {
+	sessionName, err := session.ResolveSessionName(a.deps.FS, a.sessionsDir, ref)
+	if err != nil {
+		return err
+	}
+
+	claudeSessionID := session.ExtractClaudeSessionID(sessionName)
+	if claudeSessionID == "" {
+		return fmt.Errorf("could not extract session ID for resume: %s", sessionName)
+	}
+
+	return a.finishSessionCommand(out, flags, SessionInfo{
+		Name:     sessionName,
+		Path:     filepath.Join(a.sessionsDir, sessionName),
+		ClaudeID: claudeSessionID,
+		Mode:     LaunchModeResume,
+	})
+}
+
+// sessionFork forks an existing session into a new one with the given description
+func (a *App) sessionFork(out io.Writer, flags sessionFlags, ref, description string) error {
+	originalName, err := session.ResolveSessionName(a.deps.FS, a.sessionsDir, ref)
+	if err != nil {
+		return err
+	}
+
+	forkUC := forkuc.New(a.deps.FS, a.newLLMClient(), a.deps.UUID, a.sessionsDir)
+	newSessionName, newSessionPath, newClaudeSessionID, err := forkUC.Execute(originalName, description)
+	if err != nil {
+		return fmt.Errorf("failed to fork session: %w", err)
+	}
+
+	return a.finishSessionCommand(out, flags, SessionInfo{
+		Name:         newSessionName,
+		Path:         newSessionPath,
+		ClaudeID:     newClaudeSessionID,
+		Mode:         LaunchModeFork,
+		OriginalName: originalName,
+	})
+}
+
+// sessionFresh replaces an existing session with a fresh-memory copy
+func (a *App) sessionFresh(out io.Writer, flags sessionFlags, ref string) error {
+	originalName, err := session.ResolveSessionName(a.deps.FS, a.sessionsDir, ref)
+	if err != nil {
+		return err
+	}
+
+	freshUC := freshuc.New(a.deps.FS, a.deps.UUID, a.sessionsDir)
+	newSessionName, newSessionPath, newClaudeSessionID, err := freshUC.Execute(originalName)
+	if err != nil {
+		return fmt.Errorf("failed to create fresh session: %w", err)
+	}
+
+	return a.finishSessionCommand(out, flags, SessionInfo{
+		Name:         newSessionName,
+		Path:         newSessionPath,
+		ClaudeID:     newClaudeSessionID,
+		Mode:         LaunchModeFresh,
+		OriginalName: originalName,
+	})
+}
+
+// sessionDelete removes an existing session folder. A ref that is not the full session
+// name needs --yes, so a prefix cannot delete a session the caller did not expect.
+func (a *App) sessionDelete(out io.Writer, flags sessionFlags, ref string) error {
+	sessionName, err := session.ResolveSessionName(a.deps.FS, a.sessionsDir, ref)
+	if err != nil {
+		return err
+	}
+	if sessionName != ref && !flags.yes {
+		return fmt.Errorf("%q matches session %s; pass the full name or --yes to delete it", ref, sessionName)
+	}
+
+	deleteUC := deleteuc.New(a.deps.FS, a.sessionsDir)
+	sessionPath, err := deleteUC.Execute(sessionName)
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	if flags.json {
+		return writeJSON(out, sessionDeleteResult{Name: sessionName, Path: sessionPath, Deleted: true})
+	}
+	fmt.Fprintf(out, "Deleted session %s\n", sessionName)
+	return nil
+}
+
+// finishSessionCommand prints the resulting session and launches Claude when requested
+func (a *App) finishSessionCommand(out io.Writer, flags sessionFlags, si SessionInfo) error {
+	if flags.json {
+		if err := writeJSON(out, si); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintf(out, "name: %s\n", si.Name)
+		fmt.Fprintf(out, "path: %s\n", si.Path)
+		fmt.Fprintf(out, "claude_session_id: %s\n", si.ClaudeID)
+		if si.OriginalName != "" {
+			fmt.Fprintf(out, "original_name: %s\n", si.OriginalName)
+		}
+	}
+
+	if !flags.launch {
+		return nil
+	}
+
+	if !a.isClaudeInstalled() {
+		return fmt.Errorf("claude CLI not found in PATH")
+	}
+	if err := a.setupClaudeDir(); err != nil {
+		return err
+	}
+	a.setupLogging()
+	a.renameLogFileForSession(si)
+	a.setEnvironment(si, a.cfg)
+
+	return a.launch(si)
+}
@@ -0,0 +1,223 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"claudex/internal/testutil"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unreadableFs fails to open one path, like a metadata file without read permission
+type unreadableFs struct {
+	afero.Fs
+	path string
+}
+
+func (f unreadableFs) Open(name string) (afero.File, error) {
+	if name == f.path {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrPermission}
+	}
+	return f.Fs.Open(name)
+}
+
+// newSessionCommandApp creates an App wired to the test harness for session subcommands
+func newSessionCommandApp(h *testutil.TestHarness, sessionsDir string) *App {
+	h.CreateDir(sessionsDir)
+	return &App{
+		deps: &Dependencies{
+			FS:    h.FS,
+			Cmd:   h.Commander,
+			Clock: h,
+			UUID:  h,
+			Env:   h.Env,
+		},
+		projectDir:  filepath.Dir(sessionsDir),
+		sessionsDir: sessionsDir,
+	}
+}
+
+// TestSessionCommand_ListJSON verifies sessions are listed as JSON, most recently used first
+func TestSessionCommand_ListJSON(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	sessionsDir := "/project/.claudex/sessions"
+	app := newSessionCommandApp(h, sessionsDir)
+
+	h.WriteFile(filepath.Join(sessionsDir, "old-task-aaaa1111-2222-3333-4444-555566667777", ".description"), "Old task")
+	h.WriteFile(filepath.Join(sessionsDir, "old-task-aaaa1111-2222-3333-4444-555566667777", ".last_used"), "2024-01-01T10:00:00Z")
+	h.WriteFile(filepath.Join(sessionsDir, "new-task-bbbb1111-2222-3333-4444-555566667777", ".description"), "New task")
+	h.WriteFile(filepath.Join(sessionsDir, "new-task-bbbb1111-2222-3333-4444-555566667777", ".last_used"), "2024-06-01T10:00:00Z")
+
+	// Exercise
+	var out bytes.Buffer
+	err := app.runSessionCommand([]string{"list", "--json"}, &out)
+
+	// Verify
+	require.NoError(t, err)
+	var sessions []SessionListEntry
+	require.NoError(t, json.Unmarshal(out.Bytes(), &sessions))
+	require.Len(t, sessions, 2)
+	assert.Equal(t, "new-task-bbbb1111-2222-3333-4444-555566667777", sessions[0].Name)
+	assert.Equal(t, "New task", sessions[0].Description)
+	assert.Equal(t, "bbbb1111-2222-3333-4444-555566667777", sessions[0].ClaudeSessionID)
+	assert.Equal(t, "old-task-aaaa1111-2222-3333-4444-555566667777", sessions[1].Name)
+}
+
+// TestSessionCommand_NewJSON verifies a session is created from positional args without a TTY
+func TestSessionCommand_NewJSON(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	h.UUIDs = []string{"cccc1111-2222-3333-4444-555566667777"}
+	sessionsDir := "/project/.claudex/sessions"
+	app := newSessionCommandApp(h, sessionsDir)
+
+	// Exercise
+	var out bytes.Buffer
+	err := app.runSessionCommand([]string{"new", "--json", "fix", "login", "bug"}, &out)
+
+	// Verify
+	require.NoError(t, err)
+	var si SessionInfo
+	require.NoError(t, json.Unmarshal(out.Bytes(), &si))
+	assert.Equal(t, LaunchModeNew, si.Mode)
+	assert.Equal(t, "cccc1111-2222-3333-4444-555566667777", si.ClaudeID)
+
+	description, err := afero.ReadFile(h.FS, filepath.Join(si.Path, ".description"))
+	require.NoError(t, err)
+	assert.Equal(t, "fix login bug", string(description))
+}
+
+// TestSessionCommand_NewWithName verifies --name names the session without asking the model
+func TestSessionCommand_NewWithName(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	h.UUIDs = []string{"cccc1111-2222-3333-4444-555566667777"}
+	sessionsDir := "/project/.claudex/sessions"
+	app := newSessionCommandApp(h, sessionsDir)
+
+	// Exercise
+	var out bytes.Buffer
+	err := app.runSessionCommand([]string{"new", "fix", "login", "bug", "--name", "login-fix", "--json"}, &out)
+
+	// Verify
+	require.NoError(t, err)
+	var si SessionInfo
+	require.NoError(t, json.Unmarshal(out.Bytes(), &si))
+	assert.Equal(t, "login-fix-cccc1111-2222-3333-4444-555566667777", si.Name)
+	assert.Empty(t, h.Commander.Invocations, "no model should be asked for a name")
+	testutil.AssertFileContains(t, h.FS, filepath.Join(si.Path, ".description"), "fix login bug")
+}
+
+// TestSessionCommand_DeleteByPrefix verifies delete resolves a confirmed unique prefix and removes the folder
+func TestSessionCommand_DeleteByPrefix(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	sessionsDir := "/project/.claudex/sessions"
+	app := newSessionCommandApp(h, sessionsDir)
+	sessionPath := filepath.Join(sessionsDir, "auth-work-dddd1111-2222-3333-4444-555566667777")
+	h.WriteFile(filepath.Join(sessionPath, ".description"), "Auth work")
+
+	// Exercise
+	var out bytes.Buffer
+	err := app.runSessionCommand([]string{"delete", "auth-work", "--yes", "--json"}, &out)
+
+	// Verify
+	require.NoError(t, err)
+	var result sessionDeleteResult
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	assert.True(t, result.Deleted)
+	assert.Equal(t, sessionPath, result.Path)
+
+	exists, err := afero.DirExists(h.FS, sessionPath)
+	require.NoError(t, err)
+	assert.False(t, exists, "session folder should be removed")
+}
+
+// TestSessionCommand_DeleteRequiresExactNameOrYes verifies a prefix or session ID alone deletes nothing
+func TestSessionCommand_DeleteRequiresExactNameOrYes(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	sessionsDir := "/project/.claudex/sessions"
+	app := newSessionCommandApp(h, sessionsDir)
+	sessionName := "auth-work-dddd1111-2222-3333-4444-555566667777"
+	sessionPath := filepath.Join(sessionsDir, sessionName)
+	h.WriteFile(filepath.Join(sessionPath, ".description"), "Auth work")
+
+	// Exercise
+	prefixErr := app.runSessionCommand([]string{"delete", "auth-work"}, &bytes.Buffer{})
+	idErr := app.runSessionCommand([]string{"delete", "dddd1111-2222-3333-4444-555566667777"}, &bytes.Buffer{})
+	exists, err := afero.DirExists(h.FS, sessionPath)
+	require.NoError(t, err)
+	var out bytes.Buffer
+	exactErr := app.runSessionCommand([]string{"delete", sessionName}, &out)
+
+	// Verify
+	require.Error(t, prefixErr)
+	assert.Contains(t, prefixErr.Error(), "--yes")
+	require.Error(t, idErr)
+	assert.True(t, exists, "session folder should be kept without confirmation")
+	require.NoError(t, exactErr)
+	assert.Contains(t, out.String(), "Deleted session "+sessionName)
+}
+
+// TestSessionCommand_ListSkipsUnreadableSession verifies one broken session does not fail the list
+func TestSessionCommand_ListSkipsUnreadableSession(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	sessionsDir := "/project/.claudex/sessions"
+	app := newSessionCommandApp(h, sessionsDir)
+	h.WriteFile(filepath.Join(sessionsDir, "good-aaaa1111-2222-3333-4444-555566667777", ".description"), "Good")
+	brokenDescription := filepath.Join(sessionsDir, "broken-bbbb1111-2222-3333-4444-555566667777", ".description")
+	h.WriteFile(brokenDescription, "Broken")
+	app.deps.FS = unreadableFs{Fs: h.FS, path: brokenDescription}
+
+	// Exercise
+	var out bytes.Buffer
+	err := app.runSessionCommand([]string{"list", "--json"}, &out)
+
+	// Verify
+	require.NoError(t, err)
+	var sessions []SessionListEntry
+	require.NoError(t, json.Unmarshal(out.Bytes(), &sessions))
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "good-aaaa1111-2222-3333-4444-555566667777", sessions[0].Name)
+}
+
+// TestSessionCommand_ResumeWithoutLaunch verifies resume prints session info without starting Claude
+func TestSessionCommand_ResumeWithoutLaunch(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	sessionsDir := "/project/.claudex/sessions"
+	app := newSessionCommandApp(h, sessionsDir)
+	h.CreateDir(filepath.Join(sessionsDir, "api-eeee1111-2222-3333-4444-555566667777"))
+
+	// Exercise
+	var out bytes.Buffer
+	err := app.runSessionCommand([]string{"resume", "eeee1111-2222-3333-4444-555566667777"}, &out)
+
+	// Verify
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "name: api-eeee1111-2222-3333-4444-555566667777")
+	assert.Empty(t, h.Commander.Invocations, "claude should not be started without --launch")
+}
+
+// TestSessionCommand_UnknownSubcommand verifies unknown subcommands return an error
+func TestSessionCommand_UnknownSubcommand(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	app := newSessionCommandApp(h, "/project/.claudex/sessions")
+
+	// Exercise
+	err := app.runSessionCommand([]string{"rename"}, &bytes.Buffer{})
+
+	// Verify
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown session subcommand")
+}
@@ -1,7 +1,9 @@
 package commander
 
 import (
+	"context"
 	"io"
+	"os"
 	"os/exec"
 )
 
@@ -13,6 +15,12 @@ type Commander interface {
 	Start(name string, stdin io.Reader, stdout, stderr io.Writer, args ...string) error
 }
 
+// ContextCommander is implemented by commanders that can kill a process when its
+// context expires and pass extra environment variables to it
+type ContextCommander interface {
+	StartContext(ctx context.Context, extraEnv []string, name string, stdin io.Reader, stdout, stderr io.Writer, args ...string) error
+}
+
 // OsCommander is the production implementation of Commander
 type OsCommander struct{}
 
@@ -28,6 +36,15 @@ func (c *OsCommander) Start(name string, stdin io.Reader, stdout, stderr io.Writ
 	return cmd.Run()
 }
 
+func (c *OsCommander) StartContext(ctx context.Context, extraEnv []string, name string, stdin io.Reader, stdout, stderr io.Writer, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(os.Environ(), extraEnv...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
+
 // New creates a new Commander instance
 func New() Commander {
 	return &OsCommander{}
@@ -15,10 +15,256 @@ type Features struct {
 	AutodocFrequency       int  `toml:"autodoc_frequency"`
 }
 
+// AutodocTrigger configures a single documentation update trigger
+type AutodocTrigger struct {
+	Enabled    bool   `toml:"enabled"`
+	Model      string `toml:"model"`
+	Frequency  int    `toml:"frequency"`
+	OutputFile string `toml:"output_file"`
+}
+
+// Autodoc holds the independently configurable documentation update triggers
+type Autodoc struct {
+	Progress     AutodocTrigger     `toml:"progress"`      // every N tool executions
+	Subagent     AutodocTrigger     `toml:"subagent"`      // every N subagent completions
+	SessionEnd   AutodocTrigger     `toml:"session_end"`   // when the session terminates
+	HistoryLimit int                `toml:"history_limit"` // snapshots of each document kept in <session>/.history (0 disables)
+	Validation   OverviewValidation `toml:"validation"`    // structural checks applied to generated documents
+	Redaction    AutodocRedaction   `toml:"redaction"`     // masking of transcript content sent to the model
+}
+
+// OverviewValidation configures the checks a generated session document must pass before it replaces the previous version
+type OverviewValidation struct {
+	Enabled          bool     `toml:"enabled"`
+	RequiredSections []string `toml:"required_sections"` // matched case-insensitively against headings and **Field**: lines
+	CheckLinks       bool     `toml:"check_links"`       // relative links must resolve to files in the session folder
+}
+
+// AutodocRedaction configures the masking of transcript entries before they are formatted into
+// the doc update prompt. Credentials and tokens are always masked while it is enabled.
+type AutodocRedaction struct {
+	Enabled          bool            `toml:"enabled"`
+	Emails           bool            `toml:"emails"`            // mask email addresses
+	EntropyThreshold float64         `toml:"entropy_threshold"` // bits per character that mask a random-looking token (0 disables)
+	Patterns         []SecretPattern `toml:"patterns"`          // masked in addition to the built-in patterns
+}
+
+// DocsUpdate selects which changed files make --update-docs rewrite index.md files.
+// Patterns are gitignore-style globs ("**" spans directories, "!" re-includes).
+type DocsUpdate struct {
+	Include []string `toml:"include"` // when set, only matching files count
+	Exclude []string `toml:"exclude"` // matching files never count; replaces the defaults when set
+	// PropagationDepth is how many parent index.md levels above a created or
+	// significantly changed index are refreshed too (0 disables propagation)
+	PropagationDepth int `toml:"propagation_depth"`
+	Workers          int `toml:"workers"`         // index updates run in parallel
+	TimeoutSeconds   int `toml:"timeout_seconds"` // per-index limit (0 uses the [llm] timeout)
+	// DefaultBranch seeds new branches from their merge-base with it ("" detects it from
+	// origin/HEAD, else a local main or master)
+	DefaultBranch string `toml:"default_branch"`
+}
+
+// DocsCheck sets when `claudex docs check` reports an index.md as stale or missing.
+// Files are counted through the [docs.update] include/exclude filter.
+type DocsCheck struct {
+	MaxCommits int `toml:"max_commits"`  // commits to documented files since the index changed (0 disables)
+	MaxAgeDays int `toml:"max_age_days"` // days between the index's last commit and the newest one to its files (0 disables)
+	MinFiles   int `toml:"min_files"`    // directories with this many files need an index.md (0 disables)
+}
+
+// Docs groups the project documentation settings
+type Docs struct {
+	Update DocsUpdate `toml:"update"`
+	Check  DocsCheck  `toml:"check"`
+}
+
+// LLM configures the backend used for background, non-interactive model calls
+type LLM struct {
+	Backend        string `toml:"backend"`         // "claude-cli" (default), "anthropic" or "fake"
+	Model          string `toml:"model"`           // Model for calls that don't set their own
+	TimeoutSeconds int    `toml:"timeout_seconds"` // Per-call timeout (0 disables)
+	BaseURL        string `toml:"base_url"`        // Messages API base URL (anthropic backend)
+	APIKeyEnv      string `toml:"api_key_env"`     // Env var holding the API key (anthropic backend)
+	MaxTokens      int    `toml:"max_tokens"`      // Response token limit (anthropic backend)
+}
+
+// PolicyRule matches tool calls in the PreToolUse hook. Every matcher that is set must
+// match; within a list any entry matches. Decision is "allow", "ask" or "deny".
+type PolicyRule struct {
+	Name           string   `toml:"name"`
+	Tools          []string `toml:"tools"`           // tool name globs ("Bash", "mcp__*")
+	Commands       []string `toml:"commands"`        // regular expressions matched against each Bash command
+	Paths          []string `toml:"paths"`           // gitignore-style globs on file paths, relative to the project root
+	OutsideProject bool     `toml:"outside_project"` // a file path or Bash argument resolves outside the project root
+	SubagentTypes  []string `toml:"subagent_types"`  // subagent types of Task calls, case-insensitive
+	Decision       string   `toml:"decision"`
+	Reason         string   `toml:"reason"`
+}
+
+// Policy configures the PreToolUse rule engine. When several rules match a tool call,
+// the most restrictive decision wins (deny, then ask, then allow).
+type Policy struct {
+	Enabled      bool         `toml:"enabled"`
+	Default      string       `toml:"default"`       // decision when no rule matches
+	Audit        bool         `toml:"audit"`         // record every decision in the session's policy-audit.jsonl
+	BuiltinRules bool         `toml:"builtin_rules"` // evaluate DefaultPolicyRules() along with Rules
+	Rules        []PolicyRule `toml:"rules"`
+}
+
+// SecretPattern is a custom credential pattern; when the regex has capture groups,
+// the last one is the secret
+type SecretPattern struct {
+	Name  string `toml:"name"`
+	Regex string `toml:"regex"`
+}
+
+// Secrets configures the credential scan of Write, Edit, MultiEdit and Bash tool inputs
+type Secrets struct {
+	Enabled           bool            `toml:"enabled"`
+	Action            string          `toml:"action"`             // "deny" or "ask" when a secret is found
+	EntropyThreshold  float64         `toml:"entropy_threshold"`  // bits per character that flag a random-looking token (0 disables)
+	Patterns          []SecretPattern `toml:"patterns"`           // scanned in addition to the built-in patterns
+	AllowPaths        []string        `toml:"allow_paths"`        // gitignore-style globs of files that are never scanned
+	AllowFingerprints []string        `toml:"allow_fingerprints"` // findings accepted as not secret
+}
+
 type Config struct {
 	Doc         []string `toml:"doc"`
 	NoOverwrite bool     `toml:"no_overwrite"`
 	Features    Features `toml:"features"`
+	Autodoc     Autodoc  `toml:"autodoc"`
+	Docs        Docs     `toml:"docs"`
+	LLM         LLM      `toml:"llm"`
+	Policy      Policy   `toml:"policy"`
+	Secrets     Secrets  `toml:"secrets"`
+}
+
+// DefaultLLM returns the LLM settings used when none are configured
+func DefaultLLM() LLM {
+	return LLM{
+		Backend:        "claude-cli",
+		Model:          "haiku",
+		TimeoutSeconds: 300,
+		APIKeyEnv:      "ANTHROPIC_API_KEY",
+		MaxTokens:      4096,
+	}
+}
+
+// DefaultAutodoc returns the trigger policies used when none are configured
+func DefaultAutodoc() Autodoc {
+	return Autodoc{
+		Progress:     AutodocTrigger{Enabled: true, Model: "haiku", Frequency: 5, OutputFile: "session-overview.md"},
+		Subagent:     AutodocTrigger{Enabled: true, Model: "haiku", Frequency: 1, OutputFile: "session-overview.md"},
+		SessionEnd:   AutodocTrigger{Enabled: true, Model: "haiku", Frequency: 1, OutputFile: "session-overview.md"},
+		HistoryLimit: 20,
+		Validation:   DefaultOverviewValidation(),
+		Redaction:    DefaultAutodocRedaction(),
+	}
+}
+
+// DefaultOverviewValidation returns the overview checks used when none are configured
+func DefaultOverviewValidation() OverviewValidation {
+	return OverviewValidation{
+		Enabled:          true,
+		RequiredSections: []string{"Status", "Key Decisions", "Documents", "Progress Timeline"},
+		CheckLinks:       true,
+	}
+}
+
+// DefaultAutodocRedaction returns the transcript masking used when none is configured
+func DefaultAutodocRedaction() AutodocRedaction {
+	return AutodocRedaction{
+		Enabled:          true,
+		Emails:           true,
+		EntropyThreshold: 4.5,
+	}
+}
+
+// DefaultDocsUpdate returns the --update-docs file filter used when none is configured:
+// documentation, lockfiles, vendored dependencies and generated code never trigger index rewrites
+func DefaultDocsUpdate() DocsUpdate {
+	return DocsUpdate{
+		Workers: 4,
+		Include: []string{},
+		Exclude: []string{
+			"*.md",
+			"docs/",
+			"vendor/",
+			"node_modules/",
+			"go.sum",
+			"*.lock",
+			"package-lock.json",
+			"pnpm-lock.yaml",
+			"*.pb.go",
+			"*_gen.go",
+			"*.gen.*",
+			"*.min.js",
+		},
+	}
+}
+
+// DefaultDocsCheck returns the staleness thresholds used when none are configured
+func DefaultDocsCheck() DocsCheck {
+	return DocsCheck{
+		MaxCommits: 5,
+		MaxAgeDays: 30,
+		MinFiles:   10,
+	}
+}
+
+// DefaultDocs returns the project documentation settings used when none are configured
+func DefaultDocs() Docs {
+	return Docs{Update: DefaultDocsUpdate(), Check: DefaultDocsCheck()}
+}
+
+// DefaultPolicy returns the PreToolUse policy used when none is configured
+func DefaultPolicy() Policy {
+	return Policy{
+		Enabled:      true,
+		Default:      "allow",
+		Audit:        true,
+		BuiltinRules: true,
+	}
+}
+
+// DefaultPolicyRules returns the built-in rules: no force pushes, no recursive
+// deletes outside the project and no edits to .env files
+func DefaultPolicyRules() []PolicyRule {
+	return []PolicyRule{
+		{
+			Name:     "no-force-push",
+			Tools:    []string{"Bash"},
+			Commands: []string{`\bgit\s+push\b.*(\s--force(\s|=|$)|\s-[a-zA-Z]*f[a-zA-Z]*(\s|$)|\s\+\S)`},
+			Decision: "deny",
+			Reason:   "force pushes rewrite shared history",
+		},
+		{
+			Name:           "no-rm-rf-outside-project",
+			Tools:          []string{"Bash"},
+			Commands:       []string{`\brm\s+(-\S+\s+)*(-[a-zA-Z]*[rR][a-zA-Z]*|--recursive)(\s|$)`},
+			OutsideProject: true,
+			Decision:       "deny",
+			Reason:         "recursive deletes are limited to the project directory",
+		},
+		{
+			Name:     "no-env-edits",
+			Tools:    []string{"Edit", "MultiEdit", "Write", "NotebookEdit"},
+			Paths:    []string{".env", ".env.*", "!.env.example", "!.env.sample", "!.env.template"},
+			Decision: "deny",
+			Reason:   ".env files hold secrets and are edited by hand",
+		},
+	}
+}
+
+// DefaultSecrets returns the secret scan used when none is configured: findings deny
+// the call, and lockfiles and checksums are not scanned
+func DefaultSecrets() Secrets {
+	return Secrets{
+		Enabled:          true,
+		Action:           "deny",
+		EntropyThreshold: 4.5,
+		AllowPaths:       []string{"go.sum", "*.lock", "package-lock.json", "pnpm-lock.yaml"},
+	}
 }
 
 // Load loads configuration from the specified path using the provided filesystem
@@ -31,6 +277,11 @@ func Load(fs afero.Fs, path string) (*Config, error) {
 			AutodocSessionEnd:      true,
 			AutodocFrequency:       5,
 		},
+		Autodoc: DefaultAutodoc(),
+		Docs:    DefaultDocs(),
+		LLM:     DefaultLLM(),
+		Policy:  DefaultPolicy(),
+		Secrets: DefaultSecrets(),
 	}
 
 	if _, err := fs.Stat(path); err == nil {
@@ -38,9 +289,38 @@ func Load(fs afero.Fs, path string) (*Config, error) {
 		if err != nil {
 			return nil, err
 		}
-		if _, err := toml.Decode(string(data), config); err != nil {
+		md, err := toml.Decode(string(data), config)
+		if err != nil {
 			return nil, err
 		}
+		reconcileAutodoc(md, config)
 	}
 	return config, nil
 }
+
+// reconcileAutodoc keeps the legacy [features] toggles and the [autodoc.*] triggers in sync.
+// Keys set explicitly under [autodoc.*] win; otherwise the [features] values seed the triggers.
+func reconcileAutodoc(md toml.MetaData, config *Config) {
+	if md.IsDefined("autodoc", "progress", "enabled") {
+		config.Features.AutodocSessionProgress = config.Autodoc.Progress.Enabled
+	} else {
+		config.Autodoc.Progress.Enabled = config.Features.AutodocSessionProgress
+	}
+
+	if md.IsDefined("autodoc", "progress", "frequency") {
+		config.Features.AutodocFrequency = config.Autodoc.Progress.Frequency
+	} else {
+		config.Autodoc.Progress.Frequency = config.Features.AutodocFrequency
+	}
+
+	if md.IsDefined("autodoc", "session_end", "enabled") {
+		config.Features.AutodocSessionEnd = config.Autodoc.SessionEnd.Enabled
+	} else {
+		config.Autodoc.SessionEnd.Enabled = config.Features.AutodocSessionEnd
+	}
+
+	// Subagent updates are in-session progress updates, so the legacy progress toggle disables them too
+	if !md.IsDefined("autodoc", "subagent", "enabled") {
+		config.Autodoc.Subagent.Enabled = config.Features.AutodocSessionProgress
+	}
+}
@@ -277,3 +277,264 @@ func TestLoad_FromTestdataFile(t *testing.T) {
 	require.True(t, cfg.Features.AutodocSessionEnd)
 	require.Equal(t, 10, cfg.Features.AutodocFrequency)
 }
+
+// TestLoad_AutodocTriggers_Defaults verifies every trigger has a default policy
+func TestLoad_AutodocTriggers_Defaults(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	cfg, err := Load(fs, "/test/.claudex/config.toml")
+	require.NoError(t, err)
+
+	require.Equal(t, DefaultAutodoc(), cfg.Autodoc)
+	require.Equal(t, 20, cfg.Autodoc.HistoryLimit)
+}
+
+// TestLoad_AutodocHistoryLimit verifies the overview history retention can be changed or disabled
+func TestLoad_AutodocHistoryLimit(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	configPath := "/test/.claudex/config.toml"
+	require.NoError(t, afero.WriteFile(fs, configPath, []byte("[autodoc]\nhistory_limit = 0\n"), 0644))
+
+	cfg, err := Load(fs, configPath)
+	require.NoError(t, err)
+
+	require.Equal(t, 0, cfg.Autodoc.HistoryLimit)
+	require.True(t, cfg.Autodoc.Progress.Enabled, "trigger defaults are kept")
+}
+
+// TestLoad_AutodocValidation verifies the overview checks can be customised per project
+func TestLoad_AutodocValidation(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	configPath := "/test/.claudex/config.toml"
+	content := `[autodoc.validation]
+required_sections = ["Status", "Next Steps"]
+check_links = false
+`
+	require.NoError(t, afero.WriteFile(fs, configPath, []byte(content), 0644))
+
+	cfg, err := Load(fs, configPath)
+	require.NoError(t, err)
+
+	require.True(t, cfg.Autodoc.Validation.Enabled, "enabled default is kept")
+	require.Equal(t, []string{"Status", "Next Steps"}, cfg.Autodoc.Validation.RequiredSections)
+	require.False(t, cfg.Autodoc.Validation.CheckLinks)
+}
+
+// TestLoad_DocsUpdateFilter verifies [docs.update] patterns replace the default filter
+func TestLoad_DocsUpdateFilter(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	configPath := "/test/.claudex/config.toml"
+	content := `[docs.update]
+include = ["src/**"]
+exclude = ["**/testdata/", "!src/keep.lock"]
+propagation_depth = 2
+default_branch = "develop"
+`
+	require.NoError(t, afero.WriteFile(fs, configPath, []byte(content), 0644))
+
+	cfg, err := Load(fs, configPath)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"src/**"}, cfg.Docs.Update.Include)
+	require.Equal(t, []string{"**/testdata/", "!src/keep.lock"}, cfg.Docs.Update.Exclude)
+	require.Equal(t, 2, cfg.Docs.Update.PropagationDepth)
+	require.Equal(t, "develop", cfg.Docs.Update.DefaultBranch)
+}
+
+// TestLoad_DocsUpdateDefaults verifies lockfiles, vendored and generated files are excluded by default
+func TestLoad_DocsUpdateDefaults(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	cfg, err := Load(fs, "/test/.claudex/config.toml")
+	require.NoError(t, err)
+
+	require.Empty(t, cfg.Docs.Update.Include)
+	require.Contains(t, cfg.Docs.Update.Exclude, "vendor/")
+	require.Contains(t, cfg.Docs.Update.Exclude, "go.sum")
+	require.Zero(t, cfg.Docs.Update.PropagationDepth, "propagation is opt-in")
+}
+
+// TestLoad_DocsCheck verifies [docs.check] keys override only the thresholds they set
+func TestLoad_DocsCheck(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	configPath := "/test/.claudex/config.toml"
+	content := `[docs.check]
+max_commits = 0
+min_files = 4
+`
+	require.NoError(t, afero.WriteFile(fs, configPath, []byte(content), 0644))
+
+	cfg, err := Load(fs, configPath)
+	require.NoError(t, err)
+
+	require.Zero(t, cfg.Docs.Check.MaxCommits)
+	require.Equal(t, DefaultDocsCheck().MaxAgeDays, cfg.Docs.Check.MaxAgeDays)
+	require.Equal(t, 4, cfg.Docs.Check.MinFiles)
+}
+
+// TestLoad_AutodocTriggers_ParsesPerTriggerSettings verifies each trigger is configured independently
+func TestLoad_AutodocTriggers_ParsesPerTriggerSettings(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	configPath := "/test/.claudex/config.toml"
+	content := `[autodoc.progress]
+enabled = true
+model = "sonnet"
+frequency = 12
+output_file = "progress.md"
+
+[autodoc.subagent]
+enabled = false
+
+[autodoc.session_end]
+frequency = 2`
+	require.NoError(t, afero.WriteFile(fs, configPath, []byte(content), 0644))
+
+	cfg, err := Load(fs, configPath)
+	require.NoError(t, err)
+
+	require.Equal(t, AutodocTrigger{Enabled: true, Model: "sonnet", Frequency: 12, OutputFile: "progress.md"}, cfg.Autodoc.Progress)
+	require.False(t, cfg.Autodoc.Subagent.Enabled)
+	require.Equal(t, "haiku", cfg.Autodoc.Subagent.Model, "unset keys keep defaults")
+	require.Equal(t, 2, cfg.Autodoc.SessionEnd.Frequency)
+	require.True(t, cfg.Autodoc.SessionEnd.Enabled)
+
+	// Explicit trigger settings are mirrored into the legacy feature toggles
+	require.Equal(t, 12, cfg.Features.AutodocFrequency)
+}
+
+// TestLoad_AutodocTriggers_SeededFromFeatures verifies legacy [features] toggles disable the triggers
+func TestLoad_AutodocTriggers_SeededFromFeatures(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	configPath := "/test/.claudex/config.toml"
+	content := `[features]
+autodoc_session_progress = false
+autodoc_session_end = false
+autodoc_frequency = 8`
+	require.NoError(t, afero.WriteFile(fs, configPath, []byte(content), 0644))
+
+	cfg, err := Load(fs, configPath)
+	require.NoError(t, err)
+
+	require.False(t, cfg.Autodoc.Progress.Enabled)
+	require.False(t, cfg.Autodoc.Subagent.Enabled)
+	require.False(t, cfg.Autodoc.SessionEnd.Enabled)
+	require.Equal(t, 8, cfg.Autodoc.Progress.Frequency)
+}
+
+// TestLoad_LLM_ParsesBackendSettings verifies the [llm] section overrides defaults
+func TestLoad_LLM_ParsesBackendSettings(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	configPath := "/test/.claudex/config.toml"
+	content := `[llm]
+backend = "anthropic"
+model = "sonnet"
+timeout_seconds = 60
+base_url = "http://localhost:8080"`
+	require.NoError(t, afero.WriteFile(fs, configPath, []byte(content), 0644))
+
+	cfg, err := Load(fs, configPath)
+	require.NoError(t, err)
+
+	require.Equal(t, "anthropic", cfg.LLM.Backend)
+	require.Equal(t, "sonnet", cfg.LLM.Model)
+	require.Equal(t, 60, cfg.LLM.TimeoutSeconds)
+	require.Equal(t, "http://localhost:8080", cfg.LLM.BaseURL)
+	require.Equal(t, "ANTHROPIC_API_KEY", cfg.LLM.APIKeyEnv, "unset keys keep defaults")
+	require.Equal(t, 4096, cfg.LLM.MaxTokens)
+}
+
+// TestLoad_PolicyDefaults verifies the policy engine is on with built-in rules when not configured
+func TestLoad_PolicyDefaults(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	cfg, err := Load(fs, "/test/.claudex/config.toml")
+	require.NoError(t, err)
+
+	require.Equal(t, DefaultPolicy(), cfg.Policy)
+	require.Empty(t, cfg.Policy.Rules)
+}
+
+// TestLoad_PolicyRules verifies [[policy.rules]] tables and [policy] keys are parsed
+func TestLoad_PolicyRules(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	configPath := "/test/.claudex/config.toml"
+	content := `[policy]
+default = "ask"
+builtin_rules = false
+
+[[policy.rules]]
+name = "confirm-agents"
+tools = ["Task"]
+subagent_types = ["general-purpose"]
+decision = "ask"
+reason = "confirm before delegating"
+
+[[policy.rules]]
+name = "no-migrations"
+paths = ["db/migrations/**"]
+outside_project = false
+decision = "ask"`
+	require.NoError(t, afero.WriteFile(fs, configPath, []byte(content), 0644))
+
+	cfg, err := Load(fs, configPath)
+	require.NoError(t, err)
+
+	require.True(t, cfg.Policy.Enabled, "unset keys keep defaults")
+	require.True(t, cfg.Policy.Audit)
+	require.False(t, cfg.Policy.BuiltinRules)
+	require.Equal(t, "ask", cfg.Policy.Default)
+	require.Equal(t, []PolicyRule{
+		{Name: "confirm-agents", Tools: []string{"Task"}, SubagentTypes: []string{"general-purpose"}, Decision: "ask", Reason: "confirm before delegating"},
+		{Name: "no-migrations", Paths: []string{"db/migrations/**"}, Decision: "ask"},
+	}, cfg.Policy.Rules)
+}
+
+// TestLoad_Secrets verifies [secrets] keys and [[secrets.patterns]] tables override the defaults
+func TestLoad_Secrets(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	configPath := "/test/.claudex/config.toml"
+	content := `[secrets]
+action = "ask"
+allow_paths = ["testdata/"]
+allow_fingerprints = ["0123456789abcdef"]
+
+[[secrets.patterns]]
+name = "internal-token"
+regex = 'itk_[a-z0-9]{32}'`
+	require.NoError(t, afero.WriteFile(fs, configPath, []byte(content), 0644))
+
+	cfg, err := Load(fs, configPath)
+	require.NoError(t, err)
+
+	require.True(t, cfg.Secrets.Enabled, "unset keys keep defaults")
+	require.Equal(t, 4.5, cfg.Secrets.EntropyThreshold)
+	require.Equal(t, "ask", cfg.Secrets.Action)
+	require.Equal(t, []string{"testdata/"}, cfg.Secrets.AllowPaths)
+	require.Equal(t, []string{"0123456789abcdef"}, cfg.Secrets.AllowFingerprints)
+	require.Equal(t, []SecretPattern{{Name: "internal-token", Regex: "itk_[a-z0-9]{32}"}}, cfg.Secrets.Patterns)
+
+	defaults, err := Load(fs, "/missing/config.toml")
+	require.NoError(t, err)
+	require.Equal(t, DefaultSecrets(), defaults.Secrets)
+}
+
+// TestLoad_AutodocRedaction verifies [autodoc.redaction] keys and patterns override the defaults
+func TestLoad_AutodocRedaction(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	configPath := "/test/.claudex/config.toml"
+	content := `[autodoc.redaction]
+emails = false
+
+[[autodoc.redaction.patterns]]
+name = "customer-id"
+regex = 'CUST-\d{6}'`
+	require.NoError(t, afero.WriteFile(fs, configPath, []byte(content), 0644))
+
+	cfg, err := Load(fs, configPath)
+	require.NoError(t, err)
+
+	require.True(t, cfg.Autodoc.Redaction.Enabled, "unset keys keep defaults")
+	require.False(t, cfg.Autodoc.Redaction.Emails)
+	require.Equal(t, 4.5, cfg.Autodoc.Redaction.EntropyThreshold)
+	require.Equal(t, []SecretPattern{{Name: "customer-id", Regex: `CUST-\d{6}`}}, cfg.Autodoc.Redaction.Patterns)
+}
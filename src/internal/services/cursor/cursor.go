@@ -0,0 +1,270 @@
+// Package cursor tracks how far each transcript of a session has been processed.
+// Cursors are keyed by transcript path and record a byte offset, a line number and
+// a checksum of the bytes just before the offset, so a cleared, resumed, truncated
+// or rotated transcript is never read with another file's position.
+package cursor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"claudex/internal/services/clock"
+	"claudex/internal/services/session"
+
+	"github.com/spf13/afero"
+)
+
+// StoreFile holds the cursors of a session folder
+const StoreFile = ".transcript-cursors.json"
+
+// checksumWindow is the number of bytes before the offset covered by the checksum
+const checksumWindow = 1024
+
+// Cursor is the processing position within one transcript
+type Cursor struct {
+	TranscriptPath string    `json:"transcript_path"`
+	Offset         int64     `json:"offset"`               // Byte offset just past the last processed line
+	Line           int       `json:"line"`                 // Number of lines processed
+	Checksum       string    `json:"checksum"`             // sha256 of the bytes preceding Offset (up to checksumWindow)
+	Rejections     int       `json:"rejections,omitempty"` // Updates in a row rejected from this position
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// State describes how a stored cursor relates to the transcript on disk
+type State string
+
+const (
+	// StateNew means the transcript has no cursor yet and is read from the start
+	StateNew State = "new"
+	// StateValid means the transcript still matches the cursor
+	StateValid State = "valid"
+	// StateLegacy means the position came from the old .last-processed-line-overview marker
+	StateLegacy State = "legacy"
+	// StateTruncated means the transcript is now shorter than the cursor offset
+	StateTruncated State = "truncated"
+	// StateRotated means the content before the offset changed (file replaced or rewritten)
+	StateRotated State = "rotated"
+)
+
+// Store reads and writes the cursors of one session folder
+type Store struct {
+	fs          afero.Fs
+	clock       clock.Clock
+	sessionPath string
+}
+
+// New creates a Store for the given session folder
+func New(fs afero.Fs, clk clock.Clock, sessionPath string) *Store {
+	return &Store{
+		fs:          fs,
+		clock:       clk,
+		sessionPath: sessionPath,
+	}
+}
+
+// path returns the cursor file path
+func (s *Store) path() string {
+	return filepath.Join(s.sessionPath, StoreFile)
+}
+
+// All returns every stored cursor keyed by transcript path
+func (s *Store) All() (map[string]Cursor, error) {
+	data, err := afero.ReadFile(s.fs, s.path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]Cursor{}, nil
+		}
+		return nil, fmt.Errorf("failed to read cursors: %w", err)
+	}
+
+	cursors := map[string]Cursor{}
+	if err := json.Unmarshal(data, &cursors); err != nil {
+		return nil, fmt.Errorf("failed to parse cursors: %w", err)
+	}
+	return cursors, nil
+}
+
+// Get returns the stored cursor for a transcript
+func (s *Store) Get(transcriptPath string) (Cursor, bool, error) {
+	cursors, err := s.All()
+	if err != nil {
+		return Cursor{}, false, err
+	}
+	c, ok := cursors[transcriptPath]
+	return c, ok, nil
+}
+
+// Resume returns the position to continue reading a transcript from.
+// Truncated or rotated transcripts restart at the beginning; the returned
+// State tells the caller why.
+func (s *Store) Resume(transcriptPath string) (Cursor, State, error) {
+	start := Cursor{TranscriptPath: transcriptPath}
+
+	c, ok, err := s.Get(transcriptPath)
+	if err != nil {
+		return start, StateNew, err
+	}
+
+	if !ok {
+		// Sessions tracked before cursors existed have a single line marker, which
+		// counted lines of the session's main transcript only
+		if filepath.Base(transcriptPath) != s.legacyTranscript() {
+			return start, StateNew, nil
+		}
+		line, err := session.ReadLastProcessedLine(s.fs, s.sessionPath)
+		if err == nil && line > 0 {
+			start.Line = line
+			return start, StateLegacy, nil
+		}
+		return start, StateNew, nil
+	}
+
+	state, err := s.Verify(c)
+	if err != nil {
+		return start, StateNew, err
+	}
+	if state != StateValid {
+		return start, state, nil
+	}
+	return c, StateValid, nil
+}
+
+// Verify checks that the transcript still contains the bytes the cursor was taken after
+func (s *Store) Verify(c Cursor) (State, error) {
+	info, err := s.fs.Stat(c.TranscriptPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return StateRotated, nil
+		}
+		return StateNew, fmt.Errorf("failed to stat transcript: %w", err)
+	}
+	if info.Size() < c.Offset {
+		return StateTruncated, nil
+	}
+
+	checksum, err := Checksum(s.fs, c.TranscriptPath, c.Offset)
+	if err != nil {
+		return StateNew, err
+	}
+	if checksum != c.Checksum {
+		return StateRotated, nil
+	}
+	return StateValid, nil
+}
+
+// Advance records that a transcript was processed up to offset/line.
+// The legacy line marker is removed once its transcript has a real cursor.
+func (s *Store) Advance(transcriptPath string, offset int64, line int) error {
+	checksum, err := Checksum(s.fs, transcriptPath, offset)
+	if err != nil {
+		return err
+	}
+
+	cursors, err := s.All()
+	if err != nil {
+		return err
+	}
+	cursors[transcriptPath] = Cursor{
+		TranscriptPath: transcriptPath,
+		Offset:         offset,
+		Line:           line,
+		Checksum:       checksum,
+		UpdatedAt:      s.clock.Now().UTC(),
+	}
+
+	if err := s.write(cursors); err != nil {
+		return err
+	}
+
+	// A marker no transcript can claim is dropped with the first cursor
+	if owner := s.legacyTranscript(); owner == "" || filepath.Base(transcriptPath) == owner {
+		legacy := filepath.Join(s.sessionPath, session.LastProcessedLineFile)
+		if err := s.fs.Remove(legacy); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove legacy line marker: %w", err)
+		}
+	}
+	return nil
+}
+
+// Reject records that an update reading a transcript from offset/line was rejected and
+// returns how many updates in a row were rejected from that position. A cursor at
+// another position is replaced, so the count restarts whenever the start moves.
+func (s *Store) Reject(transcriptPath string, offset int64, line int) (int, error) {
+	cursors, err := s.All()
+	if err != nil {
+		return 0, err
+	}
+
+	c, ok := cursors[transcriptPath]
+	if !ok || c.Offset != offset || c.Line != line {
+		checksum, err := Checksum(s.fs, transcriptPath, offset)
+		if err != nil {
+			return 0, err
+		}
+		c = Cursor{TranscriptPath: transcriptPath, Offset: offset, Line: line, Checksum: checksum}
+	}
+	c.Rejections++
+	c.UpdatedAt = s.clock.Now().UTC()
+	cursors[transcriptPath] = c
+
+	if err := s.write(cursors); err != nil {
+		return 0, err
+	}
+	return c.Rejections, nil
+}
+
+// legacyTranscript returns the file name of the session's main transcript, the one the
+// legacy line marker counted (<claude-session-id>.jsonl), or "" when the session folder
+// name carries no Claude session ID
+func (s *Store) legacyTranscript() string {
+	claudeSessionID := session.ExtractClaudeSessionID(filepath.Base(s.sessionPath))
+	if claudeSessionID == "" {
+		return ""
+	}
+	return claudeSessionID + ".jsonl"
+}
+
+// write saves all cursors atomically (temp file + rename)
+func (s *Store) write(cursors map[string]Cursor) error {
+	data, err := json.MarshalIndent(cursors, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cursors: %w", err)
+	}
+
+	tmpPath := s.path() + ".tmp"
+	if err := afero.WriteFile(s.fs, tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cursors: %w", err)
+	}
+	if err := s.fs.Rename(tmpPath, s.path()); err != nil {
+		return fmt.Errorf("failed to save cursors: %w", err)
+	}
+	return nil
+}
+
+// Checksum hashes the bytes of a file that precede offset (up to checksumWindow bytes)
+func Checksum(fs afero.Fs, path string, offset int64) (string, error) {
+	file, err := fs.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open transcript: %w", err)
+	}
+	defer file.Close()
+
+	start := offset - checksumWindow
+	if start < 0 {
+		start = 0
+	}
+
+	buf := make([]byte, offset-start)
+	if _, err := file.ReadAt(buf, start); err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read transcript: %w", err)
+	}
+
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,172 @@
+package cursor
+
+import (
+	"testing"
+
+	"claudex/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	sessionPath    = "/project/.claudex/sessions/task"
+	transcriptPath = "/home/user/.claude/projects/p/abc.jsonl"
+
+	// A session folder named after its Claude session, and that session's transcript
+	legacySessionPath  = "/project/.claudex/sessions/task-aaaa1111-2222-3333-4444-555566667777"
+	mainTranscriptPath = "/home/user/.claude/projects/p/aaaa1111-2222-3333-4444-555566667777.jsonl"
+)
+
+func TestStore_Resume_NewTranscript(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.WriteFile(transcriptPath, "line 1\n")
+	store := New(h.FS, h, sessionPath)
+
+	c, state, err := store.Resume(transcriptPath)
+
+	require.NoError(t, err)
+	assert.Equal(t, StateNew, state)
+	assert.Equal(t, 0, c.Line)
+	assert.Equal(t, transcriptPath, c.TranscriptPath)
+}
+
+func TestStore_Resume_ValidAfterAppend(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	h.WriteFile(transcriptPath, "line 1\nline 2\n")
+	store := New(h.FS, h, sessionPath)
+	require.NoError(t, store.Advance(transcriptPath, 14, 2))
+
+	// Claude appends to the transcript
+	h.WriteFile(transcriptPath, "line 1\nline 2\nline 3\n")
+
+	// Exercise
+	c, state, err := store.Resume(transcriptPath)
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, StateValid, state)
+	assert.Equal(t, 2, c.Line)
+	assert.Equal(t, int64(14), c.Offset)
+}
+
+func TestStore_Resume_DetectsTruncationAndRotation(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    State
+	}{
+		{name: "truncated", content: "line 1\n", want: StateTruncated},
+		{name: "rotated", content: "other 1\nother 2\n", want: StateRotated},
+		{name: "deleted", content: "", want: StateRotated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := testutil.NewTestHarness()
+			h.WriteFile(transcriptPath, "line 1\nline 2\n")
+			store := New(h.FS, h, sessionPath)
+			require.NoError(t, store.Advance(transcriptPath, 14, 2))
+
+			if tt.content == "" {
+				require.NoError(t, h.FS.Remove(transcriptPath))
+			} else {
+				h.WriteFile(transcriptPath, tt.content)
+			}
+
+			c, state, err := store.Resume(transcriptPath)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, state)
+			assert.Equal(t, 0, c.Line, "invalid cursors restart from the beginning")
+			assert.Equal(t, int64(0), c.Offset)
+		})
+	}
+}
+
+func TestStore_Resume_LegacyMarker(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.WriteFile(mainTranscriptPath, "line 1\nline 2\n")
+	h.WriteFile(legacySessionPath+"/.last-processed-line-overview", "1")
+	store := New(h.FS, h, legacySessionPath)
+
+	c, state, err := store.Resume(mainTranscriptPath)
+
+	require.NoError(t, err)
+	assert.Equal(t, StateLegacy, state)
+	assert.Equal(t, 1, c.Line)
+}
+
+func TestStore_Resume_LegacyMarkerOnlyAppliesToMainTranscript(t *testing.T) {
+	// Setup: an agent transcript is processed before the session's main transcript
+	h := testutil.NewTestHarness()
+	agentTranscript := "/home/user/.claude/projects/p/agent-1234.jsonl"
+	h.WriteFile(mainTranscriptPath, "line 1\nline 2\n")
+	h.WriteFile(agentTranscript, "agent 1\nagent 2\nagent 3\n")
+	h.WriteFile(legacySessionPath+"/.last-processed-line-overview", "1")
+	h.WriteFile(transcriptPath, "line 1\n")
+	h.WriteFile(sessionPath+"/.last-processed-line-overview", "1")
+	store := New(h.FS, h, legacySessionPath)
+
+	// Exercise
+	agent, agentState, err := store.Resume(agentTranscript)
+	require.NoError(t, err)
+	require.NoError(t, store.Advance(agentTranscript, 24, 3))
+	main, mainState, err := store.Resume(mainTranscriptPath)
+	require.NoError(t, err)
+	_, unnamedState, err := New(h.FS, h, sessionPath).Resume(transcriptPath)
+	require.NoError(t, err)
+
+	// Verify
+	assert.Equal(t, StateNew, agentState)
+	assert.Equal(t, 0, agent.Line, "the marker counted lines of another transcript")
+	assert.Equal(t, StateLegacy, mainState, "advancing another transcript keeps the marker")
+	assert.Equal(t, 1, main.Line)
+	assert.Equal(t, StateNew, unnamedState, "a folder without a Claude session ID has no main transcript")
+}
+
+func TestStore_Advance_KeepsOtherTranscripts(t *testing.T) {
+	h := testutil.NewTestHarness()
+	other := "/home/user/.claude/projects/p/def.jsonl"
+	h.WriteFile(transcriptPath, "a\n")
+	h.WriteFile(other, "b\nc\n")
+	store := New(h.FS, h, sessionPath)
+
+	require.NoError(t, store.Advance(transcriptPath, 2, 1))
+	require.NoError(t, store.Advance(other, 4, 2))
+
+	all, err := store.All()
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, 1, all[transcriptPath].Line)
+	assert.Equal(t, 2, all[other].Line)
+}
+
+func TestStore_Reject_CountsPerStartPosition(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	h.WriteFile(transcriptPath, "line 1\nline 2\n")
+	store := New(h.FS, h, sessionPath)
+
+	// Exercise
+	first, err := store.Reject(transcriptPath, 0, 0)
+	require.NoError(t, err)
+	second, err := store.Reject(transcriptPath, 0, 0)
+	require.NoError(t, err)
+	c, state, err := store.Resume(transcriptPath)
+	require.NoError(t, err)
+	moved, err := store.Reject(transcriptPath, 7, 1)
+	require.NoError(t, err)
+	require.NoError(t, store.Advance(transcriptPath, 14, 2))
+	advanced, _, err := store.Get(transcriptPath)
+	require.NoError(t, err)
+
+	// Verify
+	assert.Equal(t, 1, first)
+	assert.Equal(t, 2, second)
+	assert.Equal(t, StateValid, state, "a rejection keeps the start position")
+	assert.Equal(t, int64(0), c.Offset)
+	assert.Equal(t, 1, moved, "the count restarts when the start moves")
+	assert.Equal(t, 0, advanced.Rejections, "advancing clears the count")
+}
@@ -12,50 +12,134 @@ import (
 const (
 	trackingFileName = "doc_update_tracking.json"
 	strategyVersion  = "v1"
+
+	// fallbackLegacyBranch receives the single-entry state when no default branch is
+	// known; --update-docs assumed "main" when that layout was written
+	fallbackLegacyBranch = "main"
 )
 
+// trackingFile is the on-disk layout: one entry per branch. The embedded fields hold
+// the single-entry layout written before tracking was per branch; load moves it to
+// the legacy branch once.
+type trackingFile struct {
+	DocUpdateTracking
+	Branches map[string]DocUpdateTracking `json:"branches,omitempty"`
+}
+
 // FileTrackingService is the production implementation of TrackingService
 type FileTrackingService struct {
-	fs          afero.Fs
-	sessionPath string
+	fs           afero.Fs
+	sessionPath  string
+	legacyBranch string
 }
 
-// New creates a new TrackingService instance
-func New(fs afero.Fs, sessionPath string) TrackingService {
+// New creates a new TrackingService instance. Single-entry state written before tracking
+// was per branch is migrated to legacyBranch, the repository's default branch ("main"
+// when empty), the first time the file is loaded.
+func New(fs afero.Fs, sessionPath string, legacyBranch string) TrackingService {
+	if legacyBranch == "" {
+		legacyBranch = fallbackLegacyBranch
+	}
 	return &FileTrackingService{
-		fs:          fs,
-		sessionPath: sessionPath,
+		fs:           fs,
+		sessionPath:  sessionPath,
+		legacyBranch: legacyBranch,
+	}
+}
+
+// Read loads the tracking state of a branch
+func (fts *FileTrackingService) Read(branch string) (DocUpdateTracking, error) {
+	file, err := fts.load()
+	if err != nil {
+		return DocUpdateTracking{}, err
+	}
+	// Zero value when the branch has no entry
+	return file.Branches[branch], nil
+}
+
+// Write persists the tracking state of a branch atomically
+func (fts *FileTrackingService) Write(branch string, tracking DocUpdateTracking) error {
+	file, err := fts.load()
+	if err != nil {
+		return err
+	}
+	file.Branches[branch] = tracking
+	return fts.save(file)
+}
+
+// Initialize creates initial tracking state for a branch with its HEAD commit
+func (fts *FileTrackingService) Initialize(branch, headSHA string) error {
+	tracking := DocUpdateTracking{
+		LastProcessedCommit: headSHA,
+		UpdatedAt:           time.Now().Format(time.RFC3339),
+		StrategyVersion:     strategyVersion,
+	}
+
+	return fts.Write(branch, tracking)
+}
+
+// List returns the tracking state of every branch
+func (fts *FileTrackingService) List() (map[string]DocUpdateTracking, error) {
+	file, err := fts.load()
+	if err != nil {
+		return nil, err
+	}
+	return file.Branches, nil
+}
+
+// Delete removes the entry of a branch
+func (fts *FileTrackingService) Delete(branch string) error {
+	file, err := fts.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := file.Branches[branch]; !ok {
+		return nil
 	}
+	delete(file.Branches, branch)
+	return fts.save(file)
 }
 
-// Read loads the current tracking state from storage
-func (fts *FileTrackingService) Read() (DocUpdateTracking, error) {
+// load reads the tracking file; a missing file is empty. Legacy single-entry state is
+// moved to the legacy branch (unless that branch already has an entry) and saved.
+func (fts *FileTrackingService) load() (*trackingFile, error) {
 	trackingPath := filepath.Join(fts.sessionPath, trackingFileName)
 
+	file := &trackingFile{}
 	data, err := afero.ReadFile(fts.fs, trackingPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			// Return zero value if file doesn't exist
-			return DocUpdateTracking{}, nil
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, file); err != nil {
+			return nil, err
 		}
-		return DocUpdateTracking{}, err
 	}
-
-	var tracking DocUpdateTracking
-	if err := json.Unmarshal(data, &tracking); err != nil {
-		return DocUpdateTracking{}, err
+	if file.Branches == nil {
+		file.Branches = make(map[string]DocUpdateTracking)
 	}
 
-	return tracking, nil
+	if file.LastProcessedCommit != "" {
+		if _, ok := file.Branches[fts.legacyBranch]; !ok {
+			file.Branches[fts.legacyBranch] = file.DocUpdateTracking
+		}
+		file.DocUpdateTracking = DocUpdateTracking{}
+		if err := fts.save(file); err != nil {
+			return nil, err
+		}
+	}
+	return file, nil
 }
 
-// Write persists the tracking state to storage atomically
-func (fts *FileTrackingService) Write(tracking DocUpdateTracking) error {
+// save writes the tracking file atomically
+func (fts *FileTrackingService) save(file *trackingFile) error {
 	trackingPath := filepath.Join(fts.sessionPath, trackingFileName)
 	tempPath := trackingPath + ".tmp"
 
 	// Marshal to JSON with indentation for readability
-	data, err := json.MarshalIndent(tracking, "", "  ")
+	data, err := json.MarshalIndent(struct {
+		Branches map[string]DocUpdateTracking `json:"branches"`
+	}{file.Branches}, "", "  ")
 	if err != nil {
 		return err
 	}
@@ -68,14 +152,3 @@ func (fts *FileTrackingService) Write(tracking DocUpdateTracking) error {
 	// Atomic rename
 	return fts.fs.Rename(tempPath, trackingPath)
 }
-
-// Initialize creates initial tracking state with HEAD commit
-func (fts *FileTrackingService) Initialize(headSHA string) error {
-	tracking := DocUpdateTracking{
-		LastProcessedCommit: headSHA,
-		UpdatedAt:           time.Now().Format(time.RFC3339),
-		StrategyVersion:     strategyVersion,
-	}
-
-	return fts.Write(tracking)
-}
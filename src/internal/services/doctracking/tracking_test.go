@@ -15,10 +15,10 @@ func TestFileTrackingService_Read_MissingFile(t *testing.T) {
 	// Setup
 	fs := afero.NewMemMapFs()
 	sessionPath := "/test/session"
-	service := New(fs, sessionPath)
+	service := New(fs, sessionPath, "main")
 
 	// Execute
-	tracking, err := service.Read()
+	tracking, err := service.Read("main")
 
 	// Verify
 	require.NoError(t, err)
@@ -27,6 +27,8 @@ func TestFileTrackingService_Read_MissingFile(t *testing.T) {
 	assert.Equal(t, "", tracking.StrategyVersion)
 }
 
+// TestFileTrackingService_Read_ValidJSON verifies the single-entry layout written before
+// tracking was per branch is still read
 func TestFileTrackingService_Read_ValidJSON(t *testing.T) {
 	// Setup
 	fs := afero.NewMemMapFs()
@@ -45,10 +47,10 @@ func TestFileTrackingService_Read_ValidJSON(t *testing.T) {
 	trackingPath := filepath.Join(sessionPath, trackingFileName)
 	require.NoError(t, afero.WriteFile(fs, trackingPath, data, 0644))
 
-	service := New(fs, sessionPath)
+	service := New(fs, sessionPath, "main")
 
 	// Execute
-	tracking, err := service.Read()
+	tracking, err := service.Read("main")
 
 	// Verify
 	require.NoError(t, err)
@@ -66,10 +68,10 @@ func TestFileTrackingService_Read_InvalidJSON(t *testing.T) {
 	trackingPath := filepath.Join(sessionPath, trackingFileName)
 	require.NoError(t, afero.WriteFile(fs, trackingPath, []byte("invalid json"), 0644))
 
-	service := New(fs, sessionPath)
+	service := New(fs, sessionPath, "main")
 
 	// Execute
-	_, err := service.Read()
+	_, err := service.Read("main")
 
 	// Verify
 	require.Error(t, err)
@@ -81,7 +83,7 @@ func TestFileTrackingService_Write_CreatesFile(t *testing.T) {
 	sessionPath := "/test/session"
 	require.NoError(t, fs.MkdirAll(sessionPath, 0755))
 
-	service := New(fs, sessionPath)
+	service := New(fs, sessionPath, "main")
 
 	tracking := DocUpdateTracking{
 		LastProcessedCommit: "def456",
@@ -90,7 +92,7 @@ func TestFileTrackingService_Write_CreatesFile(t *testing.T) {
 	}
 
 	// Execute
-	err := service.Write(tracking)
+	err := service.Write("main", tracking)
 
 	// Verify
 	require.NoError(t, err)
@@ -105,8 +107,11 @@ func TestFileTrackingService_Write_CreatesFile(t *testing.T) {
 	data, err := afero.ReadFile(fs, trackingPath)
 	require.NoError(t, err)
 
-	var readTracking DocUpdateTracking
-	require.NoError(t, json.Unmarshal(data, &readTracking))
+	var file struct {
+		Branches map[string]DocUpdateTracking `json:"branches"`
+	}
+	require.NoError(t, json.Unmarshal(data, &file))
+	readTracking := file.Branches["main"]
 	assert.Equal(t, tracking.LastProcessedCommit, readTracking.LastProcessedCommit)
 	assert.Equal(t, tracking.UpdatedAt, readTracking.UpdatedAt)
 	assert.Equal(t, tracking.StrategyVersion, readTracking.StrategyVersion)
@@ -118,7 +123,7 @@ func TestFileTrackingService_Write_UpdatesFile(t *testing.T) {
 	sessionPath := "/test/session"
 	require.NoError(t, fs.MkdirAll(sessionPath, 0755))
 
-	service := New(fs, sessionPath)
+	service := New(fs, sessionPath, "main")
 
 	// Write initial tracking
 	initialTracking := DocUpdateTracking{
@@ -126,7 +131,7 @@ func TestFileTrackingService_Write_UpdatesFile(t *testing.T) {
 		UpdatedAt:           "2025-12-13T09:00:00Z",
 		StrategyVersion:     "v1",
 	}
-	require.NoError(t, service.Write(initialTracking))
+	require.NoError(t, service.Write("main", initialTracking))
 
 	// Update tracking
 	updatedTracking := DocUpdateTracking{
@@ -136,13 +141,13 @@ func TestFileTrackingService_Write_UpdatesFile(t *testing.T) {
 	}
 
 	// Execute
-	err := service.Write(updatedTracking)
+	err := service.Write("main", updatedTracking)
 
 	// Verify
 	require.NoError(t, err)
 
 	// Verify updated content
-	readTracking, err := service.Read()
+	readTracking, err := service.Read("main")
 	require.NoError(t, err)
 	assert.Equal(t, updatedTracking.LastProcessedCommit, readTracking.LastProcessedCommit)
 	assert.Equal(t, updatedTracking.UpdatedAt, readTracking.UpdatedAt)
@@ -155,7 +160,7 @@ func TestFileTrackingService_Write_NoTempFileRemains(t *testing.T) {
 	sessionPath := "/test/session"
 	require.NoError(t, fs.MkdirAll(sessionPath, 0755))
 
-	service := New(fs, sessionPath)
+	service := New(fs, sessionPath, "main")
 
 	tracking := DocUpdateTracking{
 		LastProcessedCommit: "abc123",
@@ -164,7 +169,7 @@ func TestFileTrackingService_Write_NoTempFileRemains(t *testing.T) {
 	}
 
 	// Execute
-	err := service.Write(tracking)
+	err := service.Write("main", tracking)
 	require.NoError(t, err)
 
 	// Verify temp file doesn't exist
@@ -203,6 +208,15 @@ func TestFileTrackingService_JSONRoundTrip(t *testing.T) {
 				StrategyVersion:     "v2",
 			},
 		},
+		{
+			name: "failed indexes pending retry",
+			tracking: DocUpdateTracking{
+				LastProcessedCommit: "def456",
+				UpdatedAt:           "2025-12-13T10:00:00Z",
+				StrategyVersion:     "v1",
+				FailedIndexes:       map[string]string{"/repo/src/index.md": "abc123"},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -212,20 +226,21 @@ func TestFileTrackingService_JSONRoundTrip(t *testing.T) {
 			sessionPath := "/test/session"
 			require.NoError(t, fs.MkdirAll(sessionPath, 0755))
 
-			service := New(fs, sessionPath)
+			service := New(fs, sessionPath, "main")
 
 			// Execute write
-			err := service.Write(tt.tracking)
+			err := service.Write("main", tt.tracking)
 			require.NoError(t, err)
 
 			// Execute read
-			readTracking, err := service.Read()
+			readTracking, err := service.Read("main")
 			require.NoError(t, err)
 
 			// Verify
 			assert.Equal(t, tt.tracking.LastProcessedCommit, readTracking.LastProcessedCommit)
 			assert.Equal(t, tt.tracking.UpdatedAt, readTracking.UpdatedAt)
 			assert.Equal(t, tt.tracking.StrategyVersion, readTracking.StrategyVersion)
+			assert.Equal(t, tt.tracking.FailedIndexes, readTracking.FailedIndexes)
 		})
 	}
 }
@@ -236,17 +251,17 @@ func TestFileTrackingService_Initialize(t *testing.T) {
 	sessionPath := "/test/session"
 	require.NoError(t, fs.MkdirAll(sessionPath, 0755))
 
-	service := New(fs, sessionPath)
+	service := New(fs, sessionPath, "main")
 	headSHA := "abc123def456"
 
 	// Execute
-	err := service.Initialize(headSHA)
+	err := service.Initialize("main", headSHA)
 
 	// Verify
 	require.NoError(t, err)
 
 	// Read and verify created tracking
-	tracking, err := service.Read()
+	tracking, err := service.Read("main")
 	require.NoError(t, err)
 
 	assert.Equal(t, headSHA, tracking.LastProcessedCommit)
@@ -268,23 +283,142 @@ func TestFileTrackingService_Initialize_OverwritesExisting(t *testing.T) {
 	sessionPath := "/test/session"
 	require.NoError(t, fs.MkdirAll(sessionPath, 0755))
 
-	service := New(fs, sessionPath)
+	service := New(fs, sessionPath, "main")
 
 	// Create initial tracking
 	initialSHA := "initial123"
-	require.NoError(t, service.Initialize(initialSHA))
+	require.NoError(t, service.Initialize("main", initialSHA))
 
 	// Wait a bit to ensure different timestamp
 	time.Sleep(10 * time.Millisecond)
 
 	// Initialize with new SHA
 	newSHA := "new456"
-	err := service.Initialize(newSHA)
+	err := service.Initialize("main", newSHA)
 	require.NoError(t, err)
 
 	// Verify
-	tracking, err := service.Read()
+	tracking, err := service.Read("main")
 	require.NoError(t, err)
 	assert.Equal(t, newSHA, tracking.LastProcessedCommit)
 	assert.Equal(t, "v1", tracking.StrategyVersion)
 }
+
+func TestFileTrackingService_BranchesAreIndependent(t *testing.T) {
+	// Setup
+	fs := afero.NewMemMapFs()
+	service := New(fs, "/test/session", "main")
+	require.NoError(t, service.Initialize("main", "main111"))
+	require.NoError(t, service.Initialize("feature/auth", "feat222"))
+
+	// Execute
+	main, err := service.Read("main")
+	require.NoError(t, err)
+	feature, err := service.Read("feature/auth")
+	require.NoError(t, err)
+	missing, err := service.Read("feature/other")
+	require.NoError(t, err)
+
+	// Verify
+	assert.Equal(t, "main111", main.LastProcessedCommit)
+	assert.Equal(t, "feat222", feature.LastProcessedCommit)
+	assert.Empty(t, missing.LastProcessedCommit)
+
+	entries, err := service.List()
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+// writeLegacyFile writes the single-entry layout used before tracking was per branch
+func writeLegacyFile(t *testing.T, fs afero.Fs) {
+	t.Helper()
+	trackingPath := filepath.Join("/test/session", trackingFileName)
+	require.NoError(t, afero.WriteFile(fs, trackingPath, []byte(`{"last_processed_commit":"legacy1","strategy_version":"v1"}`), 0644))
+}
+
+func TestFileTrackingService_LegacyEntryMigratesToLegacyBranch(t *testing.T) {
+	// Setup
+	fs := afero.NewMemMapFs()
+	writeLegacyFile(t, fs)
+	service := New(fs, "/test/session", "develop")
+
+	// Execute
+	other, err := service.Read("feature/x")
+	require.NoError(t, err)
+	develop, err := service.Read("develop")
+	require.NoError(t, err)
+	entries, err := service.List()
+	require.NoError(t, err)
+
+	// Verify
+	assert.Empty(t, other.LastProcessedCommit, "other branches never inherit the legacy entry")
+	assert.Equal(t, "legacy1", develop.LastProcessedCommit)
+	assert.Equal(t, []string{"develop"}, keys(entries))
+	data, err := afero.ReadFile(fs, filepath.Join("/test/session", trackingFileName))
+	require.NoError(t, err)
+	var onDisk map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(data, &onDisk))
+	assert.Contains(t, onDisk, "branches")
+	assert.NotContains(t, onDisk, "last_processed_commit", "the top-level legacy entry is gone from disk")
+}
+
+func TestFileTrackingService_LegacyEntryKeepsExistingBranchEntry(t *testing.T) {
+	// Setup
+	fs := afero.NewMemMapFs()
+	trackingPath := filepath.Join("/test/session", trackingFileName)
+	require.NoError(t, afero.WriteFile(fs, trackingPath, []byte(`{"last_processed_commit":"legacy1","branches":{"main":{"last_processed_commit":"main222"}}}`), 0644))
+	service := New(fs, "/test/session", "")
+
+	// Execute
+	main, err := service.Read("main")
+	require.NoError(t, err)
+	entries, err := service.List()
+	require.NoError(t, err)
+
+	// Verify
+	assert.Equal(t, "main222", main.LastProcessedCommit, "a newer per-branch entry wins over the legacy one")
+	assert.Equal(t, []string{"main"}, keys(entries))
+}
+
+func TestFileTrackingService_DeleteRemovesMigratedLegacyEntry(t *testing.T) {
+	// Setup
+	fs := afero.NewMemMapFs()
+	writeLegacyFile(t, fs)
+	service := New(fs, "/test/session", "main")
+
+	// Execute
+	require.NoError(t, service.Delete("main"))
+
+	// Verify
+	main, err := service.Read("main")
+	require.NoError(t, err)
+	assert.Empty(t, main.LastProcessedCommit)
+	entries, err := service.List()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestFileTrackingService_Delete(t *testing.T) {
+	// Setup
+	fs := afero.NewMemMapFs()
+	service := New(fs, "/test/session", "main")
+	require.NoError(t, service.Initialize("main", "main111"))
+	require.NoError(t, service.Initialize("old-branch", "old222"))
+
+	// Execute
+	require.NoError(t, service.Delete("old-branch"))
+	require.NoError(t, service.Delete("never-tracked"))
+
+	// Verify
+	entries, err := service.List()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main"}, keys(entries))
+}
+
+func keys(entries map[string]DocUpdateTracking) []string {
+	var names []string
+	for name := range entries {
+		names = append(names, name)
+	}
+	return names
+}
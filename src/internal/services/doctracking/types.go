@@ -1,8 +1,8 @@
 // Package doctracking provides tracking services for documentation update state.
-// It manages persistent state for the last processed commit and strategy version.
+// It manages persistent per-branch state for the last processed commit and strategy version.
 package doctracking
 
-// DocUpdateTracking represents the state of documentation updates
+// DocUpdateTracking represents the state of documentation updates on one branch
 type DocUpdateTracking struct {
 	// LastProcessedCommit is the SHA of the last commit that was processed
 	LastProcessedCommit string `json:"last_processed_commit"`
@@ -13,18 +13,29 @@ type DocUpdateTracking struct {
 	// StrategyVersion tracks the version of the update strategy used
 	// Allows future migrations if the update logic changes
 	StrategyVersion string `json:"strategy_version"`
+
+	// FailedIndexes maps index.md files whose update failed to the base commit of
+	// their pending range, so the next run retries them over base..HEAD
+	FailedIndexes map[string]string `json:"failed_indexes,omitempty"`
 }
 
-// TrackingService abstracts documentation tracking persistence for testability
+// TrackingService abstracts documentation tracking persistence for testability.
+// Entries are keyed by branch name.
 type TrackingService interface {
-	// Read loads the current tracking state from storage
-	// Returns zero-value DocUpdateTracking if file doesn't exist
-	Read() (DocUpdateTracking, error)
+	// Read loads the tracking state of a branch
+	// Returns zero-value DocUpdateTracking if the branch has no entry
+	Read(branch string) (DocUpdateTracking, error)
 
-	// Write persists the tracking state to storage atomically
-	Write(tracking DocUpdateTracking) error
+	// Write persists the tracking state of a branch atomically
+	Write(branch string, tracking DocUpdateTracking) error
 
-	// Initialize creates initial tracking state with HEAD commit
+	// Initialize creates initial tracking state for a branch with its HEAD commit
 	// Used for first-time setup
-	Initialize(headSHA string) error
+	Initialize(branch, headSHA string) error
+
+	// List returns the tracking state of every branch
+	List() (map[string]DocUpdateTracking, error)
+
+	// Delete removes the entry of a branch; deleting a missing entry is not an error
+	Delete(branch string) error
 }
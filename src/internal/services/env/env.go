@@ -8,6 +8,13 @@ type Environment interface {
 	Set(key, value string)
 }
 
+// Unsetter is implemented by environments that can tell an unset variable from an
+// empty one and remove it again
+type Unsetter interface {
+	Lookup(key string) (string, bool)
+	Unset(key string)
+}
+
 // OsEnv is the production implementation of Environment
 type OsEnv struct{}
 
@@ -19,6 +26,14 @@ func (e *OsEnv) Set(key, value string) {
 	os.Setenv(key, value)
 }
 
+func (e *OsEnv) Lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+func (e *OsEnv) Unset(key string) {
+	os.Unsetenv(key)
+}
+
 // New creates a new Environment instance
 func New() Environment {
 	return &OsEnv{}
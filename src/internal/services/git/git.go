@@ -4,7 +4,9 @@
 package git
 
 import (
+	"strconv"
 	"strings"
+	"time"
 
 	"claudex/internal/services/commander"
 )
@@ -25,8 +27,116 @@ type GitService interface {
 	// GetMergeBase returns the merge base between HEAD and the specified branch
 	// Used as fallback when base commit is unreachable (e.g., after rebase)
 	GetMergeBase(branch string) (string, error)
+
+	// GetCommits returns the commits reachable from head but not from base, newest first
+	// Uses git log base..head
+	GetCommits(base, head string) ([]Commit, error)
+
+	// GetDiffStat returns per-file added/deleted line counts between base and head commits
+	// Uses git diff --numstat base..head
+	GetDiffStat(base, head string) ([]FileStat, error)
+
+	// GetFileDiff returns the unified diff of a single file between base and head commits
+	GetFileDiff(base, head, path string) (string, error)
+
+	// GetAddedFiles returns the files created between base and head commits
+	// Uses git diff --name-only --diff-filter=A base..head
+	GetAddedFiles(base, head string) ([]string, error)
+
+	// GetCurrentBranch returns the checked-out branch name, or "" on a detached HEAD
+	GetCurrentBranch() (string, error)
+
+	// ListBranches returns the names of the local branches
+	ListBranches() ([]string, error)
+
+	// GetDefaultBranch returns the branch origin/HEAD points to, else a local main or
+	// master, else ""
+	GetDefaultBranch() (string, error)
+
+	// GetRepoRoot returns the absolute path of the working tree's top-level directory
+	// Uses git rev-parse --show-toplevel
+	GetRepoRoot() (string, error)
+
+	// ListFiles returns the files tracked at HEAD and in the index, relative to the repository root
+	// Uses git ls-files --full-name
+	ListFiles() ([]string, error)
+
+	// GetLastCommit returns the newest commit that touched path, or a zero Commit when none did.
+	// path is relative to the repository root.
+	GetLastCommit(path string) (Commit, error)
+
+	// GetPathCommits returns the commits of base..head that touched any of paths, newest first.
+	// paths are relative to the repository root.
+	GetPathCommits(base, head string, paths []string) ([]Commit, error)
+
+	// GetPendingFiles returns the uncommitted changed files of a scope
+	// Uses git diff --name-only --cached (staged) or HEAD (worktree, plus untracked files)
+	GetPendingFiles(scope Scope) ([]string, error)
+
+	// GetPendingDiffStat returns per-file added/deleted line counts of the uncommitted changes of a scope
+	GetPendingDiffStat(scope Scope) ([]FileStat, error)
+
+	// GetPendingFileDiff returns the unified diff of a single file's uncommitted changes in a scope
+	GetPendingFileDiff(scope Scope, path string) (string, error)
+
+	// GetPendingAddedFiles returns the files an uncommitted change of a scope creates
+	GetPendingAddedFiles(scope Scope) ([]string, error)
+
+	// StageFiles adds files to the index
+	StageFiles(paths []string) error
+
+	// HasUnstagedChanges reports whether the working tree copy of path differs from the
+	// index, including an untracked path
+	// Uses git status --porcelain -- path
+	HasUnstagedChanges(path string) (bool, error)
+}
+
+// Scope selects which uncommitted changes the pending-change methods compare against HEAD
+type Scope string
+
+const (
+	// ScopeStaged is what the next commit holds: the index against HEAD
+	ScopeStaged Scope = "staged"
+
+	// ScopeWorktree is every uncommitted change: the working tree, including untracked files, against HEAD
+	ScopeWorktree Scope = "worktree"
+)
+
+// Commit is a single commit of a range
+type Commit struct {
+	SHA     string
+	Subject string
+	Body    string
+	// Time is the committer date
+	Time time.Time
+}
+
+// Message returns the full commit message (subject and body)
+func (c Commit) Message() string {
+	if c.Body == "" {
+		return c.Subject
+	}
+	return c.Subject + "\n\n" + c.Body
 }
 
+// FileStat is the size of the change to one file of a range
+type FileStat struct {
+	Path    string
+	Added   int
+	Deleted int
+	// Binary is set when git reports no line counts for the file
+	Binary bool
+}
+
+const (
+	// fieldSep and recordSep delimit the fields and commits of the git log output
+	fieldSep  = "\x1f"
+	recordSep = "\x1e"
+
+	// logFormat writes the SHA, committer date, subject and body of each commit
+	logFormat = "--format=%H%x1f%ct%x1f%s%x1f%b%x1e"
+)
+
 // OsGitService is the production implementation of GitService
 type OsGitService struct {
 	cmdr commander.Commander
@@ -75,6 +185,263 @@ func (s *OsGitService) GetMergeBase(branch string) (string, error) {
 	return trimOutput(output), nil
 }
 
+// GetCommits returns the commits reachable from head but not from base, newest first
+func (s *OsGitService) GetCommits(base, head string) ([]Commit, error) {
+	output, err := s.cmdr.Run("git", "log", logFormat, base+".."+head)
+	if err != nil {
+		return nil, err
+	}
+	return parseCommits(output), nil
+}
+
+// GetDiffStat returns per-file added/deleted line counts between base and head commits
+func (s *OsGitService) GetDiffStat(base, head string) ([]FileStat, error) {
+	output, err := s.cmdr.Run("git", "diff", "--numstat", "--no-renames", base+".."+head)
+	if err != nil {
+		return nil, err
+	}
+	return parseNumstat(output), nil
+}
+
+// GetFileDiff returns the unified diff of a single file between base and head commits
+func (s *OsGitService) GetFileDiff(base, head, path string) (string, error) {
+	output, err := s.cmdr.Run("git", "diff", "--no-renames", "--unified=3", base+".."+head, "--", path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(output), "\n"), nil
+}
+
+// GetAddedFiles returns the files created between base and head commits
+func (s *OsGitService) GetAddedFiles(base, head string) ([]string, error) {
+	output, err := s.cmdr.Run("git", "diff", "--name-only", "--no-renames", "--diff-filter=A", base+".."+head)
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(output), nil
+}
+
+// GetCurrentBranch returns the checked-out branch name, or "" on a detached HEAD
+func (s *OsGitService) GetCurrentBranch() (string, error) {
+	output, err := s.cmdr.Run("git", "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	branch := trimOutput(output)
+	if branch == "HEAD" {
+		return "", nil
+	}
+	return branch, nil
+}
+
+// ListBranches returns the names of the local branches
+func (s *OsGitService) ListBranches() ([]string, error) {
+	output, err := s.cmdr.Run("git", "for-each-ref", "--format=%(refname:short)", "refs/heads")
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(output), nil
+}
+
+// GetDefaultBranch returns the branch origin/HEAD points to, else a local main or master, else ""
+func (s *OsGitService) GetDefaultBranch() (string, error) {
+	if output, err := s.cmdr.Run("git", "symbolic-ref", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		if branch := strings.TrimPrefix(trimOutput(output), "origin/"); branch != "" {
+			return branch, nil
+		}
+	}
+
+	branches, err := s.ListBranches()
+	if err != nil {
+		return "", err
+	}
+	for _, candidate := range []string{"main", "master"} {
+		for _, branch := range branches {
+			if branch == candidate {
+				return branch, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// GetRepoRoot returns the absolute path of the working tree's top-level directory
+func (s *OsGitService) GetRepoRoot() (string, error) {
+	output, err := s.cmdr.Run("git", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return trimOutput(output), nil
+}
+
+// ListFiles returns the files tracked at HEAD and in the index, relative to the repository root
+func (s *OsGitService) ListFiles() ([]string, error) {
+	output, err := s.cmdr.Run("git", "ls-files", "--full-name")
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(output), nil
+}
+
+// GetLastCommit returns the newest commit that touched path, or a zero Commit when none did
+func (s *OsGitService) GetLastCommit(path string) (Commit, error) {
+	output, err := s.cmdr.Run("git", "log", "-1", logFormat, "--", topPathspec(path))
+	if err != nil {
+		return Commit{}, err
+	}
+	commits := parseCommits(output)
+	if len(commits) == 0 {
+		return Commit{}, nil
+	}
+	return commits[0], nil
+}
+
+// GetPathCommits returns the commits of base..head that touched any of paths, newest first
+func (s *OsGitService) GetPathCommits(base, head string, paths []string) ([]Commit, error) {
+	args := []string{"log", logFormat, base + ".." + head, "--"}
+	for _, path := range paths {
+		args = append(args, topPathspec(path))
+	}
+	output, err := s.cmdr.Run("git", args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseCommits(output), nil
+}
+
+// GetPendingFiles returns the uncommitted changed files of a scope
+func (s *OsGitService) GetPendingFiles(scope Scope) ([]string, error) {
+	output, err := s.cmdr.Run("git", pendingDiffArgs(scope, "--name-only", "--no-renames")...)
+	if err != nil {
+		return nil, err
+	}
+	return s.withUntracked(scope, splitLines(output))
+}
+
+// GetPendingDiffStat returns per-file added/deleted line counts of the uncommitted changes of a scope
+func (s *OsGitService) GetPendingDiffStat(scope Scope) ([]FileStat, error) {
+	output, err := s.cmdr.Run("git", pendingDiffArgs(scope, "--numstat", "--no-renames")...)
+	if err != nil {
+		return nil, err
+	}
+	return parseNumstat(output), nil
+}
+
+// GetPendingFileDiff returns the unified diff of a single file's uncommitted changes in a scope
+func (s *OsGitService) GetPendingFileDiff(scope Scope, path string) (string, error) {
+	output, err := s.cmdr.Run("git", pendingDiffArgs(scope, "--no-renames", "--unified=3", "--", path)...)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(output), "\n"), nil
+}
+
+// GetPendingAddedFiles returns the files an uncommitted change of a scope creates
+func (s *OsGitService) GetPendingAddedFiles(scope Scope) ([]string, error) {
+	output, err := s.cmdr.Run("git", pendingDiffArgs(scope, "--name-only", "--no-renames", "--diff-filter=A")...)
+	if err != nil {
+		return nil, err
+	}
+	return s.withUntracked(scope, splitLines(output))
+}
+
+// StageFiles adds files to the index
+func (s *OsGitService) StageFiles(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	_, err := s.cmdr.Run("git", append([]string{"add", "--"}, paths...)...)
+	return err
+}
+
+// HasUnstagedChanges reports whether path has working tree changes that are not staged
+func (s *OsGitService) HasUnstagedChanges(path string) (bool, error) {
+	output, err := s.cmdr.Run("git", "status", "--porcelain", "--", path)
+	if err != nil {
+		return false, err
+	}
+	// Each line is "XY <path>"; Y is the working tree status (' ' when clean, '?' when untracked)
+	for _, line := range strings.Split(string(output), "\n") {
+		if len(line) >= 2 && line[1] != ' ' {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// pendingDiffArgs returns the git diff arguments comparing a scope against HEAD
+func pendingDiffArgs(scope Scope, args ...string) []string {
+	if scope == ScopeStaged {
+		return append([]string{"diff", "--cached"}, args...)
+	}
+	return append([]string{"diff", "HEAD"}, args...)
+}
+
+// withUntracked appends the untracked, non-ignored files to files for the worktree scope
+func (s *OsGitService) withUntracked(scope Scope, files []string) ([]string, error) {
+	if scope != ScopeWorktree {
+		return files, nil
+	}
+	output, err := s.cmdr.Run("git", "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	return append(files, splitLines(output)...), nil
+}
+
+// parseCommits parses git log output written with the fieldSep/recordSep format
+func parseCommits(output []byte) []Commit {
+	var commits []Commit
+	for _, record := range strings.Split(string(output), recordSep) {
+		record = strings.TrimSpace(record)
+		if record == "" {
+			continue
+		}
+		fields := strings.SplitN(record, fieldSep, 4)
+		commit := Commit{SHA: strings.TrimSpace(fields[0])}
+		if len(fields) > 1 {
+			if unix, err := strconv.ParseInt(strings.TrimSpace(fields[1]), 10, 64); err == nil {
+				commit.Time = time.Unix(unix, 0).UTC()
+			}
+		}
+		if len(fields) > 2 {
+			commit.Subject = strings.TrimSpace(fields[2])
+		}
+		if len(fields) > 3 {
+			commit.Body = strings.TrimSpace(fields[3])
+		}
+		commits = append(commits, commit)
+	}
+	return commits
+}
+
+// parseNumstat parses git diff --numstat output; binary files report "-" counts
+func parseNumstat(output []byte) []FileStat {
+	var stats []FileStat
+	for _, line := range splitLines(output) {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		stat := FileStat{Path: fields[2]}
+		added, addErr := strconv.Atoi(fields[0])
+		deleted, delErr := strconv.Atoi(fields[1])
+		if addErr != nil || delErr != nil {
+			stat.Binary = true
+		} else {
+			stat.Added, stat.Deleted = added, deleted
+		}
+		stats = append(stats, stat)
+	}
+	return stats
+}
+
+// topPathspec makes a repository-relative path match from the root regardless of the
+// current directory
+func topPathspec(path string) string {
+	return ":(top)" + path
+}
+
 // trimOutput removes leading and trailing whitespace from command output
 func trimOutput(output []byte) string {
 	return strings.TrimSpace(string(output))
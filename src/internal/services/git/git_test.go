@@ -3,6 +3,7 @@ package git
 import (
 	"errors"
 	"io"
+	"strings"
 	"testing"
 )
 
@@ -385,3 +386,442 @@ func TestSplitLines_EdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestGetCommits_ParsesSubjectsAndBodies(t *testing.T) {
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			if len(args) != 3 || args[0] != "log" || args[2] != "base..head" {
+				t.Errorf("expected args [log <format> base..head], got %v", args)
+			}
+			return []byte("aaa\x1f1718000000\x1ffeat: add parser\x1fLonger explanation\n\nwith paragraphs\n\x1e\n" +
+				"bbb\x1f1717000000\x1ffix: typo [skip-docs]\x1f\x1e\n"), nil
+		},
+	}
+
+	commits, err := New(mock).GetCommits("base", "head")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("expected 2 commits, got %d: %v", len(commits), commits)
+	}
+	if commits[0].SHA != "aaa" || commits[0].Subject != "feat: add parser" {
+		t.Errorf("unexpected first commit: %+v", commits[0])
+	}
+	if commits[0].Body != "Longer explanation\n\nwith paragraphs" {
+		t.Errorf("unexpected body: %q", commits[0].Body)
+	}
+	if commits[0].Time.Unix() != 1718000000 {
+		t.Errorf("unexpected commit time: %v", commits[0].Time)
+	}
+	if commits[1].Message() != "fix: typo [skip-docs]" {
+		t.Errorf("unexpected message: %q", commits[1].Message())
+	}
+}
+
+func TestGetCommits_Error(t *testing.T) {
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			return nil, errors.New("bad range")
+		},
+	}
+
+	if _, err := New(mock).GetCommits("base", "head"); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestGetDiffStat_ParsesNumstat(t *testing.T) {
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			if len(args) < 2 || args[0] != "diff" || args[1] != "--numstat" {
+				t.Errorf("expected git diff --numstat, got %v", args)
+			}
+			return []byte("12\t3\tsrc/foo.go\n-\t-\tassets/logo.png\n0\t7\tsrc/old file.go\n"), nil
+		},
+	}
+
+	stats, err := New(mock).GetDiffStat("base", "head")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []FileStat{
+		{Path: "src/foo.go", Added: 12, Deleted: 3},
+		{Path: "assets/logo.png", Binary: true},
+		{Path: "src/old file.go", Deleted: 7},
+	}
+	if len(stats) != len(expected) {
+		t.Fatalf("expected %d stats, got %d: %v", len(expected), len(stats), stats)
+	}
+	for i := range expected {
+		if stats[i] != expected[i] {
+			t.Errorf("stat %d: expected %+v, got %+v", i, expected[i], stats[i])
+		}
+	}
+}
+
+func TestGetFileDiff_LimitsToPath(t *testing.T) {
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			if args[len(args)-2] != "--" || args[len(args)-1] != "src/foo.go" {
+				t.Errorf("expected diff limited to src/foo.go, got %v", args)
+			}
+			return []byte("@@ -1 +1 @@\n-old\n+new\n"), nil
+		},
+	}
+
+	diff, err := New(mock).GetFileDiff("base", "head", "src/foo.go")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff != "@@ -1 +1 @@\n-old\n+new" {
+		t.Errorf("unexpected diff: %q", diff)
+	}
+}
+
+func TestGetAddedFiles_FiltersOnAddedStatus(t *testing.T) {
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			found := false
+			for _, arg := range args {
+				if arg == "--diff-filter=A" {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected --diff-filter=A, got %v", args)
+			}
+			return []byte("pkg/new/index.md\npkg/new/new.go\n"), nil
+		},
+	}
+
+	files, err := New(mock).GetAddedFiles("base", "head")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 2 || files[0] != "pkg/new/index.md" {
+		t.Errorf("unexpected added files: %v", files)
+	}
+}
+
+func TestGetCurrentBranch(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected string
+	}{
+		{"named branch", "feature/auth\n", "feature/auth"},
+		{"detached HEAD", "HEAD\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockCommander{
+				runFunc: func(name string, args ...string) ([]byte, error) {
+					return []byte(tt.output), nil
+				},
+			}
+
+			branch, err := New(mock).GetCurrentBranch()
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if branch != tt.expected {
+				t.Errorf("expected branch %q, got %q", tt.expected, branch)
+			}
+		})
+	}
+}
+
+func TestListBranches(t *testing.T) {
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			if len(args) != 3 || args[0] != "for-each-ref" || args[2] != "refs/heads" {
+				t.Errorf("expected git for-each-ref over refs/heads, got %v", args)
+			}
+			return []byte("main\nfeature/auth\n"), nil
+		},
+	}
+
+	branches, err := New(mock).ListBranches()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(branches) != 2 || branches[1] != "feature/auth" {
+		t.Errorf("unexpected branches: %v", branches)
+	}
+}
+
+func TestGetDefaultBranch(t *testing.T) {
+	tests := []struct {
+		name      string
+		originRef string // symbolic-ref output; "" fails as when origin/HEAD is unset
+		branches  string
+		expected  string
+	}{
+		{"origin HEAD", "origin/develop\n", "main\ndevelop\n", "develop"},
+		{"local main", "", "feature/auth\nmaster\nmain\n", "main"},
+		{"local master", "", "feature/auth\nmaster\n", "master"},
+		{"none", "", "feature/auth\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockCommander{
+				runFunc: func(name string, args ...string) ([]byte, error) {
+					if args[0] == "symbolic-ref" {
+						if tt.originRef == "" {
+							return nil, errors.New("fatal: ref refs/remotes/origin/HEAD is not a symbolic ref")
+						}
+						return []byte(tt.originRef), nil
+					}
+					return []byte(tt.branches), nil
+				},
+			}
+
+			branch, err := New(mock).GetDefaultBranch()
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if branch != tt.expected {
+				t.Errorf("expected branch %q, got %q", tt.expected, branch)
+			}
+		})
+	}
+}
+
+func TestGetLastCommit(t *testing.T) {
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			if args[0] != "log" || args[1] != "-1" || args[len(args)-1] != ":(top)src/index.md" {
+				t.Errorf("expected git log -1 limited to src/index.md, got %v", args)
+			}
+			return []byte("ccc\x1f1718000000\x1fdocs: refresh index\x1f\x1e\n"), nil
+		},
+	}
+
+	commit, err := New(mock).GetLastCommit("src/index.md")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if commit.SHA != "ccc" || commit.Time.Unix() != 1718000000 {
+		t.Errorf("unexpected commit: %+v", commit)
+	}
+}
+
+func TestGetLastCommit_Untracked(t *testing.T) {
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			return []byte(""), nil
+		},
+	}
+
+	commit, err := New(mock).GetLastCommit("new/index.md")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if commit.SHA != "" {
+		t.Errorf("expected zero commit, got %+v", commit)
+	}
+}
+
+func TestGetPathCommits_LimitsToPaths(t *testing.T) {
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			expected := []string{"log", logFormat, "base..HEAD", "--", ":(top)src/a.go", ":(top)src/b.go"}
+			if len(args) != len(expected) {
+				t.Fatalf("expected args %v, got %v", expected, args)
+			}
+			for i := range expected {
+				if args[i] != expected[i] {
+					t.Errorf("arg %d: expected %q, got %q", i, expected[i], args[i])
+				}
+			}
+			return []byte("aaa\x1f1718000000\x1ffeat: a\x1f\x1e\nbbb\x1f1717000000\x1ffeat: b\x1f\x1e\n"), nil
+		},
+	}
+
+	commits, err := New(mock).GetPathCommits("base", "HEAD", []string{"src/a.go", "src/b.go"})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(commits) != 2 || commits[1].Subject != "feat: b" {
+		t.Errorf("unexpected commits: %+v", commits)
+	}
+}
+
+func TestGetRepoRoot(t *testing.T) {
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			if len(args) != 2 || args[0] != "rev-parse" || args[1] != "--show-toplevel" {
+				t.Errorf("expected git rev-parse --show-toplevel, got %v", args)
+			}
+			return []byte("/repo\n"), nil
+		},
+	}
+
+	root, err := New(mock).GetRepoRoot()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if root != "/repo" {
+		t.Errorf("expected /repo, got %q", root)
+	}
+}
+
+func TestListFiles(t *testing.T) {
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			if len(args) != 2 || args[0] != "ls-files" || args[1] != "--full-name" {
+				t.Errorf("expected git ls-files --full-name, got %v", args)
+			}
+			return []byte("go.mod\nsrc/main.go\n"), nil
+		},
+	}
+
+	files, err := New(mock).ListFiles()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 2 || files[1] != "src/main.go" {
+		t.Errorf("unexpected files: %v", files)
+	}
+}
+
+func TestGetPendingFiles_Staged(t *testing.T) {
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			if len(args) < 3 || args[0] != "diff" || args[1] != "--cached" || args[2] != "--name-only" {
+				t.Errorf("expected git diff --cached --name-only, got %v", args)
+			}
+			return []byte("src/a.go\n"), nil
+		},
+	}
+
+	files, err := New(mock).GetPendingFiles(ScopeStaged)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 1 || files[0] != "src/a.go" {
+		t.Errorf("unexpected files: %v", files)
+	}
+}
+
+func TestGetPendingFiles_WorktreeIncludesUntracked(t *testing.T) {
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			switch {
+			case args[0] == "diff" && args[1] == "HEAD":
+				return []byte("src/a.go\n"), nil
+			case args[0] == "ls-files" && args[1] == "--others":
+				return []byte("src/new.go\n"), nil
+			}
+			t.Errorf("unexpected command: git %v", args)
+			return nil, nil
+		},
+	}
+
+	files, err := New(mock).GetPendingFiles(ScopeWorktree)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 2 || files[1] != "src/new.go" {
+		t.Errorf("expected tracked and untracked changes, got %v", files)
+	}
+}
+
+func TestGetPendingFileDiff_Staged(t *testing.T) {
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			if args[1] != "--cached" || args[len(args)-1] != "src/a.go" {
+				t.Errorf("expected staged diff of src/a.go, got %v", args)
+			}
+			return []byte("@@ -1 +1 @@\n-old\n+new\n"), nil
+		},
+	}
+
+	diff, err := New(mock).GetPendingFileDiff(ScopeStaged, "src/a.go")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff != "@@ -1 +1 @@\n-old\n+new" {
+		t.Errorf("unexpected diff: %q", diff)
+	}
+}
+
+func TestStageFiles(t *testing.T) {
+	var calls [][]string
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			calls = append(calls, args)
+			return nil, nil
+		},
+	}
+
+	if err := New(mock).StageFiles(nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := New(mock).StageFiles([]string{"src/index.md"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(calls) != 1 {
+		t.Fatalf("expected one git add, got %v", calls)
+	}
+	if calls[0][0] != "add" || calls[0][1] != "--" || calls[0][2] != "src/index.md" {
+		t.Errorf("unexpected args: %v", calls[0])
+	}
+}
+
+func TestHasUnstagedChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected bool
+	}{
+		{name: "clean", output: "", expected: false},
+		{name: "staged only", output: "M  src/index.md\n", expected: false},
+		{name: "unstaged edit", output: " M src/index.md\n", expected: true},
+		{name: "staged and unstaged edits", output: "MM src/index.md\n", expected: true},
+		{name: "untracked", output: "?? src/index.md\n", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args []string
+			mock := &mockCommander{
+				runFunc: func(name string, a ...string) ([]byte, error) {
+					args = a
+					return []byte(tt.output), nil
+				},
+			}
+
+			dirty, err := New(mock).HasUnstagedChanges("src/index.md")
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if dirty != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, dirty)
+			}
+			if strings.Join(args, " ") != "status --porcelain -- src/index.md" {
+				t.Errorf("unexpected args: %v", args)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+// Package glob matches slash-separated paths against gitignore-style glob patterns
+// with doublestar support: "**" spans any number of directories, a pattern without
+// a slash matches at any depth, a leading slash anchors it to the root, a trailing
+// slash matches directories only and a leading "!" re-includes what an earlier
+// pattern matched. A pattern that matches a directory matches everything under it.
+package glob
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Pattern is one compiled gitignore-style pattern
+type Pattern struct {
+	raw      string
+	negate   bool
+	dirOnly  bool
+	segments []string
+}
+
+// Compile parses a gitignore-style pattern
+func Compile(pattern string) (Pattern, error) {
+	p := Pattern{raw: pattern}
+	s := strings.TrimSpace(pattern)
+	if strings.HasPrefix(s, "!") {
+		p.negate = true
+		s = s[1:]
+	}
+	if strings.HasSuffix(s, "/") {
+		p.dirOnly = true
+		s = strings.TrimRight(s, "/")
+	}
+	if s == "" {
+		return Pattern{}, fmt.Errorf("invalid pattern %q: empty", pattern)
+	}
+
+	// Without a slash the pattern matches a name at any depth
+	anchored := strings.Contains(s, "/")
+	s = strings.TrimPrefix(s, "/")
+	if !anchored {
+		s = "**/" + s
+	}
+
+	for _, segment := range strings.Split(s, "/") {
+		if segment == "" {
+			continue
+		}
+		if segment != "**" {
+			// Validate the segment's syntax once so Match never fails
+			if _, err := path.Match(segment, ""); err != nil {
+				return Pattern{}, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+		}
+		p.segments = append(p.segments, segment)
+	}
+	return p, nil
+}
+
+// String returns the pattern as written
+func (p Pattern) String() string {
+	return p.raw
+}
+
+// Negated reports whether the pattern re-includes paths (leading "!")
+func (p Pattern) Negated() bool {
+	return p.negate
+}
+
+// Match reports whether the pattern matches a file path or one of its parent directories.
+// The negation prefix is ignored; Matcher applies it.
+func (p Pattern) Match(filePath string) bool {
+	parts := splitPath(filePath)
+	for n := len(parts); n > 0; n-- {
+		if p.dirOnly && n == len(parts) {
+			// The path itself is a file; only its directories can match
+			continue
+		}
+		if matchSegments(p.segments, parts[:n]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Matcher evaluates an ordered list of patterns; the last matching pattern wins
+type Matcher struct {
+	patterns []Pattern
+}
+
+// New compiles patterns into a Matcher. Blank patterns and "#" comments are ignored.
+func New(patterns []string) (*Matcher, error) {
+	m := &Matcher{}
+	for _, raw := range patterns {
+		trimmed := strings.TrimSpace(raw)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		p, err := Compile(trimmed)
+		if err != nil {
+			return nil, err
+		}
+		m.patterns = append(m.patterns, p)
+	}
+	return m, nil
+}
+
+// Empty reports whether the matcher has no patterns
+func (m *Matcher) Empty() bool {
+	return len(m.patterns) == 0
+}
+
+// Match reports whether the path is matched: the last pattern that matches decides,
+// so a later "!pattern" re-includes a path an earlier pattern matched
+func (m *Matcher) Match(filePath string) bool {
+	matched := false
+	for _, p := range m.patterns {
+		if p.Match(filePath) {
+			matched = !p.negate
+		}
+	}
+	return matched
+}
+
+// Match reports whether a single gitignore-style pattern matches the path
+func Match(pattern, filePath string) (bool, error) {
+	p, err := Compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	return p.Match(filePath) != p.negate, nil
+}
+
+// splitPath cleans a path into its segments, dropping any leading "./" or "/"
+func splitPath(filePath string) []string {
+	cleaned := path.Clean(strings.ReplaceAll(filePath, "\\", "/"))
+	cleaned = strings.TrimPrefix(cleaned, "/")
+	if cleaned == "." || cleaned == "" {
+		return nil
+	}
+	return strings.Split(cleaned, "/")
+}
+
+// matchSegments matches pattern segments against path segments; "**" spans zero or more segments
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(rest, parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
@@ -0,0 +1,78 @@
+package glob
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"basename at root", "*.md", "README.md", true},
+		{"basename at any depth", "*.md", "src/internal/index.md", true},
+		{"basename mismatch", "*.md", "src/main.go", false},
+		{"exact name at depth", "go.sum", "tools/go.sum", true},
+		{"directory name matches contents", "vendor", "src/vendor/lib/a.go", true},
+		{"dir-only matches contents", "node_modules/", "web/node_modules/x/index.js", true},
+		{"dir-only does not match file", "build/", "cmd/build", false},
+		{"anchored directory", "docs/**", "docs/guide/intro.md", true},
+		{"anchored does not float", "docs/**", "src/docs/a.md", false},
+		{"leading slash anchors", "/generated", "generated/a.go", true},
+		{"leading slash anchors at root only", "/generated", "src/generated/a.go", false},
+		{"doublestar in the middle", "src/**/*_gen.go", "src/a/b/model_gen.go", true},
+		{"doublestar spans zero dirs", "src/**/*_gen.go", "src/model_gen.go", true},
+		{"single star stays in segment", "src/*.go", "src/a/b.go", false},
+		{"character class", "*.[ch]", "lib/x.h", true},
+		{"question mark", "?.go", "a.go", true},
+		{"leading dot slash in path", "*.lock", "./Cargo.lock", true},
+		{"negation inverts", "!*.md", "README.md", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Match(tt.pattern, tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCompile_InvalidPattern(t *testing.T) {
+	_, err := Compile("[a-")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid pattern")
+
+	_, err = Compile("!")
+	require.Error(t, err)
+}
+
+func TestMatcher_LastMatchWins(t *testing.T) {
+	m, err := New([]string{
+		"# generated code",
+		"*.pb.go",
+		"",
+		"vendor/",
+		"!vendor/keep/",
+	})
+	require.NoError(t, err)
+
+	assert.True(t, m.Match("api/user.pb.go"))
+	assert.True(t, m.Match("vendor/lib/a.go"))
+	assert.False(t, m.Match("vendor/keep/a.go"), "negated pattern re-includes")
+	assert.False(t, m.Match("api/user.go"))
+	assert.False(t, m.Empty())
+}
+
+func TestMatcher_Empty(t *testing.T) {
+	m, err := New([]string{"  ", "# comment"})
+	require.NoError(t, err)
+
+	assert.True(t, m.Empty())
+	assert.False(t, m.Match("main.go"))
+}
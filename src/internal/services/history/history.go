@@ -0,0 +1,271 @@
+// Package history keeps snapshots of session documents (e.g. session-overview.md)
+// taken before each background update overwrites them, so a bad model rewrite
+// can be inspected, diffed and rolled back.
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"claudex/internal/services/clock"
+
+	"github.com/spf13/afero"
+)
+
+// DirName is the snapshot directory inside a session folder
+const DirName = ".history"
+
+// DefaultLimit is the number of snapshots kept per document when none is configured
+const DefaultLimit = 20
+
+// TriggerRestore marks snapshots taken before a restore overwrote the document
+const TriggerRestore = "restore"
+
+// Snapshot describes one saved version of a session document
+type Snapshot struct {
+	ID        string    `json:"id"`      // <UTC timestamp>-<sequence>, sorts in creation order
+	Number    int       `json:"number"`  // 1 is the newest snapshot of the document
+	File      string    `json:"file"`    // Document name relative to the session folder
+	Trigger   string    `json:"trigger"` // What overwrote the document (progress, subagent, session_end, restore)
+	CreatedAt time.Time `json:"created_at"`
+	Size      int64     `json:"size"`
+}
+
+// Store reads and writes the snapshots of one session folder
+type Store struct {
+	fs    afero.Fs
+	clock clock.Clock
+	dir   string
+}
+
+// New creates a Store for the given session folder
+func New(fs afero.Fs, clk clock.Clock, sessionPath string) *Store {
+	return &Store{
+		fs:    fs,
+		clock: clk,
+		dir:   filepath.Join(sessionPath, DirName),
+	}
+}
+
+// Dir returns the snapshot directory
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// contentPath returns the path holding a snapshot's document content
+func (s *Store) contentPath(id string) string {
+	return filepath.Join(s.dir, id+".md")
+}
+
+// metaPath returns the path holding a snapshot's metadata
+func (s *Store) metaPath(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// documentPath returns the path of a document in the session folder
+func (s *Store) documentPath(file string) string {
+	return filepath.Join(filepath.Dir(s.dir), file)
+}
+
+// Snapshot saves the current content of a document before it is overwritten.
+// Nothing is saved (nil snapshot) when the document is missing, empty or
+// identical to its newest snapshot. Older snapshots beyond limit are removed;
+// limit <= 0 keeps everything.
+func (s *Store) Snapshot(file, trigger string, limit int) (*Snapshot, error) {
+	content, err := afero.ReadFile(s.fs, s.documentPath(file))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	if len(bytes.TrimSpace(content)) == 0 {
+		return nil, nil
+	}
+
+	snapshots, err := s.List(file)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) > 0 {
+		newest, err := s.Read(snapshots[0])
+		if err == nil && bytes.Equal(newest, content) {
+			return nil, nil
+		}
+	}
+
+	if err := s.fs.MkdirAll(s.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	now := s.clock.Now().UTC()
+	snap := &Snapshot{
+		Number:    1,
+		File:      file,
+		Trigger:   trigger,
+		CreatedAt: now,
+		Size:      int64(len(content)),
+	}
+
+	// Metadata is created with O_EXCL so concurrent writers never share an ID.
+	// Sequences continue after the highest existing one so IDs freed by pruning
+	// are never reused out of order.
+	prefix := now.Format("20060102-150405")
+	for seq := s.lastSequence(prefix) + 1; seq < 1000; seq++ {
+		snap.ID = fmt.Sprintf("%s-%03d", prefix, seq)
+		meta, err := s.fs.OpenFile(s.metaPath(snap.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err != nil {
+			if os.IsExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to create snapshot: %w", err)
+		}
+		meta.Close()
+
+		if err := afero.WriteFile(s.fs, s.contentPath(snap.ID), content, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write snapshot: %w", err)
+		}
+		data, err := json.MarshalIndent(snap, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal snapshot: %w", err)
+		}
+		if err := afero.WriteFile(s.fs, s.metaPath(snap.ID), data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write snapshot metadata: %w", err)
+		}
+
+		if err := s.Prune(file, limit); err != nil {
+			return snap, err
+		}
+		return snap, nil
+	}
+
+	return nil, fmt.Errorf("failed to allocate snapshot ID for %s", prefix)
+}
+
+// lastSequence returns the highest sequence used by snapshot IDs with the given prefix
+func (s *Store) lastSequence(prefix string) int {
+	entries, err := afero.ReadDir(s.fs, s.dir)
+	if err != nil {
+		return 0
+	}
+
+	last := 0
+	for _, entry := range entries {
+		var seq int
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		if strings.HasPrefix(name, prefix+"-") {
+			if _, err := fmt.Sscanf(strings.TrimPrefix(name, prefix+"-"), "%03d", &seq); err == nil && seq > last {
+				last = seq
+			}
+		}
+	}
+	return last
+}
+
+// List returns the snapshots of a document, newest first and numbered from 1.
+// A missing history directory yields an empty list.
+func (s *Store) List(file string) ([]Snapshot, error) {
+	entries, err := afero.ReadDir(s.fs, s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Snapshot{}, nil
+		}
+		return nil, fmt.Errorf("failed to read history directory: %w", err)
+	}
+
+	snapshots := []Snapshot{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		data, err := afero.ReadFile(s.fs, filepath.Join(s.dir, name))
+		if err != nil {
+			continue
+		}
+		var snap Snapshot
+		if err := json.Unmarshal(data, &snap); err != nil || snap.File != file {
+			// Skip snapshots that are mid-write or belong to another document
+			continue
+		}
+		snapshots = append(snapshots, snap)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].ID > snapshots[j].ID })
+	for i := range snapshots {
+		snapshots[i].Number = i + 1
+	}
+	return snapshots, nil
+}
+
+// Get returns snapshot number n (1 is the newest) of a document
+func (s *Store) Get(file string, n int) (Snapshot, error) {
+	snapshots, err := s.List(file)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if n < 1 || n > len(snapshots) {
+		if len(snapshots) == 0 {
+			return Snapshot{}, fmt.Errorf("no history for %s", file)
+		}
+		return Snapshot{}, fmt.Errorf("snapshot %d not found for %s (1-%d available)", n, file, len(snapshots))
+	}
+	return snapshots[n-1], nil
+}
+
+// Read returns the document content saved in a snapshot
+func (s *Store) Read(snap Snapshot) ([]byte, error) {
+	data, err := afero.ReadFile(s.fs, s.contentPath(snap.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %d: %w", snap.Number, err)
+	}
+	return data, nil
+}
+
+// Restore overwrites a document with snapshot n. The current content is
+// snapshotted first (trigger "restore"), so a restore can itself be undone.
+func (s *Store) Restore(file string, n int, limit int) (Snapshot, error) {
+	snap, err := s.Get(file, n)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	content, err := s.Read(snap)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	if _, err := s.Snapshot(file, TriggerRestore, limit); err != nil {
+		return Snapshot{}, err
+	}
+
+	if err := afero.WriteFile(s.fs, s.documentPath(file), content, 0644); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to restore %s: %w", file, err)
+	}
+	return snap, nil
+}
+
+// Prune removes the oldest snapshots of a document beyond keep (keep <= 0 keeps everything)
+func (s *Store) Prune(file string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	snapshots, err := s.List(file)
+	if err != nil {
+		return err
+	}
+	for _, snap := range snapshots[min(keep, len(snapshots)):] {
+		if err := s.fs.Remove(s.contentPath(snap.ID)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove snapshot: %w", err)
+		}
+		if err := s.fs.Remove(s.metaPath(snap.ID)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove snapshot: %w", err)
+		}
+	}
+	return nil
+}
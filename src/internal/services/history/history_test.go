@@ -0,0 +1,145 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+
+	"claudex/internal/testutil"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	sessionPath = "/project/.claudex/sessions/task"
+	overview    = "session-overview.md"
+)
+
+// writeOverview replaces the session overview content
+func writeOverview(h *testutil.TestHarness, content string) {
+	h.WriteFile(filepath.Join(sessionPath, overview), content)
+}
+
+func TestStore_Snapshot_SkipsMissingEmptyAndUnchanged(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	store := New(h.FS, h, sessionPath)
+
+	// Exercise & Verify: missing and blank documents are not saved
+	snap, err := store.Snapshot(overview, "progress", DefaultLimit)
+	require.NoError(t, err)
+	assert.Nil(t, snap)
+
+	writeOverview(h, "  \n")
+	snap, err = store.Snapshot(overview, "progress", DefaultLimit)
+	require.NoError(t, err)
+	assert.Nil(t, snap)
+
+	// The first real version is saved once
+	writeOverview(h, "# Overview v1\n")
+	snap, err = store.Snapshot(overview, "progress", DefaultLimit)
+	require.NoError(t, err)
+	require.NotNil(t, snap)
+	assert.Equal(t, "progress", snap.Trigger)
+	assert.Equal(t, int64(len("# Overview v1\n")), snap.Size)
+
+	snap, err = store.Snapshot(overview, "subagent", DefaultLimit)
+	require.NoError(t, err)
+	assert.Nil(t, snap, "unchanged content is not saved twice")
+}
+
+func TestStore_List_NewestFirst(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	store := New(h.FS, h, sessionPath)
+	for _, v := range []string{"v1", "v2", "v3"} {
+		writeOverview(h, v)
+		_, err := store.Snapshot(overview, "progress", DefaultLimit)
+		require.NoError(t, err)
+	}
+	h.WriteFile(filepath.Join(sessionPath, "notes.md"), "other doc")
+	_, err := store.Snapshot("notes.md", "progress", DefaultLimit)
+	require.NoError(t, err)
+
+	// Exercise
+	snapshots, err := store.List(overview)
+
+	// Verify
+	require.NoError(t, err)
+	require.Len(t, snapshots, 3, "snapshots of other documents are not listed")
+	for i, want := range []string{"v3", "v2", "v1"} {
+		assert.Equal(t, i+1, snapshots[i].Number)
+		content, err := store.Read(snapshots[i])
+		require.NoError(t, err)
+		assert.Equal(t, want, string(content))
+	}
+}
+
+func TestStore_Snapshot_PrunesBeyondLimit(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	store := New(h.FS, h, sessionPath)
+
+	// Exercise
+	for _, v := range []string{"v1", "v2", "v3", "v4"} {
+		writeOverview(h, v)
+		_, err := store.Snapshot(overview, "progress", 2)
+		require.NoError(t, err)
+	}
+
+	// Verify
+	snapshots, err := store.List(overview)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	newest, err := store.Read(snapshots[0])
+	require.NoError(t, err)
+	assert.Equal(t, "v4", string(newest))
+
+	files, err := afero.ReadDir(h.FS, store.Dir())
+	require.NoError(t, err)
+	assert.Len(t, files, 4, "content and metadata of pruned snapshots are removed")
+}
+
+func TestStore_Restore_SavesCurrentFirst(t *testing.T) {
+	// Setup: v1 was overwritten by a bad rewrite
+	h := testutil.NewTestHarness()
+	store := New(h.FS, h, sessionPath)
+	writeOverview(h, "# Good overview\n")
+	_, err := store.Snapshot(overview, "progress", DefaultLimit)
+	require.NoError(t, err)
+	writeOverview(h, "# Hallucinated\n")
+
+	// Exercise
+	restored, err := store.Restore(overview, 1, DefaultLimit)
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, "progress", restored.Trigger)
+	testutil.AssertFileContains(t, h.FS, filepath.Join(sessionPath, overview), "# Good overview")
+
+	snapshots, err := store.List(overview)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, TriggerRestore, snapshots[0].Trigger)
+	replaced, err := store.Read(snapshots[0])
+	require.NoError(t, err)
+	assert.Equal(t, "# Hallucinated\n", string(replaced))
+}
+
+func TestStore_Get_OutOfRange(t *testing.T) {
+	h := testutil.NewTestHarness()
+	store := New(h.FS, h, sessionPath)
+
+	_, err := store.Get(overview, 1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no history for session-overview.md")
+
+	writeOverview(h, "v1")
+	_, err = store.Snapshot(overview, "progress", DefaultLimit)
+	require.NoError(t, err)
+
+	_, err = store.Get(overview, 2)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1-1 available")
+}
@@ -1,9 +1,13 @@
 // Package hooksetup provides Git hook installation for Claudex.
-// It safely installs a post-commit hook to trigger documentation updates
-// without breaking existing hooks.
+// It safely adds a guarded block running documentation updates to the post-commit,
+// post-merge and post-rewrite hooks without breaking existing hooks, resolves the
+// hooks directory through git (core.hooksPath, worktrees) and removes the block again.
+// Projects whose hooks are owned by husky or lefthook get a snippet instead.
 package hooksetup
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -15,12 +19,20 @@ import (
 
 const (
 	guardMarker = "# claudex-docs-hook"
+	guardEnd    = "# claudex-docs-hook end"
+	hookCommand = "claudex --update-docs &"
 	hookContent = `
 # claudex-docs-hook
 claudex --update-docs &
+# claudex-docs-hook end
 `
+	shebang = "#!/bin/sh"
 )
 
+// ErrHookManager is returned by Install when husky or lefthook owns the git hooks,
+// since a block written to the hooks directory would be overwritten or bypassed
+var ErrHookManager = errors.New("git hooks are managed by a hook manager")
+
 // FileService is the production implementation of Service
 type FileService struct {
 	fs         afero.Fs
@@ -37,51 +49,254 @@ func New(fs afero.Fs, projectDir string, cmdr commander.Commander) Service {
 	}
 }
 
-// IsGitRepo checks if .git directory exists
+// IsGitRepo checks if the project is a git repository; in a worktree .git is a file
 func (s *FileService) IsGitRepo() bool {
-	info, err := s.fs.Stat(filepath.Join(s.projectDir, ".git"))
-	return err == nil && info.IsDir()
+	if output, err := s.git("rev-parse", "--git-dir"); err == nil && output != "" {
+		return true
+	}
+	exists, err := afero.Exists(s.fs, filepath.Join(s.projectDir, ".git"))
+	return err == nil && exists
 }
 
-// IsInstalled checks for guard marker in post-commit hook
+// IsInstalled checks for guard marker in the post-commit hook
 func (s *FileService) IsInstalled() bool {
-	hookPath := filepath.Join(s.projectDir, ".git", "hooks", "post-commit")
-	data, err := afero.ReadFile(s.fs, hookPath)
-	if err != nil {
-		return false
+	for _, hook := range s.Status().Hooks {
+		if hook.Trigger == TriggerPostCommit {
+			return hook.Installed
+		}
 	}
-	return strings.Contains(string(data), guardMarker)
+	return false
 }
 
-// Install appends hook line to post-commit (creates if not exists)
+// Install appends the hook block to post-commit (creates if not exists)
 func (s *FileService) Install() error {
-	hookPath := filepath.Join(s.projectDir, ".git", "hooks", "post-commit")
+	if manager := s.detectManager(); manager != ManagerNone {
+		return fmt.Errorf("%w: %s", ErrHookManager, manager)
+	}
+	return s.InstallHooks([]Trigger{TriggerPostCommit})
+}
+
+// InstallHooks appends the hook block to each trigger's hook in the hooks directory
+func (s *FileService) InstallHooks(triggers []Trigger) error {
+	hooksDir := s.hooksDir()
 
 	// Ensure hooks directory exists
-	hooksDir := filepath.Dir(hookPath)
 	if err := s.fs.MkdirAll(hooksDir, 0755); err != nil {
 		return err
 	}
 
+	for _, trigger := range triggers {
+		if err := s.installHook(filepath.Join(hooksDir, string(trigger))); err != nil {
+			return fmt.Errorf("failed to install %s hook: %w", trigger, err)
+		}
+	}
+	return nil
+}
+
+// installHook adds the guarded block to one hook script unless it is already there
+func (s *FileService) installHook(hookPath string) error {
 	// Check if file exists
 	existing, err := afero.ReadFile(s.fs, hookPath)
 	if err != nil && !os.IsNotExist(err) {
 		return err
 	}
+	if strings.Contains(string(existing), guardMarker) {
+		return nil
+	}
 
 	var content string
 	if len(existing) == 0 {
 		// New file - add shebang
-		content = "#!/bin/sh\n" + hookContent
+		content = shebang + "\n" + hookContent
 	} else {
 		// Append to existing
-		content = string(existing) + "\n" + hookContent
+		content = strings.TrimRight(string(existing), "\n") + "\n" + hookContent
 	}
 
-	// Write file
 	if err := afero.WriteFile(s.fs, hookPath, []byte(content), 0755); err != nil {
 		return err
 	}
+	// WriteFile only applies the mode to new files; git skips hooks that aren't executable
+	return s.fs.Chmod(hookPath, 0755)
+}
 
-	return nil
+// UninstallHooks removes the guarded block from each trigger's hook, in the hooks
+// directory and in husky's scripts. A hook left with nothing but a shebang is deleted.
+func (s *FileService) UninstallHooks(triggers []Trigger) ([]string, error) {
+	var changed []string
+	for _, trigger := range triggers {
+		for _, hookPath := range []string{filepath.Join(s.hooksDir(), string(trigger)), s.huskyHook(trigger)} {
+			removed, err := s.uninstallHook(hookPath)
+			if err != nil {
+				return changed, fmt.Errorf("failed to uninstall %s hook: %w", trigger, err)
+			}
+			if removed {
+				changed = append(changed, hookPath)
+			}
+		}
+	}
+	return changed, nil
+}
+
+// uninstallHook removes the guarded block from one hook script, reporting whether it was there
+func (s *FileService) uninstallHook(hookPath string) (bool, error) {
+	data, err := afero.ReadFile(s.fs, hookPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	content, removed := removeBlock(string(data))
+	if !removed {
+		return false, nil
+	}
+
+	if rest := strings.TrimSpace(content); rest == "" || rest == shebang {
+		return true, s.fs.Remove(hookPath)
+	}
+	return true, afero.WriteFile(s.fs, hookPath, []byte(content), 0755)
+}
+
+// Status reports the hooks directory, the hook manager and every trigger's hook
+func (s *FileService) Status() Status {
+	status := Status{HooksDir: s.hooksDir(), Manager: s.detectManager()}
+	if hooksPath, err := s.git("config", "--get", "core.hooksPath"); err == nil {
+		status.HooksPath = hooksPath
+	}
+
+	for _, trigger := range Triggers {
+		var hook HookStatus
+		switch status.Manager {
+		case ManagerHusky:
+			hook = s.scriptStatus(s.huskyHook(trigger))
+		case ManagerLefthook:
+			hook = s.lefthookStatus(trigger)
+		default:
+			hook = s.scriptStatus(filepath.Join(status.HooksDir, string(trigger)))
+		}
+		hook.Trigger = trigger
+		status.Hooks = append(status.Hooks, hook)
+	}
+	return status
+}
+
+// scriptStatus inspects a hook script for the guarded block
+func (s *FileService) scriptStatus(hookPath string) HookStatus {
+	hook := HookStatus{Path: hookPath}
+	info, err := s.fs.Stat(hookPath)
+	if err != nil {
+		return hook
+	}
+	hook.Exists = true
+	hook.Executable = info.Mode()&0111 != 0
+	if data, err := afero.ReadFile(s.fs, hookPath); err == nil {
+		hook.Installed = strings.Contains(string(data), guardMarker)
+	}
+	return hook
+}
+
+// lefthookStatus looks for the trigger's claudex command in the lefthook config
+func (s *FileService) lefthookStatus(trigger Trigger) HookStatus {
+	configPath := s.lefthookConfig()
+	hook := HookStatus{Path: configPath, Executable: true}
+	data, err := afero.ReadFile(s.fs, configPath)
+	if err != nil {
+		return hook
+	}
+	hook.Exists = true
+	hook.Installed = strings.Contains(string(data), lefthookCommand(trigger)+":")
+	return hook
+}
+
+// hooksDir returns the directory git runs hooks from. git resolves core.hooksPath and
+// the common directory of worktrees; .git/hooks is the fallback outside git.
+func (s *FileService) hooksDir() string {
+	dir, err := s.git("rev-parse", "--git-path", "hooks")
+	if err != nil || dir == "" {
+		return filepath.Join(s.projectDir, ".git", "hooks")
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(s.projectDir, dir)
+	}
+	return dir
+}
+
+// detectManager reports whether husky or lefthook owns the project's hooks
+func (s *FileService) detectManager() Manager {
+	if exists, _ := afero.DirExists(s.fs, filepath.Join(s.projectDir, ".husky")); exists {
+		return ManagerHusky
+	}
+	if exists, _ := afero.Exists(s.fs, s.lefthookConfig()); exists {
+		return ManagerLefthook
+	}
+	return ManagerNone
+}
+
+// huskyHook returns the husky script of a trigger
+func (s *FileService) huskyHook(trigger Trigger) string {
+	return filepath.Join(s.projectDir, ".husky", string(trigger))
+}
+
+// lefthookConfig returns the lefthook config file in use (lefthook.yml when there is none)
+func (s *FileService) lefthookConfig() string {
+	for _, name := range []string{"lefthook.yml", ".lefthook.yml", "lefthook.yaml", ".lefthook.yaml"} {
+		path := filepath.Join(s.projectDir, name)
+		if exists, _ := afero.Exists(s.fs, path); exists {
+			return path
+		}
+	}
+	return filepath.Join(s.projectDir, "lefthook.yml")
+}
+
+// git runs a git command against the project directory and returns its trimmed output
+func (s *FileService) git(args ...string) (string, error) {
+	output, err := s.cmdr.Run("git", append([]string{"-C", s.projectDir}, args...)...)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// removeBlock drops every guarded block from a hook script, including the blank line
+// written before it. Blocks from older installs have no end marker and span the command only.
+func removeBlock(content string) (string, bool) {
+	lines := strings.Split(content, "\n")
+	var kept []string
+	removed := false
+	for i := 0; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) != guardMarker {
+			kept = append(kept, lines[i])
+			continue
+		}
+		removed = true
+		for len(kept) > 0 && strings.TrimSpace(kept[len(kept)-1]) == "" {
+			kept = kept[:len(kept)-1]
+		}
+
+		end := -1
+		for j := i + 1; j < len(lines); j++ {
+			trimmed := strings.TrimSpace(lines[j])
+			if trimmed == guardEnd {
+				end = j
+				break
+			}
+			if trimmed == guardMarker {
+				break
+			}
+		}
+		switch {
+		case end >= 0:
+			i = end
+		case i+1 < len(lines) && strings.TrimSpace(lines[i+1]) == hookCommand:
+			i++
+		}
+	}
+
+	result := strings.TrimRight(strings.Join(kept, "\n"), "\n")
+	if result != "" {
+		result += "\n"
+	}
+	return result, removed
 }
@@ -0,0 +1,32 @@
+package hooksetup
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Snippet returns what to add to a hook manager's setup so the given triggers run
+// claudex: one script per trigger for husky, hook commands for lefthook.yml
+func Snippet(manager Manager, triggers []Trigger) string {
+	var b strings.Builder
+	switch manager {
+	case ManagerHusky:
+		for i, trigger := range triggers {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "# Append to .husky/%s\n%s\n%s\n%s\n", trigger, guardMarker, hookCommand, guardEnd)
+		}
+	case ManagerLefthook:
+		b.WriteString("# Add to lefthook.yml\n")
+		for _, trigger := range triggers {
+			fmt.Fprintf(&b, "%s:\n  commands:\n    %s:\n      run: %s\n", trigger, lefthookCommand(trigger), hookCommand)
+		}
+	}
+	return b.String()
+}
+
+// lefthookCommand names the lefthook command of a trigger so status can find it
+func lefthookCommand(trigger Trigger) string {
+	return "claudex-docs-" + string(trigger)
+}
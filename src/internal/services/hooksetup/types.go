@@ -2,10 +2,62 @@ package hooksetup
 
 // Service defines the git hook setup interface
 type Service interface {
-	// IsGitRepo checks if the project directory is a git repository
+	// IsGitRepo checks if the project directory is a git repository (or a worktree of one)
 	IsGitRepo() bool
-	// IsInstalled checks if the claudex hook is already installed
+	// IsInstalled checks if the claudex hook is already installed for post-commit
 	IsInstalled() bool
-	// Install adds the claudex hook to post-commit (append-safe)
+	// Install adds the claudex hook to post-commit (append-safe); it returns
+	// ErrHookManager when husky or lefthook manages the hooks
 	Install() error
+	// InstallHooks adds the claudex block to the hooks of the given triggers in the
+	// hooks directory, skipping those that already have it
+	InstallHooks(triggers []Trigger) error
+	// UninstallHooks removes the claudex block from the hooks of the given triggers and
+	// returns the hook files it changed
+	UninstallHooks(triggers []Trigger) ([]string, error)
+	// Status reports where hooks live, which hook manager is in use and the state of every trigger
+	Status() Status
+}
+
+// Trigger is a git hook that can run claudex --update-docs
+type Trigger string
+
+const (
+	TriggerPostCommit  Trigger = "post-commit"  // after every commit
+	TriggerPostMerge   Trigger = "post-merge"   // after git merge and git pull
+	TriggerPostRewrite Trigger = "post-rewrite" // after git commit --amend and git rebase
+)
+
+// Triggers lists every supported trigger
+var Triggers = []Trigger{TriggerPostCommit, TriggerPostMerge, TriggerPostRewrite}
+
+// Manager is a hook manager that owns the git hooks of a project
+type Manager string
+
+const (
+	ManagerNone     Manager = ""
+	ManagerHusky    Manager = "husky"
+	ManagerLefthook Manager = "lefthook"
+)
+
+// Status describes the claudex-managed hooks of a project
+type Status struct {
+	// HooksDir is the directory git runs hooks from (core.hooksPath aware)
+	HooksDir string `json:"hooks_dir"`
+	// HooksPath is the configured core.hooksPath, if any
+	HooksPath string `json:"hooks_path,omitempty"`
+	// Manager is the hook manager detected in the project
+	Manager Manager      `json:"manager,omitempty"`
+	Hooks   []HookStatus `json:"hooks"`
+}
+
+// HookStatus is the state of one trigger
+type HookStatus struct {
+	Trigger Trigger `json:"trigger"`
+	// Path is the file holding the claudex block: the hook script, the husky script
+	// or the lefthook config
+	Path       string `json:"path"`
+	Exists     bool   `json:"exists"`
+	Installed  bool   `json:"installed"`
+	Executable bool   `json:"executable"`
 }
@@ -0,0 +1,67 @@
+package jobs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// HooksProcess runs a job as `claudex-hooks <kind>` with the payload on stdin
+type HooksProcess struct {
+	bin string
+}
+
+// NewHooksProcess creates a Process that executes the given claudex-hooks binary
+func NewHooksProcess(bin string) *HooksProcess {
+	return &HooksProcess{bin: bin}
+}
+
+// Run executes the job and returns the child's exit code
+func (p *HooksProcess) Run(job *Job, stderr io.Writer, onStart func(pid int)) (int, error) {
+	cmd := exec.Command(p.bin, job.Kind)
+	cmd.Stdin = bytes.NewReader(job.Payload)
+	cmd.Stderr = stderr
+	cmd.Env = os.Environ()
+
+	if err := cmd.Start(); err != nil {
+		return -1, fmt.Errorf("failed to start %s %s: %w", p.bin, job.Kind, err)
+	}
+	onStart(cmd.Process.Pid)
+
+	if err := cmd.Wait(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode(), nil
+		}
+		return -1, fmt.Errorf("failed to wait for %s %s: %w", p.bin, job.Kind, err)
+	}
+	return 0, nil
+}
+
+// SpawnWorker starts `claudex-hooks job-worker <sessionPath>` as a detached process
+// that survives the parent exit. It returns as soon as the worker has started.
+func SpawnWorker(hooksBin, sessionPath string) error {
+	cmd := exec.Command(hooksBin, "job-worker", sessionPath)
+	cmd.Env = os.Environ()
+
+	// Detach the process so it survives parent exit
+	cmd.SysProcAttr = detachedAttr()
+
+	// The worker records job output in <session>/jobs/<id>.log
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start job worker: %w", err)
+	}
+
+	// Don't wait for the worker - reap it in the background if we are still alive
+	go func() {
+		_ = cmd.Wait()
+	}()
+
+	return nil
+}
@@ -0,0 +1,30 @@
+//go:build !unix
+
+package jobs
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// detachedAttr returns no process attributes; without process groups the worker
+// only needs to be started without waiting for it
+func detachedAttr() *syscall.SysProcAttr {
+	return nil
+}
+
+// Terminate stops a running job process. There is no SIGTERM here, so the
+// process is killed outright.
+func Terminate(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		// Already gone - cancelling is still valid
+		return nil
+	}
+	defer process.Release()
+	if err := process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+//go:build unix
+
+package jobs
+
+import (
+	"errors"
+	"syscall"
+)
+
+// detachedAttr puts a spawned worker in its own process group
+func detachedAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		Setpgid: true, // Create new process group
+	}
+}
+
+// Terminate sends SIGTERM to a running job process
+func Terminate(pid int) error {
+	err := syscall.Kill(pid, syscall.SIGTERM)
+	if errors.Is(err, syscall.ESRCH) {
+		// Already gone - cancelling is still valid
+		return nil
+	}
+	return err
+}
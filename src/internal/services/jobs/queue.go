@@ -0,0 +1,213 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"claudex/internal/services/clock"
+
+	"github.com/spf13/afero"
+)
+
+// Queue stores job status files in <session>/jobs/
+type Queue struct {
+	fs    afero.Fs
+	clock clock.Clock
+	dir   string
+}
+
+// NewQueue creates a Queue for the given session folder
+func NewQueue(fs afero.Fs, clk clock.Clock, sessionPath string) *Queue {
+	return &Queue{
+		fs:    fs,
+		clock: clk,
+		dir:   filepath.Join(sessionPath, DirName),
+	}
+}
+
+// Dir returns the queue directory
+func (q *Queue) Dir() string {
+	return q.dir
+}
+
+// LockPath returns the path of the worker lock file
+func (q *Queue) LockPath() string {
+	return filepath.Join(q.dir, LockFile)
+}
+
+// LogPath returns the path of the captured stderr log for a job
+func (q *Queue) LogPath(id string) string {
+	return filepath.Join(q.dir, id+".log")
+}
+
+// statusPath returns the path of the status file for a job
+func (q *Queue) statusPath(id string) string {
+	return filepath.Join(q.dir, id+".json")
+}
+
+// Enqueue adds a queued job with the given payload.
+// IDs sort in creation order: <UTC timestamp>-<sequence>. The status file is created
+// with O_EXCL so concurrent producers never share an ID.
+func (q *Queue) Enqueue(kind string, payload interface{}) (*Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal job payload: %w", err)
+	}
+
+	if err := q.fs.MkdirAll(q.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create jobs directory: %w", err)
+	}
+
+	now := q.clock.Now().UTC()
+	job := &Job{
+		Kind:        kind,
+		Status:      StatusQueued,
+		MaxAttempts: DefaultMaxAttempts,
+		CreatedAt:   now,
+		Payload:     data,
+	}
+
+	prefix := now.Format("20060102-150405")
+	for seq := 1; seq < 1000; seq++ {
+		job.ID = fmt.Sprintf("%s-%03d", prefix, seq)
+		file, err := q.fs.OpenFile(q.statusPath(job.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err != nil {
+			if os.IsExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to create job file: %w", err)
+		}
+		file.Close()
+
+		if err := q.Save(job); err != nil {
+			return nil, err
+		}
+		return job, nil
+	}
+
+	return nil, fmt.Errorf("failed to allocate job ID for %s", prefix)
+}
+
+// Get loads a job by ID
+func (q *Queue) Get(id string) (*Job, error) {
+	data, err := afero.ReadFile(q.fs, q.statusPath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("job not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to read job %s: %w", id, err)
+	}
+
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to parse job %s: %w", id, err)
+	}
+	return &job, nil
+}
+
+// List returns all jobs in creation order. A missing jobs directory yields an empty list.
+func (q *Queue) List() ([]*Job, error) {
+	entries, err := afero.ReadDir(q.fs, q.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*Job{}, nil
+		}
+		return nil, fmt.Errorf("failed to read jobs directory: %w", err)
+	}
+
+	jobs := []*Job{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		job, err := q.Get(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			// Skip files that are mid-write or corrupt rather than hiding the rest of the queue
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID < jobs[j].ID })
+	return jobs, nil
+}
+
+// Save writes the job status file atomically (temp file + rename)
+func (q *Queue) Save(job *Job) error {
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal job %s: %w", job.ID, err)
+	}
+
+	tmpPath := q.statusPath(job.ID) + ".tmp"
+	if err := afero.WriteFile(q.fs, tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write job %s: %w", job.ID, err)
+	}
+	if err := q.fs.Rename(tmpPath, q.statusPath(job.ID)); err != nil {
+		return fmt.Errorf("failed to save job %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+// Cancel marks a queued or running job as cancelled.
+// Running jobs are stopped by calling terminate with the child PID; a running job
+// whose child has not reported its PID yet cannot be stopped and is refused.
+func (q *Queue) Cancel(id string, terminate func(pid int) error) (*Job, error) {
+	job, err := q.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Finished() {
+		return nil, fmt.Errorf("job %s already %s", id, job.Status)
+	}
+
+	if job.Status == StatusRunning {
+		if job.PID == 0 {
+			return nil, fmt.Errorf("job %s is starting; retry once its process is running", id)
+		}
+		if err := terminate(job.PID); err != nil {
+			return nil, fmt.Errorf("failed to stop job %s (pid %d): %w", id, job.PID, err)
+		}
+	}
+
+	now := q.clock.Now().UTC()
+	job.Status = StatusCancelled
+	job.EndedAt = &now
+	job.NextAttemptAt = nil
+	if err := q.Save(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Prune removes the oldest finished jobs (status file and log), keeping the newest keep
+func (q *Queue) Prune(keep int) error {
+	jobs, err := q.List()
+	if err != nil {
+		return err
+	}
+
+	var finished []*Job
+	for _, job := range jobs {
+		if job.Finished() {
+			finished = append(finished, job)
+		}
+	}
+
+	for i := 0; i < len(finished)-keep; i++ {
+		id := finished[i].ID
+		if err := q.fs.Remove(q.statusPath(id)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove job %s: %w", id, err)
+		}
+		if err := q.fs.Remove(q.LogPath(id)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove job log %s: %w", id, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package jobs
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"claudex/internal/testutil"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSessionPath = "/project/.claudex/sessions/feature-aaaa1111-2222-3333-4444-555566667777"
+
+func TestQueue_Enqueue_AllocatesSequentialIDs(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	h.FixedTime = time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	queue := NewQueue(h.FS, h, testSessionPath)
+
+	// Exercise
+	first, err1 := queue.Enqueue(KindDocUpdate, map[string]string{"session_path": testSessionPath})
+	second, err2 := queue.Enqueue(KindDocUpdate, map[string]string{"session_path": testSessionPath})
+
+	// Verify
+	require.NoError(t, err1)
+	require.NoError(t, err2)
+	assert.Equal(t, "20240115-103000-001", first.ID)
+	assert.Equal(t, "20240115-103000-002", second.ID)
+
+	loaded, err := queue.Get(first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusQueued, loaded.Status)
+	assert.Equal(t, DefaultMaxAttempts, loaded.MaxAttempts)
+	assert.JSONEq(t, `{"session_path":"`+testSessionPath+`"}`, string(loaded.Payload))
+
+	list, err := queue.List()
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, first.ID, list[0].ID)
+}
+
+func TestQueue_List_MissingDirectoryIsEmpty(t *testing.T) {
+	h := testutil.NewTestHarness()
+	queue := NewQueue(h.FS, h, testSessionPath)
+
+	list, err := queue.List()
+
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestQueue_Cancel_QueuedJob(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	queue := NewQueue(h.FS, h, testSessionPath)
+	job, err := queue.Enqueue(KindDocUpdate, struct{}{})
+	require.NoError(t, err)
+
+	terminated := false
+	terminate := func(pid int) error { terminated = true; return nil }
+
+	// Exercise
+	cancelled, err := queue.Cancel(job.ID, terminate)
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, StatusCancelled, cancelled.Status)
+	assert.NotNil(t, cancelled.EndedAt)
+	assert.False(t, terminated, "queued jobs have no process to stop")
+
+	_, err = queue.Cancel(job.ID, terminate)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already cancelled")
+}
+
+func TestQueue_Cancel_RunningJobTerminatesProcess(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	queue := NewQueue(h.FS, h, testSessionPath)
+	job, err := queue.Enqueue(KindDocUpdate, struct{}{})
+	require.NoError(t, err)
+	job.Status = StatusRunning
+	job.PID = 4242
+	require.NoError(t, queue.Save(job))
+
+	var terminatedPID int
+	terminate := func(pid int) error { terminatedPID = pid; return nil }
+
+	// Exercise
+	cancelled, err := queue.Cancel(job.ID, terminate)
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, 4242, terminatedPID)
+	assert.Equal(t, StatusCancelled, cancelled.Status)
+}
+
+func TestQueue_Cancel_TerminateFailureKeepsStatus(t *testing.T) {
+	h := testutil.NewTestHarness()
+	queue := NewQueue(h.FS, h, testSessionPath)
+	job, err := queue.Enqueue(KindDocUpdate, struct{}{})
+	require.NoError(t, err)
+	job.Status = StatusRunning
+	job.PID = 4242
+	require.NoError(t, queue.Save(job))
+
+	_, err = queue.Cancel(job.ID, func(pid int) error { return errors.New("operation not permitted") })
+
+	require.Error(t, err)
+	loaded, err := queue.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusRunning, loaded.Status)
+}
+
+func TestQueue_Cancel_RefusesRunningJobWithoutPID(t *testing.T) {
+	// Setup: the worker marked the job running but its child has not started yet
+	h := testutil.NewTestHarness()
+	queue := NewQueue(h.FS, h, testSessionPath)
+	job, err := queue.Enqueue(KindDocUpdate, struct{}{})
+	require.NoError(t, err)
+	job.Status = StatusRunning
+	require.NoError(t, queue.Save(job))
+
+	// Exercise
+	_, err = queue.Cancel(job.ID, func(pid int) error { return nil })
+
+	// Verify
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is starting")
+	loaded, err := queue.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusRunning, loaded.Status)
+}
+
+func TestQueue_Prune_KeepsNewestFinishedJobs(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	queue := NewQueue(h.FS, h, testSessionPath)
+
+	var ids []string
+	for i := 0; i < 4; i++ {
+		job, err := queue.Enqueue(KindDocUpdate, struct{}{})
+		require.NoError(t, err)
+		job.Status = StatusSucceeded
+		require.NoError(t, queue.Save(job))
+		h.WriteFile(queue.LogPath(job.ID), "log")
+		ids = append(ids, job.ID)
+	}
+	pending, err := queue.Enqueue(KindDocUpdate, struct{}{})
+	require.NoError(t, err)
+
+	// Exercise
+	require.NoError(t, queue.Prune(2))
+
+	// Verify
+	list, err := queue.List()
+	require.NoError(t, err)
+	var remaining []string
+	for _, job := range list {
+		remaining = append(remaining, job.ID)
+	}
+	assert.Equal(t, []string{ids[2], ids[3], pending.ID}, remaining)
+
+	exists, err := afero.Exists(h.FS, queue.LogPath(ids[0]))
+	require.NoError(t, err)
+	assert.False(t, exists, "pruned job log should be removed")
+}
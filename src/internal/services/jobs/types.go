@@ -0,0 +1,75 @@
+// Package jobs provides a per-session background job queue stored under
+// <session>/jobs/. A single worker per session drains the queue while holding
+// a lock, runs each job as a child process, records its status and retries
+// failed attempts with exponential backoff.
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Status is the lifecycle state of a job
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+const (
+	// DirName is the queue directory inside a session folder
+	DirName = "jobs"
+
+	// LockFile is held by the worker draining the queue
+	LockFile = ".lock"
+
+	// KindDocUpdate runs `claudex-hooks doc-update` with the job payload on stdin
+	KindDocUpdate = "doc-update"
+
+	// DefaultMaxAttempts is the number of attempts before a job is marked failed
+	DefaultMaxAttempts = 3
+
+	// ExitNoRetry is the exit code a job exits with when another attempt on the same
+	// input cannot succeed; the job is marked failed without retrying
+	ExitNoRetry = 65
+
+	// DefaultBackoff is the delay before the first retry; it doubles on each retry
+	DefaultBackoff = 5 * time.Second
+
+	// maxStderrBytes bounds the stderr tail kept in the status file (the full output stays in <id>.log)
+	maxStderrBytes = 4096
+
+	// keepFinished is the number of finished jobs kept after the queue is drained
+	keepFinished = 20
+)
+
+// ErrLocked is returned by Worker.Drain when another worker holds the queue lock
+var ErrLocked = errors.New("job queue is locked by another worker")
+
+// Job is the status file of a single queued unit of work (<session>/jobs/<id>.json)
+type Job struct {
+	ID            string          `json:"id"`
+	Kind          string          `json:"kind"`
+	Status        Status          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	MaxAttempts   int             `json:"max_attempts"`
+	PID           int             `json:"pid,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	StartedAt     *time.Time      `json:"started_at,omitempty"`
+	EndedAt       *time.Time      `json:"ended_at,omitempty"`
+	NextAttemptAt *time.Time      `json:"next_attempt_at,omitempty"`
+	ExitCode      *int            `json:"exit_code,omitempty"`
+	Stderr        string          `json:"stderr,omitempty"`
+	Error         string          `json:"error,omitempty"`
+	Payload       json.RawMessage `json:"payload,omitempty"`
+}
+
+// Finished reports whether the job reached a terminal state
+func (j *Job) Finished() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusFailed || j.Status == StatusCancelled
+}
@@ -0,0 +1,259 @@
+package jobs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"claudex/internal/services/clock"
+	"claudex/internal/services/lock"
+)
+
+// Process runs a single job attempt as a child process
+type Process interface {
+	// Run starts the attempt with stderr captured to the given writer, reports the
+	// child PID through onStart, waits for it and returns its exit code.
+	// An error means the process could not be started or waited on.
+	Run(job *Job, stderr io.Writer, onStart func(pid int)) (int, error)
+}
+
+// Worker drains a session's queue, one job at a time
+type Worker struct {
+	queue     *Queue
+	lock      lock.LockService
+	process   Process
+	clock     clock.Clock
+	backoff   time.Duration
+	sleep     func(time.Duration)
+	terminate func(pid int) error
+}
+
+// NewWorker creates a Worker for the given queue
+func NewWorker(queue *Queue, lockSvc lock.LockService, process Process, clk clock.Clock) *Worker {
+	return &Worker{
+		queue:     queue,
+		lock:      lockSvc,
+		process:   process,
+		clock:     clk,
+		backoff:   DefaultBackoff,
+		sleep:     time.Sleep,
+		terminate: Terminate,
+	}
+}
+
+// Drain runs queued jobs until none are left and returns the number of attempts made.
+// It returns ErrLocked when another worker already holds the queue lock; that worker
+// will pick up anything enqueued before it releases the lock.
+func (w *Worker) Drain() (int, error) {
+	attempts := 0
+	for round := 0; ; round++ {
+		l, err := w.lock.Acquire(w.queue.LockPath())
+		if err != nil {
+			if round == 0 {
+				return 0, ErrLocked
+			}
+			// Another worker took over after our release and owns the remaining jobs
+			return attempts, nil
+		}
+
+		n, err := w.drainLocked()
+		attempts += n
+		releaseErr := l.Release()
+		if err != nil {
+			return attempts, err
+		}
+		if releaseErr != nil {
+			return attempts, fmt.Errorf("failed to release job queue lock: %w", releaseErr)
+		}
+
+		// A producer may have enqueued after our last scan but failed to take the lock
+		// before we released it. Re-check so its job is not stranded.
+		pending, err := w.pending()
+		if err != nil {
+			return attempts, err
+		}
+		if len(pending) == 0 {
+			return attempts, w.queue.Prune(keepFinished)
+		}
+	}
+}
+
+// drainLocked runs jobs while the queue lock is held
+func (w *Worker) drainLocked() (int, error) {
+	if err := w.recoverOrphans(); err != nil {
+		return 0, err
+	}
+
+	attempts := 0
+	for {
+		pending, err := w.pending()
+		if err != nil {
+			return attempts, err
+		}
+		if len(pending) == 0 {
+			return attempts, nil
+		}
+
+		job := earliestRunnable(pending)
+		if job.NextAttemptAt != nil {
+			if wait := job.NextAttemptAt.Sub(w.clock.Now()); wait > 0 {
+				w.sleep(wait)
+			}
+		}
+
+		// The job may have been cancelled while we waited for its backoff
+		current, err := w.queue.Get(job.ID)
+		if err != nil {
+			return attempts, err
+		}
+		if current.Status != StatusQueued {
+			continue
+		}
+		job = current
+
+		if err := w.runAttempt(job); err != nil {
+			return attempts, err
+		}
+		attempts++
+	}
+}
+
+// recoverOrphans requeues jobs left running by a worker that died.
+// Holding the lock proves no other worker is running them.
+func (w *Worker) recoverOrphans() error {
+	jobs, err := w.queue.List()
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if job.Status != StatusRunning {
+			continue
+		}
+		job.PID = 0
+		job.Error = "worker exited while the job was running"
+		if job.Attempts >= job.MaxAttempts {
+			job.Status = StatusFailed
+			now := w.clock.Now().UTC()
+			job.EndedAt = &now
+		} else {
+			job.Status = StatusQueued
+		}
+		if err := w.queue.Save(job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pending returns the queued jobs in creation order
+func (w *Worker) pending() ([]*Job, error) {
+	jobs, err := w.queue.List()
+	if err != nil {
+		return nil, err
+	}
+	var pending []*Job
+	for _, job := range jobs {
+		if job.Status == StatusQueued {
+			pending = append(pending, job)
+		}
+	}
+	return pending, nil
+}
+
+// earliestRunnable picks the job that can start first, preferring creation order on ties
+func earliestRunnable(pending []*Job) *Job {
+	best := pending[0]
+	for _, job := range pending[1:] {
+		if readyAt(job).Before(readyAt(best)) {
+			best = job
+		}
+	}
+	return best
+}
+
+// readyAt returns when a queued job may start
+func readyAt(job *Job) time.Time {
+	if job.NextAttemptAt != nil {
+		return *job.NextAttemptAt
+	}
+	return job.CreatedAt
+}
+
+// runAttempt runs one attempt of a job and records the outcome in its status file
+func (w *Worker) runAttempt(job *Job) error {
+	started := w.clock.Now().UTC()
+	job.Status = StatusRunning
+	job.Attempts++
+	job.PID = 0
+	job.StartedAt = &started
+	job.EndedAt = nil
+	job.NextAttemptAt = nil
+	job.ExitCode = nil
+	job.Error = ""
+	if err := w.queue.Save(job); err != nil {
+		return err
+	}
+
+	logFile, err := w.queue.fs.OpenFile(w.queue.LogPath(job.ID), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open job log: %w", err)
+	}
+	defer logFile.Close()
+	fmt.Fprintf(logFile, "=== attempt %d/%d started %s ===\n", job.Attempts, job.MaxAttempts, started.Format(time.RFC3339))
+
+	var stderr bytes.Buffer
+	exitCode, runErr := w.process.Run(job, io.MultiWriter(&stderr, logFile), func(pid int) {
+		// A cancel recorded before the PID was known could not stop the child
+		if current, err := w.queue.Get(job.ID); err == nil && current.Status == StatusCancelled {
+			_ = w.terminate(pid)
+			return
+		}
+		job.PID = pid
+		_ = w.queue.Save(job)
+	})
+
+	ended := w.clock.Now().UTC()
+
+	// The job may have been cancelled while it was running; keep that verdict
+	if current, err := w.queue.Get(job.ID); err == nil && current.Status == StatusCancelled {
+		job = current
+		job.ExitCode = &exitCode
+		job.Stderr = tail(stderr.String(), maxStderrBytes)
+		return w.queue.Save(job)
+	}
+
+	job.EndedAt = &ended
+	job.ExitCode = &exitCode
+	job.Stderr = tail(stderr.String(), maxStderrBytes)
+
+	switch {
+	case runErr == nil && exitCode == 0:
+		job.Status = StatusSucceeded
+	default:
+		if runErr != nil {
+			job.Error = runErr.Error()
+		} else {
+			job.Error = fmt.Sprintf("exit status %d", exitCode)
+		}
+		if exitCode != ExitNoRetry && job.Attempts < job.MaxAttempts {
+			next := ended.Add(w.backoff << (job.Attempts - 1))
+			job.Status = StatusQueued
+			job.NextAttemptAt = &next
+		} else {
+			job.Status = StatusFailed
+		}
+	}
+
+	fmt.Fprintf(logFile, "=== attempt %d/%d %s (exit %d) ===\n", job.Attempts, job.MaxAttempts, job.Status, exitCode)
+	return w.queue.Save(job)
+}
+
+// tail returns the last max bytes of s
+func tail(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[len(s)-max:]
+}
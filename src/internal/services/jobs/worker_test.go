@@ -0,0 +1,287 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"claudex/internal/services/lock"
+	"claudex/internal/testutil"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProcess returns scripted exit codes and records the jobs it ran
+type fakeProcess struct {
+	exitCodes   []int
+	ran         []string
+	beforeStart func(job *Job)
+	onRun       func(job *Job)
+}
+
+func (p *fakeProcess) Run(job *Job, stderr io.Writer, onStart func(pid int)) (int, error) {
+	if p.beforeStart != nil {
+		p.beforeStart(job)
+	}
+	onStart(1000 + len(p.ran))
+	p.ran = append(p.ran, job.ID)
+	if p.onRun != nil {
+		p.onRun(job)
+	}
+
+	code := 0
+	if len(p.exitCodes) > 0 {
+		code, p.exitCodes = p.exitCodes[0], p.exitCodes[1:]
+	}
+	if code != 0 {
+		fmt.Fprintf(stderr, "attempt failed with %d\n", code)
+	}
+	return code, nil
+}
+
+// newTestWorker creates a worker whose sleeps are recorded instead of waited and
+// which never signals real processes
+func newTestWorker(h *testutil.TestHarness, process Process) (*Worker, *Queue, *[]time.Duration) {
+	queue := NewQueue(h.FS, h, testSessionPath)
+	worker := NewWorker(queue, lock.New(h.FS), process, h)
+	var sleeps []time.Duration
+	worker.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+	worker.terminate = func(pid int) error { return nil }
+	return worker, queue, &sleeps
+}
+
+func TestWorker_Drain_RunsJobsInOrder(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	process := &fakeProcess{}
+	worker, queue, _ := newTestWorker(h, process)
+
+	first, err := queue.Enqueue(KindDocUpdate, struct{}{})
+	require.NoError(t, err)
+	second, err := queue.Enqueue(KindDocUpdate, struct{}{})
+	require.NoError(t, err)
+
+	// Exercise
+	attempts, err := worker.Drain()
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, []string{first.ID, second.ID}, process.ran)
+
+	job, err := queue.Get(first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusSucceeded, job.Status)
+	assert.Equal(t, 1000, job.PID)
+	require.NotNil(t, job.ExitCode)
+	assert.Equal(t, 0, *job.ExitCode)
+	assert.NotNil(t, job.StartedAt)
+	assert.NotNil(t, job.EndedAt)
+
+	locked, err := afero.Exists(h.FS, queue.LockPath())
+	require.NoError(t, err)
+	assert.False(t, locked, "lock should be released after draining")
+}
+
+func TestWorker_Drain_RetriesWithBackoff(t *testing.T) {
+	// Setup: fail twice, then succeed
+	h := testutil.NewTestHarness()
+	process := &fakeProcess{exitCodes: []int{1, 2, 0}}
+	worker, queue, sleeps := newTestWorker(h, process)
+	worker.backoff = time.Second
+
+	job, err := queue.Enqueue(KindDocUpdate, struct{}{})
+	require.NoError(t, err)
+
+	// Exercise
+	attempts, err := worker.Drain()
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, *sleeps)
+
+	loaded, err := queue.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusSucceeded, loaded.Status)
+	assert.Equal(t, 3, loaded.Attempts)
+
+	log, err := afero.ReadFile(h.FS, queue.LogPath(job.ID))
+	require.NoError(t, err)
+	assert.Contains(t, string(log), "attempt failed with 1")
+	assert.Contains(t, string(log), "=== attempt 3/3 succeeded (exit 0) ===")
+}
+
+func TestWorker_Drain_MarksFailedAfterMaxAttempts(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	process := &fakeProcess{exitCodes: []int{1, 1, 7}}
+	worker, queue, _ := newTestWorker(h, process)
+
+	job, err := queue.Enqueue(KindDocUpdate, struct{}{})
+	require.NoError(t, err)
+
+	// Exercise
+	_, err = worker.Drain()
+
+	// Verify
+	require.NoError(t, err)
+	loaded, err := queue.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, loaded.Status)
+	assert.Equal(t, DefaultMaxAttempts, loaded.Attempts)
+	require.NotNil(t, loaded.ExitCode)
+	assert.Equal(t, 7, *loaded.ExitCode)
+	assert.Equal(t, "exit status 7", loaded.Error)
+	assert.Contains(t, loaded.Stderr, "attempt failed with 7")
+}
+
+func TestWorker_Drain_DoesNotRetryExitNoRetry(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	process := &fakeProcess{exitCodes: []int{ExitNoRetry}}
+	worker, queue, sleeps := newTestWorker(h, process)
+
+	job, err := queue.Enqueue(KindDocUpdate, struct{}{})
+	require.NoError(t, err)
+
+	// Exercise
+	attempts, err := worker.Drain()
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempts)
+	assert.Empty(t, *sleeps)
+	loaded, err := queue.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, loaded.Status)
+	assert.Equal(t, 1, loaded.Attempts)
+	assert.Nil(t, loaded.NextAttemptAt)
+	assert.Equal(t, fmt.Sprintf("exit status %d", ExitNoRetry), loaded.Error)
+}
+
+func TestWorker_Drain_ReturnsErrLockedWhenBusy(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	process := &fakeProcess{}
+	worker, queue, _ := newTestWorker(h, process)
+	_, err := queue.Enqueue(KindDocUpdate, struct{}{})
+	require.NoError(t, err)
+
+	held, err := lock.New(h.FS).Acquire(queue.LockPath())
+	require.NoError(t, err)
+	defer held.Release()
+
+	// Exercise
+	_, err = worker.Drain()
+
+	// Verify
+	assert.True(t, errors.Is(err, ErrLocked))
+	assert.Empty(t, process.ran)
+}
+
+func TestWorker_Drain_KeepsCancellationDuringRun(t *testing.T) {
+	// Setup: the job is cancelled while its process is running
+	h := testutil.NewTestHarness()
+	process := &fakeProcess{exitCodes: []int{143}}
+	worker, queue, _ := newTestWorker(h, process)
+	job, err := queue.Enqueue(KindDocUpdate, struct{}{})
+	require.NoError(t, err)
+	process.onRun = func(running *Job) {
+		_, err := queue.Cancel(running.ID, func(pid int) error { return nil })
+		require.NoError(t, err)
+	}
+
+	// Exercise
+	attempts, err := worker.Drain()
+
+	// Verify: no retry after cancellation
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempts)
+	loaded, err := queue.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCancelled, loaded.Status)
+}
+
+func TestWorker_Drain_SkipsJobCancelledDuringBackoff(t *testing.T) {
+	// Setup: the job waits for its retry and is cancelled meanwhile
+	h := testutil.NewTestHarness()
+	process := &fakeProcess{}
+	worker, queue, _ := newTestWorker(h, process)
+	job, err := queue.Enqueue(KindDocUpdate, struct{}{})
+	require.NoError(t, err)
+	next := h.Now().Add(time.Minute)
+	job.Attempts = 1
+	job.NextAttemptAt = &next
+	require.NoError(t, queue.Save(job))
+	worker.sleep = func(d time.Duration) {
+		_, err := queue.Cancel(job.ID, func(pid int) error { return nil })
+		require.NoError(t, err)
+	}
+
+	// Exercise
+	attempts, err := worker.Drain()
+
+	// Verify: the cancelled job is not run again
+	require.NoError(t, err)
+	assert.Equal(t, 0, attempts)
+	assert.Empty(t, process.ran)
+	loaded, err := queue.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCancelled, loaded.Status)
+	assert.Equal(t, 1, loaded.Attempts)
+}
+
+func TestWorker_Drain_TerminatesJobCancelledBeforeStart(t *testing.T) {
+	// Setup: a cancel lands after the job is marked running but before its PID is known
+	h := testutil.NewTestHarness()
+	process := &fakeProcess{}
+	worker, queue, _ := newTestWorker(h, process)
+	job, err := queue.Enqueue(KindDocUpdate, struct{}{})
+	require.NoError(t, err)
+	process.beforeStart = func(running *Job) {
+		cancelled, err := queue.Get(running.ID)
+		require.NoError(t, err)
+		cancelled.Status = StatusCancelled
+		require.NoError(t, queue.Save(cancelled))
+	}
+	var terminated []int
+	worker.terminate = func(pid int) error { terminated = append(terminated, pid); return nil }
+
+	// Exercise
+	_, err = worker.Drain()
+
+	// Verify: the child is stopped as soon as it starts and the cancel is kept
+	require.NoError(t, err)
+	assert.Equal(t, []int{1000}, terminated)
+	loaded, err := queue.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCancelled, loaded.Status)
+}
+
+func TestWorker_Drain_RequeuesOrphanedRunningJob(t *testing.T) {
+	// Setup: a previous worker died mid-run
+	h := testutil.NewTestHarness()
+	process := &fakeProcess{}
+	worker, queue, _ := newTestWorker(h, process)
+	job, err := queue.Enqueue(KindDocUpdate, struct{}{})
+	require.NoError(t, err)
+	job.Status = StatusRunning
+	job.Attempts = 1
+	job.PID = 999
+	require.NoError(t, queue.Save(job))
+
+	// Exercise
+	_, err = worker.Drain()
+
+	// Verify
+	require.NoError(t, err)
+	loaded, err := queue.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusSucceeded, loaded.Status)
+	assert.Equal(t, 2, loaded.Attempts)
+}
@@ -0,0 +1,143 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAnthropicURL   = "https://api.anthropic.com"
+	anthropicAPIVersion   = "2023-06-01"
+	defaultMaxTokens      = 4096
+	defaultAnthropicModel = "haiku"
+)
+
+// modelAliases maps the Claude CLI short names to Messages API model IDs
+var modelAliases = map[string]string{
+	"haiku":  "claude-haiku-4-5",
+	"sonnet": "claude-sonnet-4-5",
+	"opus":   "claude-opus-4-1",
+}
+
+// Anthropic calls the Anthropic Messages HTTP API
+type Anthropic struct {
+	baseURL        string
+	apiKey         string
+	defaultModel   string
+	maxTokens      int
+	defaultTimeout time.Duration
+	httpClient     *http.Client
+}
+
+// NewAnthropic creates a Messages API backend. An empty baseURL targets api.anthropic.com.
+func NewAnthropic(baseURL, apiKey, defaultModel string, maxTokens int, defaultTimeout time.Duration) *Anthropic {
+	if baseURL == "" {
+		baseURL = defaultAnthropicURL
+	}
+	if maxTokens <= 0 {
+		maxTokens = defaultMaxTokens
+	}
+	return &Anthropic{
+		baseURL:        strings.TrimSuffix(baseURL, "/"),
+		apiKey:         apiKey,
+		defaultModel:   defaultModel,
+		maxTokens:      maxTokens,
+		defaultTimeout: defaultTimeout,
+		httpClient:     &http.Client{},
+	}
+}
+
+// messagesRequest is the request body of POST /v1/messages
+type messagesRequest struct {
+	Model     string    `json:"model"`
+	MaxTokens int       `json:"max_tokens"`
+	Messages  []message `json:"messages"`
+}
+
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// messagesResponse is the subset of the /v1/messages response we consume
+type messagesResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Complete sends the prompt as a single user message and returns the concatenated text blocks
+func (a *Anthropic) Complete(req Request) (string, error) {
+	model := firstNonEmpty(req.Model, a.defaultModel, defaultAnthropicModel)
+	if id, ok := modelAliases[model]; ok {
+		model = id
+	}
+
+	body, err := json.Marshal(messagesRequest{
+		Model:     model,
+		MaxTokens: a.maxTokens,
+		Messages:  []message{{Role: "user", Content: req.Prompt}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequest("POST", a.baseURL+"/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", a.apiKey)
+	httpReq.Header.Set("anthropic-version", anthropicAPIVersion)
+
+	client := a.httpClient
+	timeout := req.Timeout
+	if timeout == 0 {
+		timeout = a.defaultTimeout
+	}
+	if timeout > 0 {
+		client = &http.Client{Transport: a.httpClient.Transport, Timeout: timeout}
+	}
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("messages request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var parsed messagesResponse
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return "", fmt.Errorf("failed to decode response (status %d): %w", resp.StatusCode, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if parsed.Error != nil {
+			return "", fmt.Errorf("messages API returned status %d: %s: %s", resp.StatusCode, parsed.Error.Type, parsed.Error.Message)
+		}
+		return "", fmt.Errorf("messages API returned status %d", resp.StatusCode)
+	}
+
+	var text strings.Builder
+	for _, block := range parsed.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+
+	return text.String(), nil
+}
@@ -0,0 +1,106 @@
+package llm
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnthropic_Complete_SendsMessagesRequest(t *testing.T) {
+	// Setup: local stub of the Messages API
+	var gotPath, gotKey, gotVersion string
+	var gotBody messagesRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotKey = r.Header.Get("x-api-key")
+		gotVersion = r.Header.Get("anthropic-version")
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &gotBody)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"content":[{"type":"text","text":"# Overview"},{"type":"text","text":"\nDone"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewAnthropic(server.URL, "sk-test", "haiku", 1024, 5*time.Second)
+
+	// Exercise
+	response, err := client.Complete(Request{Prompt: "write the overview"})
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, "# Overview\nDone", response)
+	assert.Equal(t, "/v1/messages", gotPath)
+	assert.Equal(t, "sk-test", gotKey)
+	assert.Equal(t, anthropicAPIVersion, gotVersion)
+	assert.Equal(t, "claude-haiku-4-5", gotBody.Model, "short model alias should be expanded")
+	assert.Equal(t, 1024, gotBody.MaxTokens)
+	require.Len(t, gotBody.Messages, 1)
+	assert.Equal(t, "user", gotBody.Messages[0].Role)
+	assert.Equal(t, "write the overview", gotBody.Messages[0].Content)
+}
+
+func TestAnthropic_Complete_PassesFullModelID(t *testing.T) {
+	// Setup
+	var gotBody messagesRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &gotBody)
+		_, _ = w.Write([]byte(`{"content":[]}`))
+	}))
+	defer server.Close()
+
+	client := NewAnthropic(server.URL, "sk-test", "haiku", 0, 0)
+
+	// Exercise
+	_, err := client.Complete(Request{Prompt: "p", Model: "claude-custom-model"})
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, "claude-custom-model", gotBody.Model)
+	assert.Equal(t, defaultMaxTokens, gotBody.MaxTokens)
+}
+
+func TestAnthropic_Complete_ReturnsAPIError(t *testing.T) {
+	// Setup
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`))
+	}))
+	defer server.Close()
+
+	client := NewAnthropic(server.URL, "bad-key", "haiku", 0, 0)
+
+	// Exercise
+	_, err := client.Complete(Request{Prompt: "p"})
+
+	// Verify
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 401")
+	assert.Contains(t, err.Error(), "authentication_error: invalid x-api-key")
+}
+
+func TestAnthropic_Complete_TimesOut(t *testing.T) {
+	// Setup
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewAnthropic(server.URL, "sk-test", "haiku", 0, 0)
+
+	// Exercise
+	_, err := client.Complete(Request{Prompt: "p", Timeout: 50 * time.Millisecond})
+
+	// Verify
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "messages request failed")
+}
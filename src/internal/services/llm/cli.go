@@ -0,0 +1,99 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"claudex/internal/services/commander"
+	"claudex/internal/services/env"
+)
+
+// recursionGuard marks child Claude processes so their hooks do not recurse into claudex
+const recursionGuard = "CLAUDE_HOOK_INTERNAL=1"
+
+// CLI runs prompts through `claude -p`
+type CLI struct {
+	cmd            commander.Commander
+	env            env.Environment
+	defaultModel   string
+	defaultTimeout time.Duration
+}
+
+// NewCLI creates a Claude CLI backend
+func NewCLI(cmd commander.Commander, env env.Environment, defaultModel string, defaultTimeout time.Duration) *CLI {
+	return &CLI{
+		cmd:            cmd,
+		env:            env,
+		defaultModel:   defaultModel,
+		defaultTimeout: defaultTimeout,
+	}
+}
+
+// Complete pipes the prompt to `claude -p` on stdin and returns stdout.
+// The child runs with CLAUDE_HOOK_INTERNAL=1 and is killed when the timeout expires.
+func (c *CLI) Complete(req Request) (string, error) {
+	args := []string{"-p"}
+	if model := firstNonEmpty(req.Model, c.defaultModel); model != "" {
+		args = append(args, "--model", model)
+	}
+
+	timeout := req.Timeout
+	if timeout == 0 {
+		timeout = c.defaultTimeout
+	}
+
+	var stdout, stderr bytes.Buffer
+	stdin := strings.NewReader(req.Prompt)
+
+	var err error
+	if cc, ok := c.cmd.(commander.ContextCommander); ok {
+		ctx := context.Background()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		err = cc.StartContext(ctx, []string{recursionGuard}, "claude", stdin, &stdout, &stderr, args...)
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("claude command timed out after %s", timeout)
+		}
+	} else {
+		// Fallback for commanders without env support: set the guard on our own environment
+		restore := c.setGuard()
+		err = c.cmd.Start("claude", stdin, &stdout, &stderr, args...)
+		restore()
+	}
+	if err != nil {
+		return "", fmt.Errorf("claude command failed: %w (stderr: %s)", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
+
+// setGuard sets the recursion guard on the process environment and returns a func
+// that puts the previous state back, removing the variable if it was not set before
+func (c *CLI) setGuard() func() {
+	key, value, _ := strings.Cut(recursionGuard, "=")
+	original := c.env.Get(key)
+	restore := func() { c.env.Set(key, original) }
+	if u, ok := c.env.(env.Unsetter); ok {
+		if _, set := u.Lookup(key); !set {
+			restore = func() { u.Unset(key) }
+		}
+	}
+	c.env.Set(key, value)
+	return restore
+}
+
+// firstNonEmpty returns the first non-empty string
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
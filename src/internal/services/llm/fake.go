@@ -0,0 +1,63 @@
+package llm
+
+import (
+	"strings"
+	"sync"
+)
+
+// Fake is a deterministic in-memory backend. It records every request and
+// answers with the first rule whose substring appears in the prompt.
+type Fake struct {
+	mu       sync.Mutex
+	requests []Request
+	rules    []fakeRule
+	fallback string
+}
+
+type fakeRule struct {
+	substring string
+	response  string
+	err       error
+}
+
+// NewFake creates a fake backend that answers unmatched prompts with fallback
+func NewFake(fallback string) *Fake {
+	return &Fake{fallback: fallback}
+}
+
+// When answers prompts containing substring with response
+func (f *Fake) When(substring, response string) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = append(f.rules, fakeRule{substring: substring, response: response})
+	return f
+}
+
+// WhenError fails prompts containing substring with err
+func (f *Fake) WhenError(substring string, err error) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = append(f.rules, fakeRule{substring: substring, err: err})
+	return f
+}
+
+// Complete records the request and returns the matching canned response
+func (f *Fake) Complete(req Request) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, req)
+
+	for _, rule := range f.rules {
+		if strings.Contains(req.Prompt, rule.substring) {
+			return rule.response, rule.err
+		}
+	}
+	return f.fallback, nil
+}
+
+// Requests returns a copy of the recorded requests
+func (f *Fake) Requests() []Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Request(nil), f.requests...)
+}
@@ -0,0 +1,51 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"claudex/internal/services/commander"
+	"claudex/internal/services/config"
+	"claudex/internal/services/env"
+)
+
+// New creates the Client selected by the [llm] config section
+func New(cfg config.LLM, cmd commander.Commander, env env.Environment) (Client, error) {
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+
+	switch cfg.Backend {
+	case "", BackendClaudeCLI:
+		return NewCLI(cmd, env, cfg.Model, timeout), nil
+	case BackendAnthropic:
+		apiKeyEnv := cfg.APIKeyEnv
+		if apiKeyEnv == "" {
+			apiKeyEnv = "ANTHROPIC_API_KEY"
+		}
+		apiKey := env.Get(apiKeyEnv)
+		if apiKey == "" {
+			return nil, fmt.Errorf("anthropic backend requires %s to be set", apiKeyEnv)
+		}
+		return NewAnthropic(cfg.BaseURL, apiKey, cfg.Model, cfg.MaxTokens, timeout), nil
+	case BackendFake:
+		return NewFake(""), nil
+	default:
+		return nil, fmt.Errorf("unknown llm backend: %s", cfg.Backend)
+	}
+}
+
+// StripCodeFence removes a single markdown code fence wrapping the whole response,
+// which models sometimes add around generated file content
+func StripCodeFence(text string) string {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "```") || !strings.HasSuffix(trimmed, "```") || len(trimmed) < 6 {
+		return trimmed
+	}
+
+	firstNewline := strings.Index(trimmed, "\n")
+	if firstNewline == -1 {
+		return trimmed
+	}
+	inner := strings.TrimSuffix(trimmed[firstNewline+1:], "```")
+	return strings.TrimSpace(inner)
+}
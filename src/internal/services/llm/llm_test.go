@@ -0,0 +1,136 @@
+package llm
+
+import (
+	"errors"
+	"testing"
+
+	"claudex/internal/services/config"
+	"claudex/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_SelectsBackend(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.Env.Set("MY_KEY", "sk-test")
+
+	tests := []struct {
+		name    string
+		cfg     config.LLM
+		want    interface{}
+		wantErr string
+	}{
+		{name: "empty backend defaults to cli", cfg: config.LLM{}, want: &CLI{}},
+		{name: "claude-cli", cfg: config.LLM{Backend: BackendClaudeCLI}, want: &CLI{}},
+		{name: "anthropic", cfg: config.LLM{Backend: BackendAnthropic, APIKeyEnv: "MY_KEY"}, want: &Anthropic{}},
+		{name: "anthropic without key", cfg: config.LLM{Backend: BackendAnthropic, APIKeyEnv: "MISSING_KEY"}, wantErr: "MISSING_KEY"},
+		{name: "fake", cfg: config.LLM{Backend: BackendFake}, want: &Fake{}},
+		{name: "unknown", cfg: config.LLM{Backend: "gpt"}, wantErr: "unknown llm backend: gpt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := New(tt.cfg, h.Commander, h.Env)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.IsType(t, tt.want, client)
+		})
+	}
+}
+
+func TestCLI_Complete_PipesPromptWithModel(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	h.Commander.OnPattern("claude", "-p").Return([]byte("generated text"), nil)
+	client := NewCLI(h.Commander, h.Env, "haiku", 0)
+
+	// Exercise
+	response, err := client.Complete(Request{Prompt: "summarize this", Model: "sonnet"})
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, "generated text", response)
+	require.Len(t, h.Commander.Invocations, 1)
+	inv := h.Commander.Invocations[0]
+	assert.Equal(t, "claude", inv.Name)
+	assert.Equal(t, []string{"-p", "--model", "sonnet"}, inv.Args)
+	assert.Equal(t, "summarize this", inv.Stdin)
+	_, set := h.Env.Lookup("CLAUDE_HOOK_INTERNAL")
+	assert.False(t, set, "recursion guard should be removed after the call")
+}
+
+func TestCLI_Complete_RestoresExistingGuardValue(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	h.Env.Set("CLAUDE_HOOK_INTERNAL", "0")
+	h.Commander.OnPattern("claude", "-p").Return([]byte("ok"), nil)
+	client := NewCLI(h.Commander, h.Env, "", 0)
+
+	// Exercise
+	_, err := client.Complete(Request{Prompt: "hi"})
+
+	// Verify
+	require.NoError(t, err)
+	value, set := h.Env.Lookup("CLAUDE_HOOK_INTERNAL")
+	assert.True(t, set)
+	assert.Equal(t, "0", value)
+}
+
+func TestCLI_Complete_ReturnsCommandError(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	h.Commander.OnPattern("claude", "-p").Return(nil, errors.New("exit status 1"))
+	client := NewCLI(h.Commander, h.Env, "haiku", 0)
+
+	// Exercise
+	_, err := client.Complete(Request{Prompt: "hello"})
+
+	// Verify
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "claude command failed")
+}
+
+func TestFake_Complete_MatchesRulesInOrder(t *testing.T) {
+	// Setup
+	fake := NewFake("fallback").
+		When("index.md", "# Index").
+		WhenError("boom", errors.New("backend down"))
+
+	// Exercise
+	first, err1 := fake.Complete(Request{Prompt: "update index.md please"})
+	second, err2 := fake.Complete(Request{Prompt: "boom"})
+	third, err3 := fake.Complete(Request{Prompt: "anything else"})
+
+	// Verify
+	require.NoError(t, err1)
+	assert.Equal(t, "# Index", first)
+	require.Error(t, err2)
+	assert.Empty(t, second)
+	require.NoError(t, err3)
+	assert.Equal(t, "fallback", third)
+	assert.Len(t, fake.Requests(), 3)
+}
+
+func TestStripCodeFence(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "plain text", input: "  # Title\n\nBody\n", want: "# Title\n\nBody"},
+		{name: "fenced markdown", input: "```markdown\n# Title\n\nBody\n```", want: "# Title\n\nBody"},
+		{name: "bare fence", input: "```\ncontent\n```\n", want: "content"},
+		{name: "inner fence kept", input: "# Title\n\n```go\ncode\n```", want: "# Title\n\n```go\ncode\n```"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, StripCodeFence(tt.input))
+		})
+	}
+}
@@ -0,0 +1,26 @@
+// Package llm provides a pluggable backend for one-shot, non-interactive model calls
+// (documentation updates, index generation, session naming).
+// Backends: the Claude CLI (`claude -p`), the Anthropic Messages HTTP API and a
+// deterministic fake for tests and offline runs.
+package llm
+
+import "time"
+
+// Backend names accepted in the [llm] config section
+const (
+	BackendClaudeCLI = "claude-cli"
+	BackendAnthropic = "anthropic"
+	BackendFake      = "fake"
+)
+
+// Request describes a single prompt completion
+type Request struct {
+	Prompt  string        // Full prompt text
+	Model   string        // Model alias ("haiku") or full model ID; empty uses the backend default
+	Timeout time.Duration // Zero uses the backend default
+}
+
+// Client sends prompts to a model and returns its text response
+type Client interface {
+	Complete(req Request) (string, error)
+}
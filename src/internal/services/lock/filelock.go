@@ -1,41 +1,130 @@
 package lock
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"claudex/internal/services/clock"
 	"github.com/spf13/afero"
 )
 
+// DefaultTTL bounds how long a lock is honored when its holder cannot be checked
+// (another host, or a filesystem without flock)
+const DefaultTTL = time.Hour
+
+// unreadableGrace is how long a lock file without holder info is honored;
+// its creator may not have written it yet
+const unreadableGrace = 10 * time.Second
+
+// errFlockHeld is returned by a non-blocking flockFile when another process holds the flock
+var errFlockHeld = errors.New("flock held by another process")
+
 // FileLock is the production implementation of LockService
 type FileLock struct {
-	fs afero.Fs
+	fs       afero.Fs
+	clock    clock.Clock
+	hostname string
+	ttl      time.Duration
+	alive    func(pid int) bool
 }
 
 // New creates a new LockService instance
 func New(fs afero.Fs) LockService {
+	hostname, _ := os.Hostname()
 	return &FileLock{
-		fs: fs,
+		fs:       fs,
+		clock:    clock.New(),
+		hostname: hostname,
+		ttl:      DefaultTTL,
+		alive:    processAlive,
 	}
 }
 
 // Acquire attempts to acquire a lock at the specified path.
 // Uses O_CREATE|O_EXCL flags for atomic acquisition to prevent race conditions.
-// Writes the current process PID to the lock file for debugging purposes.
-// Returns a Lock object if successful, or an error if the lock is already held.
+// Writes the holder's PID, hostname, start time and TTL to the lock file and, on a
+// real filesystem, keeps an exclusive flock on it until Release.
+// A stale lock (holder gone, or TTL expired) is broken and acquisition retried once.
+// Returns a Lock object if successful, or an error wrapping ErrHeld if the lock is held.
 func (fl *FileLock) Acquire(path string) (*Lock, error) {
+	l, err := fl.create(path)
+	if !errors.Is(err, os.ErrExist) {
+		return l, err
+	}
+
+	holder, broken, err := fl.breakStale(path)
+	if err != nil {
+		return nil, err
+	}
+	if !broken {
+		return nil, fmt.Errorf("failed to acquire lock: %w (%s)", ErrHeld, holder)
+	}
+
+	l, err = fl.create(path)
+	if errors.Is(err, os.ErrExist) {
+		// Another process broke the same stale lock first and now holds it
+		return nil, fmt.Errorf("failed to acquire lock: %w", ErrHeld)
+	}
+	return l, err
+}
+
+// IsLocked checks if a lock file exists at the given path and its holder is alive.
+// Returns false for missing and stale locks.
+func (fl *FileLock) IsLocked(path string) (bool, error) {
+	file, err := fl.fs.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check lock status: %w", err)
+	}
+	defer file.Close()
+
+	_, held, err := fl.inspect(file)
+	if err != nil {
+		return false, fmt.Errorf("failed to check lock status: %w", err)
+	}
+	return held, nil
+}
+
+// create atomically creates the lock file, flocks it and writes the holder info.
+// It returns an error satisfying errors.Is(err, os.ErrExist) when the file exists.
+func (fl *FileLock) create(path string) (*Lock, error) {
 	// Use O_CREATE|O_EXCL for atomic lock acquisition
 	// This ensures only one process can create the file
-	file, err := fl.fs.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	file, err := fl.fs.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0644)
 	if err != nil {
-		// Lock already exists or file operation failed
+		if errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to acquire lock: %w", err)
 	}
 
-	// Write current PID to lock file for debugging
-	pid := os.Getpid()
-	if _, err := file.Write([]byte(fmt.Sprintf("%d\n", pid))); err != nil {
-		// Clean up the lock file if we can't write the PID
+	// Block rather than fail: a process inspecting the new file holds the flock only briefly
+	if _, err := flockFile(file, true); err != nil {
+		file.Close()
+		fl.fs.Remove(path)
+		return nil, fmt.Errorf("failed to flock lock file: %w", err)
+	}
+
+	info := Info{
+		PID:        os.Getpid(),
+		Hostname:   fl.hostname,
+		StartedAt:  fl.clock.Now().UTC(),
+		TTLSeconds: int64(fl.ttl / time.Second),
+	}
+	data, err := json.Marshal(info)
+	if err == nil {
+		_, err = file.Write(append(data, '\n'))
+	}
+	if err != nil {
+		// Clean up the lock file if we can't write the holder info
 		file.Close()
 		fl.fs.Remove(path)
 		return nil, fmt.Errorf("failed to write PID to lock file: %w", err)
@@ -44,19 +133,100 @@ func (fl *FileLock) Acquire(path string) (*Lock, error) {
 	return &Lock{
 		Path: path,
 		File: file,
+		Info: info,
 		fs:   fl.fs,
 	}, nil
 }
 
-// IsLocked checks if a lock file exists at the given path.
-// Returns true if the lock file exists, false otherwise.
-func (fl *FileLock) IsLocked(path string) (bool, error) {
-	_, err := fl.fs.Stat(path)
+// breakStale removes the lock file when its holder is gone. It returns the holder and
+// whether the path is free to be created again.
+func (fl *FileLock) breakStale(path string) (Info, bool, error) {
+	file, err := fl.fs.OpenFile(path, os.O_RDWR, 0)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return false, nil
+			// Released between our create attempt and now
+			return Info{}, true, nil
 		}
-		return false, fmt.Errorf("failed to check lock status: %w", err)
+		return Info{}, false, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	defer file.Close()
+
+	holder, held, err := fl.inspect(file)
+	if err != nil {
+		return Info{}, false, fmt.Errorf("failed to inspect lock file: %w", err)
+	}
+	if held {
+		return holder, false, nil
+	}
+
+	// Only remove the file we inspected: another process may have broken it and
+	// created a fresh lock at the same path in the meantime
+	if current, err := fl.fs.Stat(path); err != nil || !sameFile(file, current) {
+		return holder, false, nil
+	}
+	if err := fl.fs.Remove(path); err != nil && !os.IsNotExist(err) {
+		return Info{}, false, fmt.Errorf("failed to remove stale lock: %w", err)
+	}
+	return holder, true, nil
+}
+
+// inspect reads a lock file's holder and reports whether the lock is still held.
+// A held flock proves a live holder; otherwise the holder info decides.
+func (fl *FileLock) inspect(file afero.File) (Info, bool, error) {
+	if _, err := flockFile(file, false); err != nil {
+		if errors.Is(err, errFlockHeld) {
+			return readInfo(file), true, nil
+		}
+		return Info{}, false, err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		return Info{}, false, err
+	}
+	info := readInfo(file)
+	return info, !fl.stale(info, stat.ModTime()), nil
+}
+
+// stale reports whether a lock nobody holds a flock on can be broken
+func (fl *FileLock) stale(info Info, modTime time.Time) bool {
+	now := fl.clock.Now()
+	if info.Expired(now) {
+		return true
+	}
+	if info.Hostname != "" && info.Hostname != fl.hostname {
+		// The holder's process can't be checked from here; only the TTL breaks it
+		return false
+	}
+	if info.PID > 0 {
+		return !fl.alive(info.PID)
+	}
+	return now.Sub(modTime) > unreadableGrace
+}
+
+// readInfo parses the holder info of a lock file. Locks written before the info
+// was added hold only a PID; unreadable content yields an empty Info.
+func readInfo(file afero.File) Info {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return Info{}
+	}
+	var info Info
+	if json.Unmarshal(data, &info) == nil {
+		return info
+	}
+	if pid, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+		return Info{PID: pid}
+	}
+	return Info{}
+}
+
+// sameFile reports whether an open file is still the one at its path. Files without
+// a descriptor can't be compared and are assumed unchanged.
+func sameFile(file afero.File, current os.FileInfo) bool {
+	if _, ok := file.(interface{ Fd() uintptr }); !ok {
+		return true
 	}
-	return true, nil
+	opened, err := file.Stat()
+	return err == nil && os.SameFile(opened, current)
 }
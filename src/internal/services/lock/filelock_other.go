@@ -0,0 +1,27 @@
+//go:build !unix
+
+package lock
+
+import (
+	"os"
+
+	"github.com/spf13/afero"
+)
+
+// flockFile is a no-op without flock: the lock file and its holder info alone
+// decide who holds the lock, as on filesystems without a descriptor
+func flockFile(file afero.File, block bool) (bool, error) {
+	return false, nil
+}
+
+// processAlive reports whether a process with the given PID exists on this host.
+// FindProcess fails for unknown PIDs on Windows; where it always succeeds the
+// holder is treated as alive and only the TTL breaks its lock.
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = process.Release()
+	return true
+}
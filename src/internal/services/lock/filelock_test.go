@@ -1,7 +1,13 @@
 package lock
 
 import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/afero"
 )
@@ -149,7 +155,7 @@ func TestFileLock_IsLocked_ReturnsCorrectState(t *testing.T) {
 	}
 }
 
-func TestFileLock_Acquire_WritesPID(t *testing.T) {
+func TestFileLock_Acquire_WritesHolderInfo(t *testing.T) {
 	fs := afero.NewMemMapFs()
 	lockService := New(fs)
 	lockPath := "/test.lock"
@@ -160,20 +166,31 @@ func TestFileLock_Acquire_WritesPID(t *testing.T) {
 	}
 	defer lock.Release()
 
-	// Read the PID from the lock file
+	// Read the holder info from the lock file
 	content, err := afero.ReadFile(fs, lockPath)
 	if err != nil {
 		t.Fatalf("failed to read lock file: %v", err)
 	}
 
-	// Verify the content is not empty and looks like a number
-	if len(content) == 0 {
-		t.Error("expected lock file to contain PID")
+	var info Info
+	if err := json.Unmarshal(content, &info); err != nil {
+		t.Fatalf("expected JSON holder info, got %q: %v", content, err)
 	}
-
-	// Verify it starts with a digit (PID should be a number)
-	if content[0] < '0' || content[0] > '9' {
-		t.Errorf("expected lock file content to start with a digit, got: %s", content)
+	hostname, _ := os.Hostname()
+	if info.PID != os.Getpid() {
+		t.Errorf("expected pid %d, got %d", os.Getpid(), info.PID)
+	}
+	if info.Hostname != hostname {
+		t.Errorf("expected hostname %q, got %q", hostname, info.Hostname)
+	}
+	if info.StartedAt.IsZero() {
+		t.Error("expected a start time")
+	}
+	if info.TTL() != DefaultTTL {
+		t.Errorf("expected TTL %s, got %s", DefaultTTL, info.TTL())
+	}
+	if lock.Info != info {
+		t.Errorf("expected Lock.Info to match the file, got %+v", lock.Info)
 	}
 }
 
@@ -217,3 +234,169 @@ func TestFileLock_IsLocked_ErrorHandling(t *testing.T) {
 		t.Error("expected non-existent lock to return false")
 	}
 }
+
+// fixedClock returns the same time on every call
+type fixedClock struct{ now time.Time }
+
+func (c fixedClock) Now() time.Time { return c.now }
+
+// newTestLock creates a FileLock on fs whose liveness check reports the given PIDs alive
+func newTestLock(fs afero.Fs, now time.Time, alivePIDs ...int) *FileLock {
+	return &FileLock{
+		fs:       fs,
+		clock:    fixedClock{now: now},
+		hostname: "this-host",
+		ttl:      DefaultTTL,
+		alive: func(pid int) bool {
+			for _, alive := range alivePIDs {
+				if pid == alive {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// writeHolder writes a lock file as another process would have
+func writeHolder(t *testing.T, fs afero.Fs, path string, info Info) {
+	t.Helper()
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatalf("failed to encode holder: %v", err)
+	}
+	if err := afero.WriteFile(fs, path, data, 0644); err != nil {
+		t.Fatalf("failed to write lock file: %v", err)
+	}
+}
+
+func TestFileLock_Acquire_BreaksLockOfDeadProcess(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	now := time.Now()
+	lockPath := "/test.lock"
+	writeHolder(t, fs, lockPath, Info{PID: 4242, Hostname: "this-host", StartedAt: now.Add(-time.Minute), TTLSeconds: 3600})
+
+	lockService := newTestLock(fs, now)
+	if locked, err := lockService.IsLocked(lockPath); err != nil || locked {
+		t.Errorf("expected a lock of a dead process to read as unlocked, got %v (err %v)", locked, err)
+	}
+
+	lock, err := lockService.Acquire(lockPath)
+	if err != nil {
+		t.Fatalf("expected the stale lock to be broken, got: %v", err)
+	}
+	defer lock.Release()
+	if lock.Info.PID != os.Getpid() {
+		t.Errorf("expected the new lock to be ours, got pid %d", lock.Info.PID)
+	}
+}
+
+func TestFileLock_Acquire_BreaksLegacyPIDLock(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	lockPath := "/test.lock"
+	if err := afero.WriteFile(fs, lockPath, []byte("4242\n"), 0644); err != nil {
+		t.Fatalf("failed to write lock file: %v", err)
+	}
+
+	// A PID-only lock from an older claudex belongs to this host
+	lock, err := newTestLock(fs, time.Now(), 4242).Acquire(lockPath)
+	if !errors.Is(err, ErrHeld) {
+		t.Fatalf("expected ErrHeld while pid 4242 is alive, got lock %v, err %v", lock, err)
+	}
+
+	lock, err = newTestLock(fs, time.Now()).Acquire(lockPath)
+	if err != nil {
+		t.Fatalf("expected the legacy lock to be broken once pid 4242 exited, got: %v", err)
+	}
+	lock.Release()
+}
+
+func TestFileLock_Acquire_KeepsLockOfLiveProcess(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	now := time.Now()
+	lockPath := "/test.lock"
+	writeHolder(t, fs, lockPath, Info{PID: 4242, Hostname: "this-host", StartedAt: now.Add(-time.Minute), TTLSeconds: 3600})
+
+	lock, err := newTestLock(fs, now, 4242).Acquire(lockPath)
+	if !errors.Is(err, ErrHeld) {
+		t.Fatalf("expected ErrHeld, got lock %v, err %v", lock, err)
+	}
+	if !strings.Contains(err.Error(), "pid 4242 on this-host") {
+		t.Errorf("expected the error to name the holder, got: %v", err)
+	}
+}
+
+func TestFileLock_Acquire_BreaksExpiredLock(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	now := time.Now()
+	lockPath := "/test.lock"
+	// Another host's process can't be checked; only its TTL frees the lock
+	writeHolder(t, fs, lockPath, Info{PID: 4242, Hostname: "other-host", StartedAt: now.Add(-30 * time.Minute), TTLSeconds: 3600})
+
+	if _, err := newTestLock(fs, now).Acquire(lockPath); !errors.Is(err, ErrHeld) {
+		t.Fatalf("expected another host's lock to be honored within its TTL, got: %v", err)
+	}
+
+	lock, err := newTestLock(fs, now.Add(time.Hour)).Acquire(lockPath)
+	if err != nil {
+		t.Fatalf("expected the expired lock to be broken, got: %v", err)
+	}
+	lock.Release()
+}
+
+func TestFileLock_Acquire_HonorsLockBeingWritten(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	lockPath := "/test.lock"
+	if err := afero.WriteFile(fs, lockPath, nil, 0644); err != nil {
+		t.Fatalf("failed to write lock file: %v", err)
+	}
+
+	// An empty lock file may belong to a process that has not written its info yet
+	if _, err := newTestLock(fs, time.Now()).Acquire(lockPath); !errors.Is(err, ErrHeld) {
+		t.Fatalf("expected a fresh empty lock to be honored, got: %v", err)
+	}
+
+	lock, err := newTestLock(fs, time.Now().Add(time.Minute)).Acquire(lockPath)
+	if err != nil {
+		t.Fatalf("expected an old empty lock to be broken, got: %v", err)
+	}
+	lock.Release()
+}
+
+func TestFileLock_Flock_HeldLockIsNeverBroken(t *testing.T) {
+	fs := afero.NewOsFs()
+	lockPath := filepath.Join(t.TempDir(), "test.lock")
+
+	lock, err := New(fs).Acquire(lockPath)
+	if err != nil {
+		t.Fatalf("lock acquisition failed: %v", err)
+	}
+	defer lock.Release()
+
+	// Even when the PID check wrongly reports the holder gone, its flock keeps the lock
+	contender := newTestLock(fs, time.Now())
+	if locked, err := contender.IsLocked(lockPath); err != nil || !locked {
+		t.Errorf("expected the flocked lock to read as locked, got %v (err %v)", locked, err)
+	}
+	if _, err := contender.Acquire(lockPath); !errors.Is(err, ErrHeld) {
+		t.Fatalf("expected ErrHeld while the flock is held, got: %v", err)
+	}
+}
+
+func TestFileLock_Flock_BreaksLockLeftByCrash(t *testing.T) {
+	fs := afero.NewOsFs()
+	lockPath := filepath.Join(t.TempDir(), "test.lock")
+	now := time.Now()
+	writeHolder(t, fs, lockPath, Info{PID: 4242, Hostname: "this-host", StartedAt: now.Add(-time.Minute), TTLSeconds: 3600})
+
+	lock, err := newTestLock(fs, now).Acquire(lockPath)
+	if err != nil {
+		t.Fatalf("expected the crashed holder's lock to be broken, got: %v", err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Errorf("failed to release lock: %v", err)
+	}
+	if exists, _ := afero.Exists(fs, lockPath); exists {
+		t.Error("expected lock file to be removed after release")
+	}
+}
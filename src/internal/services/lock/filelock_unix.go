@@ -0,0 +1,37 @@
+//go:build unix
+
+package lock
+
+import (
+	"errors"
+	"syscall"
+
+	"github.com/spf13/afero"
+)
+
+// flockFile takes an exclusive flock on a file backed by the OS. It reports false
+// without error for files that have no descriptor (in-memory filesystems), and
+// errFlockHeld when block is false and another process holds the flock.
+func flockFile(file afero.File, block bool) (bool, error) {
+	fd, ok := file.(interface{ Fd() uintptr })
+	if !ok {
+		return false, nil
+	}
+	how := syscall.LOCK_EX
+	if !block {
+		how |= syscall.LOCK_NB
+	}
+	if err := syscall.Flock(int(fd.Fd()), how); err != nil {
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return false, errFlockHeld
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// processAlive reports whether a process with the given PID exists on this host
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
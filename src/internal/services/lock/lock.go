@@ -2,37 +2,83 @@
 // It enables cross-process synchronization using atomic file operations.
 package lock
 
-import "github.com/spf13/afero"
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// ErrHeld is returned by Acquire when a live holder owns the lock
+var ErrHeld = errors.New("lock is held by another process")
+
+// Info identifies the holder of a lock; it is written to the lock file as JSON
+type Info struct {
+	PID       int       `json:"pid"`
+	Hostname  string    `json:"hostname"`
+	StartedAt time.Time `json:"started_at"`
+	// TTLSeconds bounds how long the lock is honored when its holder cannot be checked
+	TTLSeconds int64 `json:"ttl_seconds"`
+}
+
+// TTL returns how long the lock is honored; zero means forever
+func (i Info) TTL() time.Duration {
+	return time.Duration(i.TTLSeconds) * time.Second
+}
+
+// Expired reports whether the lock outlived its TTL at now
+func (i Info) Expired(now time.Time) bool {
+	return i.TTLSeconds > 0 && !i.StartedAt.IsZero() && now.After(i.StartedAt.Add(i.TTL()))
+}
+
+// String describes the holder for error messages
+func (i Info) String() string {
+	holder := fmt.Sprintf("pid %d", i.PID)
+	if i.Hostname != "" {
+		holder += " on " + i.Hostname
+	}
+	if !i.StartedAt.IsZero() {
+		holder += " since " + i.StartedAt.Format(time.RFC3339)
+	}
+	return holder
+}
 
 // Lock represents an acquired lock with its associated file handle
 type Lock struct {
 	// Path is the absolute path to the lock file
 	Path string
 
-	// File is the underlying file handle (for release operations)
+	// File is the underlying file handle (for release operations); on a real
+	// filesystem it holds an exclusive flock for as long as the lock is held
 	File afero.File
 
+	// Info is what was written to the lock file
+	Info Info
+
 	// fs is the filesystem abstraction for cleanup
 	fs afero.Fs
 }
 
-// Release removes the lock file and releases the lock
+// Release removes the lock file and releases the lock. The file is removed before
+// the handle is closed so the flock covers the whole lifetime of the file.
 func (l *Lock) Release() error {
+	removeErr := l.fs.Remove(l.Path)
 	if l.File != nil {
 		if err := l.File.Close(); err != nil {
 			return err
 		}
 	}
-	return l.fs.Remove(l.Path)
+	return removeErr
 }
 
 // LockService abstracts file-based locking for testability
 type LockService interface {
 	// Acquire attempts to acquire a lock at the specified path
-	// Returns Lock if successful, error if already locked or operation fails
-	// Uses O_CREATE|O_EXCL for atomic acquisition
+	// Returns Lock if successful, an error wrapping ErrHeld if a live process holds it
+	// Uses O_CREATE|O_EXCL for atomic acquisition; stale locks are broken first
 	Acquire(path string) (*Lock, error)
 
-	// IsLocked checks if a lock file exists at the given path
+	// IsLocked checks if a lock file exists at the given path and its holder is alive
 	IsLocked(path string) (bool, error)
 }
@@ -0,0 +1,226 @@
+// Package secrets finds credentials in text: built-in patterns for AWS, GitHub and
+// Anthropic keys and private key blocks, custom patterns from config, and high-entropy
+// tokens. Each finding carries a fingerprint so known false positives can be allowlisted
+// without writing the value itself into the config.
+package secrets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"claudex/internal/services/config"
+	"claudex/internal/services/glob"
+)
+
+// HighEntropyRule names findings of the entropy check
+const HighEntropyRule = "high-entropy-string"
+
+// Entropy candidates: tokens of this many characters or more, up to maxTokenLength
+const (
+	minTokenLength = 20
+	maxTokenLength = 128
+)
+
+// Rule is a named credential pattern. When the pattern has capture groups, the last
+// non-empty group is the secret; otherwise the whole match is.
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// builtinRules are always scanned for
+var builtinRules = []Rule{
+	{"aws-access-key-id", regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`)},
+	{"aws-secret-access-key", regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})\b`)},
+	{"github-token", regexp.MustCompile(`\b(?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36,}\b|\bgithub_pat_[A-Za-z0-9_]{22,}\b`)},
+	{"anthropic-api-key", regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_\-]{32,}`)},
+	{"private-key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY(?: BLOCK)?-----[\s\S]*?(?:-----END [A-Z ]*PRIVATE KEY(?: BLOCK)?-----|\z)`)},
+}
+
+// tokenPattern finds entropy candidates; "/" and "." are left out so paths don't qualify
+var tokenPattern = regexp.MustCompile(`[A-Za-z0-9+=_\-]{20,}`)
+
+// Finding is a credential found in a text
+type Finding struct {
+	Rule        string
+	Secret      string
+	Fingerprint string
+	Line        int // 1-based line of the secret's first character
+	Start, End  int // byte offsets of the secret in the text
+}
+
+// Masked returns a preview of the secret that is safe to log
+func (f Finding) Masked() string {
+	if len(f.Secret) <= 8 {
+		return "…"
+	}
+	return f.Secret[:4] + "…"
+}
+
+// String describes the finding without revealing the secret
+func (f Finding) String() string {
+	return fmt.Sprintf("%s %s (line %d, fingerprint %s)", f.Rule, f.Masked(), f.Line, f.Fingerprint)
+}
+
+// Scanner finds credentials in text
+type Scanner struct {
+	rules             []Rule
+	entropyThreshold  float64
+	allowPaths        *glob.Matcher
+	allowFingerprints map[string]bool
+}
+
+// New creates a Scanner from the [secrets] settings. Custom patterns are scanned
+// after the built-in ones; an invalid pattern is an error.
+func New(cfg config.Secrets) (*Scanner, error) {
+	s := &Scanner{
+		rules:             append([]Rule{}, builtinRules...),
+		entropyThreshold:  cfg.EntropyThreshold,
+		allowFingerprints: make(map[string]bool),
+	}
+	for i, p := range cfg.Patterns {
+		re, err := regexp.Compile(p.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret pattern %q: %w", p.Name, err)
+		}
+		name := p.Name
+		if name == "" {
+			name = fmt.Sprintf("custom-%d", i+1)
+		}
+		s.rules = append(s.rules, Rule{Name: name, Pattern: re})
+	}
+
+	allowPaths, err := glob.New(cfg.AllowPaths)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret allow path: %w", err)
+	}
+	s.allowPaths = allowPaths
+
+	for _, fp := range cfg.AllowFingerprints {
+		s.allowFingerprints[strings.ToLower(strings.TrimSpace(fp))] = true
+	}
+	return s, nil
+}
+
+// AllowedPath reports whether a file (slash-separated, relative to the project root)
+// is allowlisted and never scanned
+func (s *Scanner) AllowedPath(relPath string) bool {
+	return s.allowPaths.Match(relPath)
+}
+
+// Scan returns the findings in text ordered by position. Allowlisted fingerprints are
+// dropped, and an entropy candidate overlapping a rule's finding is not reported twice.
+func (s *Scanner) Scan(text string) []Finding {
+	var findings []Finding
+	for _, rule := range s.rules {
+		for _, m := range rule.Pattern.FindAllStringSubmatchIndex(text, -1) {
+			start, end := secretBounds(m)
+			findings = append(findings, newFinding(rule.Name, text, start, end))
+		}
+	}
+
+	if s.entropyThreshold > 0 {
+		for _, m := range tokenPattern.FindAllStringIndex(text, -1) {
+			if overlaps(findings, m[0], m[1]) || !s.highEntropy(text[m[0]:m[1]]) {
+				continue
+			}
+			findings = append(findings, newFinding(HighEntropyRule, text, m[0], m[1]))
+		}
+	}
+
+	kept := findings[:0]
+	for _, f := range findings {
+		if !s.allowFingerprints[f.Fingerprint] {
+			kept = append(kept, f)
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].Start < kept[j].Start })
+	return kept
+}
+
+// highEntropy reports whether a token looks like a random credential: mixed letters and
+// digits, not hex (hashes and commit ids), and above the entropy threshold
+func (s *Scanner) highEntropy(token string) bool {
+	if len(token) < minTokenLength || len(token) > maxTokenLength {
+		return false
+	}
+	if !strings.ContainsAny(token, "0123456789") || isHex(token) {
+		return false
+	}
+	if strings.IndexFunc(token, func(r rune) bool { return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') }) < 0 {
+		return false
+	}
+	return Entropy(token) >= s.entropyThreshold
+}
+
+// Entropy returns the Shannon entropy of s in bits per character
+func Entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	n := float64(len([]rune(s)))
+	var entropy float64
+	for _, c := range counts {
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// Fingerprint identifies a secret without revealing it: the first 16 hex digits of its SHA-256
+func Fingerprint(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// secretBounds returns the offsets of the last non-empty capture group, else of the match
+func secretBounds(m []int) (int, int) {
+	for i := len(m)/2 - 1; i > 0; i-- {
+		if m[2*i] >= 0 && m[2*i+1] > m[2*i] {
+			return m[2*i], m[2*i+1]
+		}
+	}
+	return m[0], m[1]
+}
+
+// newFinding builds the finding for text[start:end]
+func newFinding(rule, text string, start, end int) Finding {
+	secret := text[start:end]
+	return Finding{
+		Rule:        rule,
+		Secret:      secret,
+		Fingerprint: Fingerprint(secret),
+		Line:        strings.Count(text[:start], "\n") + 1,
+		Start:       start,
+		End:         end,
+	}
+}
+
+// overlaps reports whether [start, end) intersects a finding
+func overlaps(findings []Finding, start, end int) bool {
+	for _, f := range findings {
+		if start < f.End && f.Start < end {
+			return true
+		}
+	}
+	return false
+}
+
+// isHex reports whether s only holds hexadecimal digits
+func isHex(s string) bool {
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+	return true
+}
@@ -14,6 +14,12 @@ const (
 	// DocUpdateCounterFile is the filename for the auto-doc update counter
 	DocUpdateCounterFile = ".doc-update-counter"
 
+	// SubagentCounterFile is the filename for the subagent-stop doc update counter
+	SubagentCounterFile = ".doc-update-counter-subagent"
+
+	// SessionEndCounterFile is the filename for the session-end doc update counter
+	SessionEndCounterFile = ".doc-update-counter-session-end"
+
 	// LastProcessedLineFile is the filename for the last processed line tracker
 	LastProcessedLineFile = ".last-processed-line-overview"
 )
@@ -53,6 +59,34 @@ func ResetCounter(fs afero.Fs, sessionPath string) error {
 	return WriteCounter(fs, sessionPath, 0)
 }
 
+// ReadCounterFile reads the named counter file in the session folder.
+// Returns 0 if the file does not exist.
+func ReadCounterFile(fs afero.Fs, sessionPath, filename string) (int, error) {
+	return readIntFile(fs, filepath.Join(sessionPath, filename))
+}
+
+// IncrementCounterFile reads, increments, and writes the named counter file in the session folder.
+// Returns the new counter value.
+func IncrementCounterFile(fs afero.Fs, sessionPath, filename string) (int, error) {
+	path := filepath.Join(sessionPath, filename)
+	current, err := readIntFile(fs, path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read counter: %w", err)
+	}
+
+	newValue := current + 1
+	if err := writeIntFile(fs, path, newValue); err != nil {
+		return 0, fmt.Errorf("failed to write incremented counter: %w", err)
+	}
+
+	return newValue, nil
+}
+
+// ResetCounterFile sets the named counter file in the session folder to 0.
+func ResetCounterFile(fs afero.Fs, sessionPath, filename string) error {
+	return writeIntFile(fs, filepath.Join(sessionPath, filename), 0)
+}
+
 // ReadLastProcessedLine reads the last processed line number for transcript tracking.
 // Returns 0 if the file does not exist (meaning no lines have been processed yet).
 func ReadLastProcessedLine(fs afero.Fs, sessionPath string) (int, error) {
@@ -99,3 +99,43 @@ func GetSessionID(sessionPath string) string {
 
 	return ""
 }
+
+// ResolveSessionName resolves a user-supplied session reference to a session folder name.
+// The reference may be the full folder name, a unique prefix of it, or the Claude session ID.
+// Returns an error if nothing matches or if a prefix matches more than one session.
+func ResolveSessionName(fs afero.Fs, sessionsDir string, ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", fmt.Errorf("session name is required")
+	}
+
+	entries, err := afero.ReadDir(fs, sessionsDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read sessions directory: %w", err)
+	}
+
+	var matches []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+
+		// Exact name or Claude session ID wins immediately
+		if name == ref || ExtractClaudeSessionID(name) == ref {
+			return name, nil
+		}
+		if strings.HasPrefix(name, ref) {
+			matches = append(matches, name)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("session not found: %s", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("session reference %q is ambiguous (matches: %s)", ref, strings.Join(matches, ", "))
+	}
+}
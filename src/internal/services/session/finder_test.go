@@ -174,3 +174,64 @@ func Test_GetSessionID_ValidUUID(t *testing.T) {
 		})
 	}
 }
+
+// Test_ResolveSessionName tests resolving session references by name, prefix, and Claude ID
+func Test_ResolveSessionName(t *testing.T) {
+	h := testutil.NewTestHarness()
+
+	sessionsDir := "/project/.claudex/sessions"
+	h.CreateDir(sessionsDir + "/auth-refactor-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee")
+	h.CreateDir(sessionsDir + "/api-fix-11112222-3333-4444-5555-666666666666")
+	h.CreateDir(sessionsDir + "/api-docs-77778888-9999-aaaa-bbbb-cccccccccccc")
+
+	tests := []struct {
+		name        string
+		ref         string
+		expected    string
+		errContains string
+	}{
+		{
+			name:     "Exact name",
+			ref:      "api-fix-11112222-3333-4444-5555-666666666666",
+			expected: "api-fix-11112222-3333-4444-5555-666666666666",
+		},
+		{
+			name:     "Unique prefix",
+			ref:      "auth",
+			expected: "auth-refactor-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee",
+		},
+		{
+			name:     "Claude session ID",
+			ref:      "77778888-9999-aaaa-bbbb-cccccccccccc",
+			expected: "api-docs-77778888-9999-aaaa-bbbb-cccccccccccc",
+		},
+		{
+			name:        "Ambiguous prefix",
+			ref:         "api-",
+			errContains: "ambiguous",
+		},
+		{
+			name:        "No match",
+			ref:         "billing",
+			errContains: "session not found",
+		},
+		{
+			name:        "Empty reference",
+			ref:         "  ",
+			errContains: "required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ResolveSessionName(h.FS, sessionsDir, tt.ref)
+			if tt.errContains != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
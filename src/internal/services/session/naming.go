@@ -3,14 +3,12 @@
 package session
 
 import (
-	"bytes"
 	"fmt"
-	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 
-	"claudex/internal/services/commander"
+	"claudex/internal/services/llm"
 
 	"github.com/spf13/afero"
 )
@@ -92,22 +90,17 @@ func RenameWithClaudeID(fs afero.Fs, sessionPath, claudeSessionID string) error
 	return nil
 }
 
-// GenerateNameWithCmd generates a session name using the provided Commander
-func GenerateNameWithCmd(cmd commander.Commander, description string) (string, error) {
+// GenerateName generates a session name slug from the description using the LLM backend
+func GenerateName(client llm.Client, description string) (string, error) {
 	prompt := fmt.Sprintf("Generate a short, descriptive slug (2-4 words max, lowercase, hyphen-separated) for a work session based on this Description: '%s'. Reply with ONLY the slug, nothing else. Examples: 'auth-refactor', 'api-performance-fix', 'user-dashboard-ui'", description)
 
-	// Create a pipe to capture output
-	var stdout bytes.Buffer
-	stdin := strings.NewReader(prompt)
-
-	// Use Start method which supports stdin/stdout/stderr
-	err := cmd.Start("claude", stdin, &stdout, os.Stderr, "-p")
+	response, err := client.Complete(llm.Request{Prompt: prompt})
 	if err != nil {
 		return "", err
 	}
 
 	re := regexp.MustCompile(`[a-z0-9-]+`)
-	matches := re.FindAllString(stdout.String(), -1)
+	matches := re.FindAllString(response, -1)
 
 	if len(matches) == 0 {
 		return "", fmt.Errorf("no valid slug")
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"claudex/internal/services/llm"
 	"claudex/internal/testutil"
 
 	"github.com/stretchr/testify/require"
@@ -106,8 +107,8 @@ func Test_StripClaudeSessionID(t *testing.T) {
 	}
 }
 
-// Test_GenerateNameWithCmd tests slug generation using Claude CLI
-func Test_GenerateNameWithCmd(t *testing.T) {
+// Test_GenerateName tests slug generation using the Claude CLI backend
+func Test_GenerateName(t *testing.T) {
 	// Setup
 	h := testutil.NewTestHarness()
 
@@ -115,7 +116,7 @@ func Test_GenerateNameWithCmd(t *testing.T) {
 	h.Commander.OnPattern("claude", "-p").Return([]byte("feature-login"), nil)
 
 	description := "Implement login feature"
-	slug, err := GenerateNameWithCmd(h.Commander, description)
+	slug, err := GenerateName(llm.NewCLI(h.Commander, h.Env, "haiku", 0), description)
 
 	// Verify slug generation
 	require.NoError(t, err)
@@ -25,6 +25,8 @@ import (
 //   - Adds missing hooks from template to each hook type
 //   - Preserves all existing hooks (user customizations)
 //   - Deduplicates by command path within each hook type
+//   - Widens an existing entry holding only a template hook that the template runs for
+//     every tool (no matcher), e.g. pre-tool-use.sh once limited to "Task"
 func MergeSettings(template, existing []byte) ([]byte, error) {
 	// Parse template settings first (always required for validation)
 	var templateSettings Settings
@@ -66,6 +68,8 @@ func MergeSettings(template, existing []byte) ([]byte, error) {
 			}
 		}
 
+		widenAllToolHooks(existingEntries, templateEntries)
+
 		// Add missing hooks from template
 		for _, templateEntry := range templateEntries {
 			for _, templateHook := range templateEntry.Hooks {
@@ -112,3 +116,23 @@ func MergeSettings(template, existing []byte) ([]byte, error) {
 
 	return mergedJSON, nil
 }
+
+// widenAllToolHooks clears the matcher of existing entries that hold a single hook the
+// template registers without a matcher, so the hook keeps running for every tool
+func widenAllToolHooks(existingEntries, templateEntries []HookEntry) {
+	allTools := make(map[string]bool)
+	for _, templateEntry := range templateEntries {
+		if templateEntry.Matcher != "" {
+			continue
+		}
+		for _, templateHook := range templateEntry.Hooks {
+			allTools[filepath.Base(templateHook.Command)] = true
+		}
+	}
+
+	for i, entry := range existingEntries {
+		if entry.Matcher != "" && len(entry.Hooks) == 1 && allTools[filepath.Base(entry.Hooks[0].Command)] {
+			existingEntries[i].Matcher = ""
+		}
+	}
+}
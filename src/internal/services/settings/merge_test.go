@@ -580,3 +580,34 @@ func TestMergeSettings_ErrorCases(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeSettings_WidensAllToolHooks(t *testing.T) {
+	template := []byte(`{"permissions":{"allow":[],"deny":[],"ask":[]},"hooks":{"PreToolUse":[
+  {"hooks":[{"type":"command","command":".claude/hooks/pre-tool-use.sh"}]}
+]}}`)
+	// Settings generated before pre-tool-use.sh ran for every tool, plus a user hook
+	existing := []byte(`{"permissions":{"allow":[],"deny":[],"ask":[]},"hooks":{"PreToolUse":[
+  {"matcher":"Task","hooks":[{"type":"command","command":"/project/.claude/hooks/pre-tool-use.sh"}]},
+  {"matcher":"Bash","hooks":[{"type":"command","command":"/project/.claude/hooks/my-bash-guard.sh"}]}
+]}}`)
+
+	result, err := MergeSettings(template, existing)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var resultSettings Settings
+	if err := json.Unmarshal(result, &resultSettings); err != nil {
+		t.Fatalf("failed to unmarshal result: %v", err)
+	}
+	preToolUse := resultSettings.Hooks["PreToolUse"]
+	if len(preToolUse) != 2 {
+		t.Fatalf("expected 2 PreToolUse entries, got %d", len(preToolUse))
+	}
+	if preToolUse[0].Matcher != "" {
+		t.Errorf("expected pre-tool-use.sh to run for every tool, got matcher %q", preToolUse[0].Matcher)
+	}
+	if preToolUse[1].Matcher != "Bash" {
+		t.Errorf("expected user hook matcher to be preserved, got %q", preToolUse[1].Matcher)
+	}
+}
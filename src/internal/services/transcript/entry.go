@@ -0,0 +1,229 @@
+package transcript
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// editTools maps the tools that modify files to the input field holding the path
+var editTools = map[string]string{
+	"Edit":         "file_path",
+	"MultiEdit":    "file_path",
+	"Write":        "file_path",
+	"NotebookEdit": "notebook_path",
+}
+
+// commandPrefixes mark user turns that echo slash commands and their local output
+var commandPrefixes = []string{
+	"<command-name>",
+	"<command-message>",
+	"<local-command-stdout>",
+	"<local-command-stderr>",
+}
+
+// ParseEntry decodes one transcript line
+func ParseEntry(data []byte) (*Entry, error) {
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to parse transcript entry: %w", err)
+	}
+	return &entry, nil
+}
+
+// UnmarshalJSON decodes an entry. A message that is not an object (older or
+// foreign formats) is ignored rather than failing the whole line.
+func (e *Entry) UnmarshalJSON(data []byte) error {
+	type plain Entry
+	var raw struct {
+		plain
+		Message json.RawMessage `json:"message"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*e = Entry(raw.plain)
+	e.Message = nil
+	if len(raw.Message) > 0 && raw.Message[0] == '{' {
+		var msg Message
+		if err := json.Unmarshal(raw.Message, &msg); err == nil {
+			e.Message = &msg
+		}
+	}
+	return nil
+}
+
+// UnmarshalJSON accepts either a block array or a plain string
+func (b *Blocks) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		*b = Blocks{{Type: BlockText, Text: text}}
+		return nil
+	}
+
+	var blocks []Block
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		return err
+	}
+	*b = blocks
+	return nil
+}
+
+// Blocks returns the message content blocks of the given types (all blocks when none are given)
+func (e *Entry) Blocks(types ...BlockType) []Block {
+	if e.Message == nil {
+		return nil
+	}
+	if len(types) == 0 {
+		return e.Message.Content
+	}
+
+	var out []Block
+	for _, block := range e.Message.Content {
+		for _, t := range types {
+			if block.Type == t {
+				out = append(out, block)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// HasBlock reports whether the message contains a block of the given type
+func (e *Entry) HasBlock(t BlockType) bool {
+	return len(e.Blocks(t)) > 0
+}
+
+// Text returns the non-blank text blocks of the message
+func (e *Entry) Text() []string {
+	if e.Message == nil {
+		return []string{}
+	}
+	return e.Message.Content.Text()
+}
+
+// Thinking returns the non-blank thinking blocks of the message
+func (e *Entry) Thinking() []string {
+	texts := []string{}
+	for _, block := range e.Blocks(BlockThinking) {
+		if strings.TrimSpace(block.Thinking) != "" {
+			texts = append(texts, block.Thinking)
+		}
+	}
+	return texts
+}
+
+// ToolUses returns the tool_use blocks of an assistant message
+func (e *Entry) ToolUses() []Block {
+	return e.Blocks(BlockToolUse)
+}
+
+// ToolResults returns the tool_result blocks of a user message
+func (e *Entry) ToolResults() []Block {
+	return e.Blocks(BlockToolResult)
+}
+
+// Usage returns the token usage of the message, or nil when none was reported
+func (e *Entry) Usage() *Usage {
+	if e.Message == nil {
+		return nil
+	}
+	return e.Message.Usage
+}
+
+// IsUserPrompt reports whether the entry is a prompt typed by the user.
+// Tool results, injected meta messages, compaction summaries and slash-command echoes are not prompts.
+func (e *Entry) IsUserPrompt() bool {
+	if e.Type != TypeUser || e.Message == nil || e.IsMeta || e.IsCompactSummary {
+		return false
+	}
+	if e.HasBlock(BlockToolResult) {
+		return false
+	}
+
+	texts := e.Text()
+	if len(texts) == 0 {
+		return false
+	}
+	first := strings.TrimSpace(texts[0])
+	for _, prefix := range commandPrefixes {
+		if strings.HasPrefix(first, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsCompaction reports whether the entry summarises earlier conversation
+func (e *Entry) IsCompaction() bool {
+	return e.Type == TypeSummary || e.IsCompactSummary
+}
+
+// AgentResult returns the sub-agent result carried by toolUseResult, if any
+func (e *Entry) AgentResult() (*AgentResult, bool) {
+	if len(e.ToolUseResult) == 0 || e.ToolUseResult[0] != '{' {
+		return nil, false
+	}
+
+	var result AgentResult
+	if err := json.Unmarshal(e.ToolUseResult, &result); err != nil || result.AgentID == "" {
+		return nil, false
+	}
+	return &result, true
+}
+
+// EditedFiles returns the files targeted by file-editing tool calls, in order and without duplicates
+func (e *Entry) EditedFiles() []string {
+	var files []string
+	seen := map[string]bool{}
+	for _, block := range e.ToolUses() {
+		path := block.EditedFile()
+		if path != "" && !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// EditedFile returns the path modified by an Edit, MultiEdit, Write or NotebookEdit tool_use block
+func (b Block) EditedFile() string {
+	field, ok := editTools[b.Name]
+	if b.Type != BlockToolUse || !ok || len(b.Input) == 0 {
+		return ""
+	}
+
+	var input map[string]any
+	if err := json.Unmarshal(b.Input, &input); err != nil {
+		return ""
+	}
+	path, _ := input[field].(string)
+	return path
+}
+
+// Text returns the non-blank text blocks
+func (bs Blocks) Text() []string {
+	texts := []string{}
+	for _, block := range bs {
+		if block.Type == BlockText && strings.TrimSpace(block.Text) != "" {
+			texts = append(texts, block.Text)
+		}
+	}
+	return texts
+}
+
+// Add accumulates another usage report
+func (u *Usage) Add(other *Usage) {
+	if other == nil {
+		return
+	}
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.CacheCreationInputTokens += other.CacheCreationInputTokens
+	u.CacheReadInputTokens += other.CacheReadInputTokens
+}
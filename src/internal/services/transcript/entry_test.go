@@ -0,0 +1,190 @@
+package transcript
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEntry_AssistantBlocks(t *testing.T) {
+	// Setup
+	line := `{"type":"assistant","uuid":"u2","parentUuid":"u1","sessionId":"s1","timestamp":"2024-01-15T10:30:00.123Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet","content":[{"type":"thinking","thinking":"Plan the edit"},{"type":"text","text":"Editing now."},{"type":"tool_use","id":"t1","name":"MultiEdit","input":{"file_path":"/repo/a.go","edits":[]}},{"type":"tool_use","id":"t2","name":"NotebookEdit","input":{"notebook_path":"/repo/b.ipynb"}},{"type":"tool_use","id":"t3","name":"Bash","input":{"command":"ls"}}],"usage":{"input_tokens":10,"output_tokens":5,"cache_read_input_tokens":100}}}`
+
+	// Exercise
+	entry, err := ParseEntry([]byte(line))
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, TypeAssistant, entry.Type)
+	assert.Equal(t, "u1", entry.ParentUUID)
+	assert.Equal(t, 2024, entry.Time().Year())
+	assert.Equal(t, "claude-sonnet", entry.Message.Model)
+	assert.Equal(t, []string{"Plan the edit"}, entry.Thinking())
+	assert.Equal(t, []string{"Editing now."}, entry.Text())
+	assert.Len(t, entry.ToolUses(), 3)
+	assert.Equal(t, []string{"/repo/a.go", "/repo/b.ipynb"}, entry.EditedFiles())
+	assert.Equal(t, &Usage{InputTokens: 10, OutputTokens: 5, CacheReadInputTokens: 100}, entry.Usage())
+	assert.False(t, entry.IsUserPrompt())
+}
+
+func TestParseEntry_UserContentForms(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		prompt bool
+		text   []string
+	}{
+		{
+			name:   "string content",
+			line:   `{"type":"user","message":{"role":"user","content":"Add tests"}}`,
+			prompt: true,
+			text:   []string{"Add tests"},
+		},
+		{
+			name:   "text blocks",
+			line:   `{"type":"user","message":{"role":"user","content":[{"type":"text","text":"Add tests"}]}}`,
+			prompt: true,
+			text:   []string{"Add tests"},
+		},
+		{
+			name:   "tool result",
+			line:   `{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":[{"type":"text","text":"out"}],"is_error":true}]}}`,
+			prompt: false,
+			text:   []string{},
+		},
+		{
+			name:   "meta",
+			line:   `{"type":"user","isMeta":true,"message":{"role":"user","content":"Caveat"}}`,
+			prompt: false,
+			text:   []string{"Caveat"},
+		},
+		{
+			name:   "slash command echo",
+			line:   `{"type":"user","message":{"role":"user","content":"<command-name>/clear</command-name>"}}`,
+			prompt: false,
+			text:   []string{"<command-name>/clear</command-name>"},
+		},
+		{
+			name:   "compaction summary",
+			line:   `{"type":"user","isCompactSummary":true,"message":{"role":"user","content":"Summary of earlier work"}}`,
+			prompt: false,
+			text:   []string{"Summary of earlier work"},
+		},
+		{
+			name:   "non-object message",
+			line:   `{"type":"user","message":"legacy"}`,
+			prompt: false,
+			text:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := ParseEntry([]byte(tt.line))
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.prompt, entry.IsUserPrompt())
+			assert.Equal(t, tt.text, entry.Text())
+		})
+	}
+}
+
+func TestParseEntry_ToolResultError(t *testing.T) {
+	entry, err := ParseEntry([]byte(`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"denied","is_error":true}]}}`))
+
+	require.NoError(t, err)
+	results := entry.ToolResults()
+	require.Len(t, results, 1)
+	assert.Equal(t, "t1", results[0].ToolUseID)
+	assert.True(t, results[0].IsError)
+	assert.Equal(t, []string{"denied"}, results[0].Content.Text())
+}
+
+func TestEntry_AgentResult(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		agent string
+		ok    bool
+	}{
+		{name: "completed", line: `{"type":"user","toolUseResult":{"status":"completed","agentId":"a1","content":[{"type":"text","text":"Done"}]}}`, agent: "a1", ok: true},
+		{name: "no agent id", line: `{"type":"user","toolUseResult":{"status":"completed","content":[]}}`},
+		{name: "string result", line: `{"type":"user","toolUseResult":"Error: file not found"}`},
+		{name: "edit result", line: `{"type":"user","toolUseResult":{"filePath":"/repo/a.go","oldString":"a"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := ParseEntry([]byte(tt.line))
+			require.NoError(t, err)
+
+			result, ok := entry.AgentResult()
+
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.agent, result.AgentID)
+				assert.Equal(t, []string{"Done"}, result.Content.Text())
+			}
+		})
+	}
+}
+
+func TestEntry_Compaction(t *testing.T) {
+	entry, err := ParseEntry([]byte(`{"type":"summary","summary":"Refactored the loader","leafUuid":"u9"}`))
+
+	require.NoError(t, err)
+	assert.True(t, entry.IsCompaction())
+	assert.Equal(t, "Refactored the loader", entry.Summary)
+	assert.Equal(t, "u9", entry.LeafUUID)
+}
+
+func TestEntry_MarshalRoundTrip(t *testing.T) {
+	line := `{"type":"user","uuid":"u1","message":{"role":"user","content":[{"type":"text","text":"Hi"}]}}`
+	entry, err := ParseEntry([]byte(line))
+	require.NoError(t, err)
+
+	data, err := json.Marshal(entry)
+	require.NoError(t, err)
+	again, err := ParseEntry(data)
+
+	require.NoError(t, err)
+	assert.Equal(t, entry, again)
+}
+
+func TestBlocks_Text(t *testing.T) {
+	tests := []struct {
+		name     string
+		blocks   Blocks
+		expected []string
+	}{
+		{name: "text only", blocks: Blocks{{Type: BlockText, Text: "Hello"}}, expected: []string{"Hello"}},
+		{
+			name:     "mixed types",
+			blocks:   Blocks{{Type: BlockText, Text: "First"}, {Type: BlockToolUse, Text: "ignored"}, {Type: BlockText, Text: "Second"}},
+			expected: []string{"First", "Second"},
+		},
+		{
+			name:     "empty text filtered",
+			blocks:   Blocks{{Type: BlockText, Text: ""}, {Type: BlockText, Text: "   "}, {Type: BlockText, Text: "Valid"}},
+			expected: []string{"Valid"},
+		},
+		{name: "no text content", blocks: Blocks{{Type: BlockToolUse}}, expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.blocks.Text())
+		})
+	}
+}
+
+func TestUsage_Add(t *testing.T) {
+	total := Usage{InputTokens: 1}
+
+	total.Add(&Usage{InputTokens: 2, OutputTokens: 3, CacheCreationInputTokens: 4, CacheReadInputTokens: 5})
+	total.Add(nil)
+
+	assert.Equal(t, Usage{InputTokens: 3, OutputTokens: 3, CacheCreationInputTokens: 4, CacheReadInputTokens: 5}, total)
+}
@@ -0,0 +1,66 @@
+package transcript
+
+// Filter selects entries while reading
+type Filter func(e *Entry) bool
+
+// OfType keeps entries of any of the given types
+func OfType(types ...EntryType) Filter {
+	return func(e *Entry) bool {
+		for _, t := range types {
+			if e.Type == t {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// WithBlock keeps entries whose message contains a block of the given type
+func WithBlock(t BlockType) Filter {
+	return func(e *Entry) bool {
+		return e.HasBlock(t)
+	}
+}
+
+// UserPrompts keeps prompts typed by the user
+func UserPrompts() Filter {
+	return func(e *Entry) bool {
+		return e.IsUserPrompt()
+	}
+}
+
+// MainThread drops sub-agent (sidechain) entries
+func MainThread() Filter {
+	return func(e *Entry) bool {
+		return !e.IsSidechain
+	}
+}
+
+// Any keeps entries matching at least one filter
+func Any(filters ...Filter) Filter {
+	return func(e *Entry) bool {
+		for _, f := range filters {
+			if f(e) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not inverts a filter
+func Not(f Filter) Filter {
+	return func(e *Entry) bool {
+		return !f(e)
+	}
+}
+
+// matches applies filters conjunctively
+func matches(e *Entry, filters []Filter) bool {
+	for _, f := range filters {
+		if !f(e) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package transcript
+
+import (
+	"bufio"
+	"io"
+)
+
+// DefaultMaxLineBytes is the largest transcript line that is parsed.
+// Longer lines (huge tool results) are skipped and reported instead of
+// aborting the read.
+const DefaultMaxLineBytes = 8 * 1024 * 1024
+
+// lineReadBufferSize is the bufio buffer used to stream lines in chunks
+const lineReadBufferSize = 64 * 1024
+
+// rawLine is one line read from a transcript
+type rawLine struct {
+	Offset     int64  // Byte offset of the first byte of the line
+	Size       int64  // Bytes consumed, including the newline
+	Data       []byte // Line content; nil when Skipped
+	Skipped    bool   // Line exceeded the size limit and was discarded
+	Terminated bool   // Line ended with a newline (false for a trailing fragment at EOF)
+}
+
+// lineReader streams newline-separated lines in bounded memory, tracking exact byte offsets.
+// Lines of any length are consumed; only lines up to maxLine bytes are buffered.
+type lineReader struct {
+	r       *bufio.Reader
+	offset  int64
+	maxLine int
+}
+
+// newLineReader reads lines from r, which must already be positioned at offset
+func newLineReader(r io.Reader, offset int64, maxLine int) *lineReader {
+	if maxLine <= 0 {
+		maxLine = DefaultMaxLineBytes
+	}
+	return &lineReader{
+		r:       bufio.NewReaderSize(r, lineReadBufferSize),
+		offset:  offset,
+		maxLine: maxLine,
+	}
+}
+
+// next returns the next line, or io.EOF when no bytes are left
+func (lr *lineReader) next() (rawLine, error) {
+	line := rawLine{Offset: lr.offset}
+	var buf []byte
+
+	for {
+		chunk, err := lr.r.ReadSlice('\n')
+		line.Size += int64(len(chunk))
+		lr.offset += int64(len(chunk))
+
+		if !line.Skipped {
+			if len(buf)+len(chunk) > lr.maxLine {
+				line.Skipped = true
+				buf = nil
+			} else {
+				buf = append(buf, chunk...)
+			}
+		}
+
+		switch err {
+		case nil:
+			line.Terminated = true
+			line.Data = trimNewline(buf)
+			return line, nil
+		case bufio.ErrBufferFull:
+			// Line continues past the buffer - keep streaming
+			continue
+		case io.EOF:
+			if line.Size == 0 {
+				return line, io.EOF
+			}
+			line.Data = buf
+			return line, nil
+		default:
+			return line, err
+		}
+	}
+}
+
+// trimNewline removes a trailing "\n" or "\r\n"
+func trimNewline(b []byte) []byte {
+	if n := len(b); n > 0 && b[n-1] == '\n' {
+		b = b[:n-1]
+		if n := len(b); n > 0 && b[n-1] == '\r' {
+			b = b[:n-1]
+		}
+	}
+	return b
+}
@@ -0,0 +1,56 @@
+package transcript
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineReader_TracksOffsetsAcrossBufferBoundaries(t *testing.T) {
+	// Setup: the middle line spans several bufio buffers
+	long := strings.Repeat("a", 3*lineReadBufferSize)
+	input := "one\r\n" + long + "\nthree"
+	reader := newLineReader(strings.NewReader(input), 0, 0)
+
+	// Exercise
+	var lines []rawLine
+	for {
+		line, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		lines = append(lines, line)
+	}
+
+	// Verify
+	require.Len(t, lines, 3)
+	assert.Equal(t, "one", string(lines[0].Data))
+	assert.Equal(t, int64(5), lines[0].Size)
+	assert.Equal(t, long, string(lines[1].Data))
+	assert.Equal(t, int64(5), lines[1].Offset)
+	assert.True(t, lines[1].Terminated)
+	assert.Equal(t, "three", string(lines[2].Data))
+	assert.Equal(t, int64(len(input)-5), lines[2].Offset)
+	assert.False(t, lines[2].Terminated)
+}
+
+func TestLineReader_SkipsLinesOverLimit(t *testing.T) {
+	reader := newLineReader(strings.NewReader("0123456789\nok\n"), 100, 4)
+
+	first, err := reader.next()
+	require.NoError(t, err)
+	second, err := reader.next()
+	require.NoError(t, err)
+
+	assert.True(t, first.Skipped)
+	assert.Nil(t, first.Data)
+	assert.Equal(t, int64(100), first.Offset)
+	assert.Equal(t, int64(11), first.Size)
+	assert.False(t, second.Skipped)
+	assert.Equal(t, "ok", string(second.Data))
+	assert.Equal(t, int64(111), second.Offset)
+}
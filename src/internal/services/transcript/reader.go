@@ -0,0 +1,147 @@
+package transcript
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/afero"
+)
+
+// Reader streams entries from a transcript, starting at a Position.
+// Malformed lines are skipped, lines over the size limit are skipped and recorded,
+// and an unterminated trailing line is left for the next read unless it is already complete JSON.
+type Reader struct {
+	lines     *lineReader
+	closer    io.Closer
+	filters   []Filter
+	pos       Position
+	skipUntil int
+	skipped   []SkippedLine
+}
+
+// Open opens a transcript and positions the reader at start.
+// The file is seeked to start.Offset, so resuming does not rescan earlier lines.
+// A line-only position (Offset 0, Line > 0) skips that many lines from the top.
+func Open(fs afero.Fs, path string, start Position, filters ...Filter) (*Reader, error) {
+	file, err := fs.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transcript: %w", err)
+	}
+
+	if start.Offset > 0 {
+		if _, err := file.Seek(start.Offset, io.SeekStart); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to seek transcript: %w", err)
+		}
+	}
+
+	r := NewReader(file, start, filters...)
+	r.closer = file
+	return r, nil
+}
+
+// NewReader reads entries from r, which must already be positioned at start.Offset.
+// Only entries matching every filter are returned.
+func NewReader(r io.Reader, start Position, filters ...Filter) *Reader {
+	reader := &Reader{
+		lines:   newLineReader(r, start.Offset, DefaultMaxLineBytes),
+		filters: filters,
+		pos:     start,
+	}
+
+	// Line-only positions come from the legacy marker or an explicit start line
+	if start.Offset == 0 && start.Line > 0 {
+		reader.pos.Line = 0
+		reader.skipUntil = start.Line
+	}
+	return reader
+}
+
+// SetMaxLineBytes changes the size above which lines are skipped
+func (r *Reader) SetMaxLineBytes(n int) {
+	if n > 0 {
+		r.lines.maxLine = n
+	}
+}
+
+// Next returns the next matching entry, or io.EOF once the transcript is exhausted
+func (r *Reader) Next() (*Entry, error) {
+	for {
+		line, err := r.lines.next()
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading transcript: %w", err)
+		}
+
+		// A trailing line without a newline may still be being written.
+		// Only consume it when it already holds a complete JSON value.
+		if !line.Terminated && (line.Skipped || !json.Valid(bytes.TrimSpace(line.Data))) {
+			return nil, io.EOF
+		}
+
+		r.pos.Line++
+		r.pos.Offset = line.Offset + line.Size
+
+		if r.pos.Line <= r.skipUntil {
+			continue
+		}
+
+		if line.Skipped {
+			r.skipped = append(r.skipped, SkippedLine{Line: r.pos.Line, Offset: line.Offset, Size: line.Size})
+			continue
+		}
+
+		if len(bytes.TrimSpace(line.Data)) == 0 {
+			continue
+		}
+
+		entry, err := ParseEntry(line.Data)
+		if err != nil {
+			// Skip malformed JSON lines gracefully
+			continue
+		}
+		entry.Line = r.pos.Line
+		entry.Offset = line.Offset
+
+		if matches(entry, r.filters) {
+			return entry, nil
+		}
+	}
+}
+
+// Position returns how far the transcript has been consumed
+func (r *Reader) Position() Position {
+	return r.pos
+}
+
+// Skipped returns the oversized lines passed over so far
+func (r *Reader) Skipped() []SkippedLine {
+	return r.skipped
+}
+
+// Close closes the underlying file when the reader was created by Open
+func (r *Reader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
+
+// ReadAll returns every remaining matching entry
+func (r *Reader) ReadAll() ([]*Entry, error) {
+	var entries []*Entry
+	for {
+		entry, err := r.Next()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return entries, err
+		}
+		entries = append(entries, entry)
+	}
+}
@@ -0,0 +1,139 @@
+package transcript
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTranscript = `{"type":"user","uuid":"u1","message":{"role":"user","content":"Fix the bug"}}
+{"type":"assistant","uuid":"a1","message":{"role":"assistant","content":[{"type":"text","text":"Looking."},{"type":"tool_use","id":"t1","name":"Read","input":{"file_path":"/repo/a.go"}}]}}
+not json
+{"type":"user","uuid":"u2","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"package a"}]}}
+{"type":"assistant","uuid":"a2","isSidechain":true,"message":{"role":"assistant","content":[{"type":"text","text":"Agent work"}]}}
+{"type":"summary","summary":"Earlier work"}
+`
+
+func readUUIDs(t *testing.T, r *Reader) []string {
+	t.Helper()
+	entries, err := r.ReadAll()
+	require.NoError(t, err)
+	var ids []string
+	for _, e := range entries {
+		ids = append(ids, e.UUID+e.Summary)
+	}
+	return ids
+}
+
+func TestReader_StreamsEntriesWithPositions(t *testing.T) {
+	// Setup
+	r := NewReader(strings.NewReader(testTranscript), Position{})
+
+	// Exercise
+	first, err := r.Next()
+	require.NoError(t, err)
+	second, err := r.Next()
+	require.NoError(t, err)
+
+	// Verify
+	assert.Equal(t, 1, first.Line)
+	assert.Equal(t, int64(0), first.Offset)
+	assert.Equal(t, 2, second.Line)
+	assert.Equal(t, int64(strings.Index(testTranscript, `{"type":"assistant"`)), second.Offset)
+	assert.Equal(t, Position{Line: 2, Offset: int64(strings.Index(testTranscript, "not json"))}, r.Position())
+
+	ids := readUUIDs(t, r)
+	assert.Equal(t, []string{"u2", "a2", "Earlier work"}, ids, "malformed lines are skipped")
+	assert.Equal(t, Position{Line: 6, Offset: int64(len(testTranscript))}, r.Position())
+
+	_, err = r.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestReader_Filters(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters []Filter
+		want    []string
+	}{
+		{name: "by type", filters: []Filter{OfType(TypeAssistant)}, want: []string{"a1", "a2"}},
+		{name: "user prompts", filters: []Filter{UserPrompts()}, want: []string{"u1"}},
+		{name: "with block", filters: []Filter{WithBlock(BlockToolResult)}, want: []string{"u2"}},
+		{name: "main thread assistant", filters: []Filter{OfType(TypeAssistant), MainThread()}, want: []string{"a1"}},
+		{name: "any", filters: []Filter{Any(UserPrompts(), OfType(TypeSummary))}, want: []string{"u1", "Earlier work"}},
+		{name: "not", filters: []Filter{Not(OfType(TypeUser, TypeAssistant))}, want: []string{"Earlier work"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReader(strings.NewReader(testTranscript), Position{}, tt.filters...)
+
+			assert.Equal(t, tt.want, readUUIDs(t, r))
+			assert.Equal(t, 6, r.Position().Line, "filtered entries still advance the position")
+		})
+	}
+}
+
+func TestOpen_SeeksToOffset(t *testing.T) {
+	// Setup
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/t.jsonl", []byte(testTranscript), 0644))
+	offset := int64(strings.Index(testTranscript, `{"type":"user","uuid":"u2"`))
+
+	// Exercise
+	r, err := Open(fs, "/t.jsonl", Position{Line: 3, Offset: offset})
+	require.NoError(t, err)
+	defer r.Close()
+
+	// Verify
+	entry, err := r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "u2", entry.UUID)
+	assert.Equal(t, 4, entry.Line)
+	assert.Equal(t, offset, entry.Offset)
+}
+
+func TestOpen_LineOnlyPositionSkipsLines(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/t.jsonl", []byte(testTranscript), 0644))
+
+	r, err := Open(fs, "/t.jsonl", Position{Line: 4})
+	require.NoError(t, err)
+	defer r.Close()
+
+	assert.Equal(t, []string{"a2", "Earlier work"}, readUUIDs(t, r))
+	assert.Equal(t, Position{Line: 6, Offset: int64(len(testTranscript))}, r.Position())
+}
+
+func TestOpen_MissingFile(t *testing.T) {
+	_, err := Open(afero.NewMemMapFs(), "/missing.jsonl", Position{})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to open transcript")
+}
+
+func TestReader_SkipsOversizedLines(t *testing.T) {
+	input := `{"type":"user","toolUseResult":"` + strings.Repeat("x", 64) + `"}` + "\n" + `{"type":"summary","summary":"after"}` + "\n"
+	r := NewReader(strings.NewReader(input), Position{})
+	r.SetMaxLineBytes(48)
+
+	ids := readUUIDs(t, r)
+
+	assert.Equal(t, []string{"after"}, ids)
+	require.Len(t, r.Skipped(), 1)
+	assert.Equal(t, 1, r.Skipped()[0].Line)
+}
+
+func TestReader_LeavesPartialTrailingLine(t *testing.T) {
+	input := `{"type":"summary","summary":"done"}` + "\n" + `{"type":"assis`
+	r := NewReader(strings.NewReader(input), Position{})
+
+	ids := readUUIDs(t, r)
+
+	assert.Equal(t, []string{"done"}, ids)
+	assert.Equal(t, Position{Line: 1, Offset: int64(strings.Index(input, "\n") + 1)}, r.Position())
+}
@@ -0,0 +1,129 @@
+// Package transcript provides a typed model of Claude Code session transcripts
+// (JSONL files under ~/.claude/projects) with a streaming, offset-aware reader
+// and entry filters.
+package transcript
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EntryType is the top-level "type" of a transcript line
+type EntryType string
+
+const (
+	// TypeUser is a user turn: a prompt typed by the user or tool results sent back to the model
+	TypeUser EntryType = "user"
+	// TypeAssistant is a model turn: text, thinking and tool_use blocks
+	TypeAssistant EntryType = "assistant"
+	// TypeSystem is an informational line written by Claude Code (hook output, compaction markers)
+	TypeSystem EntryType = "system"
+	// TypeSummary is a compaction summary of earlier conversation
+	TypeSummary EntryType = "summary"
+)
+
+// BlockType is the "type" of a message content block
+type BlockType string
+
+const (
+	BlockText       BlockType = "text"
+	BlockThinking   BlockType = "thinking"
+	BlockToolUse    BlockType = "tool_use"
+	BlockToolResult BlockType = "tool_result"
+	BlockImage      BlockType = "image"
+)
+
+// Entry is one parsed transcript line
+type Entry struct {
+	Type             EntryType       `json:"type"`
+	UUID             string          `json:"uuid,omitempty"`
+	ParentUUID       string          `json:"parentUuid,omitempty"`
+	SessionID        string          `json:"sessionId,omitempty"`
+	Timestamp        string          `json:"timestamp,omitempty"` // ISO 8601, see Time
+	Cwd              string          `json:"cwd,omitempty"`
+	GitBranch        string          `json:"gitBranch,omitempty"`
+	Version          string          `json:"version,omitempty"`
+	IsSidechain      bool            `json:"isSidechain,omitempty"`      // Sub-agent conversation
+	IsMeta           bool            `json:"isMeta,omitempty"`           // Injected by Claude Code, not typed by the user
+	IsCompactSummary bool            `json:"isCompactSummary,omitempty"` // User turn carrying a compaction summary
+	Message          *Message        `json:"message,omitempty"`
+	ToolUseResult    json.RawMessage `json:"toolUseResult,omitempty"` // Tool-specific result payload, see AgentResult
+	Summary          string          `json:"summary,omitempty"`       // Set on TypeSummary entries
+	LeafUUID         string          `json:"leafUuid,omitempty"`      // Last message covered by a summary
+	Subtype          string          `json:"subtype,omitempty"`       // Set on some TypeSystem entries
+	Content          string          `json:"content,omitempty"`       // Text of TypeSystem entries
+
+	Line   int   `json:"-"` // 1-indexed line number in the transcript
+	Offset int64 `json:"-"` // Byte offset of the line
+}
+
+// Message is the model message carried by user and assistant entries
+type Message struct {
+	ID         string `json:"id,omitempty"`
+	Role       string `json:"role"`
+	Model      string `json:"model,omitempty"`
+	Content    Blocks `json:"content"`
+	StopReason string `json:"stop_reason,omitempty"`
+	Usage      *Usage `json:"usage,omitempty"`
+}
+
+// Blocks is message content. Plain-string content is decoded as a single text block.
+type Blocks []Block
+
+// Block is one content block of a message or tool result
+type Block struct {
+	Type BlockType `json:"type"`
+
+	// BlockText
+	Text string `json:"text,omitempty"`
+
+	// BlockThinking
+	Thinking string `json:"thinking,omitempty"`
+
+	// BlockToolUse
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+
+	// BlockToolResult
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   Blocks `json:"content,omitempty"`
+	IsError   bool   `json:"is_error,omitempty"`
+}
+
+// Usage is the token accounting reported on assistant messages
+type Usage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+// AgentResult is the toolUseResult payload of a completed sub-agent (Task tool) run
+type AgentResult struct {
+	Status  string `json:"status"`
+	AgentID string `json:"agentId"`
+	Content Blocks `json:"content"`
+}
+
+// Position is how far a transcript has been read
+type Position struct {
+	Line   int   // Number of lines read
+	Offset int64 // Byte offset just past the last line read
+}
+
+// SkippedLine records a transcript line that was too large to parse
+type SkippedLine struct {
+	Line   int   // 1-indexed line number
+	Offset int64 // Byte offset of the line
+	Size   int64 // Line size in bytes
+}
+
+// Time parses the entry timestamp; the zero time is returned when it is missing or malformed
+func (e *Entry) Time() time.Time {
+	t, err := time.Parse(time.RFC3339Nano, e.Timestamp)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -159,3 +159,14 @@ func (e *MockEnv) Get(key string) string {
 func (e *MockEnv) Set(key, value string) {
 	e.vars[key] = value
 }
+
+// Lookup retrieves an environment variable and reports whether it is set
+func (e *MockEnv) Lookup(key string) (string, bool) {
+	value, ok := e.vars[key]
+	return value, ok
+}
+
+// Unset removes an environment variable
+func (e *MockEnv) Unset(key string) {
+	delete(e.vars, key)
+}
@@ -1,18 +1,18 @@
 // Package createindex provides the usecase for generating index.md documentation
-// for any directory using Claude. It scans the directory structure, finds nearby
-// index.md files for style reference, and invokes Claude to generate contextually
-// relevant documentation.
+// for any directory using the LLM backend. It scans the directory structure, finds nearby
+// index.md files for style reference, and asks the model to generate contextually
+// relevant documentation. Offline, it writes a deterministic skeleton instead.
 package createindex
 
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
-	"claudex/internal/services/commander"
+	"claudex/internal/doc/skeleton"
 	"claudex/internal/services/env"
+	"claudex/internal/services/llm"
 
 	"github.com/spf13/afero"
 )
@@ -20,15 +20,15 @@ import (
 // CreateIndexUseCase orchestrates the index.md generation workflow
 type CreateIndexUseCase struct {
 	fs  afero.Fs
-	cmd commander.Commander
+	llm llm.Client
 	env env.Environment
 }
 
 // New creates a new CreateIndexUseCase instance with the given dependencies
-func New(fs afero.Fs, cmd commander.Commander, env env.Environment) *CreateIndexUseCase {
+func New(fs afero.Fs, client llm.Client, env env.Environment) *CreateIndexUseCase {
 	return &CreateIndexUseCase{
 		fs:  fs,
-		cmd: cmd,
+		llm: client,
 		env: env,
 	}
 }
@@ -49,21 +49,9 @@ var codeExtensions = []string{
 // Returns an error if the generation fails.
 func (uc *CreateIndexUseCase) Execute(dirPath string) error {
 	// 1. Validate directory exists
-	absPath, err := filepath.Abs(dirPath)
+	absPath, err := uc.resolveDir(dirPath)
 	if err != nil {
-		return fmt.Errorf("failed to resolve path: %w", err)
-	}
-
-	info, err := uc.fs.Stat(absPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return fmt.Errorf("directory does not exist: %s", absPath)
-		}
-		return fmt.Errorf("failed to access path: %w", err)
-	}
-
-	if !info.IsDir() {
-		return fmt.Errorf("path is a file, not a directory: %s", absPath)
+		return err
 	}
 
 	// 2. Scan directory for code files
@@ -79,12 +67,13 @@ func (uc *CreateIndexUseCase) Execute(dirPath string) error {
 		styleReference = "(No nearby index.md found for style reference)"
 	}
 
-	// 4. Build prompt
-	prompt := uc.buildPrompt(absPath, fileListing, styleReference)
+	// 4. Build prompt, enriching an existing index.md (e.g. an --offline skeleton)
+	existing, _ := afero.ReadFile(uc.fs, filepath.Join(absPath, "index.md"))
+	prompt := uc.buildPrompt(absPath, fileListing, styleReference, strings.TrimSpace(string(existing)))
 
-	// 5. Invoke Claude with haiku model - Claude will create the file directly
+	// 5. Invoke the LLM backend and write the returned content to index.md
 	outputPath := filepath.Join(absPath, "index.md")
-	if err := uc.invokeClaudeSync(prompt, outputPath); err != nil {
+	if err := uc.generateIndex(prompt, outputPath); err != nil {
 		return fmt.Errorf("failed to generate index.md: %w", err)
 	}
 
@@ -94,6 +83,63 @@ func (uc *CreateIndexUseCase) Execute(dirPath string) error {
 	return nil
 }
 
+// ExecuteOffline writes a deterministic index.md skeleton for the specified directory
+// without calling the model: Go package docs and exported identifiers, header comments
+// and exported symbols of other source files, and links to subdirectory indexes.
+// Running Execute afterwards enriches the skeleton. An existing index.md is left
+// unchanged unless overwrite is set.
+func (uc *CreateIndexUseCase) ExecuteOffline(dirPath string, overwrite bool) error {
+	absPath, err := uc.resolveDir(dirPath)
+	if err != nil {
+		return err
+	}
+
+	outputPath := filepath.Join(absPath, "index.md")
+	if !overwrite {
+		exists, err := afero.Exists(uc.fs, outputPath)
+		if err != nil {
+			return fmt.Errorf("failed to check %s: %w", outputPath, err)
+		}
+		if exists {
+			fmt.Printf("index.md already exists at: %s (left unchanged; pass --force to replace it)\n", outputPath)
+			return nil
+		}
+	}
+
+	s, err := skeleton.Build(uc.fs, absPath)
+	if err != nil {
+		return fmt.Errorf("failed to scan directory: %w", err)
+	}
+
+	if err := afero.WriteFile(uc.fs, outputPath, []byte(s.Markdown()), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputPath, err)
+	}
+
+	fmt.Printf("✓ Created index.md skeleton at: %s\n", outputPath)
+	return nil
+}
+
+// resolveDir returns the absolute path of dirPath, checking it is an existing directory
+func (uc *CreateIndexUseCase) resolveDir(dirPath string) (string, error) {
+	absPath, err := filepath.Abs(dirPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path: %w", err)
+	}
+
+	info, err := uc.fs.Stat(absPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("directory does not exist: %s", absPath)
+		}
+		return "", fmt.Errorf("failed to access path: %w", err)
+	}
+
+	if !info.IsDir() {
+		return "", fmt.Errorf("path is a file, not a directory: %s", absPath)
+	}
+	return absPath, nil
+}
+
 // scanDirectory scans the directory and returns a formatted listing of code files
 func (uc *CreateIndexUseCase) scanDirectory(dirPath string) (string, error) {
 	var files []string
@@ -187,7 +233,15 @@ func (uc *CreateIndexUseCase) findStyleReference(dirPath string) (string, error)
 }
 
 // buildPrompt constructs the Claude prompt for index.md generation
-func (uc *CreateIndexUseCase) buildPrompt(dirPath, fileListing, styleReference string) string {
+func (uc *CreateIndexUseCase) buildPrompt(dirPath, fileListing, styleReference, existing string) string {
+	existingSection := ""
+	if existing != "" {
+		existingSection = fmt.Sprintf(`
+EXISTING index.md (keep what is accurate and enrich it):
+%s
+`, existing)
+	}
+
 	return fmt.Sprintf(`Create an index.md documentation file for the directory: %s
 
 FILES IN DIRECTORY:
@@ -195,7 +249,7 @@ FILES IN DIRECTORY:
 
 STYLE REFERENCE (from nearby index.md):
 %s
-
+%s
 Requirements:
 - Create a lightweight documentation pointer that helps developers understand this directory
 - Include a title based on the package/directory name
@@ -203,29 +257,28 @@ Requirements:
 - List key files with brief descriptions (if relevant)
 - If subdirectories have index.md files, use markdown links: [subdir/](./subdir/index.md)
 - Match the style and tone of the reference index.md
-- Use the Write tool to create the file directly at the target path
-- Do NOT output the content to stdout - write it to the file`, dirPath, fileListing, styleReference)
+- Reply with ONLY the markdown content of index.md - no preamble, no code fences`, dirPath, fileListing, styleReference, existingSection)
 }
 
-// invokeClaudeSync invokes Claude synchronously to create the index.md file directly
-func (uc *CreateIndexUseCase) invokeClaudeSync(prompt string, outputPath string) error {
+// generateIndex asks the LLM backend for the index.md content and writes it to outputPath
+func (uc *CreateIndexUseCase) generateIndex(prompt string, outputPath string) error {
 	// Recursion guard: check if we're already inside a hook invocation
 	if uc.env.Get("CLAUDE_HOOK_INTERNAL") == "1" {
 		return fmt.Errorf("recursion guard: already inside Claude hook invocation")
 	}
 
-	// Add output path to prompt so Claude knows where to write
-	fullPrompt := fmt.Sprintf("%s\n\nWrite the index.md file to: %s", prompt, outputPath)
-
-	// Create command with haiku model for cost efficiency
-	cmd := exec.Command("claude", "-p", fullPrompt, "--model", "haiku")
+	response, err := uc.llm.Complete(llm.Request{Prompt: prompt})
+	if err != nil {
+		return fmt.Errorf("llm invocation failed: %w", err)
+	}
 
-	// Set recursion guard in environment for this command
-	cmd.Env = append(os.Environ(), "CLAUDE_HOOK_INTERNAL=1")
+	content := llm.StripCodeFence(response)
+	if content == "" {
+		return fmt.Errorf("llm returned empty content")
+	}
 
-	// Run the command - Claude will write the file directly using the Write tool
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("claude invocation failed: %w", err)
+	if err := afero.WriteFile(uc.fs, outputPath, []byte(content+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputPath, err)
 	}
 
 	return nil
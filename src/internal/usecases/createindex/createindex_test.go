@@ -0,0 +1,47 @@
+package createindex
+
+import (
+	"testing"
+
+	"claudex/internal/testutil"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteOffline_LeavesExistingIndexUnchanged(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	h.WriteFile("/project/pkg/auth/auth.go", "// Package auth checks credentials\npackage auth\n\nfunc Login() {}\n")
+	curated := "# auth\n\nHand-written notes.\n"
+	h.WriteFile("/project/pkg/auth/index.md", curated)
+	uc := New(h.FS, nil, h.Env)
+
+	// Exercise
+	err := uc.ExecuteOffline("/project/pkg/auth", false)
+
+	// Verify
+	require.NoError(t, err)
+	content, err := afero.ReadFile(h.FS, "/project/pkg/auth/index.md")
+	require.NoError(t, err)
+	assert.Equal(t, curated, string(content))
+}
+
+func TestExecuteOffline_OverwriteReplacesIndex(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	h.WriteFile("/project/pkg/auth/auth.go", "// Package auth checks credentials\npackage auth\n\nfunc Login() {}\n")
+	h.WriteFile("/project/pkg/auth/index.md", "# auth\n\nHand-written notes.\n")
+	uc := New(h.FS, nil, h.Env)
+
+	// Exercise
+	err := uc.ExecuteOffline("/project/pkg/auth", true)
+
+	// Verify
+	require.NoError(t, err)
+	content, err := afero.ReadFile(h.FS, "/project/pkg/auth/index.md")
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "Login")
+	assert.NotContains(t, string(content), "Hand-written")
+}
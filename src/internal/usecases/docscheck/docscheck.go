@@ -0,0 +1,259 @@
+// Package docscheck provides the usecase behind `claudex docs check`: an offline CI gate
+// that reports index.md files whose documented code changed after they were last
+// committed, and directories large enough to need an index.md that have none.
+// It only reads git history and never calls a model.
+package docscheck
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"claudex/internal/doc/rangeupdater"
+	"claudex/internal/services/commander"
+	"claudex/internal/services/git"
+
+	"github.com/spf13/afero"
+)
+
+// indexFile is the name of the per-directory documentation file
+const indexFile = "index.md"
+
+// Options configures a check. ProjectDir may be any directory of the repository;
+// paths are resolved against the repository root since git reports files relative to it.
+type Options struct {
+	ProjectDir string
+	// MaxCommits is how many commits to documented files an index may lag behind (0 disables)
+	MaxCommits int
+	// MaxAge is how far the newest commit to documented files may be from the index's last commit (0 disables)
+	MaxAge time.Duration
+	// MinFiles is how many files make a directory need its own index.md (0 disables)
+	MinFiles int
+	// Include and Exclude are the [docs.update] globs deciding which files count
+	Include []string
+	Exclude []string
+}
+
+// StaleIndex is an index.md whose documented files changed beyond the thresholds since it was committed
+type StaleIndex struct {
+	Index          string    `json:"index"`
+	IndexCommit    string    `json:"index_commit"`
+	IndexUpdatedAt time.Time `json:"index_updated_at"`
+	// Commits counts the commits to documented files since IndexCommit
+	Commits      int       `json:"commits"`
+	LatestCommit string    `json:"latest_commit"`
+	LatestAt     time.Time `json:"latest_at"`
+	LagDays      int       `json:"lag_days"`
+	// Files are the documented files changed since IndexCommit
+	Files   []string `json:"files"`
+	Reasons []string `json:"reasons"`
+}
+
+// MissingIndex is a directory holding at least MinFiles files without an index.md of its own
+type MissingIndex struct {
+	Dir   string `json:"dir"`
+	Files int    `json:"files"`
+	// CoveredBy is the nearest index.md above the directory, if any
+	CoveredBy string `json:"covered_by,omitempty"`
+}
+
+// Report is the outcome of a check; paths are relative to the repository root
+type Report struct {
+	Head    string         `json:"head"`
+	Checked int            `json:"checked"`
+	Stale   []StaleIndex   `json:"stale"`
+	Missing []MissingIndex `json:"missing"`
+}
+
+// Failed reports whether any index.md is stale or missing
+func (r *Report) Failed() bool {
+	return len(r.Stale) > 0 || len(r.Missing) > 0
+}
+
+// UseCase checks index.md freshness from git history
+type UseCase struct {
+	fs  afero.Fs
+	cmd commander.Commander
+}
+
+// New creates a new UseCase instance with the given dependencies
+func New(fs afero.Fs, cmd commander.Commander) *UseCase {
+	return &UseCase{fs: fs, cmd: cmd}
+}
+
+// Execute checks every tracked index.md against the commits to the files it documents
+// and looks for directories that need an index.md
+func (uc *UseCase) Execute(opts Options) (*Report, error) {
+	gitSvc := git.New(uc.cmd)
+
+	filter, err := rangeupdater.NewFileFilter(opts.Include, opts.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := gitSvc.GetRepoRoot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the repository root: %w", err)
+	}
+	if root != "" {
+		opts.ProjectDir = root
+	}
+
+	head, err := gitSvc.GetCurrentSHA()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	files, err := gitSvc.ListFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tracked files: %w", err)
+	}
+
+	// Split tracked files into indexes and per-directory counts of the files that count
+	var indexes []string
+	counts := make(map[string]int)
+	for _, file := range files {
+		if path.Base(file) == indexFile {
+			indexes = append(indexes, file)
+			continue
+		}
+		if filter.Allows(file) {
+			counts[path.Dir(file)]++
+		}
+	}
+	sort.Strings(indexes)
+
+	report := &Report{Head: head, Checked: len(indexes), Stale: []StaleIndex{}, Missing: []MissingIndex{}}
+	for _, index := range indexes {
+		stale, err := uc.checkIndex(gitSvc, filter, opts, index, head)
+		if err != nil {
+			return nil, err
+		}
+		if stale != nil {
+			report.Stale = append(report.Stale, *stale)
+		}
+	}
+
+	if opts.MinFiles > 0 {
+		report.Missing = uc.missingIndexes(opts, counts)
+	}
+	return report, nil
+}
+
+// checkIndex returns the staleness of one index.md, or nil when it is fresh enough
+func (uc *UseCase) checkIndex(gitSvc git.GitService, filter *rangeupdater.FileFilter, opts Options, index, head string) (*StaleIndex, error) {
+	last, err := gitSvc.GetLastCommit(index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history of %s: %w", index, err)
+	}
+	if last.SHA == "" {
+		// Staged but never committed: nothing to compare against yet
+		return nil, nil
+	}
+
+	changed, err := gitSvc.GetChangedFiles(last.SHA, head)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff %s..%s: %w", last.SHA, head, err)
+	}
+	kept, _ := filter.Apply(changed)
+
+	// Only files whose nearest index.md is this one are documented by it
+	absIndex := filepath.Join(opts.ProjectDir, index)
+	absFiles := make([]string, len(kept))
+	for i, file := range kept {
+		absFiles[i] = filepath.Join(opts.ProjectDir, file)
+	}
+	affected, err := rangeupdater.ResolveAffectedIndexes(uc.fs, absFiles)
+	if err != nil {
+		return nil, err
+	}
+	if !contains(affected, absIndex) {
+		return nil, nil
+	}
+
+	var documented []string
+	for i, file := range kept {
+		if rangeupdater.NearestIndex(uc.fs, absFiles[i]) == absIndex {
+			documented = append(documented, file)
+		}
+	}
+
+	commits, err := gitSvc.GetPathCommits(last.SHA, head, documented)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history of files under %s: %w", index, err)
+	}
+	if len(commits) == 0 {
+		return nil, nil
+	}
+
+	latest := commits[0]
+	lag := latest.Time.Sub(last.Time)
+	var reasons []string
+	if opts.MaxCommits > 0 && len(commits) > opts.MaxCommits {
+		reasons = append(reasons, fmt.Sprintf("%d commits to documented files since it changed (max %d)", len(commits), opts.MaxCommits))
+	}
+	if opts.MaxAge > 0 && lag > opts.MaxAge {
+		reasons = append(reasons, fmt.Sprintf("documented files changed %d days after it (max %d)", days(lag), days(opts.MaxAge)))
+	}
+	if len(reasons) == 0 {
+		return nil, nil
+	}
+
+	return &StaleIndex{
+		Index:          index,
+		IndexCommit:    last.SHA,
+		IndexUpdatedAt: last.Time,
+		Commits:        len(commits),
+		LatestCommit:   latest.SHA,
+		LatestAt:       latest.Time,
+		LagDays:        days(lag),
+		Files:          documented,
+		Reasons:        reasons,
+	}, nil
+}
+
+// missingIndexes returns the directories holding at least MinFiles files and no index.md, sorted
+func (uc *UseCase) missingIndexes(opts Options, counts map[string]int) []MissingIndex {
+	missing := []MissingIndex{}
+	for dir, n := range counts {
+		if n < opts.MinFiles {
+			continue
+		}
+		absDir := filepath.Join(opts.ProjectDir, dir)
+		if exists, _ := afero.Exists(uc.fs, filepath.Join(absDir, indexFile)); exists {
+			continue
+		}
+
+		entry := MissingIndex{Dir: dir, Files: n}
+		// NearestIndex starts from a file's directory, so ask for a file inside dir
+		if covering := rangeupdater.NearestIndex(uc.fs, filepath.Join(absDir, indexFile)); covering != "" {
+			if rel, err := filepath.Rel(opts.ProjectDir, covering); err == nil && !isOutside(rel) {
+				entry.CoveredBy = filepath.ToSlash(rel)
+			}
+		}
+		missing = append(missing, entry)
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i].Dir < missing[j].Dir })
+	return missing
+}
+
+// contains reports whether values holds value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// isOutside reports whether a relative path leaves its base directory
+func isOutside(rel string) bool {
+	return rel == ".." || len(rel) > 2 && rel[:3] == ".."+string(filepath.Separator)
+}
+
+// days returns a duration in whole days
+func days(d time.Duration) int {
+	return int(d / (24 * time.Hour))
+}
@@ -0,0 +1,180 @@
+package docscheck
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"claudex/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testProjectDir = "/project"
+
+// indexCommitTime is when src/api/index.md was last committed in these tests
+var indexCommitTime = time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+// logRecord renders one commit in the git log format the git service parses
+func logRecord(sha string, at time.Time, subject string) string {
+	return fmt.Sprintf("%s\x1f%d\x1f%s\x1f\x1e\n", sha, at.Unix(), subject)
+}
+
+// newTestUseCase sets up a project whose tracked files are given relative to the project directory
+func newTestUseCase(h *testutil.TestHarness, files ...string) *UseCase {
+	for _, file := range files {
+		h.WriteFile(testProjectDir+"/"+file, "content")
+	}
+	h.Commander.OnPattern("git", "rev-parse", "--show-toplevel").Return([]byte(testProjectDir+"\n"), nil)
+	h.Commander.OnPattern("git", "rev-parse", "HEAD").Return([]byte("head\n"), nil)
+	h.Commander.OnPattern("git", "ls-files").Return([]byte(strings.Join(files, "\n")+"\n"), nil)
+	return New(h.FS, h.Commander)
+}
+
+func testOptions() Options {
+	return Options{
+		ProjectDir: testProjectDir,
+		MaxCommits: 5,
+		MaxAge:     30 * 24 * time.Hour,
+		Exclude:    []string{"*.md"},
+	}
+}
+
+func TestExecute_ReportsIndexBehindItsFiles(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	uc := newTestUseCase(h,
+		"src/api/index.md", "src/api/handler.go", "src/api/README.md",
+		"src/api/v2/index.md", "src/api/v2/types.go",
+	)
+	h.Commander.OnPattern("git", "-1", "src/api/index.md").Return([]byte(logRecord("idx1", indexCommitTime, "docs: api index")), nil)
+	h.Commander.OnPattern("git", "diff", "idx1..head").Return([]byte("src/api/handler.go\nsrc/api/README.md\nsrc/api/v2/types.go\n"), nil)
+	var commits strings.Builder
+	for i := 7; i > 0; i-- {
+		commits.WriteString(logRecord(fmt.Sprintf("c%d", i), indexCommitTime.Add(time.Duration(i)*7*24*time.Hour), "feat: handler"))
+	}
+	h.Commander.OnPattern("git", "log", "idx1..head").Return([]byte(commits.String()), nil)
+	// src/api/v2/index.md has never been committed
+
+	// Exercise
+	report, err := uc.Execute(testOptions())
+
+	// Verify
+	require.NoError(t, err)
+	assert.True(t, report.Failed())
+	assert.Equal(t, 2, report.Checked)
+	require.Len(t, report.Stale, 1)
+	stale := report.Stale[0]
+	assert.Equal(t, "src/api/index.md", stale.Index)
+	assert.Equal(t, 7, stale.Commits)
+	assert.Equal(t, "c7", stale.LatestCommit)
+	assert.Equal(t, 49, stale.LagDays)
+	assert.Equal(t, []string{"src/api/handler.go"}, stale.Files, "markdown is filtered out and v2 files belong to their own index")
+	require.Len(t, stale.Reasons, 2)
+	assert.Contains(t, stale.Reasons[0], "7 commits")
+	assert.Contains(t, stale.Reasons[1], "49 days")
+
+	for _, inv := range h.Commander.Invocations {
+		if inv.Args[0] == "log" && inv.Args[2] == "idx1..head" {
+			assert.Equal(t, []string{"--", ":(top)src/api/handler.go"}, inv.Args[3:], "commits are counted over documented files only")
+		}
+	}
+}
+
+func TestExecute_IndexWithinThresholdsIsFresh(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	uc := newTestUseCase(h, "src/api/index.md", "src/api/handler.go")
+	h.Commander.OnPattern("git", "-1", "src/api/index.md").Return([]byte(logRecord("idx1", indexCommitTime, "docs: api index")), nil)
+	h.Commander.OnPattern("git", "diff", "idx1..head").Return([]byte("src/api/handler.go\n"), nil)
+	h.Commander.OnPattern("git", "log", "idx1..head").Return([]byte(logRecord("c1", indexCommitTime.Add(24*time.Hour), "fix: handler")), nil)
+
+	// Exercise
+	report, err := uc.Execute(testOptions())
+
+	// Verify
+	require.NoError(t, err)
+	assert.False(t, report.Failed())
+	assert.Empty(t, report.Stale)
+}
+
+func TestExecute_FromSubdirectoryUsesRepositoryRoot(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	uc := newTestUseCase(h, "src/api/index.md", "src/api/handler.go")
+	h.Commander.OnPattern("git", "-1", "src/api/index.md").Return([]byte(logRecord("idx1", indexCommitTime, "docs: api index")), nil)
+	h.Commander.OnPattern("git", "diff", "idx1..head").Return([]byte("src/api/handler.go\n"), nil)
+	h.Commander.OnPattern("git", "log", "idx1..head").Return([]byte(logRecord("c1", indexCommitTime.Add(60*24*time.Hour), "feat: handler")), nil)
+	opts := testOptions()
+	opts.ProjectDir = testProjectDir + "/src/api"
+
+	// Exercise
+	report, err := uc.Execute(opts)
+
+	// Verify
+	require.NoError(t, err)
+	require.Len(t, report.Stale, 1)
+	assert.Equal(t, "src/api/index.md", report.Stale[0].Index)
+	assert.Equal(t, []string{"src/api/handler.go"}, report.Stale[0].Files)
+	for _, inv := range h.Commander.Invocations {
+		if inv.Args[0] == "ls-files" {
+			assert.Contains(t, inv.Args, "--full-name", "tracked files must be listed relative to the repository root")
+		}
+	}
+}
+
+func TestExecute_ReportsLargeDirectoriesWithoutIndex(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	uc := newTestUseCase(h,
+		"index.md",
+		"src/big/a.go", "src/big/b.go", "src/big/c.go", "src/big/notes.md",
+		"src/small/a.go",
+	)
+	opts := testOptions()
+	opts.MinFiles = 3
+
+	// Exercise
+	report, err := uc.Execute(opts)
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, []MissingIndex{{Dir: "src/big", Files: 3, CoveredBy: "index.md"}}, report.Missing)
+	assert.True(t, report.Failed())
+}
+
+func TestExecute_InvalidPattern(t *testing.T) {
+	h := testutil.NewTestHarness()
+	uc := newTestUseCase(h, "index.md")
+	opts := testOptions()
+	opts.Exclude = []string{"[a-"}
+
+	_, err := uc.Execute(opts)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid exclude pattern")
+}
+
+func TestReport_SARIF(t *testing.T) {
+	// Setup
+	report := &Report{
+		Stale:   []StaleIndex{{Index: "src/api/index.md", Reasons: []string{"7 commits to documented files since it changed (max 5)"}}},
+		Missing: []MissingIndex{{Dir: "src/big", Files: 12}},
+	}
+
+	// Exercise
+	log := report.SARIF()
+
+	// Verify
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	results := log.Runs[0].Results
+	require.Len(t, results, 2)
+	assert.Equal(t, "stale-index", results[0].RuleID)
+	assert.Equal(t, "src/api/index.md", results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Contains(t, results[0].Message.Text, "7 commits")
+	assert.Equal(t, "missing-index", results[1].RuleID)
+	assert.Equal(t, "src/big/", results[1].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+}
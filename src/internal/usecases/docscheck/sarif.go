@@ -0,0 +1,113 @@
+package docscheck
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// sarifSchema and sarifVersion identify the SARIF format the report is written in
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+
+	ruleStale   = "stale-index"
+	ruleMissing = "missing-index"
+)
+
+// SARIFLog is the subset of a SARIF 2.1.0 log code scanning tools need to annotate findings
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+// SARIFRun is the single run of a check
+type SARIFRun struct {
+	Tool    SARIFTool     `json:"tool"`
+	Results []SARIFResult `json:"results"`
+}
+
+// SARIFTool names the tool and the rules its results refer to
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+// SARIFDriver describes claudex and its rules
+type SARIFDriver struct {
+	Name  string      `json:"name"`
+	Rules []SARIFRule `json:"rules"`
+}
+
+// SARIFRule is one kind of finding
+type SARIFRule struct {
+	ID               string       `json:"id"`
+	ShortDescription SARIFMessage `json:"shortDescription"`
+}
+
+// SARIFResult is one finding
+type SARIFResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   SARIFMessage    `json:"message"`
+	Locations []SARIFLocation `json:"locations"`
+}
+
+// SARIFMessage is a plain-text message
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+// SARIFLocation points a finding at a file or directory
+type SARIFLocation struct {
+	PhysicalLocation SARIFPhysicalLocation `json:"physicalLocation"`
+}
+
+// SARIFPhysicalLocation holds the artifact a finding refers to
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+}
+
+// SARIFArtifactLocation is a path relative to the repository root
+type SARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// SARIF converts the report into a SARIF log with one error result per finding
+func (r *Report) SARIF() SARIFLog {
+	results := []SARIFResult{}
+	for _, stale := range r.Stale {
+		results = append(results, sarifResult(ruleStale, stale.Index,
+			fmt.Sprintf("%s is stale: %s", stale.Index, strings.Join(stale.Reasons, "; "))))
+	}
+	for _, missing := range r.Missing {
+		results = append(results, sarifResult(ruleMissing, missing.Dir+"/",
+			fmt.Sprintf("%s has %d files and no index.md", missing.Dir, missing.Files)))
+	}
+
+	return SARIFLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []SARIFRun{{
+			Tool: SARIFTool{Driver: SARIFDriver{
+				Name: "claudex docs check",
+				Rules: []SARIFRule{
+					{ID: ruleStale, ShortDescription: SARIFMessage{Text: "index.md lags behind the code it documents"}},
+					{ID: ruleMissing, ShortDescription: SARIFMessage{Text: "Directory needs an index.md"}},
+				},
+			}},
+			Results: results,
+		}},
+	}
+}
+
+// sarifResult builds an error result located at uri
+func sarifResult(rule, uri, message string) SARIFResult {
+	return SARIFResult{
+		RuleID:  rule,
+		Level:   "error",
+		Message: SARIFMessage{Text: message},
+		Locations: []SARIFLocation{{
+			PhysicalLocation: SARIFPhysicalLocation{ArtifactLocation: SARIFArtifactLocation{URI: uri}},
+		}},
+	}
+}
@@ -21,6 +21,79 @@ const defaultConfigContent = `# Claudex Configuration
 autodoc_session_progress = true
 autodoc_session_end = true
 autodoc_frequency = 5
+
+# Per-trigger autodoc policies (explicit keys here override [features])
+# [autodoc]
+# history_limit = 20   # overview versions kept in .history/ (0 disables)
+#
+# [autodoc.validation]  # updates that break these are rejected
+# enabled = true
+# required_sections = ["Status", "Key Decisions", "Documents", "Progress Timeline"]
+# check_links = true
+#
+# [autodoc.redaction]  # masked in transcripts before they reach the doc updater
+# enabled = true
+# emails = true
+# entropy_threshold = 4.5   # random-looking tokens (0 disables)
+#
+# [[autodoc.redaction.patterns]]
+# name = "customer-id"
+# regex = 'CUST-\d{6}'
+#
+# [autodoc.progress]
+# enabled = true
+# model = "haiku"
+# frequency = 5
+# output_file = "session-overview.md"
+#
+# [autodoc.subagent]
+# enabled = true
+# frequency = 1
+#
+# [autodoc.session_end]
+# enabled = true
+
+# Changed files that make --update-docs rewrite index.md files (gitignore-style globs)
+# [docs.update]
+# include = []   # only matching files count (empty: all files)
+# exclude = ["*.md", "docs/", "vendor/", "node_modules/", "go.sum", "*.lock", "*_gen.go"]
+# propagation_depth = 0   # parent index levels refreshed when an index is created or changes significantly
+# workers = 4             # index updates run in parallel
+# timeout_seconds = 0     # per-index limit (0: use the [llm] timeout)
+
+# Tool policy checked before every tool call; built-in rules deny force pushes,
+# rm -rf outside the project and .env edits
+# [policy]
+# default = "allow"     # decision when no rule matches (allow leaves it to Claude Code's permissions, ask, deny)
+# audit = true          # decisions are appended to <session>/policy-audit.jsonl
+# builtin_rules = true
+#
+# [[policy.rules]]
+# name = "confirm-migrations"
+# tools = ["Edit", "Write"]        # tool name globs
+# paths = ["db/migrations/"]       # gitignore-style globs relative to the project root
+# # commands = ['^terraform apply'] # regular expressions on Bash commands
+# # outside_project = true         # a path resolves outside the project root
+# # subagent_types = ["Explore"]   # subagent type of Task calls
+# decision = "ask"
+# reason = "migrations need review"
+
+# Credential scan of Write/Edit/MultiEdit/Bash inputs (AWS, GitHub, Anthropic, private keys)
+# [secrets]
+# action = "deny"             # or "ask"
+# entropy_threshold = 4.5     # bits per character of random-looking tokens (0 disables)
+# allow_paths = ["go.sum", "*.lock", "package-lock.json", "pnpm-lock.yaml"]
+# allow_fingerprints = []     # fingerprints printed in deny reasons
+#
+# [[secrets.patterns]]
+# name = "internal-token"
+# regex = 'itk_[a-z0-9]{32}'
+
+# Model backend for background calls (claude-cli, anthropic, fake)
+# [llm]
+# backend = "claude-cli"
+# model = "haiku"
+# timeout_seconds = 300
 `
 
 // Migrator handles migration of legacy Claudex artifacts and initialization
@@ -0,0 +1,410 @@
+// Package overview provides the use cases that bring a session overview up to date
+// outside the hooks: catching up on the transcript tail no hook processed (e.g. when
+// the SessionEnd hook was killed) and regenerating the overview from the full transcripts.
+// Both feed the transcripts to doc.Updater.Run in chunks, synchronously.
+package overview
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"claudex/internal/doc"
+	"claudex/internal/services/clock"
+	"claudex/internal/services/cursor"
+	"claudex/internal/services/env"
+	"claudex/internal/services/history"
+	"claudex/internal/services/jobs"
+	"claudex/internal/services/lock"
+	"claudex/internal/services/session"
+	"claudex/internal/services/transcript"
+
+	"github.com/spf13/afero"
+)
+
+// Triggers recorded in the overview snapshots taken by these use cases
+const (
+	TriggerUpdate  = "manual"
+	TriggerRebuild = "rebuild"
+)
+
+// DefaultChunkEntries is the number of transcript entries sent to the model per update
+const DefaultChunkEntries = 200
+
+// projectDirPattern matches the characters Claude replaces when naming a project's transcript folder
+var projectDirPattern = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+// Options identifies the session and document to bring up to date
+type Options struct {
+	SessionName  string
+	SessionPath  string
+	ProjectDir   string               // Project root holding .claude/hooks/prompts
+	OutputFile   string               // Session document to update (defaults to doc.DefaultOutputFile)
+	Model        string               // Model used for the updates
+	HistoryLimit int                  // Snapshots kept in <session>/.history (0 disables)
+	Validation   *doc.ValidationRules // Checks each generated version must pass (nil disables)
+	Redaction    *doc.RedactionRules  // Masking of transcript entries before they reach the model (nil disables)
+	ChunkEntries int                  // Entries per model call (defaults to DefaultChunkEntries)
+}
+
+// Tail is the part of a transcript the overview does not cover yet
+type Tail struct {
+	TranscriptPath string       `json:"transcript_path"`
+	Entries        int          `json:"entries"` // Prompts, assistant messages and agent results left to document
+	State          cursor.State `json:"state"`   // How the transcript relates to its stored cursor
+}
+
+// Result summarises an update or rebuild
+type Result struct {
+	Transcripts []Tail `json:"transcripts"`
+	Chunks      int    `json:"chunks"` // Model calls made
+}
+
+// Entries returns the number of entries documented
+func (r Result) Entries() int {
+	total := 0
+	for _, tail := range r.Transcripts {
+		total += tail.Entries
+	}
+	return total
+}
+
+// UseCase catches up and rebuilds session overviews
+type UseCase struct {
+	fs      afero.Fs
+	env     env.Environment
+	clock   clock.Clock
+	lock    lock.LockService
+	updater doc.DocumentationUpdater
+}
+
+// New creates a new overview use case
+func New(fs afero.Fs, environ env.Environment, clk clock.Clock, lockSvc lock.LockService, updater doc.DocumentationUpdater) *UseCase {
+	return &UseCase{
+		fs:      fs,
+		env:     environ,
+		clock:   clk,
+		lock:    lockSvc,
+		updater: updater,
+	}
+}
+
+// Pending returns the transcripts of a session with entries the overview does not cover yet
+func (uc *UseCase) Pending(opts Options) ([]Tail, error) {
+	paths, err := uc.transcripts(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	cursors := cursor.New(uc.fs, uc.clock, opts.SessionPath)
+	tails := []Tail{}
+	for _, path := range paths {
+		resume, state, err := cursors.Resume(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read transcript cursor: %w", err)
+		}
+		entries, err := uc.countEntries(path, transcript.Position{Line: resume.Line, Offset: resume.Offset})
+		if err != nil {
+			return nil, err
+		}
+		if entries > 0 {
+			tails = append(tails, Tail{TranscriptPath: path, Entries: entries, State: state})
+		}
+	}
+	return tails, nil
+}
+
+// Update documents the pending transcript tails, continuing from each transcript's cursor
+func (uc *UseCase) Update(opts Options) (Result, error) {
+	release, err := uc.acquire(opts.SessionPath)
+	if err != nil {
+		return Result{}, err
+	}
+	defer release()
+
+	tails, err := uc.Pending(opts)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{Transcripts: tails}
+	for _, tail := range tails {
+		chunks, err := uc.process(opts, tail, TriggerUpdate, false)
+		result.Chunks += chunks
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// Rebuild regenerates the overview from the start of every transcript of the session.
+// The current overview is snapshotted first; if any chunk fails, the overview and
+// the transcript cursors are put back as they were.
+func (uc *UseCase) Rebuild(opts Options) (Result, error) {
+	release, err := uc.acquire(opts.SessionPath)
+	if err != nil {
+		return Result{}, err
+	}
+	defer release()
+
+	paths, err := uc.transcripts(opts)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{Transcripts: []Tail{}}
+	for _, path := range paths {
+		entries, err := uc.countEntries(path, transcript.Position{})
+		if err != nil {
+			return result, err
+		}
+		if entries > 0 {
+			result.Transcripts = append(result.Transcripts, Tail{TranscriptPath: path, Entries: entries, State: cursor.StateNew})
+		}
+	}
+	if len(result.Transcripts) == 0 {
+		return result, fmt.Errorf("no transcript with documentable entries found for %s", opts.SessionName)
+	}
+
+	outputFile := outputFile(opts)
+	outputPath := filepath.Join(opts.SessionPath, outputFile)
+	cursorPath := filepath.Join(opts.SessionPath, cursor.StoreFile)
+	restoreOverview, err := uc.saveFile(outputPath)
+	if err != nil {
+		return result, err
+	}
+	restoreCursors, err := uc.saveFile(cursorPath)
+	if err != nil {
+		return result, err
+	}
+
+	if opts.HistoryLimit > 0 {
+		if _, err := history.New(uc.fs, uc.clock, opts.SessionPath).Snapshot(outputFile, TriggerRebuild, opts.HistoryLimit); err != nil {
+			return result, err
+		}
+	}
+
+	// The first chunk starts from an empty document so nothing of the old overview carries over
+	if err := uc.fs.Remove(outputPath); err != nil && !os.IsNotExist(err) {
+		return result, fmt.Errorf("failed to remove %s: %w", outputFile, err)
+	}
+
+	// Intermediate versions are not worth keeping; the snapshot above covers the rebuild
+	chunkOpts := opts
+	chunkOpts.HistoryLimit = 0
+	for _, tail := range result.Transcripts {
+		chunks, err := uc.process(chunkOpts, tail, TriggerRebuild, true)
+		result.Chunks += chunks
+		if err != nil {
+			if restoreErr := restoreOverview(); restoreErr != nil {
+				return result, fmt.Errorf("%w (restoring %s also failed: %v)", err, outputFile, restoreErr)
+			}
+			if restoreErr := restoreCursors(); restoreErr != nil {
+				return result, fmt.Errorf("%w (restoring transcript cursors also failed: %v)", err, restoreErr)
+			}
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// process runs doc updates over one transcript tail, a chunk at a time, until no entries are left.
+// fromStart reads the transcript from its first line instead of its cursor. The tail's entry
+// count is decremented per chunk; the transcript is only recounted from the cursor once that
+// estimate runs out, so long transcripts are not rescanned for every chunk.
+func (uc *UseCase) process(opts Options, tail Tail, trigger string, fromStart bool) (int, error) {
+	transcriptPath := tail.TranscriptPath
+	chunkEntries := opts.ChunkEntries
+	if chunkEntries <= 0 {
+		chunkEntries = DefaultChunkEntries
+	}
+	config := doc.UpdaterConfig{
+		SessionPath:    opts.SessionPath,
+		TranscriptPath: transcriptPath,
+		OutputFile:     outputFile(opts),
+		PromptTemplate: filepath.Join(opts.ProjectDir, ".claude", "hooks", "prompts", "session-overview-documenter.md"),
+		SessionContext: uc.sessionContext(opts.SessionPath),
+		Model:          opts.Model,
+		Trigger:        trigger,
+		HistoryLimit:   opts.HistoryLimit,
+		Validation:     opts.Validation,
+		Redaction:      opts.Redaction,
+		MaxEntries:     chunkEntries,
+	}
+
+	cursors := cursor.New(uc.fs, uc.clock, opts.SessionPath)
+	chunks := 0
+	remaining := tail.Entries
+	for {
+		start := transcript.Position{}
+		if !fromStart || chunks > 0 {
+			resume, _, err := cursors.Resume(transcriptPath)
+			if err != nil {
+				return chunks, fmt.Errorf("failed to read transcript cursor: %w", err)
+			}
+			start = transcript.Position{Line: resume.Line, Offset: resume.Offset}
+		}
+
+		if remaining <= 0 {
+			entries, err := uc.countEntries(transcriptPath, start)
+			if err != nil {
+				return chunks, err
+			}
+			if entries == 0 {
+				return chunks, nil
+			}
+			remaining = entries
+		}
+
+		config.StartLine = 0
+		if fromStart && chunks == 0 {
+			config.StartLine = 1
+		}
+		if err := uc.updater.Run(config); err != nil {
+			return chunks, fmt.Errorf("failed to update %s from %s: %w", config.OutputFile, filepath.Base(transcriptPath), err)
+		}
+		chunks++
+		remaining -= chunkEntries
+
+		// Guard against a run that reported success without moving the cursor
+		after, ok, err := cursors.Get(transcriptPath)
+		if err != nil {
+			return chunks, fmt.Errorf("failed to read transcript cursor: %w", err)
+		}
+		if !ok || (after.Offset == start.Offset && after.Line == start.Line) {
+			return chunks, fmt.Errorf("doc update made no progress on %s", transcriptPath)
+		}
+	}
+}
+
+// transcripts returns the transcripts of a session, oldest first: every transcript
+// with a stored cursor plus the Claude transcript named after the session's Claude ID
+func (uc *UseCase) transcripts(opts Options) ([]string, error) {
+	cursors, err := cursor.New(uc.fs, uc.clock, opts.SessionPath).All()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var paths []string
+	for path := range cursors {
+		seen[path] = true
+		paths = append(paths, path)
+	}
+	if path := uc.claudeTranscript(opts); path != "" && !seen[path] {
+		paths = append(paths, path)
+	}
+
+	type found struct {
+		path    string
+		modTime int64
+	}
+	var existing []found
+	for _, path := range paths {
+		info, err := uc.fs.Stat(path)
+		if err != nil {
+			continue
+		}
+		existing = append(existing, found{path: path, modTime: info.ModTime().UnixNano()})
+	}
+	sort.Slice(existing, func(i, j int) bool {
+		if existing[i].modTime != existing[j].modTime {
+			return existing[i].modTime < existing[j].modTime
+		}
+		return existing[i].path < existing[j].path
+	})
+
+	result := make([]string, 0, len(existing))
+	for _, f := range existing {
+		result = append(result, f.path)
+	}
+	return result, nil
+}
+
+// claudeTranscript returns where Claude stores the transcript of the session's Claude ID:
+// ~/.claude/projects/<project dir with non-alphanumerics replaced by '-'>/<id>.jsonl
+func (uc *UseCase) claudeTranscript(opts Options) string {
+	claudeID := session.ExtractClaudeSessionID(opts.SessionName)
+	home := uc.env.Get("HOME")
+	if claudeID == "" || home == "" || opts.ProjectDir == "" {
+		return ""
+	}
+	project := projectDirPattern.ReplaceAllString(opts.ProjectDir, "-")
+	return filepath.Join(home, ".claude", "projects", project, claudeID+".jsonl")
+}
+
+// countEntries returns the number of documentable entries after start
+func (uc *UseCase) countEntries(transcriptPath string, start transcript.Position) (int, error) {
+	entries, _, err := doc.ParseTranscriptFrom(uc.fs, transcriptPath, start)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	return len(entries), nil
+}
+
+// acquire takes the session's job queue lock so no background update runs concurrently
+func (uc *UseCase) acquire(sessionPath string) (func(), error) {
+	queue := jobs.NewQueue(uc.fs, uc.clock, sessionPath)
+	if err := uc.fs.MkdirAll(queue.Dir(), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create jobs directory: %w", err)
+	}
+	l, err := uc.lock.Acquire(queue.LockPath())
+	if errors.Is(err, lock.ErrHeld) {
+		return nil, fmt.Errorf("a background doc update is running for this session; retry when 'claudex jobs list' shows it finished")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return func() { _ = l.Release() }, nil
+}
+
+// saveFile captures a file so it can be put back; a missing file is removed again on restore
+func (uc *UseCase) saveFile(path string) (func() error, error) {
+	data, err := afero.ReadFile(uc.fs, path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	existed := err == nil
+
+	return func() error {
+		if !existed {
+			if err := uc.fs.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			return nil
+		}
+		return afero.WriteFile(uc.fs, path, data, 0644)
+	}, nil
+}
+
+// sessionContext lists the markdown files of the session folder, as the hooks do
+func (uc *UseCase) sessionContext(sessionPath string) string {
+	files, err := afero.ReadDir(uc.fs, sessionPath)
+	if err != nil {
+		return ""
+	}
+
+	var context strings.Builder
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), ".md") {
+			if context.Len() == 0 {
+				context.WriteString("Existing documentation files in session:\n")
+			}
+			context.WriteString(fmt.Sprintf("- %s\n", file.Name()))
+		}
+	}
+	return context.String()
+}
+
+// outputFile returns the session document to update
+func outputFile(opts Options) string {
+	if opts.OutputFile == "" {
+		return doc.DefaultOutputFile
+	}
+	return opts.OutputFile
+}
@@ -0,0 +1,262 @@
+package overview
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"claudex/internal/doc"
+	"claudex/internal/services/cursor"
+	"claudex/internal/services/history"
+	"claudex/internal/services/jobs"
+	"claudex/internal/services/llm"
+	"claudex/internal/services/lock"
+	"claudex/internal/testutil"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testProjectDir  = "/project"
+	testSessionName = "auth-work-aaaa1111-2222-3333-4444-555566667777"
+	testSessionPath = "/project/.claudex/sessions/" + testSessionName
+	testTranscript  = "/home/user/.claude/projects/-project/aaaa1111-2222-3333-4444-555566667777.jsonl"
+)
+
+// promptLines returns n user prompt transcript lines numbered from first
+func promptLines(first, n int) string {
+	var b strings.Builder
+	for i := first; i < first+n; i++ {
+		fmt.Fprintf(&b, `{"type":"user","timestamp":"2024-01-15T10:%02d:00Z","message":{"role":"user","content":"prompt %d"}}`+"\n", i, i)
+	}
+	return b.String()
+}
+
+// newTestUseCase sets up a project with the documenter template and a session transcript
+func newTestUseCase(h *testutil.TestHarness, fake *llm.Fake, transcript string) *UseCase {
+	h.Env.Set("HOME", "/home/user")
+	h.CreateDir(testSessionPath)
+	h.WriteFile(filepath.Join(testProjectDir, ".claude", "hooks", "prompts", "session-overview-documenter.md"), "$RELEVANT_CONTENT")
+	h.WriteFile(testTranscript, transcript)
+
+	updater := doc.NewUpdater(h.FS, h.Commander, h.Env, fake)
+	return New(h.FS, h.Env, h, lock.New(h.FS), updater)
+}
+
+func testOptions() Options {
+	return Options{
+		SessionName:  testSessionName,
+		SessionPath:  testSessionPath,
+		ProjectDir:   testProjectDir,
+		Model:        "haiku",
+		HistoryLimit: history.DefaultLimit,
+		ChunkEntries: 2,
+	}
+}
+
+func TestPending_FindsClaudeTranscriptWithoutCursor(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	uc := newTestUseCase(h, llm.NewFake("# Overview"), promptLines(1, 3))
+
+	// Exercise
+	tails, err := uc.Pending(testOptions())
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, []Tail{{TranscriptPath: testTranscript, Entries: 3, State: cursor.StateNew}}, tails)
+}
+
+func TestPending_CountsOnlyEntriesAfterCursor(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	processed := promptLines(1, 4)
+	uc := newTestUseCase(h, llm.NewFake("# Overview"), processed+promptLines(5, 1))
+	require.NoError(t, cursor.New(h.FS, h, testSessionPath).Advance(testTranscript, int64(len(processed)), 4))
+
+	// Exercise
+	tails, err := uc.Pending(testOptions())
+
+	// Verify
+	require.NoError(t, err)
+	require.Len(t, tails, 1)
+	assert.Equal(t, 1, tails[0].Entries)
+	assert.Equal(t, cursor.StateValid, tails[0].State)
+}
+
+func TestUpdate_ProcessesTailInChunks(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	fake := llm.NewFake("# Overview\n\nCaught up")
+	transcript := promptLines(1, 5)
+	uc := newTestUseCase(h, fake, transcript)
+	h.WriteFile(testSessionPath+"/session-overview.md", "# Overview\n\nBefore")
+
+	// Exercise
+	result, err := uc.Update(testOptions())
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, 5, result.Entries())
+	assert.Equal(t, 3, result.Chunks)
+
+	requests := fake.Requests()
+	require.Len(t, requests, 3)
+	assert.Contains(t, requests[0].Prompt, "prompt 1")
+	assert.Contains(t, requests[0].Prompt, "prompt 2")
+	assert.NotContains(t, requests[0].Prompt, "prompt 3")
+	assert.Contains(t, requests[2].Prompt, "prompt 5")
+
+	testutil.AssertFileContains(t, h.FS, testSessionPath+"/session-overview.md", "Caught up")
+	c, ok, err := cursor.New(h.FS, h, testSessionPath).Get(testTranscript)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, int64(len(transcript)), c.Offset)
+
+	snapshots, err := history.New(h.FS, h, testSessionPath).List(doc.DefaultOutputFile)
+	require.NoError(t, err)
+	require.NotEmpty(t, snapshots)
+	assert.Equal(t, TriggerUpdate, snapshots[len(snapshots)-1].Trigger)
+}
+
+// scanCountingFs counts the sequential reads of a file; checksum reads use ReadAt and are not counted
+type scanCountingFs struct {
+	afero.Fs
+	path  string
+	scans int
+}
+
+func (fs *scanCountingFs) Open(name string) (afero.File, error) {
+	file, err := fs.Fs.Open(name)
+	if err != nil || name != fs.path {
+		return file, err
+	}
+	return &scanCountingFile{File: file, fs: fs}, nil
+}
+
+type scanCountingFile struct {
+	afero.File
+	fs      *scanCountingFs
+	scanned bool
+}
+
+func (f *scanCountingFile) Read(p []byte) (int, error) {
+	if !f.scanned {
+		f.scanned = true
+		f.fs.scans++
+	}
+	return f.File.Read(p)
+}
+
+func TestUpdate_ReadsTranscriptOncePerChunk(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	fake := llm.NewFake("# Overview\n\nCaught up")
+	newTestUseCase(h, fake, promptLines(1, 6))
+	fs := &scanCountingFs{Fs: h.FS, path: testTranscript}
+	uc := New(fs, h.Env, h, lock.New(fs), doc.NewUpdater(fs, h.Commander, h.Env, fake))
+
+	// Exercise
+	result, err := uc.Update(testOptions())
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Chunks)
+	// One count for Pending, one read per chunk and one final count of the empty tail
+	assert.Equal(t, 1+3+1, fs.scans)
+}
+
+func TestUpdate_NothingPending(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	fake := llm.NewFake("# Overview")
+	transcript := promptLines(1, 2)
+	uc := newTestUseCase(h, fake, transcript)
+	require.NoError(t, cursor.New(h.FS, h, testSessionPath).Advance(testTranscript, int64(len(transcript)), 2))
+
+	// Exercise
+	result, err := uc.Update(testOptions())
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Entries())
+	assert.Empty(t, fake.Requests())
+}
+
+func TestUpdate_FailsWhileBackgroundUpdateRuns(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	uc := newTestUseCase(h, llm.NewFake("# Overview"), promptLines(1, 2))
+	queue := jobs.NewQueue(h.FS, h, testSessionPath)
+	h.CreateDir(queue.Dir())
+	held, err := lock.New(h.FS).Acquire(queue.LockPath())
+	require.NoError(t, err)
+	defer held.Release()
+
+	// Exercise
+	_, err = uc.Update(testOptions())
+
+	// Verify
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "background doc update is running")
+}
+
+func TestRebuild_RegeneratesFromStart(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	fake := llm.NewFake("# Overview\n\nRebuilt")
+	transcript := promptLines(1, 3)
+	uc := newTestUseCase(h, fake, transcript)
+	h.WriteFile(testSessionPath+"/session-overview.md", "# Overview\n\nStale summary")
+	require.NoError(t, cursor.New(h.FS, h, testSessionPath).Advance(testTranscript, int64(len(transcript)), 3))
+
+	// Exercise
+	result, err := uc.Rebuild(testOptions())
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Entries())
+	assert.Equal(t, 2, result.Chunks)
+
+	requests := fake.Requests()
+	require.Len(t, requests, 2)
+	assert.Contains(t, requests[0].Prompt, "prompt 1")
+	assert.NotContains(t, requests[0].Prompt, "Stale summary", "the first chunk starts from an empty document")
+	assert.Contains(t, requests[1].Prompt, "prompt 3")
+	testutil.AssertFileContains(t, h.FS, testSessionPath+"/session-overview.md", "Rebuilt")
+
+	snapshots, err := history.New(h.FS, h, testSessionPath).List(doc.DefaultOutputFile)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1, "intermediate chunks are not snapshotted")
+	assert.Equal(t, TriggerRebuild, snapshots[0].Trigger)
+	saved, err := history.New(h.FS, h, testSessionPath).Read(snapshots[0])
+	require.NoError(t, err)
+	assert.Equal(t, "# Overview\n\nStale summary", string(saved))
+}
+
+func TestRebuild_FailureRestoresOverviewAndCursor(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	fake := llm.NewFake("# Overview\n\nPartial").WhenError("prompt 3", errors.New("rate limited"))
+	transcript := promptLines(1, 3)
+	uc := newTestUseCase(h, fake, transcript)
+	h.WriteFile(testSessionPath+"/session-overview.md", "# Overview\n\nOriginal")
+	cursors := cursor.New(h.FS, h, testSessionPath)
+	require.NoError(t, cursors.Advance(testTranscript, int64(len(transcript)), 3))
+
+	// Exercise
+	_, err := uc.Rebuild(testOptions())
+
+	// Verify
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rate limited")
+	testutil.AssertFileContains(t, h.FS, testSessionPath+"/session-overview.md", "Original")
+	c, ok, err := cursors.Get(testTranscript)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, 3, c.Line, "cursor is put back where it was")
+}
@@ -0,0 +1,49 @@
+// Package delete provides the use case for deleting sessions.
+// It removes the session directory and all artifacts stored in it.
+package delete
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+)
+
+// UseCase handles deletion of existing sessions
+type UseCase struct {
+	fs          afero.Fs
+	sessionsDir string
+}
+
+// New creates a new session deletion use case
+func New(fs afero.Fs, sessionsDir string) *UseCase {
+	return &UseCase{
+		fs:          fs,
+		sessionsDir: sessionsDir,
+	}
+}
+
+// Execute deletes a session by:
+// 1. Verifying the session directory exists
+// 2. Removing the session directory and everything in it
+// 3. Returning the path that was removed
+func (uc *UseCase) Execute(sessionName string) (sessionPath string, err error) {
+	if sessionName == "" || sessionName != filepath.Base(sessionName) {
+		return "", fmt.Errorf("invalid session name: %q", sessionName)
+	}
+
+	sessionPath = filepath.Join(uc.sessionsDir, sessionName)
+	exists, err := afero.DirExists(uc.fs, sessionPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to check session directory: %w", err)
+	}
+	if !exists {
+		return "", fmt.Errorf("session not found: %s", sessionName)
+	}
+
+	if err := uc.fs.RemoveAll(sessionPath); err != nil {
+		return "", fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	return sessionPath, nil
+}
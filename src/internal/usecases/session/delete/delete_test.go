@@ -0,0 +1,61 @@
+package delete
+
+import (
+	"path/filepath"
+	"testing"
+
+	"claudex/internal/testutil"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_Execute_RemovesSessionDirectory tests that the whole session folder is deleted
+func Test_Execute_RemovesSessionDirectory(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	sessionsDir := "/project/.claudex/sessions"
+	sessionName := "login-feature-aaaabbbb-cccc-dddd-eeee-ffffffffffff"
+	sessionPath := filepath.Join(sessionsDir, sessionName)
+	h.CreateSessionWithFiles(sessionPath, map[string]string{
+		".description":        "Login feature",
+		"session-overview.md": "# Overview",
+	})
+
+	// Exercise
+	uc := New(h.FS, sessionsDir)
+	removedPath, err := uc.Execute(sessionName)
+
+	// Verify
+	require.NoError(t, err)
+	require.Equal(t, sessionPath, removedPath)
+	testutil.AssertNoDirExists(t, h.FS, sessionPath)
+	testutil.AssertDirExists(t, h.FS, sessionsDir)
+}
+
+// Test_Execute_SessionNotFound tests error when the session does not exist
+func Test_Execute_SessionNotFound(t *testing.T) {
+	h := testutil.NewTestHarness()
+	sessionsDir := "/project/.claudex/sessions"
+	h.CreateDir(sessionsDir)
+
+	uc := New(h.FS, sessionsDir)
+	_, err := uc.Execute("missing-session")
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "session not found")
+}
+
+// Test_Execute_RejectsPathTraversal tests that names containing separators are rejected
+func Test_Execute_RejectsPathTraversal(t *testing.T) {
+	h := testutil.NewTestHarness()
+	sessionsDir := "/project/.claudex/sessions"
+	h.CreateDir(sessionsDir)
+	h.CreateDir("/project/src")
+
+	uc := New(h.FS, sessionsDir)
+	_, err := uc.Execute("../../src")
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid session name")
+	testutil.AssertDirExists(t, h.FS, "/project/src")
+}
@@ -10,7 +10,7 @@ import (
 	"time"
 
 	"claudex/internal/services/clock"
-	"claudex/internal/services/commander"
+	"claudex/internal/services/llm"
 	"claudex/internal/services/session"
 	"claudex/internal/services/uuid"
 
@@ -20,17 +20,17 @@ import (
 // UseCase handles the creation of new sessions
 type UseCase struct {
 	fs          afero.Fs
-	cmd         commander.Commander
+	llm         llm.Client
 	uuidGen     uuid.UUIDGenerator
 	clock       clock.Clock
 	sessionsDir string
 }
 
 // New creates a new session creation use case
-func New(fs afero.Fs, cmd commander.Commander, uuidGen uuid.UUIDGenerator, clk clock.Clock, sessionsDir string) *UseCase {
+func New(fs afero.Fs, client llm.Client, uuidGen uuid.UUIDGenerator, clk clock.Clock, sessionsDir string) *UseCase {
 	return &UseCase{
 		fs:          fs,
-		cmd:         cmd,
+		llm:         client,
 		uuidGen:     uuidGen,
 		clock:       clk,
 		sessionsDir: sessionsDir,
@@ -39,22 +39,37 @@ func New(fs afero.Fs, cmd commander.Commander, uuidGen uuid.UUIDGenerator, clk c
 
 // Execute creates a new session by:
 // 1. Generating a UUID for the session
-// 2. Generating session name from description (via Claude CLI or manual slug)
+// 2. Generating session name from description (via the LLM backend or manual slug)
 // 3. Creating session directory with metadata files
 // 4. Returning session info for launching Claude
 func (uc *UseCase) Execute(description string) (sessionName, sessionPath, claudeSessionID string, err error) {
+	return uc.ExecuteNamed(description, "")
+}
+
+// ExecuteNamed creates a new session like Execute, using name instead of a generated
+// slug when it is not empty. The name must be a slug (lowercase letters, digits and
+// hyphens) that no existing session uses.
+func (uc *UseCase) ExecuteNamed(description, name string) (sessionName, sessionPath, claudeSessionID string, err error) {
 	description = strings.TrimSpace(description)
 	if description == "" {
 		return "", "", "", fmt.Errorf("description cannot be empty")
 	}
+	if name != "" {
+		if err := uc.validateName(name); err != nil {
+			return "", "", "", err
+		}
+	}
 
 	// Generate UUID for the session upfront
 	claudeSessionID = uc.uuidGen.New()
 
-	// Generate session name using Claude CLI or fallback to manual slug
-	baseSessionName, err := session.GenerateNameWithCmd(uc.cmd, description)
-	if err != nil {
-		baseSessionName = session.CreateManualSlug(description)
+	// Use the given name, or generate one using the LLM backend or fallback to manual slug
+	baseSessionName := name
+	if baseSessionName == "" {
+		baseSessionName, err = session.GenerateName(uc.llm, description)
+		if err != nil {
+			baseSessionName = session.CreateManualSlug(description)
+		}
 	}
 
 	// Create final session name with Claude session ID
@@ -103,6 +118,10 @@ func (uc *UseCase) Execute(description string) (sessionName, sessionPath, claude
 
 Session just started. Waiting for first task...
 
+## Key Decisions
+
+(Decisions will appear here as work progresses)
+
 ## Key Documents
 
 (Documents will appear here as work progresses)
@@ -119,3 +138,21 @@ Session just started. Waiting for first task...
 
 	return sessionName, sessionPath, claudeSessionID, nil
 }
+
+// validateName checks that name is a slug and that no existing session uses it
+func (uc *UseCase) validateName(name string) error {
+	if session.CreateManualSlug(name) != name {
+		return fmt.Errorf("invalid session name %q: use lowercase letters, digits and hyphens (at most 50 characters)", name)
+	}
+
+	entries, err := afero.ReadDir(uc.fs, uc.sessionsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read sessions directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && session.StripClaudeSessionID(entry.Name()) == name {
+			return fmt.Errorf("session name %q is already used by %s", name, entry.Name())
+		}
+	}
+	return nil
+}
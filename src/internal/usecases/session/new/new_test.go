@@ -6,8 +6,10 @@ import (
 	"testing"
 	"time"
 
+	"claudex/internal/services/llm"
 	"claudex/internal/testutil"
 
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
 )
 
@@ -25,7 +27,7 @@ func Test_Execute_CreatesSessionWithMetadata(t *testing.T) {
 	h.UUIDs = []string{"aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"}
 
 	// Create usecase and execute
-	uc := New(h.FS, h.Commander, h, h, sessionsDir)
+	uc := New(h.FS, llm.NewCLI(h.Commander, h.Env, "haiku", 0), h, h, sessionsDir)
 	sessionName, sessionPath, claudeSessionID, err := uc.Execute("Add user authentication")
 
 	// Verify success
@@ -59,7 +61,7 @@ func Test_Execute_FallsBackToManualSlug(t *testing.T) {
 	h.UUIDs = []string{"11111111-2222-3333-4444-555555555555"}
 
 	// Create usecase and execute
-	uc := New(h.FS, h.Commander, h, h, sessionsDir)
+	uc := New(h.FS, llm.NewCLI(h.Commander, h.Env, "haiku", 0), h, h, sessionsDir)
 	sessionName, sessionPath, _, err := uc.Execute("Fix login bug in dashboard")
 
 	// Verify success with manual slug fallback
@@ -88,7 +90,7 @@ func Test_Execute_HandlesCollision(t *testing.T) {
 	h.UUIDs = []string{"aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"}
 
 	// Create usecase and execute
-	uc := New(h.FS, h.Commander, h, h, sessionsDir)
+	uc := New(h.FS, llm.NewCLI(h.Commander, h.Env, "haiku", 0), h, h, sessionsDir)
 	sessionName, sessionPath, _, err := uc.Execute("My task description")
 
 	// Verify collision handling - should append counter
@@ -108,7 +110,7 @@ func Test_Execute_RejectsEmptyDescription(t *testing.T) {
 	sessionsDir := "/project/sessions"
 	h.CreateDir(sessionsDir)
 
-	uc := New(h.FS, h.Commander, h, h, sessionsDir)
+	uc := New(h.FS, llm.NewCLI(h.Commander, h.Env, "haiku", 0), h, h, sessionsDir)
 
 	// Test empty string
 	_, _, _, err := uc.Execute("")
@@ -136,7 +138,7 @@ func Test_Execute_GeneratesUniqueUUID(t *testing.T) {
 		"uuid-2222-2222-2222-222222222222",
 	}
 
-	uc := New(h.FS, h.Commander, h, h, sessionsDir)
+	uc := New(h.FS, llm.NewCLI(h.Commander, h.Env, "haiku", 0), h, h, sessionsDir)
 
 	// Create first session
 	_, _, uuid1, err := uc.Execute("First task")
@@ -164,7 +166,7 @@ func Test_Execute_InvokesClaudeCLI(t *testing.T) {
 	h.UUIDs = []string{"test-uuid"}
 
 	// Create usecase and execute
-	uc := New(h.FS, h.Commander, h, h, sessionsDir)
+	uc := New(h.FS, llm.NewCLI(h.Commander, h.Env, "haiku", 0), h, h, sessionsDir)
 	_, _, _, err := uc.Execute("My description for testing")
 
 	// Verify Claude CLI was invoked
@@ -187,7 +189,7 @@ func Test_Execute_CreatesSessionsDirectory(t *testing.T) {
 	h.UUIDs = []string{"test-uuid"}
 
 	// Create usecase and execute
-	uc := New(h.FS, h.Commander, h, h, sessionsDir)
+	uc := New(h.FS, llm.NewCLI(h.Commander, h.Env, "haiku", 0), h, h, sessionsDir)
 	_, sessionPath, _, err := uc.Execute("New feature description")
 
 	// Should succeed and create the directory structure
@@ -207,7 +209,7 @@ func Test_Execute_HandlesSpecialCharactersInDescription(t *testing.T) {
 	h.Commander.OnPattern("claude").Return(nil, fmt.Errorf("unavailable"))
 	h.UUIDs = []string{"test-uuid"}
 
-	uc := New(h.FS, h.Commander, h, h, sessionsDir)
+	uc := New(h.FS, llm.NewCLI(h.Commander, h.Env, "haiku", 0), h, h, sessionsDir)
 	sessionName, _, _, err := uc.Execute("Fix bug #123 (urgent!)")
 
 	// Verify slug is sanitized (manual fallback)
@@ -230,7 +232,7 @@ func Test_Execute_SetsCorrectFilePermissions(t *testing.T) {
 	h.Commander.OnPattern("claude", "-p").Return([]byte("test-task"), nil)
 	h.UUIDs = []string{"test-uuid"}
 
-	uc := New(h.FS, h.Commander, h, h, sessionsDir)
+	uc := New(h.FS, llm.NewCLI(h.Commander, h.Env, "haiku", 0), h, h, sessionsDir)
 	_, sessionPath, _, err := uc.Execute("Test task")
 
 	require.NoError(t, err)
@@ -245,3 +247,46 @@ func Test_Execute_SetsCorrectFilePermissions(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "-rw-r--r--", createdInfo.Mode().String())
 }
+
+// Test_ExecuteNamed_UsesGivenNameWithoutModel tests that a given name replaces the generated slug
+// The LLM backend is not asked for a name
+func Test_ExecuteNamed_UsesGivenNameWithoutModel(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	sessionsDir := "/project/sessions"
+	h.CreateDir(sessionsDir)
+	h.UUIDs = []string{"aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"}
+	fake := llm.NewFake("generated-name")
+
+	// Exercise
+	uc := New(h.FS, fake, h, h, sessionsDir)
+	sessionName, sessionPath, _, err := uc.ExecuteNamed("Add user authentication", "auth-rework")
+
+	// Verify
+	require.NoError(t, err)
+	require.Equal(t, "auth-rework-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee", sessionName)
+	testutil.AssertFileContains(t, h.FS, filepath.Join(sessionPath, ".description"), "Add user authentication")
+	require.Empty(t, fake.Requests())
+}
+
+// Test_ExecuteNamed_RejectsInvalidOrTakenName tests name validation against existing sessions
+// Nothing is created when the name is rejected
+func Test_ExecuteNamed_RejectsInvalidOrTakenName(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	sessionsDir := "/project/sessions"
+	h.CreateDir(filepath.Join(sessionsDir, "auth-rework-11111111-2222-3333-4444-555555555555"))
+	h.UUIDs = []string{"aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"}
+	uc := New(h.FS, llm.NewFake("generated-name"), h, h, sessionsDir)
+
+	// Exercise
+	_, _, _, takenErr := uc.ExecuteNamed("Another auth task", "auth-rework")
+	_, _, _, invalidErr := uc.ExecuteNamed("Another auth task", "Auth Rework!")
+
+	// Verify
+	require.ErrorContains(t, takenErr, "already used by auth-rework-11111111-2222-3333-4444-555555555555")
+	require.ErrorContains(t, invalidErr, "invalid session name")
+	entries, err := afero.ReadDir(h.FS, sessionsDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
@@ -7,8 +7,8 @@ import (
 	"fmt"
 	"path/filepath"
 
-	"claudex/internal/services/commander"
 	"claudex/internal/services/filesystem"
+	"claudex/internal/services/llm"
 	"claudex/internal/services/session"
 	"claudex/internal/services/uuid"
 
@@ -18,16 +18,16 @@ import (
 // UseCase handles forking of existing sessions
 type UseCase struct {
 	fs          afero.Fs
-	cmd         commander.Commander
+	llm         llm.Client
 	uuidGen     uuid.UUIDGenerator
 	sessionsDir string
 }
 
 // New creates a new fork use case
-func New(fs afero.Fs, cmd commander.Commander, uuidGen uuid.UUIDGenerator, sessionsDir string) *UseCase {
+func New(fs afero.Fs, client llm.Client, uuidGen uuid.UUIDGenerator, sessionsDir string) *UseCase {
 	return &UseCase{
 		fs:          fs,
-		cmd:         cmd,
+		llm:         client,
 		uuidGen:     uuidGen,
 		sessionsDir: sessionsDir,
 	}
@@ -35,7 +35,7 @@ func New(fs afero.Fs, cmd commander.Commander, uuidGen uuid.UUIDGenerator, sessi
 
 // Execute forks a session with a new description by:
 // 1. Generating a new UUID for the forked session
-// 2. Generating a new session name from the description (via Claude CLI or manual slug)
+// 2. Generating a new session name from the description (via the LLM backend or manual slug)
 // 3. Copying the session directory
 // 4. Updating the .description file with the new description
 // 5. Returning the new session info
@@ -44,9 +44,9 @@ func (uc *UseCase) Execute(originalSessionName, description string) (sessionName
 	claudeSessionID = uc.uuidGen.New()
 
 	// Generate new session name from description (like new session creation)
-	baseSessionName, err := session.GenerateNameWithCmd(uc.cmd, description)
+	baseSessionName, err := session.GenerateName(uc.llm, description)
 	if err != nil {
-		// Fallback to manual slug if Claude API fails
+		// Fallback to manual slug if the LLM backend fails
 		baseSessionName = session.CreateManualSlug(description)
 	}
 
@@ -4,6 +4,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"claudex/internal/services/llm"
 	"claudex/internal/testutil"
 
 	"github.com/stretchr/testify/require"
@@ -30,7 +31,7 @@ func Test_Execute_CopiesDirectoryAndCreatesNewSession(t *testing.T) {
 	h.UUIDs = []string{"new-uuid-aaaa-bbbb-cccc-dddd-eeeeeeeeeeee"}
 
 	// Create usecase and exercise
-	uc := New(h.FS, h.Commander, h, sessionsDir)
+	uc := New(h.FS, llm.NewCLI(h.Commander, h.Env, "haiku", 0), h, sessionsDir)
 	newSessionName, newSessionPath, claudeSessionID, err := uc.Execute(
 		originalSessionName, "Refactor to OAuth",
 	)
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"path/filepath"
 
+	"claudex/internal/services/cursor"
 	"claudex/internal/services/filesystem"
 	"claudex/internal/services/session"
 	"claudex/internal/services/uuid"
@@ -34,7 +35,7 @@ func New(fs afero.Fs, uuidGen uuid.UUIDGenerator, sessionsDir string) *UseCase {
 // 1. Generating a new UUID for the fresh session
 // 2. Stripping the Claude session ID from the original session name to get the base name
 // 3. Copying the session directory
-// 4. Removing tracking files (.last-processed-line, etc.)
+// 4. Removing tracking files (.last-processed-line, transcript cursors, etc.)
 // 5. Resetting the doc update counter
 // 6. Deleting the original session directory
 // 7. Returning the new session info
@@ -59,6 +60,7 @@ func (uc *UseCase) Execute(originalSessionName string) (sessionName, sessionPath
 	trackingFiles := []string{
 		filepath.Join(sessionPath, ".last-processed-line-overview"),
 		filepath.Join(sessionPath, ".last-processed-line"),
+		filepath.Join(sessionPath, cursor.StoreFile),
 	}
 	for _, f := range trackingFiles {
 		uc.fs.Remove(f) // Ignore errors - file may not exist
@@ -25,6 +25,7 @@ func Test_Execute_CopiesAndDeletesOriginal(t *testing.T) {
 		".created":                      "2024-01-10T10:00:00Z",
 		".last-processed-line-overview": "50",
 		".last-processed-line":          "100",
+		".transcript-cursors.json":      "{}",
 		".doc-update-counter":           "5",
 		"session-history.md":            "# History",
 	})
@@ -52,6 +53,7 @@ func Test_Execute_CopiesAndDeletesOriginal(t *testing.T) {
 	// Tracking files REMOVED
 	testutil.AssertNoFileExists(t, h.FS, filepath.Join(newSessionPath, ".last-processed-line-overview"))
 	testutil.AssertNoFileExists(t, h.FS, filepath.Join(newSessionPath, ".last-processed-line"))
+	testutil.AssertNoFileExists(t, h.FS, filepath.Join(newSessionPath, ".transcript-cursors.json"))
 
 	// Counter reset
 	testutil.AssertFileExists(t, h.FS, filepath.Join(newSessionPath, ".doc-update-counter"))
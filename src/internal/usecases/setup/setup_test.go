@@ -1,9 +1,13 @@
 package setup
 
 import (
+	"encoding/json"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
 
+	"claudex/internal/services/settings"
 	"claudex/internal/testutil"
 
 	"github.com/spf13/afero"
@@ -381,6 +385,10 @@ func Test_Execute_GeneratesAbsoluteHookPaths(t *testing.T) {
 	testutil.AssertFileContains(t, h.FS, "/project/.claude/settings.local.json", "/project/.claude/hooks/pre-tool-use.sh")
 	testutil.AssertFileContains(t, h.FS, "/project/.claude/settings.local.json", "/project/.claude/hooks/post-tool-use.sh")
 	testutil.AssertFileContains(t, h.FS, "/project/.claude/settings.local.json", "/project/.claude/hooks/auto-doc-updater.sh")
+	testutil.AssertFileContains(t, h.FS, "/project/.claude/settings.local.json", "/project/.claude/hooks/session-start.sh")
+	testutil.AssertFileContains(t, h.FS, "/project/.claude/settings.local.json", "/project/.claude/hooks/user-prompt-submit.sh")
+	testutil.AssertFileContains(t, h.FS, "/project/.claude/settings.local.json", "/project/.claude/hooks/pre-compact.sh")
+	testutil.AssertFileContains(t, h.FS, "/project/.claude/settings.local.json", "/project/.claude/hooks/stop.sh")
 
 	// Verify - no relative paths remain
 	content, err := afero.ReadFile(h.FS, "/project/.claude/settings.local.json")
@@ -634,3 +642,88 @@ func Test_Execute_CleansUpDeprecatedAgents(t *testing.T) {
 	// Assert - custom user agents should be preserved
 	testutil.AssertFileExists(t, h.FS, "/project/.claude/agents/custom-agent.md")
 }
+
+// hookMatchers returns the matchers of the event's entries that run the given hook script
+func hookMatchers(t *testing.T, h *testutil.TestHarness, event, script string) []string {
+	t.Helper()
+	content, err := afero.ReadFile(h.FS, "/project/.claude/settings.local.json")
+	require.NoError(t, err)
+	var generated settings.Settings
+	require.NoError(t, json.Unmarshal(content, &generated))
+
+	var matchers []string
+	for _, entry := range generated.Hooks[event] {
+		for _, hook := range entry.Hooks {
+			if filepath.Base(hook.Command) == script {
+				matchers = append(matchers, entry.Matcher)
+			}
+		}
+	}
+	return matchers
+}
+
+// Test_Execute_RoutesEveryToolThroughPreToolUse verifies the generated settings run
+// pre-tool-use.sh for every tool, so the tool policy applies beyond Task calls, also
+// when settings from an older setup limited it to Task
+func Test_Execute_RoutesEveryToolThroughPreToolUse(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+	}{
+		{"fresh setup", ""},
+		{"settings limited to Task", `{"permissions":{"allow":[],"deny":[],"ask":[]},"hooks":{"PreToolUse":[
+  {"matcher":"Task","hooks":[{"type":"command","command":"/project/.claude/hooks/pre-tool-use.sh"}]}
+]}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			h := testutil.NewTestHarness()
+			h.Env.Set("HOME", "/home/user")
+			h.WriteFile("/project/package.json", `{"name": "test"}`)
+			if tt.existing != "" {
+				h.WriteFile("/project/.claude/settings.local.json", tt.existing)
+			}
+
+			// Exercise
+			require.NoError(t, New(h.FS, h.Env).Execute("/project", false))
+
+			// Verify - a single entry without matcher runs the hook for every tool
+			assert.Equal(t, []string{""}, hookMatchers(t, h, "PreToolUse", "pre-tool-use.sh"))
+		})
+	}
+}
+
+// matchesTool reports whether a hook matcher selects the tool the way Claude Code does:
+// an empty matcher or "*" selects every tool, otherwise the matcher is a regular expression
+func matchesTool(t *testing.T, matcher, tool string) bool {
+	t.Helper()
+	if matcher == "" || matcher == "*" {
+		return true
+	}
+	re, err := regexp.Compile("^(?:" + matcher + ")$")
+	require.NoError(t, err)
+	return re.MatchString(tool)
+}
+
+// Test_Execute_SecretScanToolsReachPreToolUse verifies Claude Code calls pre-tool-use.sh,
+// and with it the secret scan, for every tool whose input is scanned
+func Test_Execute_SecretScanToolsReachPreToolUse(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	h.Env.Set("HOME", "/home/user")
+	h.WriteFile("/project/package.json", `{"name": "test"}`)
+
+	// Exercise
+	require.NoError(t, New(h.FS, h.Env).Execute("/project", false))
+
+	// Verify
+	matchers := hookMatchers(t, h, "PreToolUse", "pre-tool-use.sh")
+	for _, tool := range []string{"Write", "Edit", "MultiEdit", "Bash"} {
+		routed := false
+		for _, matcher := range matchers {
+			routed = routed || matchesTool(t, matcher, tool)
+		}
+		assert.True(t, routed, "%s calls must run pre-tool-use.sh", tool)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"claudex/internal/services/hooksetup"
 	"claudex/internal/services/preferences"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -20,6 +21,13 @@ type mockHookService struct {
 func (m *mockHookService) IsGitRepo() bool   { return m.isGitRepo }
 func (m *mockHookService) IsInstalled() bool { return m.isInstall }
 func (m *mockHookService) Install() error    { return m.installErr }
+func (m *mockHookService) InstallHooks(triggers []hooksetup.Trigger) error {
+	return m.installErr
+}
+func (m *mockHookService) UninstallHooks(triggers []hooksetup.Trigger) ([]string, error) {
+	return nil, nil
+}
+func (m *mockHookService) Status() hooksetup.Status { return hooksetup.Status{} }
 
 // mockPrefService is a mock implementation of preferences.Service
 type mockPrefService struct {
@@ -1,6 +1,6 @@
 // Package updatedocs provides the usecase for updating index.md documentation
 // based on git history changes. It orchestrates git operations, locking,
-// tracking, and Claude invocations to keep documentation current.
+// tracking, and LLM invocations to keep documentation current.
 package updatedocs
 
 import (
@@ -8,12 +8,15 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"time"
 
 	"claudex/internal/doc/rangeupdater"
 	"claudex/internal/services/commander"
+	"claudex/internal/services/config"
 	"claudex/internal/services/doctracking"
 	"claudex/internal/services/env"
 	"claudex/internal/services/git"
+	"claudex/internal/services/llm"
 	"claudex/internal/services/lock"
 	"claudex/internal/services/paths"
 
@@ -24,14 +27,16 @@ import (
 type UpdateDocsUseCase struct {
 	fs  afero.Fs
 	cmd commander.Commander
+	llm llm.Client
 	env env.Environment
 }
 
 // New creates a new UpdateDocsUseCase instance with the given dependencies
-func New(fs afero.Fs, cmd commander.Commander, env env.Environment) *UpdateDocsUseCase {
+func New(fs afero.Fs, cmd commander.Commander, client llm.Client, env env.Environment) *UpdateDocsUseCase {
 	return &UpdateDocsUseCase{
 		fs:  fs,
 		cmd: cmd,
+		llm: client,
 		env: env,
 	}
 }
@@ -45,64 +50,132 @@ func New(fs afero.Fs, cmd commander.Commander, env env.Environment) *UpdateDocsU
 //
 // Returns an error if the update fails.
 func (uc *UpdateDocsUseCase) Execute(projectDir string) error {
+	updater, err := uc.newUpdater(projectDir)
+	if err != nil {
+		return err
+	}
+
+	// Run update
+	result, err := updater.Run()
+	if err != nil {
+		return fmt.Errorf("update failed: %w", err)
+	}
+
+	// Display result
+	displayResult(result)
+
+	return nil
+}
+
+// ExecutePending updates the index.md files affected by uncommitted changes instead of
+// a commit range: the staged changes (updated indexes are staged, for pre-commit hooks)
+// or the whole working tree. Tracking is not touched.
+func (uc *UpdateDocsUseCase) ExecutePending(projectDir string, scope git.Scope) error {
+	updater, err := uc.newUpdater(projectDir)
+	if err != nil {
+		return err
+	}
+
+	result, err := updater.RunPending(scope)
+	if err != nil {
+		return fmt.Errorf("update failed: %w", err)
+	}
+
+	displayResult(result)
+
+	return nil
+}
+
+// newUpdater wires the range updater for projectDir from its [docs.update] config
+func (uc *UpdateDocsUseCase) newUpdater(projectDir string) (*rangeupdater.RangeUpdater, error) {
 	// Use .claudex directory for tracking state
 	sessionPath := filepath.Join(projectDir, paths.ClaudexDir)
 
 	// Create services
 	gitSvc := git.New(uc.cmd)
 	lockSvc := lock.New(uc.fs)
-	trackingSvc := doctracking.New(uc.fs, sessionPath)
+
+	// Changed-file filter and propagation from [docs.update]
+	cfg, err := config.Load(uc.fs, filepath.Join(projectDir, paths.ConfigFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Merge-base seeding uses the configured default branch, else the detected one
+	defaultBranch := cfg.Docs.Update.DefaultBranch
+	if defaultBranch == "" {
+		if defaultBranch, err = gitSvc.GetDefaultBranch(); err != nil {
+			return nil, fmt.Errorf("failed to detect the default branch: %w", err)
+		}
+	}
+	trackingSvc := doctracking.New(uc.fs, sessionPath, defaultBranch)
 
 	// Configure updater
-	config := rangeupdater.RangeUpdaterConfig{
-		SessionPath:   sessionPath,
-		DefaultBranch: "main",
-		SkipPatterns:  []string{"*.md", "docs/**"},
+	updaterConfig := rangeupdater.RangeUpdaterConfig{
+		SessionPath:      sessionPath,
+		DefaultBranch:    defaultBranch,
+		IncludePatterns:  cfg.Docs.Update.Include,
+		ExcludePatterns:  cfg.Docs.Update.Exclude,
+		PropagationDepth: cfg.Docs.Update.PropagationDepth,
+		RootDir:          projectDir,
+		Workers:          cfg.Docs.Update.Workers,
+		IndexTimeout:     time.Duration(cfg.Docs.Update.TimeoutSeconds) * time.Second,
 	}
 
-	// Create updater
-	updater := rangeupdater.New(
-		config,
+	return rangeupdater.New(
+		updaterConfig,
 		gitSvc,
 		lockSvc,
 		trackingSvc,
-		uc.cmd,
+		uc.llm,
 		uc.fs,
 		uc.env,
-	)
-
-	// Run update
-	result, err := updater.Run()
-	if err != nil {
-		return fmt.Errorf("update failed: %w", err)
-	}
-
-	// Display result
-	displayResult(result)
-
-	return nil
+	), nil
 }
 
 // displayResult prints the update result to stdout
 func displayResult(result *rangeupdater.UpdateResult) {
 	switch result.Status {
-	case "success":
-		if len(result.AffectedIndexes) == 0 {
+	case "success", "partial":
+		if len(result.Indexes) == 0 {
 			fmt.Printf("✓ Documentation update completed\n")
 			if result.Reason != "" {
 				fmt.Printf("  %s\n", result.Reason)
 			}
+			return
+		}
+
+		if result.Status == "partial" {
+			fmt.Printf("⚠ Documentation update partially completed (%s)\n", result.ProcessedRange)
 		} else {
 			fmt.Printf("✓ Documentation update completed (%s)\n", result.ProcessedRange)
-			fmt.Printf("  Updated %d index.md file(s):\n", len(result.AffectedIndexes))
-			for _, idx := range result.AffectedIndexes {
-				// Make path relative to current directory for cleaner output
-				rel, err := filepath.Rel(".", idx)
-				if err != nil {
-					rel = idx
-				}
-				fmt.Printf("    - %s\n", rel)
+		}
+		if result.Branch != "" {
+			fmt.Printf("  Branch: %s\n", result.Branch)
+		}
+		fmt.Printf("  Processed %d index.md file(s):\n", len(result.Indexes))
+		for _, idx := range result.Indexes {
+			// Make path relative to current directory for cleaner output
+			rel, err := filepath.Rel(".", idx.Path)
+			if err != nil {
+				rel = idx.Path
 			}
+			duration := idx.Duration.Round(100 * time.Millisecond)
+			if idx.Status == rangeupdater.IndexFailed {
+				fmt.Printf("    ✗ %s (%s): %s\n", rel, duration, idx.Error)
+			} else {
+				fmt.Printf("    - %s: %s (%s)\n", rel, idx.Status, duration)
+			}
+		}
+		for _, path := range result.Unstaged {
+			rel, err := filepath.Rel(".", path)
+			if err != nil {
+				rel = path
+			}
+			fmt.Printf("  Not staged, it had unstaged edits (review and stage it yourself): %s\n", rel)
+		}
+		if result.Reason != "" {
+			fmt.Printf("  %s\n", result.Reason)
 		}
 
 	case "skipped":